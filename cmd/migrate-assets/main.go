@@ -0,0 +1,124 @@
+// migrate-assets ย้ายไฟล์ asset ที่เก็บบน disk (storage = "local") ขึ้น S3/MinIO แล้วแก้ Asset.URL
+//
+//	STORAGE_DRIVER ไม่ต้องเป็น s3 ระหว่างย้าย (ใช้แค่ค่า S3_* และ UPLOAD_BASE_DIR)
+//	go run ./cmd/migrate-assets -dry-run
+//	go run ./cmd/migrate-assets -delete-local
+//
+// รันซ้ำได้: asset ที่ย้ายแล้วไม่ถูกหยิบอีก, ตัวที่พังรอบก่อนจะถูกลองใหม่
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/Marugo/birdlax/internal/config"
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+	contentrepo "github.com/Marugo/birdlax/internal/modules/content/repo"
+	contentstorage "github.com/Marugo/birdlax/internal/modules/content/storage"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "แสดงรายการที่จะย้ายโดยไม่อัปโหลด/แก้ DB")
+	deleteLocal := flag.Bool("delete-local", false, "ลบไฟล์บน disk หลังย้ายสำเร็จ")
+	batch := flag.Int("batch", 50, "จำนวน asset ต่อรอบ query")
+	flag.Parse()
+
+	if err := config.Init(); err != nil {
+		log.Fatalf("config init error: %v", err)
+	}
+	ctx := context.Background()
+	cfg := config.Storage()
+	s3, err := contentstorage.NewS3(cfg.S3)
+	if err != nil {
+		log.Fatalf("storage: %v", err)
+	}
+	if err := s3.Check(ctx); err != nil {
+		log.Fatal(err)
+	}
+	assets := contentrepo.NewAssetRepo(config.DB)
+
+	var moved, missing, failed int
+	afterID := ""
+	for {
+		rows, err := assets.ListByStorage(contentstorage.BackendLocal, afterID, *batch)
+		if err != nil {
+			log.Fatalf("list assets: %v", err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		for i := range rows {
+			a := &rows[i]
+			afterID = a.ID
+			err := migrate(ctx, assets, s3, cfg.LocalDir, a, *dryRun, *deleteLocal)
+			switch {
+			case err == nil:
+				moved++
+			case errors.Is(err, os.ErrNotExist):
+				missing++
+				log.Printf("skip %s: %v", a.ID, err)
+			default:
+				failed++
+				log.Printf("fail %s: %v", a.ID, err)
+			}
+		}
+	}
+
+	verb := "moved"
+	if *dryRun {
+		verb = "would move"
+	}
+	log.Printf("%s %d, missing file %d, failed %d", verb, moved, missing, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// assetMover ส่วนของ AssetRepo ที่ migrate ใช้
+type assetMover interface {
+	MoveStorage(id, from, to, filename, url string) error
+}
+
+func migrate(ctx context.Context, assets assetMover, s3 *contentstorage.S3, localDir string,
+	a *models.Asset, dryRun, deleteLocal bool) error {
+	// Filename ของ local = ชื่อไฟล์ใน localDir (Base กัน path แปลกๆ ใน DB)
+	name := filepath.Base(a.Filename)
+	src := filepath.Join(localDir, name)
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	key := s3.Key(name)
+	if dryRun {
+		log.Printf("%s: %s (%d bytes) -> %s", a.ID, src, st.Size(), s3.URL(key))
+		return nil
+	}
+	n, err := s3.Put(ctx, key, f, st.Size(), a.MimeType)
+	if err != nil {
+		return err
+	}
+	if n != st.Size() {
+		return fmt.Errorf("uploaded %d of %d bytes", n, st.Size())
+	}
+	if err := assets.MoveStorage(a.ID, contentstorage.BackendLocal, contentstorage.BackendS3, key, s3.URL(key)); err != nil {
+		return fmt.Errorf("update asset: %w", err)
+	}
+	if deleteLocal {
+		// ย้ายสำเร็จแล้ว ลบไม่ได้ก็แค่เตือน
+		if err := os.Remove(src); err != nil {
+			log.Printf("warn %s: remove local file: %v", a.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+	contentstorage "github.com/Marugo/birdlax/internal/modules/content/storage"
+)
+
+type move struct{ id, from, to, filename, url string }
+
+type fakeMover struct {
+	moves []move
+	err   error
+}
+
+func (f *fakeMover) MoveStorage(id, from, to, filename, url string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.moves = append(f.moves, move{id, from, to, filename, url})
+	return nil
+}
+
+func newTestBucket(t *testing.T) *contentstorage.S3 {
+	t.Helper()
+	backend := s3mem.New()
+	if err := backend.CreateBucket("assets"); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(gofakes3.New(backend).Server())
+	t.Cleanup(srv.Close)
+	s3, err := contentstorage.NewS3(contentstorage.S3Config{
+		Endpoint:  strings.TrimPrefix(srv.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "assets",
+		AccessKey: "key",
+		SecretKey: "secret",
+		PathStyle: true,
+		Prefix:    "videos",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s3
+}
+
+// fetch อ่าน object ผ่าน URL ที่เขียนลง Asset.URL (gofakes3 ไม่บังคับ auth ตอน GET)
+func fetch(t *testing.T, url string) (int, string) {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(b)
+}
+
+func localAsset(t *testing.T, dir, name, content string) *models.Asset {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return &models.Asset{ID: "a-" + name, Filename: name, MimeType: "video/mp4", Storage: contentstorage.BackendLocal}
+}
+
+func TestMigrateMovesFileAndRewritesURL(t *testing.T) {
+	ctx := context.Background()
+	s3, dir := newTestBucket(t), t.TempDir()
+	mover := &fakeMover{}
+	a := localAsset(t, dir, "intro.mp4", "frames")
+
+	if err := migrate(ctx, mover, s3, dir, a, false, false); err != nil {
+		t.Fatal(err)
+	}
+	want := move{a.ID, contentstorage.BackendLocal, contentstorage.BackendS3, "videos/intro.mp4", s3.URL("videos/intro.mp4")}
+	if len(mover.moves) != 1 || mover.moves[0] != want {
+		t.Fatalf("moves = %+v", mover.moves)
+	}
+	if code, body := fetch(t, want.url); code != 200 || body != "frames" {
+		t.Fatalf("object = %d %q", code, body)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "intro.mp4")); err != nil {
+		t.Fatalf("local file removed without -delete-local: %v", err)
+	}
+}
+
+func TestMigrateDeleteLocal(t *testing.T) {
+	s3, dir := newTestBucket(t), t.TempDir()
+	a := localAsset(t, dir, "x.mp4", "data")
+
+	if err := migrate(context.Background(), &fakeMover{}, s3, dir, a, false, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "x.mp4")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("local file still there: %v", err)
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	s3, dir := newTestBucket(t), t.TempDir()
+	mover := &fakeMover{}
+	a := localAsset(t, dir, "d.mp4", "data")
+
+	if err := migrate(context.Background(), mover, s3, dir, a, true, true); err != nil {
+		t.Fatal(err)
+	}
+	if len(mover.moves) != 0 {
+		t.Fatalf("dry run updated DB: %+v", mover.moves)
+	}
+	if code, _ := fetch(t, s3.URL("videos/d.mp4")); code != 404 {
+		t.Fatalf("dry run uploaded: %d", code)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "d.mp4")); err != nil {
+		t.Fatalf("dry run removed local file: %v", err)
+	}
+}
+
+func TestMigrateFailures(t *testing.T) {
+	ctx := context.Background()
+	s3, dir := newTestBucket(t), t.TempDir()
+
+	missing := &models.Asset{ID: "gone", Filename: "gone.mp4", Storage: contentstorage.BackendLocal}
+	if err := migrate(ctx, &fakeMover{}, s3, dir, missing, false, true); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("missing file: err = %v", err)
+	}
+
+	// DB อัปเดตไม่ได้ => ไม่ลบไฟล์ local (รอบหน้าลองใหม่ได้)
+	a := localAsset(t, dir, "keep.mp4", "data")
+	if err := migrate(ctx, &fakeMover{err: errors.New("db down")}, s3, dir, a, false, true); err == nil {
+		t.Fatal("db failure: want error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "keep.mp4")); err != nil {
+		t.Fatalf("local file removed after failed update: %v", err)
+	}
+}
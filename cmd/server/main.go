@@ -7,12 +7,16 @@ import (
 
 	"github.com/Marugo/birdlax/internal/app"
 	"github.com/Marugo/birdlax/internal/config"
+	"github.com/Marugo/birdlax/internal/shared/security"
 )
 
 func main() {
 	if err := config.Init(); err != nil {
 		log.Fatalf("config init error: %v", err)
 	}
+	if err := security.InitKeys(); err != nil {
+		log.Fatalf("jwt keys error: %v", err)
+	}
 
 	appSrv := fiber.New(fiber.Config{
 		AppName:      config.AppName(),
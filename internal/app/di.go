@@ -1,9 +1,15 @@
 package app
 
 import (
+	"context"
+	"log"
 	"os"
+	"time"
 
 	"github.com/Marugo/birdlax/internal/config"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/Marugo/birdlax/internal/shared/notify"
+	"github.com/Marugo/birdlax/internal/shared/security"
 
 	// auth & users
 	"github.com/Marugo/birdlax/internal/modules/auth"
@@ -13,6 +19,30 @@ import (
 	usersrepo "github.com/Marugo/birdlax/internal/modules/user/repo"
 	usersvc "github.com/Marugo/birdlax/internal/modules/user/service"
 
+	// audit
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditrepo "github.com/Marugo/birdlax/internal/modules/audit/repo"
+	auditsvc "github.com/Marugo/birdlax/internal/modules/audit/service"
+
+	// authorization (role -> permission)
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	authzrepo "github.com/Marugo/birdlax/internal/modules/authz/repo"
+	authzsvc "github.com/Marugo/birdlax/internal/modules/authz/service"
+
+	// api keys
+	"github.com/Marugo/birdlax/internal/modules/apikey"
+	apikeyrepo "github.com/Marugo/birdlax/internal/modules/apikey/repo"
+	apikeysvc "github.com/Marugo/birdlax/internal/modules/apikey/service"
+
+	// scim provisioning
+	scimhandler "github.com/Marugo/birdlax/internal/modules/scim/handler"
+	scimsvc "github.com/Marugo/birdlax/internal/modules/scim/service"
+
+	// personal data (PDPA export / anonymize)
+	privacyhandler "github.com/Marugo/birdlax/internal/modules/privacy/handler"
+	privacyrepo "github.com/Marugo/birdlax/internal/modules/privacy/repo"
+	privacysvc "github.com/Marugo/birdlax/internal/modules/privacy/service"
+
 	// content
 	contenthandler "github.com/Marugo/birdlax/internal/modules/content/handler"
 	contentrepo "github.com/Marugo/birdlax/internal/modules/content/repo"
@@ -31,10 +61,15 @@ import (
 )
 
 type Deps struct {
-	UserSvc user.Service
-	AuthSvc auth.Service
+	UserSvc   user.Service
+	AuthSvc   auth.Service
+	APIKeySvc apikey.Service
+	AuditSvc  audit.Service
+	AuthzSvc  authz.Service
 
 	// HTTP handlers
+	SCIMHTTP         *scimhandler.HTTPHandler
+	PrivacyHTTP      *privacyhandler.HTTPHandler
 	ContentHTTP      *contenthandler.Handler
 	AssessHTTP       *assesshandler.Handler
 	AttemptHTTP      *assesshandler.AttemptHandler
@@ -42,31 +77,71 @@ type Deps struct {
 	CourseHTTP       *contenthandler.CourseHandler
 	CategoryHTTP     *contenthandler.CategoryHandler
 	MyHandler        *learnhdl.MyHandler
+	ManagerHandler   *learnhdl.ManagerHandler
 	AnalyticsHandler *learnhdl.AnalyticsHandler // <<< เพิ่มตรงนี้
 }
 
 func Build() Deps {
+	// ===== Audit =====
+	auds := auditsvc.NewService(auditrepo.NewGormRepository(config.DB))
+	middleware.SetImpersonationAuditor(auds) // ทุก request ภายใต้ impersonation ลง audit log
+
+	// ===== Permissions =====
+	azs := authzsvc.NewService(authzrepo.NewGormRepository(config.DB), auds)
+	if err := azs.EnsureDefaults(context.Background()); err != nil {
+		log.Fatalf("authz defaults: %v", err)
+	}
+	middleware.SetPermissionResolver(azs.Permissions) // RequirePermission ใช้ตัวนี้
+	middleware.SetHumanOnlyPermissions(authz.HumanOnly()...)
+
 	// ===== Users/Auth =====
 	ur := usersrepo.NewGormRepository(config.DB)
 	us := usersvc.NewService(ur)
 	ar := authrepo.NewGormRepository(config.DB)
-	as := authsvc.New(ur, ar)
+	middleware.SetImpersonationRevocations(ar) // DELETE /auth/impersonation มีผลทันที
+	verifiers, err := authsvc.NewVerifiers(config.AuthProviders(), config.LDAP())
+	if err != nil {
+		log.Fatalf("auth providers: %v", err)
+	}
+	as := authsvc.New(ur, ar, notify.NewQueue(notify.FromEnv(), 256), auds, config.OIDC(), verifiers)
+
+	// token version check ใน AuthRequired (cache สั้นๆ ต่อ user)
+	security.SetTokenStateLookup(func(ctx context.Context, id string) (security.TokenState, error) {
+		u, err := ur.FindByID(ctx, id)
+		if err != nil {
+			return security.TokenState{}, err
+		}
+		return security.TokenState{Version: u.TokenVersion, Active: u.IsActive}, nil
+	}, tokenStateCacheTTL())
+
+	// ===== SCIM (sync user/แผนกจากระบบ HR) =====
+	scimHTTP := scimhandler.NewHTTPHandler(scimsvc.NewService(ur, us, as, config.SCIM().BaseURL))
+
+	// ===== Personal data (PDPA) =====
+	privacyHTTP := privacyhandler.NewHTTPHandler(privacysvc.NewService(privacyrepo.NewGormRepository(config.DB), auds))
+
+	// ===== Service accounts / API keys =====
+	aks := apikeysvc.NewService(apikeyrepo.NewGormRepository(config.DB))
 
 	// ===== Content =====
-	uploadBaseDir := os.Getenv("UPLOAD_BASE_DIR")
-	if uploadBaseDir == "" {
-		uploadBaseDir = "/data/uploads/videos"
+	storageCfg := config.Storage()
+	uploader, err := contentstorage.New(storageCfg)
+	if err != nil {
+		log.Fatalf("storage: %v", err)
 	}
-	publicBaseURL := os.Getenv("PUBLIC_BASE_URL")
-	if publicBaseURL == "" {
-		publicBaseURL = "/static/videos"
+	signer, err := contentstorage.NewSigner(storageCfg, uploader)
+	if err != nil {
+		log.Fatalf("storage: %v", err)
 	}
-	uploader := &contentstorage.LocalFS{BaseDir: uploadBaseDir, BaseURL: publicBaseURL}
 
 	assetRepo := contentrepo.NewAssetRepo(config.DB)
 	lessonRepo := contentrepo.NewLessonRepo(config.DB)
-	contentSvc := contentservice.New(assetRepo, lessonRepo, uploader)
-	contentHTTP := contenthandler.New(contentSvc)
+	contentSvc := contentservice.New(assetRepo, lessonRepo, uploader, signer)
+
+	// course visibility (catalog + enrollment + lesson/asset)
+	courseRepo := contentrepo.NewCourseRepo(config.DB)
+	accessSvc := contentservice.NewAccessService(courseRepo, contentrepo.NewCourseAccessRepo(config.DB))
+	contentHTTP := contenthandler.New(contentSvc, accessSvc)
 
 	// ===== Assessment =====
 	assRepo := assessrepo.New(config.DB)
@@ -84,34 +159,42 @@ func Build() Deps {
 	myCoursesSvc := learnsvc.NewMyCoursesService(myCoursesRepo)
 	myHandler := learnhdl.NewMyHandler(myCoursesSvc)
 
+	// Team learning data (for /manager endpoints)
+	managerSvc := learnsvc.NewManagerService(learnrepo.NewManagerRepo(config.DB), myCoursesRepo)
+	managerHandler := learnhdl.NewManagerHandler(managerSvc)
+
 	// Metrics (analytics)
 	metricsRepo := learnrepo.NewMetricsRepo(config.DB)
 	metricsSvc := learnsvc.NewMetricsService(metricsRepo)
 	analyticsHandler := learnhdl.NewAnalyticsHandler(metricsSvc)
 
 	// Learning service (main)
-	ls := learnsvc.New(lr, metricsSvc)
+	ls := learnsvc.New(lr, metricsSvc, accessSvc)
 	lh := learnhdl.New(ls)
 
 	// Attempt service (assessment attempts) — ปรับตาม signature ของคุณ
 	// ถ้า NewAttemptService ต้องการ (assRepo, attRepo) เป็นอันพอ
 	// attSvc := assesssvc.NewAttemptService(assRepo, attRepo)
-	attSvc := assesssvc.NewAttemptService(assRepo, attRepo, lr, metricsSvc)
+	attSvc := assesssvc.NewAttemptService(assRepo, attRepo, lr, metricsSvc, accessSvc)
 	attHTTP := assesshandler.NewAttemptHandler(attSvc)
 
 	// Courses/Category
-	courseRepo := contentrepo.NewCourseRepo(config.DB)
 	moduleRepo := contentrepo.NewModuleRepo(config.DB)
 	categoryRepo := contentrepo.NewCategoryRepo(config.DB)
 	courseDeptRepo := contentrepo.NewCourseDeptRepo(config.DB)
 	courseSvc := contentservice.NewCourseService(courseRepo, moduleRepo, lessonRepo, categoryRepo, courseDeptRepo)
 	categorySvc := contentservice.NewCategoryService(categoryRepo, courseRepo)
-	courseHTTP := contenthandler.NewCourseHandler(courseSvc)
+	courseHTTP := contenthandler.NewCourseHandler(courseSvc, accessSvc)
 	categoryHTTP := contenthandler.NewCategoryHandler(categorySvc)
 
 	return Deps{
 		UserSvc:          us,
 		AuthSvc:          as,
+		APIKeySvc:        aks,
+		AuditSvc:         auds,
+		AuthzSvc:         azs,
+		SCIMHTTP:         scimHTTP,
+		PrivacyHTTP:      privacyHTTP,
 		ContentHTTP:      contentHTTP,
 		AssessHTTP:       assHTTP,
 		AttemptHTTP:      attHTTP,
@@ -119,6 +202,14 @@ func Build() Deps {
 		CourseHTTP:       courseHTTP,
 		CategoryHTTP:     categoryHTTP,
 		MyHandler:        myHandler,
+		ManagerHandler:   managerHandler,
 		AnalyticsHandler: analyticsHandler,
 	}
 }
+
+func tokenStateCacheTTL() time.Duration {
+	if d, err := time.ParseDuration(os.Getenv("AUTH_TOKEN_STATE_CACHE_TTL")); err == nil && d >= 0 {
+		return d
+	}
+	return 10 * time.Second
+}
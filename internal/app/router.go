@@ -3,7 +3,13 @@ package app
 import (
 	"os"
 
+	"github.com/Marugo/birdlax/internal/config"
+	apikeyhandler "github.com/Marugo/birdlax/internal/modules/apikey/handler"
+	audithandler "github.com/Marugo/birdlax/internal/modules/audit/handler"
 	authhandler "github.com/Marugo/birdlax/internal/modules/auth/handler"
+	authzhandler "github.com/Marugo/birdlax/internal/modules/authz/handler"
+	privacyhandler "github.com/Marugo/birdlax/internal/modules/privacy/handler"
+	scimhandler "github.com/Marugo/birdlax/internal/modules/scim/handler"
 	userhandler "github.com/Marugo/birdlax/internal/modules/user/handler"
 
 	assesshandler "github.com/Marugo/birdlax/internal/modules/assessment/handler"
@@ -37,14 +43,9 @@ func Register(app *fiber.App) {
 	}))
 
 	// 2) Static videos: รองรับ Byte Range และใส่ Accept-Ranges ให้ชัด
-	publicBaseURL := os.Getenv("PUBLIC_BASE_URL")
-	if publicBaseURL == "" {
-		publicBaseURL = "/static/videos"
-	}
-	uploadBaseDir := os.Getenv("UPLOAD_BASE_DIR")
-	if uploadBaseDir == "" {
-		uploadBaseDir = "/data/uploads/videos"
-	}
+	// เสิร์ฟต่อแม้ใช้ S3 แล้ว: asset เก่าที่ยังไม่ได้ย้ายยังชี้มาที่นี่
+	storageCfg := config.Storage()
+	publicBaseURL, uploadBaseDir := storageCfg.LocalBaseURL, storageCfg.LocalDir
 
 	// เสิร์ฟไฟล์แบบ byte range (จำเป็นต่อการ seek/สตรีม)
 	app.Static(publicBaseURL, uploadBaseDir, fiber.Static{
@@ -74,13 +75,22 @@ func Register(app *fiber.App) {
 	api := app.Group("/api/v1")
 
 	// Public
-	authHTTP := authhandler.NewHTTPHandler(deps.AuthSvc, deps.UserSvc)
+	authHTTP := authhandler.NewHTTPHandler(deps.AuthSvc, deps.UserSvc, config.OIDC())
 	authhandler.Register(api, authHTTP)
+	authhandler.RegisterWellKnown(app, authHTTP)
+
+	// SCIM 2.0 สำหรับระบบ HR (bearer token ของตัวเอง ไม่ใช้ JWT/API key)
+	scimhandler.Register(app, deps.SCIMHTTP, config.SCIM())
 
-	// Protected
-	protected := api.Group("", middleware.AuthRequired())
+	// Protected (JWT ของคน หรือ API key ของ service account)
+	protected := api.Group("", middleware.AuthRequiredOrAPIKey(deps.APIKeySvc))
 
 	userhandler.Register(protected, deps.UserSvc)
+	authhandler.RegisterAdminRoutes(protected, authHTTP)
+	privacyhandler.Register(protected, deps.PrivacyHTTP)
+	apikeyhandler.Register(protected, deps.APIKeySvc)
+	audithandler.Register(protected, deps.AuditSvc)
+	authzhandler.Register(protected, deps.AuthzSvc)
 	contenthandler.Register(protected, deps.ContentHTTP)
 	assesshandler.Register(protected, deps.AssessHTTP)
 	learninghandler.Register(protected, deps.LearningHTTP)
@@ -88,6 +98,7 @@ func Register(app *fiber.App) {
 	assesshandler.RegisterAttemptRoutes(protected, deps.AttemptHTTP)
 	contenthandler.RegisterCategoryRoutes(api, deps.CategoryHTTP)
 	learninghandler.MyRegister(protected, deps.LearningHTTP, deps.MyHandler)
+	learninghandler.ManagerRegister(protected, deps.ManagerHandler)
 	learninghandler.RegisterAdminRoutes(protected, deps.AnalyticsHandler)
 
 }
@@ -12,7 +12,10 @@ import (
 	"github.com/joho/godotenv"
 	"gorm.io/gorm"
 
+	apikeymodels "github.com/Marugo/birdlax/internal/modules/apikey/models"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
 	authrepo "github.com/Marugo/birdlax/internal/modules/auth/repo"
+	authzmodels "github.com/Marugo/birdlax/internal/modules/authz/models"
 	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
@@ -37,7 +40,18 @@ func Init() error {
 	// Auto-migrate เฉพาะโมดูล user
 	if err := DB.AutoMigrate(
 		&usermodels.User{},
+		&usermodels.PasswordHistory{},
 		&authrepo.RefreshToken{},
+		&authrepo.LoginThrottle{},
+		&authrepo.PasswordResetToken{},
+		&authrepo.SecurityEvent{},
+		&authrepo.MFAFactor{},
+		&authrepo.MFARecoveryCode{},
+		&authrepo.RevokedImpersonation{},
+		&apikeymodels.ServiceAccount{},
+		&apikeymodels.APIKey{},
+		&auditmodels.AuditLog{},
+		&authzmodels.RolePermission{},
 		&contentmodels.Asset{},
 		&contentmodels.Lesson{},
 		&assessmentmodels.Assessment{},
@@ -53,12 +67,26 @@ func Init() error {
 		&usermodels.Department{},
 		&usermodels.UserDepartmentRole{},
 		&contentmodels.CourseDepartmentTarget{},
+		&contentmodels.CourseInvitation{},
 		&learningmodels.LearningMetric{},
 		&learningmodels.CourseOutcome{},
 	); err != nil {
 		return fmt.Errorf("auto-migrate: %w", err)
 	}
 
+	// แผนกที่มีอยู่ก่อนมี hierarchy = ระดับบนสุดทั้งหมด
+	if err := DB.Exec("UPDATE departments SET path = CONCAT('/', id, '/') WHERE path = '' OR path IS NULL").Error; err != nil {
+		return fmt.Errorf("backfill department paths: %w", err)
+	}
+
+	// สังกัดแผนกที่มีอยู่ก่อนมีวันที่มีผล: เริ่มวันที่สร้าง, แถวที่ถูกลบไปแล้ว = จบวันที่ลบ
+	if err := DB.Exec("UPDATE user_department_roles SET effective_from = created_at WHERE effective_from IS NULL").Error; err != nil {
+		return fmt.Errorf("backfill membership effective_from: %w", err)
+	}
+	if err := DB.Exec("UPDATE user_department_roles SET effective_to = deleted_at WHERE deleted_at IS NOT NULL AND effective_to IS NULL").Error; err != nil {
+		return fmt.Errorf("backfill membership effective_to: %w", err)
+	}
+
 	return nil
 }
 
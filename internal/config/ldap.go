@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+)
+
+// AuthProviders ลำดับ verifier ที่ใช้ตอน login เช่น "ldap,local" (default: local)
+func AuthProviders() []string {
+	var out []string
+	for _, p := range strings.Split(getEnv("AUTH_PROVIDERS", "local"), ",") {
+		if p = strings.TrimSpace(strings.ToLower(p)); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// LDAP อ่านค่า LDAP/AD จาก env
+//
+//	LDAP_GROUP_ROLES = "CN=LMS-Admins,OU=Groups,DC=corp,DC=local=>admin;CN=LMS-HR,OU=Groups,DC=corp,DC=local=>hr"
+func LDAP() auth.LDAPConfig {
+	timeout, err := time.ParseDuration(os.Getenv("LDAP_TIMEOUT"))
+	if err != nil {
+		timeout = 5 * time.Second
+	}
+	return auth.LDAPConfig{
+		URL:                os.Getenv("LDAP_URL"),
+		StartTLS:           getEnv("LDAP_START_TLS", "false") == "true",
+		InsecureSkipVerify: getEnv("LDAP_INSECURE_SKIP_VERIFY", "false") == "true",
+		BindDN:             os.Getenv("LDAP_BIND_DN"),
+		BindPassword:       os.Getenv("LDAP_BIND_PASSWORD"),
+		BaseDN:             os.Getenv("LDAP_BASE_DN"),
+		UserFilter:         getEnv("LDAP_USER_FILTER", "(&(objectClass=user)(sAMAccountName=%s))"),
+		GroupAttr:          getEnv("LDAP_GROUP_ATTR", "memberOf"),
+		GroupRoles:         parseGroupRoles(os.Getenv("LDAP_GROUP_ROLES")),
+		DefaultRole:        os.Getenv("LDAP_DEFAULT_ROLE"),
+		Timeout:            timeout,
+	}
+}
+
+func parseGroupRoles(s string) map[string]string {
+	out := map[string]string{}
+	for _, pair := range strings.Split(s, ";") {
+		group, role, ok := strings.Cut(pair, "=>")
+		if !ok {
+			continue
+		}
+		group = strings.ToLower(strings.TrimSpace(group))
+		if role = strings.TrimSpace(role); group != "" && role != "" {
+			out[group] = role
+		}
+	}
+	return out
+}
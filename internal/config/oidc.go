@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+)
+
+// OIDC อ่านค่า SSO จาก env; OIDC_ISSUER_URL ว่าง = ปิด
+func OIDC() auth.OIDCConfig {
+	scopes := strings.Fields(strings.ReplaceAll(getEnv("OIDC_SCOPES", "openid email profile"), ",", " "))
+	return auth.OIDCConfig{
+		IssuerURL:       os.Getenv("OIDC_ISSUER_URL"),
+		ClientID:        os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:    os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:     getEnv("OIDC_REDIRECT_URL", "http://localhost:3000/api/v1/auth/oidc/callback"),
+		Scopes:          scopes,
+		EmployeeIDClaim: getEnv("OIDC_EMPLOYEE_ID_CLAIM", "employee_id"),
+		AutoCreate:      getEnv("OIDC_AUTO_CREATE", "false") == "true",
+		DefaultRole:     getEnv("OIDC_DEFAULT_ROLE", "employee"),
+		PostLoginURL:    getEnv("OIDC_POST_LOGIN_URL", "http://localhost:8000/"),
+	}
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"strings"
+
+	"github.com/Marugo/birdlax/internal/modules/scim"
+)
+
+// SCIM อ่านค่า SCIM provisioning จาก env; SCIM_BEARER_TOKENS ว่าง = ปิด
+//
+//	SCIM_BEARER_TOKENS = "token-ปัจจุบัน,token-เก่าที่ยังหมุนไม่เสร็จ"
+func SCIM() scim.Config {
+	var tokens []string
+	for _, t := range strings.Split(os.Getenv("SCIM_BEARER_TOKENS"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return scim.Config{
+		Tokens:  tokens,
+		BaseURL: getEnv("SCIM_BASE_URL", "/scim/v2"),
+	}
+}
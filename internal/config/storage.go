@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	contentstorage "github.com/Marugo/birdlax/internal/modules/content/storage"
+)
+
+// Storage อ่านค่าที่เก็บไฟล์ asset จาก env; STORAGE_DRIVER=s3 ใช้ bucket (AWS/MinIO) แทน disk
+//
+//	S3_ENDPOINT=minio:9000 S3_BUCKET=birdlax S3_PATH_STYLE=true S3_USE_SSL=false
+func Storage() contentstorage.Config {
+	partMB, _ := strconv.ParseUint(getEnv("S3_PART_SIZE_MB", "16"), 10, 64)
+	ttlMin, _ := strconv.Atoi(getEnv("S3_SIGNED_URL_TTL_MIN", "15"))
+	return contentstorage.Config{
+		Driver:       getEnv("STORAGE_DRIVER", contentstorage.BackendLocal),
+		LocalDir:     getEnv("UPLOAD_BASE_DIR", "/data/uploads/videos"),
+		LocalBaseURL: getEnv("PUBLIC_BASE_URL", "/static/videos"),
+		S3: contentstorage.S3Config{
+			Endpoint:      os.Getenv("S3_ENDPOINT"),
+			Region:        os.Getenv("S3_REGION"),
+			Bucket:        os.Getenv("S3_BUCKET"),
+			AccessKey:     os.Getenv("S3_ACCESS_KEY"),
+			SecretKey:     os.Getenv("S3_SECRET_KEY"),
+			UseSSL:        getEnv("S3_USE_SSL", "true") == "true",
+			PathStyle:     getEnv("S3_PATH_STYLE", "false") == "true",
+			Prefix:        getEnv("S3_PREFIX", "videos"),
+			PublicBaseURL: os.Getenv("S3_PUBLIC_BASE_URL"),
+			PartSize:      partMB << 20,
+			SignedURLTTL:  time.Duration(ttlMin) * time.Minute,
+		},
+	}
+}
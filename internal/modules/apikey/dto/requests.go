@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+type ServiceAccountCreateRequest struct {
+	Name        string `json:"name"        validate:"required,max=100"`
+	Description string `json:"description" validate:"max=255"`
+	Role        string `json:"role"        validate:"omitempty,oneof=admin employee hr"`
+}
+
+type ServiceAccountUpdateRequest struct {
+	Name        *string `json:"name"        validate:"omitempty,max=100"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+	Role        *string `json:"role"        validate:"omitempty,oneof=admin employee hr"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type APIKeyCreateRequest struct {
+	Name      string     `json:"name"       validate:"max=100"`
+	Scopes    []string   `json:"scopes"     validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
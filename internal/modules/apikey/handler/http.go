@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/apikey"
+	"github.com/Marugo/birdlax/internal/modules/apikey/dto"
+	apiservice "github.com/Marugo/birdlax/internal/modules/apikey/service"
+	"github.com/Marugo/birdlax/internal/shared/response"
+)
+
+type HTTPHandler struct {
+	svc       apikey.Service
+	validator *validator.Validate
+}
+
+func NewHTTPHandler(s apikey.Service) *HTTPHandler {
+	return &HTTPHandler{svc: s, validator: validator.New()}
+}
+
+// ===================== Service accounts =====================
+
+func (h *HTTPHandler) List(c *fiber.Ctx) error {
+	items, err := h.svc.ListServiceAccounts(c.Context())
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, items)
+}
+
+func (h *HTTPHandler) Create(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+	var req dto.ServiceAccountCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	sa, err := h.svc.CreateServiceAccount(c.Context(), actorID, req.Name, req.Description, req.Role)
+	if err != nil {
+		if errors.Is(err, apiservice.ErrNameAlreadyExists) {
+			return response.Err(c, fiber.StatusConflict, "NAME_ALREADY_EXISTS", err.Error())
+		}
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	return response.OK(c, sa)
+}
+
+func (h *HTTPHandler) Get(c *fiber.Ctx) error {
+	sa, err := h.svc.GetServiceAccount(c.Context(), c.Params("id"))
+	if err != nil {
+		return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "service account not found")
+	}
+	return response.OK(c, sa)
+}
+
+func (h *HTTPHandler) Update(c *fiber.Ctx) error {
+	var req dto.ServiceAccountUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	sa, err := h.svc.UpdateServiceAccount(c.Context(), c.Params("id"), req.Name, req.Description, req.Role, req.IsActive)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "service account not found")
+		}
+		if errors.Is(err, apiservice.ErrNameAlreadyExists) {
+			return response.Err(c, fiber.StatusConflict, "NAME_ALREADY_EXISTS", err.Error())
+		}
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	return response.OK(c, sa)
+}
+
+func (h *HTTPHandler) Delete(c *fiber.Ctx) error {
+	if err := h.svc.DeleteServiceAccount(c.Context(), c.Params("id")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "service account not found")
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"deleted": true})
+}
+
+// ===================== API keys =====================
+
+func (h *HTTPHandler) ListKeys(c *fiber.Ctx) error {
+	items, err := h.svc.ListKeys(c.Context(), c.Params("id"))
+	if err != nil {
+		return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "service account not found")
+	}
+	out := make([]*dto.APIKeyResponse, 0, len(items))
+	for i := range items {
+		out = append(out, dto.FromKey(&items[i]))
+	}
+	return response.OK(c, out)
+}
+
+// POST /service-accounts/:id/keys — key ตัวเต็มแสดงครั้งเดียวในคำตอบนี้
+func (h *HTTPHandler) CreateKey(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+	var req dto.APIKeyCreateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	k, plain, err := h.svc.CreateKey(c.Context(), actorID, c.Params("id"), req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "service account not found")
+		}
+		if errors.Is(err, apiservice.ErrInvalidScope) {
+			return response.Err(c, fiber.StatusBadRequest, "INVALID_SCOPE", "scopes must look like <resource>.read, <resource>.write, <resource>.* or *")
+		}
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	out := dto.FromKey(k)
+	out.Key = plain
+	return response.OK(c, out)
+}
+
+func (h *HTTPHandler) RevokeKey(c *fiber.Ctx) error {
+	if err := h.svc.RevokeKey(c.Context(), c.Params("id"), c.Params("keyID")); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "api key not found")
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"revoked": true})
+}
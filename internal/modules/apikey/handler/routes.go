@@ -0,0 +1,24 @@
+package handler
+
+import (
+	"github.com/Marugo/birdlax/internal/modules/apikey"
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Register(r fiber.Router, svc apikey.Service) {
+	h := NewHTTPHandler(svc)
+
+	// ===== Service accounts & API keys (admin เท่านั้น และต้องเป็นคน ไม่ใช่ API key) =====
+	g := r.Group("/service-accounts", middleware.RequireUser(), middleware.DenyImpersonation(), middleware.RequirePermission(authz.PermAPIKeyManage))
+	g.Get("/", h.List)
+	g.Post("/", h.Create)
+	g.Get("/:id", h.Get)
+	g.Put("/:id", h.Update)
+	g.Delete("/:id", h.Delete)
+
+	g.Get("/:id/keys", h.ListKeys)
+	g.Post("/:id/keys", h.CreateKey)
+	g.Delete("/:id/keys/:keyID", h.RevokeKey)
+}
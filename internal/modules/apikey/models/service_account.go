@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// ServiceAccount ตัวตนของระบบภายนอก (HRIS, BI ...) ที่เรียก API ด้วย API key แทนคน
+type ServiceAccount struct {
+	ID          string          `gorm:"type:char(36);primaryKey" json:"id"`
+	Name        string          `gorm:"size:100;uniqueIndex;not null" json:"name"`
+	Description string          `gorm:"size:255" json:"description"`
+	Role        usermodels.Role `gorm:"type:varchar(32);not null;default:'employee'" json:"role"`
+	IsActive    bool            `gorm:"type:tinyint(1);default:1" json:"is_active"`
+	CreatedBy   string          `gorm:"size:36" json:"created_by"`
+
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (s *ServiceAccount) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	return nil
+}
+
+// APIKey รูปแบบ bk_<prefix>.<secret>; เก็บ prefix ไว้ค้นหา และเก็บเฉพาะ hash ของ secret
+type APIKey struct {
+	ID               string     `gorm:"type:char(36);primaryKey" json:"id"`
+	ServiceAccountID string     `gorm:"size:36;index;not null" json:"service_account_id"`
+	Name             string     `gorm:"size:100" json:"name"`
+	Prefix           string     `gorm:"size:16;uniqueIndex;not null" json:"prefix"`
+	SecretHash       string     `gorm:"size:64;not null" json:"-"`
+	Scopes           []string   `gorm:"serializer:json;type:text" json:"scopes"`
+	ExpiresAt        *time.Time `json:"expires_at"`
+	LastUsedAt       *time.Time `json:"last_used_at"`
+	LastUsedIP       string     `gorm:"size:64" json:"last_used_ip"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	CreatedBy        string     `gorm:"size:36" json:"created_by"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+func (k *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if k.ID == "" {
+		k.ID = uuid.NewString()
+	}
+	return nil
+}
+
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
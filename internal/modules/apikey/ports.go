@@ -0,0 +1,41 @@
+package apikey
+
+import (
+	"context"
+	"time"
+
+	apimodels "github.com/Marugo/birdlax/internal/modules/apikey/models"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+)
+
+type Repository interface {
+	// Service accounts
+	ListServiceAccounts(ctx context.Context) ([]apimodels.ServiceAccount, error)
+	GetServiceAccount(ctx context.Context, id string) (*apimodels.ServiceAccount, error)
+	CreateServiceAccount(ctx context.Context, sa *apimodels.ServiceAccount) error
+	UpdateServiceAccount(ctx context.Context, sa *apimodels.ServiceAccount) error
+	DeleteServiceAccount(ctx context.Context, id string) error // revoke key ทั้งหมดด้วย
+
+	// API keys
+	ListKeys(ctx context.Context, serviceAccountID string) ([]apimodels.APIKey, error)
+	FindKeyByPrefix(ctx context.Context, prefix string) (*apimodels.APIKey, error)
+	CreateKey(ctx context.Context, k *apimodels.APIKey) error
+	RevokeKey(ctx context.Context, serviceAccountID, keyID string) error
+	TouchKey(ctx context.Context, keyID, ip string, at, olderThan time.Time) error
+}
+
+type Service interface {
+	ListServiceAccounts(ctx context.Context) ([]apimodels.ServiceAccount, error)
+	GetServiceAccount(ctx context.Context, id string) (*apimodels.ServiceAccount, error)
+	CreateServiceAccount(ctx context.Context, actorID, name, description, role string) (*apimodels.ServiceAccount, error)
+	UpdateServiceAccount(ctx context.Context, id string, name, description, role *string, isActive *bool) (*apimodels.ServiceAccount, error)
+	DeleteServiceAccount(ctx context.Context, id string) error
+
+	ListKeys(ctx context.Context, serviceAccountID string) ([]apimodels.APIKey, error)
+	// CreateKey คืน key ตัวเต็ม (plaintext) ครั้งเดียวเท่านั้น
+	CreateKey(ctx context.Context, actorID, serviceAccountID, name string, scopes []string, expiresAt *time.Time) (*apimodels.APIKey, string, error)
+	RevokeKey(ctx context.Context, serviceAccountID, keyID string) error
+
+	// ใช้โดย middleware
+	middleware.APIKeyAuthenticator
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/apikey"
+	apimodels "github.com/Marugo/birdlax/internal/modules/apikey/models"
+)
+
+type gormRepo struct{ db *gorm.DB }
+
+func NewGormRepository(db *gorm.DB) apikey.Repository { return &gormRepo{db: db} }
+
+// ===== Service accounts =====
+
+func (r *gormRepo) ListServiceAccounts(ctx context.Context) ([]apimodels.ServiceAccount, error) {
+	var rows []apimodels.ServiceAccount
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&rows).Error
+	return rows, err
+}
+
+func (r *gormRepo) GetServiceAccount(ctx context.Context, id string) (*apimodels.ServiceAccount, error) {
+	var sa apimodels.ServiceAccount
+	if err := r.db.WithContext(ctx).First(&sa, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &sa, nil
+}
+
+func (r *gormRepo) CreateServiceAccount(ctx context.Context, sa *apimodels.ServiceAccount) error {
+	return r.db.WithContext(ctx).Create(sa).Error
+}
+
+func (r *gormRepo) UpdateServiceAccount(ctx context.Context, sa *apimodels.ServiceAccount) error {
+	return r.db.WithContext(ctx).Save(sa).Error
+}
+
+func (r *gormRepo) DeleteServiceAccount(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&apimodels.APIKey{}).
+			Where("service_account_id = ? AND revoked_at IS NULL", id).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&apimodels.ServiceAccount{}, "id = ?", id).Error
+	})
+}
+
+// ===== API keys =====
+
+func (r *gormRepo) ListKeys(ctx context.Context, serviceAccountID string) ([]apimodels.APIKey, error) {
+	var rows []apimodels.APIKey
+	err := r.db.WithContext(ctx).
+		Where("service_account_id = ?", serviceAccountID).
+		Order("created_at DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+func (r *gormRepo) FindKeyByPrefix(ctx context.Context, prefix string) (*apimodels.APIKey, error) {
+	var k apimodels.APIKey
+	if err := r.db.WithContext(ctx).First(&k, "prefix = ?", prefix).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *gormRepo) CreateKey(ctx context.Context, k *apimodels.APIKey) error {
+	return r.db.WithContext(ctx).Create(k).Error
+}
+
+func (r *gormRepo) RevokeKey(ctx context.Context, serviceAccountID, keyID string) error {
+	res := r.db.WithContext(ctx).Model(&apimodels.APIKey{}).
+		Where("id = ? AND service_account_id = ? AND revoked_at IS NULL", keyID, serviceAccountID).
+		Update("revoked_at", time.Now())
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// TouchKey อัปเดต last_used เฉพาะเมื่อค่าเดิมเก่ากว่า olderThan (ไม่เขียน DB ทุก request)
+func (r *gormRepo) TouchKey(ctx context.Context, keyID, ip string, at, olderThan time.Time) error {
+	return r.db.WithContext(ctx).Model(&apimodels.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyID, olderThan).
+		Updates(map[string]any{"last_used_at": at, "last_used_ip": ip}).Error
+}
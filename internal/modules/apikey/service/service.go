@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/apikey"
+	apimodels "github.com/Marugo/birdlax/internal/modules/apikey/models"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/identity"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+)
+
+var (
+	ErrInvalidAPIKey      = errors.New("invalid api key")
+	ErrInvalidScope       = errors.New("invalid scope")
+	ErrNameAlreadyExists  = errors.New("service account name already exists")
+	ErrServiceAccountGone = errors.New("service account is inactive")
+)
+
+// scope รูปแบบ <resource>.<read|write|*> หรือ "*"
+var scopePattern = regexp.MustCompile(`^([a-z][a-z0-9_-]*\.(read|write|\*)|\*)$`)
+
+// อัปเดต last_used_at อย่างมากนาทีละครั้งต่อ key
+const touchInterval = time.Minute
+
+type svc struct{ repo apikey.Repository }
+
+func NewService(r apikey.Repository) apikey.Service { return &svc{repo: r} }
+
+// ===== Service accounts =====
+
+func (s *svc) ListServiceAccounts(ctx context.Context) ([]apimodels.ServiceAccount, error) {
+	return s.repo.ListServiceAccounts(ctx)
+}
+
+func (s *svc) GetServiceAccount(ctx context.Context, id string) (*apimodels.ServiceAccount, error) {
+	return s.repo.GetServiceAccount(ctx, id)
+}
+
+func (s *svc) CreateServiceAccount(ctx context.Context, actorID, name, description, role string) (*apimodels.ServiceAccount, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+	if strings.TrimSpace(role) == "" {
+		role = string(usermodels.RoleEmployee)
+	}
+	r, ok := usermodels.ParseRole(role)
+	if !ok {
+		return nil, errors.New("invalid role")
+	}
+	sa := &apimodels.ServiceAccount{
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Role:        r,
+		IsActive:    true,
+		CreatedBy:   actorID,
+	}
+	if err := s.repo.CreateServiceAccount(ctx, sa); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameAlreadyExists
+		}
+		return nil, err
+	}
+	return sa, nil
+}
+
+func (s *svc) UpdateServiceAccount(ctx context.Context, id string, name, description, role *string, isActive *bool) (*apimodels.ServiceAccount, error) {
+	sa, err := s.repo.GetServiceAccount(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		n := strings.TrimSpace(*name)
+		if n == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		sa.Name = n
+	}
+	if description != nil {
+		sa.Description = strings.TrimSpace(*description)
+	}
+	if role != nil {
+		r, ok := usermodels.ParseRole(*role)
+		if !ok {
+			return nil, errors.New("invalid role")
+		}
+		sa.Role = r
+	}
+	if isActive != nil {
+		sa.IsActive = *isActive
+	}
+	if err := s.repo.UpdateServiceAccount(ctx, sa); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, ErrNameAlreadyExists
+		}
+		return nil, err
+	}
+	return sa, nil
+}
+
+func (s *svc) DeleteServiceAccount(ctx context.Context, id string) error {
+	if _, err := s.repo.GetServiceAccount(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteServiceAccount(ctx, id)
+}
+
+// ===== API keys =====
+
+func (s *svc) ListKeys(ctx context.Context, serviceAccountID string) ([]apimodels.APIKey, error) {
+	if _, err := s.repo.GetServiceAccount(ctx, serviceAccountID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListKeys(ctx, serviceAccountID)
+}
+
+func (s *svc) CreateKey(ctx context.Context, actorID, serviceAccountID, name string, scopes []string, expiresAt *time.Time) (*apimodels.APIKey, string, error) {
+	if _, err := s.repo.GetServiceAccount(ctx, serviceAccountID); err != nil {
+		return nil, "", err
+	}
+	clean, err := normalizeScopes(scopes)
+	if err != nil {
+		return nil, "", err
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", errors.New("expires_at must be in the future")
+	}
+
+	prefix, secret := randomHex(6), identity.RandomSecret()
+	k := &apimodels.APIKey{
+		ServiceAccountID: serviceAccountID,
+		Name:             strings.TrimSpace(name),
+		Prefix:           prefix,
+		SecretHash:       hashSecret(secret),
+		Scopes:           clean,
+		ExpiresAt:        expiresAt,
+		CreatedBy:        actorID,
+	}
+	if err := s.repo.CreateKey(ctx, k); err != nil {
+		return nil, "", err
+	}
+	return k, middleware.APIKeyPrefix + prefix + "." + secret, nil
+}
+
+func (s *svc) RevokeKey(ctx context.Context, serviceAccountID, keyID string) error {
+	return s.repo.RevokeKey(ctx, serviceAccountID, keyID)
+}
+
+// Authenticate ตรวจ key จาก header (ใช้ผ่าน middleware.AuthRequiredOrAPIKey)
+func (s *svc) Authenticate(ctx context.Context, key, ip string) (*middleware.APIKeyPrincipal, error) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(key), middleware.APIKeyPrefix)
+	if !ok {
+		return nil, ErrInvalidAPIKey
+	}
+	prefix, secret, ok := strings.Cut(rest, ".")
+	if !ok || prefix == "" || secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	k, err := s.repo.FindKeyByPrefix(ctx, prefix)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(k.SecretHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	now := time.Now()
+	if !k.Active(now) {
+		return nil, ErrInvalidAPIKey
+	}
+	sa, err := s.repo.GetServiceAccount(ctx, k.ServiceAccountID)
+	if err != nil || !sa.IsActive {
+		return nil, ErrServiceAccountGone
+	}
+
+	_ = s.repo.TouchKey(ctx, k.ID, ip, now, now.Add(-touchInterval)) // ไม่ให้ล้ม request เพราะเขียน last_used ไม่ได้
+
+	return &middleware.APIKeyPrincipal{
+		ServiceAccountID: sa.ID,
+		KeyID:            k.ID,
+		Role:             string(sa.Role),
+		Scopes:           k.Scopes,
+	}, nil
+}
+
+func normalizeScopes(in []string) ([]string, error) {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(in))
+	for _, sc := range in {
+		sc = strings.ToLower(strings.TrimSpace(sc))
+		if sc == "" || seen[sc] {
+			continue
+		}
+		if !scopePattern.MatchString(sc) {
+			return nil, ErrInvalidScope
+		}
+		seen[sc] = true
+		out = append(out, sc)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	return out, nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// secret สุ่ม 256 bit จึงใช้ sha256 ได้ (ไม่ต้อง bcrypt ซึ่งช้าเกินไปสำหรับทุก request)
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
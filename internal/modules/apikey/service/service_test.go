@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/apikey"
+	apimodels "github.com/Marugo/birdlax/internal/modules/apikey/models"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// fakeRepo implement เฉพาะเมธอดที่ test ใช้ (ที่เหลือ panic ผ่าน interface nil)
+type fakeRepo struct {
+	apikey.Repository
+	accounts map[string]*apimodels.ServiceAccount
+	keys     map[string]*apimodels.APIKey
+}
+
+func (f *fakeRepo) GetServiceAccount(_ context.Context, id string) (*apimodels.ServiceAccount, error) {
+	if sa, ok := f.accounts[id]; ok {
+		return sa, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepo) CreateKey(_ context.Context, k *apimodels.APIKey) error {
+	if f.keys == nil {
+		f.keys = map[string]*apimodels.APIKey{}
+	}
+	k.ID = "k-" + k.Prefix
+	f.keys[k.Prefix] = k
+	return nil
+}
+
+func (f *fakeRepo) FindKeyByPrefix(_ context.Context, prefix string) (*apimodels.APIKey, error) {
+	if k, ok := f.keys[prefix]; ok {
+		return k, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeRepo) RevokeKey(_ context.Context, _, keyID string) error {
+	for _, k := range f.keys {
+		if k.ID == keyID {
+			now := time.Now()
+			k.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (f *fakeRepo) TouchKey(context.Context, string, string, time.Time, time.Time) error { return nil }
+
+func TestAuthenticate(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeRepo{accounts: map[string]*apimodels.ServiceAccount{
+		"sa1": {ID: "sa1", Role: usermodels.RoleHR, IsActive: true},
+	}}
+	s := NewService(repo)
+
+	k, plain, err := s.CreateKey(ctx, "admin", "sa1", "hris", []string{"Users.Write", "courses.*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := s.Authenticate(ctx, plain, "10.0.0.1")
+	if err != nil {
+		t.Fatalf("valid key: %v", err)
+	}
+	if p.ServiceAccountID != "sa1" || p.KeyID != k.ID || p.Role != "hr" || len(p.Scopes) != 2 || p.Scopes[0] != "users.write" {
+		t.Fatalf("principal = %+v", p)
+	}
+
+	for _, bad := range []string{"", "bk_", "bk_" + k.Prefix, "bk_" + k.Prefix + ".wrong", plain[len("bk_"):]} {
+		if _, err := s.Authenticate(ctx, bad, ""); !errors.Is(err, ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) = %v, want ErrInvalidAPIKey", bad, err)
+		}
+	}
+
+	repo.accounts["sa1"].IsActive = false
+	if _, err := s.Authenticate(ctx, plain, ""); !errors.Is(err, ErrServiceAccountGone) {
+		t.Fatalf("inactive account: err = %v", err)
+	}
+	repo.accounts["sa1"].IsActive = true
+
+	if err := s.RevokeKey(ctx, "sa1", k.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Authenticate(ctx, plain, ""); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("revoked key: err = %v", err)
+	}
+
+	past := time.Now().Add(-time.Minute)
+	k2, plain2, err := s.CreateKey(ctx, "admin", "sa1", "bi", []string{"*"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k2.ExpiresAt = &past
+	if _, err := s.Authenticate(ctx, plain2, ""); !errors.Is(err, ErrInvalidAPIKey) {
+		t.Fatalf("expired key: err = %v", err)
+	}
+}
@@ -17,3 +17,38 @@ type GradeResp struct {
 	TotalQuestions int `json:"total_questions"`
 	Correct        int `json:"correct"`
 }
+
+// AttemptDeliveryResp ข้อสอบสำหรับผู้เรียกระหว่างทำ attempt — ไม่มีเฉลย (is_correct) และคำอธิบาย
+type AttemptDeliveryResp struct {
+	Attempt    AttemptResp        `json:"attempt"`
+	Assessment DeliveryAssessment `json:"assessment"`
+	ExpiresAt  *string            `json:"expires_at,omitempty"` // nil = ไม่จำกัดเวลา
+	Questions  []DeliveryQuestion `json:"questions"`
+}
+
+type DeliveryAssessment struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Title      string `json:"title"`
+	PassScore  int    `json:"pass_score"`
+	TimeLimitS *int   `json:"time_limit_s,omitempty"`
+}
+
+type DeliveryQuestion struct {
+	ID      string           `json:"id"`
+	Type    string           `json:"type"`
+	Stem    string           `json:"stem"`
+	Points  int              `json:"points"`
+	Seq     int              `json:"seq"`
+	Choices []DeliveryChoice `json:"choices,omitempty"`
+
+	// คำตอบที่บันทึกไว้แล้วใน attempt นี้ (กลับมาทำต่อได้)
+	SelectedChoiceIDs []string `json:"selected_choice_ids,omitempty"`
+	TextAnswer        *string  `json:"text_answer,omitempty"`
+}
+
+type DeliveryChoice struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+	Seq   int    `json:"seq"`
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/Marugo/birdlax/internal/modules/assessment/dto"
 	"github.com/Marugo/birdlax/internal/modules/assessment/service"
+	contentservice "github.com/Marugo/birdlax/internal/modules/content/service"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -95,6 +97,11 @@ func (h *AttemptHandler) Start(c *fiber.Ctx) error {
 	_ = c.BodyParser(&req)
 	at, err := h.svc.StartAttempt(userID, assessID, req)
 	if err != nil {
+		// คอร์สเจ้าของข้อสอบเปิดให้ user นี้ไม่ได้: ตอบ reason code แบบเดียวกับ enroll
+		var ee *contentservice.EligibilityError
+		if errors.As(err, &ee) {
+			return c.Status(ee.HTTPStatus()).JSON(fiber.Map{"error": ee.Error(), "reason": ee.Reason})
+		}
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	return c.Status(fiber.StatusCreated).JSON(at)
@@ -110,6 +117,33 @@ func (h *AttemptHandler) Get(c *fiber.Ctx) error {
 	return c.JSON(at)
 }
 
+// GET /v1/attempts/:id/questions — ข้อสอบแบบไม่มีเฉลย สำหรับทำ attempt
+func (h *AttemptHandler) Questions(c *fiber.Ctx) error {
+	res, err := h.svc.GetDelivery(uid(c), c.Params("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAttemptNotFound):
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		case errors.Is(err, service.ErrAttemptNotInProgress), errors.Is(err, service.ErrAttemptExpired):
+			return fiber.NewError(fiber.StatusConflict, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(res)
+}
+
+// GET /v1/attempts/:id/review — ผู้ตรวจ (assessment.grade) ดู attempt ของผู้เรียนคนอื่นพร้อมคำตอบ
+func (h *AttemptHandler) Review(c *fiber.Ctx) error {
+	at, answers, err := h.svc.ReviewAttempt(c.Params("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrAttemptNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, err.Error())
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"attempt": at, "answers": answers})
+}
+
 // POST /v1/attempts/:id/answers
 func (h *AttemptHandler) UpsertAnswer(c *fiber.Ctx) error {
 	userID := uid(c)
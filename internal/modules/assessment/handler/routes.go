@@ -1,33 +1,44 @@
 package handler
 
 import (
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 func Register(r fiber.Router, h *Handler) {
+	// authoring ต้องมี assessment.write (ไม่ใส่ที่ group เพราะ /assessments/:id/attempts ของผู้เรียกอยู่ใต้ prefix เดียวกัน)
+	write := middleware.RequirePermission(authz.PermAssessmentWrite)
+
 	g := r.Group("/assessments")
 	g.Get("/", h.ListAssessments)
-	g.Get("/:id", h.GetAssessment)
-	g.Post("/", h.CreateAssessment)
-	g.Post("/:id/questions", h.AddQuestion)
-	g.Delete("/:id", h.DeleteAssessment)
-	g.Put("/:id/questions/:qid", h.UpdateQuestion)
-	g.Put("/:id", h.UpdateAssessment)
-	g.Delete("/:id/questions/:qid", h.DeleteQuestion)
+	g.Get("/:id", write, h.GetAssessment) // มีเฉลย; ผู้เรียนใช้ GET /attempts/:id/questions
+	g.Post("/", write, h.CreateAssessment)
+	g.Post("/:id/questions", write, h.AddQuestion)
+	g.Delete("/:id", write, h.DeleteAssessment)
+	g.Put("/:id/questions/:qid", write, h.UpdateQuestion)
+	g.Put("/:id", write, h.UpdateAssessment)
+	g.Delete("/:id/questions/:qid", write, h.DeleteQuestion)
 
-	g.Put("/:id/questions/:qid/choices", h.ReplaceChoices)    // replace-all
-	g.Post("/:id/questions/:qid/choices", h.AddChoice)        // add one
-	g.Put("/:id/questions/:qid/choices/:cid", h.UpdateChoice) // update one
-	g.Delete("/:id/questions/:qid/choices/:cid", h.DeleteChoice)
+	g.Put("/:id/questions/:qid/choices", write, h.ReplaceChoices)    // replace-all
+	g.Post("/:id/questions/:qid/choices", write, h.AddChoice)        // add one
+	g.Put("/:id/questions/:qid/choices/:cid", write, h.UpdateChoice) // update one
+	g.Delete("/:id/questions/:qid/choices/:cid", write, h.DeleteChoice)
 }
 
 // NEW: register attempts
 func RegisterAttemptRoutes(r fiber.Router, h *AttemptHandler) {
+	// attempt เป็นของผู้เรียน (user_id): API key ของ service account ทำข้อสอบแทนไม่ได้
+	human := middleware.RequireUser()
+	grade := middleware.RequirePermission(authz.PermAssessmentGrade)
+
 	g := r.Group("/assessments")
-	g.Post("/:id/attempts", h.Start)
+	g.Post("/:id/attempts", human, h.Start)
 
 	a := r.Group("/attempts")
-	a.Get("/:id", h.Get)
-	a.Post("/:id/answers", h.UpsertAnswer)
-	a.Post("/:id/submit", h.Submit)
+	a.Get("/:id", human, h.Get)
+	a.Get("/:id/questions", human, h.Questions)
+	a.Get("/:id/review", grade, h.Review) // ของผู้เรียนคนอื่น
+	a.Post("/:id/answers", human, h.UpsertAnswer)
+	a.Post("/:id/submit", human, h.Submit)
 }
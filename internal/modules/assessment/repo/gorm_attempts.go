@@ -32,6 +32,15 @@ func (r *AttemptRepo) GetAttempt(id, userID string) (*models.Attempt, error) {
 	}
 	return &a, nil
 }
+
+// FindAttempt ไม่กรองเจ้าของ — ใช้กับหน้าตรวจของผู้มีสิทธิ์ assessment.grade เท่านั้น
+func (r *AttemptRepo) FindAttempt(id string) (*models.Attempt, error) {
+	var a models.Attempt
+	if err := r.db.First(&a, "id=?", id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
 func (r *AttemptRepo) UpdateAttempt(a *models.Attempt) error {
 	return r.db.Model(&models.Attempt{}).Where("id=?", a.ID).Updates(a).Error
 }
@@ -4,21 +4,35 @@ import (
 	"errors"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/Marugo/birdlax/internal/modules/assessment/dto"
 	"github.com/Marugo/birdlax/internal/modules/assessment/models"
 	assrepo "github.com/Marugo/birdlax/internal/modules/assessment/repo"
+	contentservice "github.com/Marugo/birdlax/internal/modules/content/service"
 	learnmodels "github.com/Marugo/birdlax/internal/modules/learning/models"
 	learningservice "github.com/Marugo/birdlax/internal/modules/learning/service"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+var (
+	ErrAttemptNotFound      = errors.New("attempt not found")
+	ErrAttemptNotInProgress = errors.New("attempt is not in progress")
+	ErrAttemptExpired       = errors.New("attempt expired")
+)
+
 type AttemptService interface {
 	StartAttempt(userID, assessmentID string, req dto.StartAttemptReq) (*models.Attempt, error)
 	UpsertAnswer(userID, attemptID string, req dto.UpsertAnswerReq) (*models.Answer, error)
 	SubmitAttempt(userID, attemptID string, req dto.SubmitAttemptReq) (*models.Attempt, int, int, error)
 	GetAttempt(userID, attemptID string) (*models.Attempt, error)
+
+	// ข้อสอบสำหรับผู้เรียน (ไม่มีเฉลย) ดูได้เฉพาะเจ้าของ attempt ที่ยัง in_progress
+	GetDelivery(userID, attemptID string) (*dto.AttemptDeliveryResp, error)
+
+	// attempt ของใครก็ได้พร้อมคำตอบ (is_correct) — handler ต้องกันด้วย assessment.grade
+	ReviewAttempt(attemptID string) (*models.Attempt, []models.Answer, error)
 }
 
 type attemptSvc struct {
@@ -26,10 +40,11 @@ type attemptSvc struct {
 	rr   AttemptRepo // attempt repo
 	er   EnrollmentRepo
 	ms   learningservice.MetricsService
+	cp   CoursePolicy
 }
 
-func NewAttemptService(repo Repo, rr AttemptRepo, er EnrollmentRepo, ms learningservice.MetricsService) AttemptService {
-	return &attemptSvc{repo: repo, rr: rr, er: er, ms: ms}
+func NewAttemptService(repo Repo, rr AttemptRepo, er EnrollmentRepo, ms learningservice.MetricsService, cp CoursePolicy) AttemptService {
+	return &attemptSvc{repo: repo, rr: rr, er: er, ms: ms, cp: cp}
 }
 
 func (s *attemptSvc) StartAttempt(userID, assessmentID string, _ dto.StartAttemptReq) (*models.Attempt, error) {
@@ -37,6 +52,10 @@ func (s *attemptSvc) StartAttempt(userID, assessmentID string, _ dto.StartAttemp
 	if err != nil {
 		return nil, errors.New("assessment not found")
 	}
+	// ต้องเห็นคอร์สเจ้าของ assessment ก่อน (คืน *EligibilityError พร้อม reason code)
+	if err := s.canAttempt(userID, a); err != nil {
+		return nil, err
+	}
 
 	// MaxAttempts check
 	if a.MaxAttempts != nil && *a.MaxAttempts > 0 {
@@ -64,10 +83,110 @@ func (s *attemptSvc) StartAttempt(userID, assessmentID string, _ dto.StartAttemp
 	return at, nil
 }
 
+// canAttempt ใช้กติกาเดียวกับ catalog: คอร์สที่ user เปิดดูไม่ได้ ก็เริ่มทำข้อสอบของคอร์สนั้นไม่ได้
+func (s *attemptSvc) canAttempt(userID string, a *models.Assessment) error {
+	switch a.OwnerType {
+	case "course":
+		return s.cp.CanView(userID, a.OwnerID)
+	case "module":
+		return s.cp.CanViewModule(userID, a.OwnerID)
+	case "lesson":
+		return s.cp.CanViewLesson(userID, a.OwnerID)
+	}
+	return &contentservice.EligibilityError{Reason: contentservice.ReasonCourseNotFound}
+}
+
 func (s *attemptSvc) GetAttempt(userID, attemptID string) (*models.Attempt, error) {
 	return s.rr.GetAttempt(attemptID, userID)
 }
 
+func (s *attemptSvc) ReviewAttempt(attemptID string) (*models.Attempt, []models.Answer, error) {
+	at, err := s.rr.FindAttempt(attemptID)
+	if err != nil {
+		return nil, nil, ErrAttemptNotFound
+	}
+	answers, err := s.rr.ListAnswers(at.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return at, answers, nil
+}
+
+func (s *attemptSvc) GetDelivery(userID, attemptID string) (*dto.AttemptDeliveryResp, error) {
+	at, err := s.rr.GetAttempt(attemptID, userID)
+	if err != nil {
+		return nil, ErrAttemptNotFound
+	}
+	if at.Status != "in_progress" {
+		return nil, ErrAttemptNotInProgress
+	}
+	if s.expireIfOverdue(at) {
+		return nil, ErrAttemptExpired
+	}
+
+	a, qs, csByQ, err := s.repo.GetAssessmentWithItems(at.AssessmentID)
+	if err != nil {
+		return nil, err
+	}
+	answers, err := s.rr.ListAnswers(at.ID)
+	if err != nil {
+		return nil, err
+	}
+	ansByQ := make(map[string]models.Answer, len(answers))
+	for _, x := range answers {
+		ansByQ[x.QuestionID] = x
+	}
+
+	out := &dto.AttemptDeliveryResp{
+		Attempt: dto.AttemptResp{
+			ID:           at.ID,
+			AssessmentID: at.AssessmentID,
+			Status:       at.Status,
+			StartedAt:    at.StartedAt.Format(time.RFC3339),
+		},
+		Assessment: dto.DeliveryAssessment{
+			ID: a.ID, Type: a.Type, Title: a.Title, PassScore: a.PassScore, TimeLimitS: at.TimeLimitS,
+		},
+		Questions: make([]dto.DeliveryQuestion, 0, len(qs)),
+	}
+	if at.TimeLimitS != nil && *at.TimeLimitS > 0 {
+		exp := at.StartedAt.Add(time.Duration(*at.TimeLimitS) * time.Second).Format(time.RFC3339)
+		out.ExpiresAt = &exp
+	}
+
+	// ตั้งใจไม่ใส่ Choice.IsCorrect / Question.Explanation
+	for _, q := range qs {
+		dq := dto.DeliveryQuestion{
+			ID: q.ID, Type: q.Type, Stem: strings.TrimSpace(q.Stem), Points: q.Points, Seq: q.Seq,
+		}
+		for _, c := range csByQ[q.ID] {
+			dq.Choices = append(dq.Choices, dto.DeliveryChoice{ID: c.ID, Label: strings.TrimSpace(c.Label), Seq: c.Seq})
+		}
+		if ans, ok := ansByQ[q.ID]; ok {
+			if ans.SelectedChoiceIDs != nil {
+				dq.SelectedChoiceIDs = assrepo.SplitCSV(*ans.SelectedChoiceIDs)
+			}
+			dq.TextAnswer = ans.TextAnswer
+		}
+		out.Questions = append(out.Questions, dq)
+	}
+	return out, nil
+}
+
+// expireIfOverdue mark attempt เป็น expired ถ้าเกินเวลาที่กำหนด (true = หมดเวลาแล้ว)
+func (s *attemptSvc) expireIfOverdue(at *models.Attempt) bool {
+	if at.TimeLimitS == nil || *at.TimeLimitS <= 0 {
+		return false
+	}
+	elapsed := int(s.rr.Now().Sub(at.StartedAt).Seconds())
+	if elapsed <= *at.TimeLimitS {
+		return false
+	}
+	at.Status = "expired"
+	_ = s.rr.UpdateAttempt(at)
+	return true
+}
+
 func (s *attemptSvc) UpsertAnswer(userID, attemptID string, req dto.UpsertAnswerReq) (*models.Answer, error) {
 	at, err := s.rr.GetAttempt(attemptID, userID)
 	if err != nil {
@@ -76,14 +195,14 @@ func (s *attemptSvc) UpsertAnswer(userID, attemptID string, req dto.UpsertAnswer
 	if at.Status != "in_progress" {
 		return nil, errors.New("attempt not editable")
 	}
-	// Time limit
-	if at.TimeLimitS != nil && *at.TimeLimitS > 0 {
-		elapsed := int(s.rr.Now().Sub(at.StartedAt).Seconds())
-		if elapsed > *at.TimeLimitS {
-			at.Status = "expired"
-			_ = s.rr.UpdateAttempt(at)
-			return nil, errors.New("attempt expired")
-		}
+	if s.expireIfOverdue(at) {
+		return nil, ErrAttemptExpired
+	}
+
+	// คำถามต้องอยู่ใน assessment ของ attempt นี้
+	q, err := s.repo.GetQuestionByID(req.QuestionID)
+	if err != nil || q.AssessmentID != at.AssessmentID {
+		return nil, errors.New("question not in this assessment")
 	}
 
 	ans := &models.Answer{
@@ -113,14 +232,8 @@ func (s *attemptSvc) SubmitAttempt(userID, attemptID string, _ dto.SubmitAttempt
 	if at.Status != "in_progress" {
 		return nil, 0, 0, errors.New("attempt not editable")
 	}
-	// Time limit
-	if at.TimeLimitS != nil && *at.TimeLimitS > 0 {
-		elapsed := int(s.rr.Now().Sub(at.StartedAt).Seconds())
-		if elapsed > *at.TimeLimitS {
-			at.Status = "expired"
-			_ = s.rr.UpdateAttempt(at)
-			return nil, 0, 0, errors.New("attempt expired")
-		}
+	if s.expireIfOverdue(at) {
+		return nil, 0, 0, ErrAttemptExpired
 	}
 
 	// Load questions & answers
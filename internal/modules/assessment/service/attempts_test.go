@@ -0,0 +1,208 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Marugo/birdlax/internal/modules/assessment/dto"
+	"github.com/Marugo/birdlax/internal/modules/assessment/models"
+	contentservice "github.com/Marugo/birdlax/internal/modules/content/service"
+)
+
+type fakeRepo struct {
+	Repo
+	a  *models.Assessment
+	qs []models.Question
+	cs map[string][]models.Choice
+}
+
+func (f *fakeRepo) GetAssessmentWithItems(string) (*models.Assessment, []models.Question, map[string][]models.Choice, error) {
+	return f.a, f.qs, f.cs, nil
+}
+
+func (f *fakeRepo) GetAssessmentByID(string) (*models.Assessment, error) { return f.a, nil }
+
+// fakePolicy เห็นได้เฉพาะคอร์ส/โมดูล/บทเรียนที่อยู่ใน open
+type fakePolicy struct{ open map[string]bool }
+
+func (p fakePolicy) check(id string) error {
+	if p.open[id] {
+		return nil
+	}
+	return &contentservice.EligibilityError{Reason: contentservice.ReasonNotInTargetDept}
+}
+func (p fakePolicy) CanView(_, courseID string) error       { return p.check(courseID) }
+func (p fakePolicy) CanViewModule(_, moduleID string) error { return p.check(moduleID) }
+func (p fakePolicy) CanViewLesson(_, lessonID string) error { return p.check(lessonID) }
+
+type fakeAttempts struct {
+	AttemptRepo
+	attempts map[string]*models.Attempt
+	answers  []models.Answer
+	now      time.Time
+	updated  []models.Attempt
+	created  []models.Attempt
+}
+
+func (f *fakeAttempts) CreateAttempt(a *models.Attempt) error {
+	f.created = append(f.created, *a)
+	return nil
+}
+
+func (f *fakeAttempts) GetAttempt(id, userID string) (*models.Attempt, error) {
+	at, ok := f.attempts[id]
+	if !ok || at.UserID != userID {
+		return nil, errors.New("record not found")
+	}
+	return at, nil
+}
+
+func (f *fakeAttempts) FindAttempt(id string) (*models.Attempt, error) {
+	at, ok := f.attempts[id]
+	if !ok {
+		return nil, errors.New("record not found")
+	}
+	return at, nil
+}
+
+func (f *fakeAttempts) UpdateAttempt(a *models.Attempt) error {
+	f.updated = append(f.updated, *a)
+	return nil
+}
+
+func (f *fakeAttempts) ListAnswers(string) ([]models.Answer, error) { return f.answers, nil }
+func (f *fakeAttempts) Now() time.Time                              { return f.now }
+
+func newDeliverySvc(at *models.Attempt) (*attemptSvc, *fakeAttempts) {
+	explain := "because B"
+	repo := &fakeRepo{
+		a: &models.Assessment{ID: "as1", Type: "quiz", Title: "Quiz", PassScore: 60},
+		qs: []models.Question{
+			{ID: "q1", AssessmentID: "as1", Type: "single_choice", Stem: " Pick one ", Explanation: &explain, Points: 1, Seq: 1},
+			{ID: "q2", AssessmentID: "as1", Type: "short_text", Stem: "Why?", Explanation: &explain, Points: 2, Seq: 2},
+		},
+		cs: map[string][]models.Choice{"q1": {
+			{ID: "c1", QuestionID: "q1", Label: "A", Seq: 1},
+			{ID: "c2", QuestionID: "q1", Label: "B", IsCorrect: true, Seq: 2},
+		}},
+	}
+	sel, text := "c1", "no idea"
+	rr := &fakeAttempts{
+		attempts: map[string]*models.Attempt{at.ID: at},
+		answers: []models.Answer{
+			{AttemptID: at.ID, QuestionID: "q1", SelectedChoiceIDs: &sel},
+			{AttemptID: at.ID, QuestionID: "q2", TextAnswer: &text},
+		},
+		now: at.StartedAt.Add(time.Minute),
+	}
+	return &attemptSvc{repo: repo, rr: rr}, rr
+}
+
+func TestGetDeliveryHidesAnswerKey(t *testing.T) {
+	limit := 600
+	at := &models.Attempt{ID: "at1", AssessmentID: "as1", UserID: "u1", Status: "in_progress", StartedAt: time.Now(), TimeLimitS: &limit}
+	s, _ := newDeliverySvc(at)
+
+	out, err := s.GetDelivery("u1", "at1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Questions) != 2 || len(out.Questions[0].Choices) != 2 || out.Questions[0].Stem != "Pick one" {
+		t.Fatalf("questions = %+v", out.Questions)
+	}
+	if out.ExpiresAt == nil {
+		t.Fatal("expires_at missing for timed attempt")
+	}
+	// คำตอบที่บันทึกไว้กลับมาด้วย
+	if got := out.Questions[0].SelectedChoiceIDs; len(got) != 1 || got[0] != "c1" {
+		t.Fatalf("selected = %v", got)
+	}
+	if out.Questions[1].TextAnswer == nil || *out.Questions[1].TextAnswer != "no idea" {
+		t.Fatalf("text answer = %v", out.Questions[1].TextAnswer)
+	}
+
+	raw, err := json.Marshal(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range []string{"is_correct", "IsCorrect", "explanation", "because B"} {
+		if strings.Contains(string(raw), leak) {
+			t.Fatalf("delivery leaks %q: %s", leak, raw)
+		}
+	}
+}
+
+func TestGetDeliveryOwnerAndStatus(t *testing.T) {
+	at := &models.Attempt{ID: "at1", AssessmentID: "as1", UserID: "u1", Status: "in_progress", StartedAt: time.Now()}
+	s, _ := newDeliverySvc(at)
+
+	if _, err := s.GetDelivery("u2", "at1"); !errors.Is(err, ErrAttemptNotFound) {
+		t.Fatalf("other user: err = %v", err)
+	}
+	at.Status = "submitted"
+	if _, err := s.GetDelivery("u1", "at1"); !errors.Is(err, ErrAttemptNotInProgress) {
+		t.Fatalf("submitted: err = %v", err)
+	}
+}
+
+func TestGetDeliveryExpiresOverdueAttempt(t *testing.T) {
+	limit := 60
+	at := &models.Attempt{ID: "at1", AssessmentID: "as1", UserID: "u1", Status: "in_progress", StartedAt: time.Now(), TimeLimitS: &limit}
+	s, rr := newDeliverySvc(at)
+	rr.now = at.StartedAt.Add(2 * time.Minute)
+
+	if _, err := s.GetDelivery("u1", "at1"); !errors.Is(err, ErrAttemptExpired) {
+		t.Fatalf("err = %v", err)
+	}
+	if len(rr.updated) != 1 || rr.updated[0].Status != "expired" {
+		t.Fatalf("updated = %+v", rr.updated)
+	}
+	// เรียกซ้ำ => ไม่ใช่ in_progress แล้ว
+	if _, err := s.GetDelivery("u1", "at1"); !errors.Is(err, ErrAttemptNotInProgress) {
+		t.Fatalf("second call: err = %v", err)
+	}
+}
+
+func TestReviewAttemptIgnoresOwner(t *testing.T) {
+	at := &models.Attempt{ID: "at1", AssessmentID: "as1", UserID: "u1", Status: "submitted", StartedAt: time.Now()}
+	s, _ := newDeliverySvc(at)
+
+	got, answers, err := s.ReviewAttempt("at1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.UserID != "u1" || len(answers) != 2 {
+		t.Fatalf("attempt = %+v answers = %d", got, len(answers))
+	}
+	if _, _, err := s.ReviewAttempt("nope"); !errors.Is(err, ErrAttemptNotFound) {
+		t.Fatalf("missing: err = %v", err)
+	}
+}
+
+func TestStartAttemptRequiresCourseVisibility(t *testing.T) {
+	for _, owner := range []string{"course", "module", "lesson"} {
+		repo := &fakeRepo{a: &models.Assessment{ID: "as1", OwnerType: owner, OwnerID: "o1", Type: "post"}}
+		rr := &fakeAttempts{now: time.Now()}
+		s := &attemptSvc{repo: repo, rr: rr, cp: fakePolicy{}}
+
+		_, err := s.StartAttempt("u1", "as1", dto.StartAttemptReq{})
+		var ee *contentservice.EligibilityError
+		if !errors.As(err, &ee) || ee.Reason != contentservice.ReasonNotInTargetDept {
+			t.Fatalf("%s: err = %v", owner, err)
+		}
+		if len(rr.created) != 0 {
+			t.Fatalf("%s: attempt created for hidden course", owner)
+		}
+
+		s.cp = fakePolicy{open: map[string]bool{"o1": true}}
+		if _, err := s.StartAttempt("u1", "as1", dto.StartAttemptReq{}); err != nil {
+			t.Fatalf("%s visible: %v", owner, err)
+		}
+		if len(rr.created) != 1 {
+			t.Fatalf("%s: created = %d", owner, len(rr.created))
+		}
+	}
+}
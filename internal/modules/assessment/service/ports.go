@@ -55,6 +55,7 @@ type AttemptRepo interface {
 	CountUserAttempts(assessmentID, userID string) (int64, error)
 	CreateAttempt(a *models.Attempt) error
 	GetAttempt(id, userID string) (*models.Attempt, error)
+	FindAttempt(id string) (*models.Attempt, error) // ไม่กรอง user_id (สำหรับผู้ตรวจ)
 	UpdateAttempt(a *models.Attempt) error
 
 	UpsertAnswer(ans *models.Answer) error
@@ -68,3 +69,10 @@ type EnrollmentRepo interface {
 	GetEnrollment(userID, courseID string) (*learnmodels.Enrollment, error)
 	UpsertEnrollment(e *learnmodels.Enrollment) error
 }
+
+// CoursePolicy กติกา visibility ของคอร์สที่ assessment ผูกอยู่ (content.AccessService)
+type CoursePolicy interface {
+	CanView(userID, courseID string) error
+	CanViewModule(userID, moduleID string) error
+	CanViewLesson(userID, lessonID string) error
+}
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	"github.com/Marugo/birdlax/internal/shared/response"
+)
+
+type HTTPHandler struct {
+	svc audit.Service
+}
+
+func NewHTTPHandler(s audit.Service) *HTTPHandler {
+	return &HTTPHandler{svc: s}
+}
+
+// GET /audit-logs?action=&actor_id=&subject_id=&session_id=&from=&to=
+func (h *HTTPHandler) List(c *fiber.Ctx) error {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	perPage, _ := strconv.Atoi(c.Query("per_page", "50"))
+	f := audit.ListFilter{
+		Action:    c.Query("action"),
+		ActorID:   c.Query("actor_id"),
+		SubjectID: c.Query("subject_id"),
+		SessionID: c.Query("session_id"),
+	}
+	for key, dst := range map[string]**time.Time{"from": &f.From, "to": &f.To} {
+		v := c.Query(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", key+" must be RFC3339")
+		}
+		*dst = &t
+	}
+
+	items, total, err := h.svc.List(c.Context(), f, page, perPage)
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{
+		"items":    items,
+		"total":    total,
+		"page":     page,
+		"per_page": perPage,
+	})
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Register(r fiber.Router, svc audit.Service) {
+	h := NewHTTPHandler(svc)
+
+	// audit log อ่านได้เฉพาะคนตัวจริง (ไม่ใช่ API key / impersonation)
+	g := r.Group("/audit-logs", middleware.RequireUser(), middleware.DenyImpersonation(), middleware.RequirePermission(authz.PermAuditRead))
+	g.Get("/", h.List)
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// action ที่บันทึกลง audit log
+const (
+	ActionImpersonationStart   = "impersonation.start"
+	ActionImpersonationRequest = "impersonation.request"
+	ActionImpersonationEnd     = "impersonation.end"
+
+	ActionRolePermissionsUpdate = "authz.role_permissions.update"
+
+	ActionUserDataExport = "user.data_export"
+	ActionUserAnonymize  = "user.anonymize"
+)
+
+// AuditLog การกระทำที่ต้องตรวจสอบย้อนหลังได้ (ใครทำ ในนามใคร ทำอะไร)
+type AuditLog struct {
+	ID        string `gorm:"type:char(36);primaryKey" json:"id"`
+	Action    string `gorm:"size:64;index;not null" json:"action"`
+	ActorID   string `gorm:"size:36;index" json:"actor_id"`   // คนที่ทำจริง
+	SubjectID string `gorm:"size:36;index" json:"subject_id"` // user ที่ถูกกระทำ/ถูกสวมรอย
+	SessionID string `gorm:"size:64;index" json:"session_id"` // impersonation session (jti)
+	Method    string `gorm:"size:10" json:"method,omitempty"`
+	Path      string `gorm:"size:500" json:"path,omitempty"`
+	Status    int    `json:"status,omitempty"`
+	IP        string `gorm:"size:64" json:"ip"`
+	UserAgent string `gorm:"size:255" json:"user_agent"`
+	Detail    string `gorm:"type:text" json:"detail,omitempty"`
+
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (a *AuditLog) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == "" {
+		a.ID = uuid.NewString()
+	}
+	return nil
+}
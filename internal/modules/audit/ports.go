@@ -0,0 +1,32 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+)
+
+// ListFilter เงื่อนไขค้น audit log (ค่าว่าง = ไม่กรอง)
+type ListFilter struct {
+	Action    string
+	ActorID   string
+	SubjectID string
+	SessionID string
+	From      *time.Time
+	To        *time.Time
+}
+
+type Repository interface {
+	Save(ctx context.Context, e *auditmodels.AuditLog) error
+	List(ctx context.Context, f ListFilter, limit, offset int) ([]auditmodels.AuditLog, int64, error)
+}
+
+type Service interface {
+	Record(ctx context.Context, e *auditmodels.AuditLog) error
+	List(ctx context.Context, f ListFilter, page, perPage int) ([]auditmodels.AuditLog, int64, error)
+
+	// ใช้โดย middleware บันทึกทุก request ที่ทำภายใต้ impersonation
+	middleware.ImpersonationAuditor
+}
@@ -0,0 +1,50 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+)
+
+type gormRepo struct{ db *gorm.DB }
+
+func NewGormRepository(db *gorm.DB) audit.Repository { return &gormRepo{db: db} }
+
+func (r *gormRepo) Save(ctx context.Context, e *auditmodels.AuditLog) error {
+	return r.db.WithContext(ctx).Create(e).Error
+}
+
+func (r *gormRepo) List(ctx context.Context, f audit.ListFilter, limit, offset int) ([]auditmodels.AuditLog, int64, error) {
+	tx := r.db.WithContext(ctx).Model(&auditmodels.AuditLog{})
+	if f.Action != "" {
+		tx = tx.Where("action = ?", f.Action)
+	}
+	if f.ActorID != "" {
+		tx = tx.Where("actor_id = ?", f.ActorID)
+	}
+	if f.SubjectID != "" {
+		tx = tx.Where("subject_id = ?", f.SubjectID)
+	}
+	if f.SessionID != "" {
+		tx = tx.Where("session_id = ?", f.SessionID)
+	}
+	if f.From != nil {
+		tx = tx.Where("created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		tx = tx.Where("created_at < ?", *f.To)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var rows []auditmodels.AuditLog
+	if err := tx.Order("created_at DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+)
+
+type svc struct{ repo audit.Repository }
+
+func NewService(r audit.Repository) audit.Service { return &svc{repo: r} }
+
+func (s *svc) Record(ctx context.Context, e *auditmodels.AuditLog) error {
+	e.Path = truncate(e.Path, 500)
+	e.UserAgent = truncate(e.UserAgent, 255)
+	return s.repo.Save(ctx, e)
+}
+
+func (s *svc) List(ctx context.Context, f audit.ListFilter, page, perPage int) ([]auditmodels.AuditLog, int64, error) {
+	if perPage <= 0 || perPage > 200 {
+		perPage = 50
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return s.repo.List(ctx, f, perPage, (page-1)*perPage)
+}
+
+func (s *svc) RecordImpersonatedRequest(ctx context.Context, r middleware.ImpersonatedRequest) error {
+	return s.Record(ctx, &auditmodels.AuditLog{
+		Action:    auditmodels.ActionImpersonationRequest,
+		ActorID:   r.ActorID,
+		SubjectID: r.SubjectID,
+		SessionID: r.SessionID,
+		Method:    r.Method,
+		Path:      r.Path,
+		Status:    r.Status,
+		IP:        r.IP,
+		UserAgent: r.UserAgent,
+	})
+}
+
+// truncate ตัดที่ n ตัวอักษร (ไม่ใช่ byte) ให้ตรงกับ varchar(n) และไม่ผ่ากลางตัวอักษรไทย
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateKeepsRunes(t *testing.T) {
+	th := strings.Repeat("ทดสอบ", 100) // 500 ตัวอักษร, 1500 byte
+	got := truncate(th, 255)
+	if !utf8.ValidString(got) {
+		t.Fatal("truncate produced invalid UTF-8")
+	}
+	if n := utf8.RuneCountInString(got); n != 255 {
+		t.Fatalf("rune count = %d, want 255", n)
+	}
+	if truncate("short", 255) != "short" {
+		t.Fatal("short string must be unchanged")
+	}
+	if got := truncate("กขค", 2); got != "กข" {
+		t.Fatalf("got %q", got)
+	}
+}
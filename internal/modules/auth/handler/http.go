@@ -1,11 +1,16 @@
 package handler
 
 import (
+	"errors"
+	"math"
+	"strconv"
 	"time"
 
 	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	authservice "github.com/Marugo/birdlax/internal/modules/auth/service"
 	user "github.com/Marugo/birdlax/internal/modules/user"
 	"github.com/Marugo/birdlax/internal/modules/user/dto"
+	"github.com/Marugo/birdlax/internal/shared/password"
 	"github.com/Marugo/birdlax/internal/shared/response"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,10 +18,11 @@ import (
 type HTTPHandler struct {
 	svc     auth.Service
 	userSvc user.Service
+	oidc    auth.OIDCConfig
 }
 
-func NewHTTPHandler(s auth.Service, u user.Service) *HTTPHandler {
-	return &HTTPHandler{svc: s, userSvc: u}
+func NewHTTPHandler(s auth.Service, u user.Service, oidcCfg auth.OIDCConfig) *HTTPHandler {
+	return &HTTPHandler{svc: s, userSvc: u, oidc: oidcCfg}
 }
 
 type loginReq struct {
@@ -28,27 +34,77 @@ type refreshReq struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type forgotPasswordReq struct {
+	EmployeeCode string `json:"employee_code"`
+	Email        string `json:"email"`
+}
+
+type resetPasswordReq struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
 func (h *HTTPHandler) Login(c *fiber.Ctx) error {
 	var r loginReq
 	if err := c.BodyParser(&r); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
 	}
-	access, refresh, err := h.svc.Login(c.Context(), r.EmployeeCode, r.Password) // 👈 ส่ง employee_code
+	res, err := h.svc.Login(c.Context(), r.EmployeeCode, r.Password, clientInfo(c)) // 👈 ส่ง employee_code
 	if err != nil {
-		return response.Err(c, fiber.StatusUnauthorized, "INVALID_CREDENTIALS", err.Error())
+		return loginError(c, err)
+	}
+	return writeLoginResult(c, res)
+}
+
+// writeLoginResult ถ้าต้องยืนยัน MFA ตอบ challenge; ไม่งั้น set refresh cookie + ตอบ access token
+func writeLoginResult(c *fiber.Ctx, res *auth.LoginResult) error {
+	if res.MFARequired {
+		return response.OK(c, fiber.Map{
+			"mfa_required":        true,
+			"mfa_enroll_required": res.MFAEnrollRequired,
+			"mfa_token":           res.MFAToken,
+		})
 	}
 	c.Cookie(&fiber.Cookie{
 		Name:     "refresh_token",
-		Value:    refresh, // refresh token string
+		Value:    res.Refresh, // refresh token string
 		HTTPOnly: true,
 		Secure:   false, // true ถ้าใช้ https
 		SameSite: "Lax", // fiber v2 ใช้ fiber.CookieSameSiteLaxMode ได้เช่นกัน
 		Path:     "/",
 		MaxAge:   int((7 * 24 * time.Hour).Seconds()),
 	})
-	return response.OK(c, fiber.Map{
-		"access_token": access, // ให้ frontend ใส่ลง Authorization header
-	})
+	out := fiber.Map{
+		"access_token": res.Access, // ให้ frontend ใส่ลง Authorization header
+	}
+	if len(res.RecoveryCodes) > 0 {
+		out["recovery_codes"] = res.RecoveryCodes // แสดงครั้งเดียว
+	}
+	if res.PasswordChangeRequired {
+		out["password_change_required"] = true // frontend พาไปหน้าเปลี่ยนรหัส
+	}
+	return response.OK(c, out)
+}
+
+func loginError(c *fiber.Ctx, err error) error {
+	var te *authservice.ThrottledError
+	if errors.As(err, &te) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(te.RetryAfter.Seconds()))))
+		code := "TOO_MANY_ATTEMPTS"
+		if te.Locked {
+			code = "ACCOUNT_LOCKED"
+		}
+		return response.Err(c, fiber.StatusTooManyRequests, code, err.Error())
+	}
+	switch {
+	case errors.Is(err, authservice.ErrAuthUnavailable):
+		return response.Err(c, fiber.StatusServiceUnavailable, "AUTH_UNAVAILABLE", err.Error())
+	case errors.Is(err, authservice.ErrMFAInvalidCode):
+		return response.Err(c, fiber.StatusUnauthorized, "INVALID_MFA_CODE", err.Error())
+	case errors.Is(err, authservice.ErrMFAInvalidChallenge):
+		return response.Err(c, fiber.StatusUnauthorized, "INVALID_MFA_CHALLENGE", err.Error())
+	}
+	return response.Err(c, fiber.StatusUnauthorized, "INVALID_CREDENTIALS", err.Error())
 }
 
 func (h *HTTPHandler) Refresh(c *fiber.Ctx) error {
@@ -61,8 +117,18 @@ func (h *HTTPHandler) Refresh(c *fiber.Ctx) error {
 		_ = c.BodyParser(&r)
 		rt = r.RefreshToken
 	}
-	access, newRefresh, err := h.svc.Refresh(c.Context(), rt)
+	access, newRefresh, err := h.svc.Refresh(c.Context(), rt, clientInfo(c))
 	if err != nil {
+		if errors.Is(err, authservice.ErrRefreshReused) {
+			// ทั้ง family ถูก revoke แล้ว — ล้าง cookie ให้ต้อง login ใหม่
+			c.Cookie(&fiber.Cookie{
+				Name:   "refresh_token",
+				Value:  "",
+				MaxAge: -1, Path: "/",
+				HTTPOnly: true, Secure: false, SameSite: "Lax",
+			})
+			return response.Err(c, fiber.StatusUnauthorized, "REFRESH_REUSED", err.Error())
+		}
 		return response.Err(c, fiber.StatusUnauthorized, "INVALID_REFRESH", err.Error())
 	}
 
@@ -99,6 +165,47 @@ func (h *HTTPHandler) Logout(c *fiber.Ctx) error {
 	return response.OK(c, fiber.Map{"ok": true})
 }
 
+// POST /auth/forgot-password — ตอบ ok เสมอ ไม่บอกว่ามี account หรือไม่
+func (h *HTTPHandler) ForgotPassword(c *fiber.Ctx) error {
+	var r forgotPasswordReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	identifier := r.EmployeeCode
+	if identifier == "" {
+		identifier = r.Email
+	}
+	// ตอบ 200 เหมือนกันทุกกรณี ยกเว้น IP นี้ขอถี่เกิน (429)
+	var te *authservice.ThrottledError
+	if err := h.svc.ForgotPassword(c.Context(), identifier, clientInfo(c)); errors.As(err, &te) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(te.RetryAfter.Seconds()))))
+		return response.Err(c, fiber.StatusTooManyRequests, "TOO_MANY_ATTEMPTS", "too many requests, try again later")
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
+
+// POST /auth/reset-password
+func (h *HTTPHandler) ResetPassword(c *fiber.Ctx) error {
+	var r resetPasswordReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.svc.ResetPassword(c.Context(), r.Token, r.NewPassword); err != nil {
+		if errors.Is(err, authservice.ErrInvalidResetToken) {
+			return response.Err(c, fiber.StatusBadRequest, "INVALID_RESET_TOKEN", err.Error())
+		}
+		if errors.Is(err, authservice.ErrPasswordManagedExternally) {
+			return response.Err(c, fiber.StatusConflict, "PASSWORD_MANAGED_EXTERNALLY", err.Error())
+		}
+		var pe *password.PolicyError
+		if errors.As(err, &pe) {
+			return passwordPolicyError(c, "new_password", pe)
+		}
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
+
 func (h *HTTPHandler) Me(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(string)
 	role, _ := c.Locals("role").(string)
@@ -115,8 +222,22 @@ func (h *HTTPHandler) Me(c *fiber.Ctx) error {
 	// 2) หรือเชื่อ Token:
 	_ = role // ใช้ถ้าต้องการยึดตาม token
 
-	// ตอบเฉพาะฟิลด์ที่อยากโชว์ (ถ้าต้องการลดรูป)
-	return response.OK(c, fiber.Map{
+	me := meResponse(out)
+	if actor, _ := c.Locals("actor_id").(string); actor != "" {
+		// frontend แสดงแถบ "กำลังดูในนาม ..." ระหว่าง impersonation
+		readOnly, _ := c.Locals("impersonation_read_only").(bool)
+		me["impersonation"] = fiber.Map{
+			"actor_id":   actor,
+			"session_id": c.Locals("impersonation_id"),
+			"read_only":  readOnly,
+		}
+	}
+	return response.OK(c, me)
+}
+
+// meResponse ตอบเฉพาะฟิลด์ที่อยากโชว์ (ถ้าต้องการลดรูป)
+func meResponse(out *dto.UserResponse) fiber.Map {
+	return fiber.Map{
 		"id":            out.ID,
 		"employee_code": out.EmployeeCode,
 		"email":         out.Email,
@@ -125,5 +246,21 @@ func (h *HTTPHandler) Me(c *fiber.Ctx) error {
 		"role":          out.Role, // หรือ role จาก token
 		"phone":         out.Phone,
 		"is_active":     out.IsActive,
-	})
+		// lockout state
+		"is_locked":          out.IsLocked,
+		"locked_until":       out.LockedUntil,
+		"failed_login_count": out.FailedLoginCount,
+
+		"must_change_password": out.MustChangePassword,
+	}
+}
+
+// passwordPolicyError 422 พร้อมรายการกฎที่ไม่ผ่านของฟิลด์รหัสผ่าน
+func passwordPolicyError(c *fiber.Ctx, field string, pe *password.PolicyError) error {
+	return response.Invalid(c, "PASSWORD_POLICY", "password does not meet the password policy",
+		map[string]interface{}{field: pe.Violations})
+}
+
+func clientInfo(c *fiber.Ctx) auth.ClientInfo {
+	return auth.ClientInfo{IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
 }
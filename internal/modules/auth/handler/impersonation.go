@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+
+	authservice "github.com/Marugo/birdlax/internal/modules/auth/service"
+	"github.com/Marugo/birdlax/internal/shared/response"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type impersonateReq struct {
+	Reason     string `json:"reason"`      // เช่นเลข ticket ที่ user แจ้งมา
+	AllowWrite bool   `json:"allow_write"` // ค่าเริ่มต้น read-only
+}
+
+// POST /users/:id/impersonate (admin)
+func (h *HTTPHandler) Impersonate(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+	var r impersonateReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	imp, err := h.svc.Impersonate(c.Context(), actorID, c.Params("id"), r.Reason, r.AllowWrite, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+		case errors.Is(err, authservice.ErrImpersonationReasonRequired):
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		case errors.Is(err, authservice.ErrImpersonationNotAllowed):
+			return response.Err(c, fiber.StatusForbidden, "IMPERSONATION_NOT_ALLOWED", err.Error())
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{
+		"access_token": imp.Access,
+		"session_id":   imp.ID,
+		"subject_id":   imp.SubjectID,
+		"read_only":    imp.ReadOnly,
+		"expires_at":   imp.ExpiresAt,
+	})
+}
+
+// DELETE /auth/impersonation — จบ impersonation (เรียกด้วย impersonation token; read-only ก็เรียกได้)
+func (h *HTTPHandler) EndImpersonation(c *fiber.Ctx) error {
+	sessionID, _ := c.Locals("impersonation_id").(string)
+	actorID, _ := c.Locals("actor_id").(string)
+	subjectID, _ := c.Locals("user_id").(string)
+	if err := h.svc.EndImpersonation(c.Context(), sessionID, actorID, subjectID, clientInfo(c)); err != nil {
+		if errors.Is(err, authservice.ErrNotImpersonating) {
+			return response.Err(c, fiber.StatusBadRequest, "NOT_IMPERSONATING", err.Error())
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
@@ -0,0 +1,13 @@
+package handler
+
+import (
+	"github.com/Marugo/birdlax/internal/shared/security"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GET /.well-known/jwks.json — public key สำหรับ service อื่นใช้ verify access token
+// ตอบเป็น JWKS ตรงๆ (ไม่ห่อ response.OK) เพราะ client มาตรฐานคาดหวังรูปแบบนี้
+func (h *HTTPHandler) JWKS(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(security.PublicJWKS())
+}
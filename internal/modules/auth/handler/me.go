@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	authservice "github.com/Marugo/birdlax/internal/modules/auth/service"
+	"github.com/Marugo/birdlax/internal/modules/user"
+	"github.com/Marugo/birdlax/internal/modules/user/dto"
+	"github.com/Marugo/birdlax/internal/shared/password"
+	"github.com/Marugo/birdlax/internal/shared/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ฟิลด์ที่ผู้ใช้แก้เองได้ผ่าน PATCH /auth/me (ที่เหลือต้องให้ HR แก้ผ่าน /users/:id)
+var selfEditableFields = map[string]bool{"phone": true}
+
+type updateMeReq struct {
+	Phone *string `json:"phone"`
+}
+
+type changePasswordReq struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
+}
+
+// PATCH /auth/me
+func (h *HTTPHandler) UpdateMe(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(c.Body(), &raw); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	for k := range raw {
+		if !selfEditableFields[k] {
+			return response.Err(c, fiber.StatusBadRequest, "FIELD_NOT_EDITABLE", k+" cannot be changed here")
+		}
+	}
+	var r updateMeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if r.Phone != nil {
+		p := strings.TrimSpace(*r.Phone)
+		if len(p) > 50 {
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "phone must be at most 50 characters")
+		}
+		r.Phone = &p
+	}
+
+	u, err := h.userSvc.Update(c.Context(), userID, user.UpdateInput{Phone: r.Phone})
+	if err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+	}
+	return response.OK(c, meResponse(dto.FromModel(u)))
+}
+
+// POST /auth/me/password — สำเร็จแล้ว session อื่นทั้งหมดถูก revoke; เครื่องนี้ได้ token ชุดใหม่
+func (h *HTTPHandler) ChangeMyPassword(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var r changePasswordReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	res, err := h.svc.ChangePassword(c.Context(), userID, r.CurrentPassword, r.NewPassword, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrCurrentPasswordInvalid):
+			return response.Err(c, fiber.StatusBadRequest, "INVALID_CURRENT_PASSWORD", err.Error())
+		case errors.Is(err, authservice.ErrPasswordManagedExternally):
+			return response.Err(c, fiber.StatusConflict, "PASSWORD_MANAGED_EXTERNALLY", err.Error())
+		}
+		var pe *password.PolicyError
+		if errors.As(err, &pe) {
+			return passwordPolicyError(c, "new_password", pe)
+		}
+		return loginError(c, err)
+	}
+	return writeLoginResult(c, res)
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/auth"
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+type fakeUserSvc struct {
+	user.Service
+	id string
+	in *user.UpdateInput
+}
+
+func (f *fakeUserSvc) Update(_ context.Context, id string, in user.UpdateInput) (*usermodels.User, error) {
+	f.id, f.in = id, &in
+	return &usermodels.User{ID: id, Phone: in.Phone}, nil
+}
+
+func newMeApp(us user.Service) *fiber.App {
+	app := fiber.New()
+	h := NewHTTPHandler(nil, us, auth.OIDCConfig{})
+	app.Patch("/auth/me", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "u1")
+		return c.Next()
+	}, h.UpdateMe)
+	return app
+}
+
+func patchMe(t *testing.T, app *fiber.App, body string) int {
+	t.Helper()
+	req := httptest.NewRequest("PATCH", "/auth/me", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestUpdateMeOnlyTouchesPhone(t *testing.T) {
+	us := &fakeUserSvc{}
+	app := newMeApp(us)
+
+	if code := patchMe(t, app, `{"phone":" 0812345678 "}`); code != 200 {
+		t.Fatalf("status = %d", code)
+	}
+	if us.id != "u1" || us.in == nil || us.in.Phone == nil || *us.in.Phone != "0812345678" {
+		t.Fatalf("update = %s %+v", us.id, us.in)
+	}
+	in := *us.in
+	in.Phone = nil
+	if !reflect.DeepEqual(in, user.UpdateInput{}) {
+		t.Fatalf("unexpected fields set: %+v", in)
+	}
+
+	us.in = nil
+	if code := patchMe(t, app, `{"role":"admin"}`); code != 400 || us.in != nil {
+		t.Fatalf("role: status = %d, update = %+v", code, us.in)
+	}
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+
+	authservice "github.com/Marugo/birdlax/internal/modules/auth/service"
+	"github.com/Marugo/birdlax/internal/shared/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type mfaChallengeReq struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"` // รหัส 6 หลัก หรือ recovery code
+}
+
+type mfaCodeReq struct {
+	Code string `json:"code"`
+}
+
+// ===== ขั้นที่สองของ login (ใช้ mfa_token แทน bearer) =====
+
+// POST /auth/mfa/verify
+func (h *HTTPHandler) VerifyMFA(c *fiber.Ctx) error {
+	var r mfaChallengeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	res, err := h.svc.VerifyMFA(c.Context(), r.MFAToken, r.Code, clientInfo(c))
+	if err != nil {
+		return loginError(c, err)
+	}
+	return writeLoginResult(c, res)
+}
+
+// POST /auth/mfa/setup — policy บังคับ MFA แต่ยังไม่ได้ลงทะเบียน
+func (h *HTTPHandler) SetupMFA(c *fiber.Ctx) error {
+	var r mfaChallengeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	e, err := h.svc.BeginMFASetup(c.Context(), r.MFAToken)
+	if err != nil {
+		return loginError(c, err)
+	}
+	return response.OK(c, fiber.Map{"secret": e.Secret, "otpauth_uri": e.URI})
+}
+
+// POST /auth/mfa/setup/confirm — ยืนยันแล้วได้ token + recovery codes
+func (h *HTTPHandler) ConfirmSetupMFA(c *fiber.Ctx) error {
+	var r mfaChallengeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	res, err := h.svc.CompleteMFASetup(c.Context(), r.MFAToken, r.Code, clientInfo(c))
+	if err != nil {
+		return loginError(c, err)
+	}
+	return writeLoginResult(c, res)
+}
+
+// ===== จัดการ MFA ของตัวเอง (ต้อง login แล้ว) =====
+
+// GET /auth/mfa
+func (h *HTTPHandler) MFAStatus(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	st, err := h.svc.MFAStatus(c.Context(), userID)
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{
+		"enabled":                  st.Enabled,
+		"required":                 st.Required,
+		"recovery_codes_remaining": st.RecoveryCodesRemaining,
+	})
+}
+
+// POST /auth/mfa/enroll
+func (h *HTTPHandler) EnrollMFA(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	e, err := h.svc.BeginMFAEnrollment(c.Context(), userID)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return response.OK(c, fiber.Map{"secret": e.Secret, "otpauth_uri": e.URI})
+}
+
+// POST /auth/mfa/confirm
+func (h *HTTPHandler) ConfirmMFA(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var r mfaCodeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	codes, err := h.svc.ConfirmMFAEnrollment(c.Context(), userID, r.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return response.OK(c, fiber.Map{"recovery_codes": codes})
+}
+
+// POST /auth/mfa/recovery-codes — ออกชุดใหม่ (ชุดเก่าใช้ไม่ได้)
+func (h *HTTPHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var r mfaCodeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	codes, err := h.svc.RegenerateRecoveryCodes(c.Context(), userID, r.Code)
+	if err != nil {
+		return mfaError(c, err)
+	}
+	return response.OK(c, fiber.Map{"recovery_codes": codes})
+}
+
+// POST /auth/mfa/disable
+func (h *HTTPHandler) DisableMFA(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	var r mfaCodeReq
+	if err := c.BodyParser(&r); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.svc.DisableMFA(c.Context(), userID, r.Code); err != nil {
+		return mfaError(c, err)
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
+
+// DELETE /users/:id/mfa (admin, hr) — user ทำเครื่องหาย; MFA ของ admin/hr reset ได้เฉพาะ admin
+func (h *HTTPHandler) ResetUserMFA(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+	if err := h.svc.ResetMFA(c.Context(), actorID, c.Params("id")); err != nil {
+		if errors.Is(err, authservice.ErrMFAResetNotAllowed) {
+			return response.Err(c, fiber.StatusForbidden, "FORBIDDEN", err.Error())
+		}
+		return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
+
+func mfaError(c *fiber.Ctx, err error) error {
+	var te *authservice.ThrottledError
+	if errors.As(err, &te) {
+		return loginError(c, err)
+	}
+	switch {
+	case errors.Is(err, authservice.ErrMFAInvalidCode):
+		return response.Err(c, fiber.StatusBadRequest, "INVALID_MFA_CODE", err.Error())
+	case errors.Is(err, authservice.ErrMFAAlreadyEnabled):
+		return response.Err(c, fiber.StatusConflict, "MFA_ALREADY_ENABLED", err.Error())
+	case errors.Is(err, authservice.ErrMFANotEnabled):
+		return response.Err(c, fiber.StatusBadRequest, "MFA_NOT_ENABLED", err.Error())
+	case errors.Is(err, authservice.ErrMFARequiredByPolicy):
+		return response.Err(c, fiber.StatusForbidden, "MFA_REQUIRED_BY_POLICY", err.Error())
+	}
+	return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+}
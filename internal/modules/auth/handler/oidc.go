@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	authservice "github.com/Marugo/birdlax/internal/modules/auth/service"
+	"github.com/Marugo/birdlax/internal/shared/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+const oidcFlowCookie = "oidc_flow"
+
+// GET /auth/oidc/login — redirect ไป IdP; state/nonce/verifier เก็บใน cookie อายุสั้น
+func (h *HTTPHandler) OIDCLogin(c *fiber.Ctx) error {
+	authURL, flow, err := h.svc.OIDCAuthURL(c.Context())
+	if err != nil {
+		if errors.Is(err, authservice.ErrOIDCDisabled) {
+			return response.Err(c, fiber.StatusNotFound, "SSO_DISABLED", err.Error())
+		}
+		return response.Err(c, fiber.StatusBadGateway, "SSO_UNAVAILABLE", err.Error())
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcFlowCookie,
+		Value:    flow.State + "." + flow.Nonce + "." + flow.Verifier,
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax", // callback เป็น top-level GET จาก IdP จึงยังส่ง cookie มา
+		Path:     "/",
+		MaxAge:   int((10 * time.Minute).Seconds()),
+	})
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// GET /auth/oidc/callback — แลก code แล้ว set refresh cookie เหมือน /auth/login
+// จากนั้น redirect กลับ frontend ซึ่งเรียก /auth/refresh เพื่อเอา access token
+func (h *HTTPHandler) OIDCCallback(c *fiber.Ctx) error {
+	flow := readOIDCFlow(c.Cookies(oidcFlowCookie))
+	c.Cookie(&fiber.Cookie{
+		Name:   oidcFlowCookie,
+		Value:  "",
+		MaxAge: -1, Path: "/",
+		HTTPOnly: true, Secure: false, SameSite: "Lax",
+	})
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		return h.oidcFail(c, "SSO_DENIED")
+	}
+	if flow.State == "" || c.Query("state") != flow.State {
+		return h.oidcFail(c, "SSO_INVALID_STATE")
+	}
+	res, err := h.svc.OIDCLogin(c.Context(), c.Query("code"), flow, clientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrOIDCNotProvisioned):
+			return h.oidcFail(c, "SSO_NOT_PROVISIONED")
+		case errors.Is(err, authservice.ErrInvalidCredentials):
+			return h.oidcFail(c, "ACCOUNT_DISABLED")
+		default:
+			return h.oidcFail(c, "SSO_FAILED")
+		}
+	}
+	if res.MFARequired {
+		return h.oidcMFA(c, res)
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    res.Refresh,
+		HTTPOnly: true,
+		Secure:   false,
+		SameSite: "Lax",
+		Path:     "/",
+		MaxAge:   int((7 * 24 * time.Hour).Seconds()),
+	})
+	return c.Redirect(h.oidc.PostLoginURL, fiber.StatusFound)
+}
+
+// oidcFail ส่งกลับ frontend พร้อม ?sso_error=CODE (browser flow ตอบ JSON ไม่ได้)
+func (h *HTTPHandler) oidcFail(c *fiber.Ctx, code string) error {
+	u, err := url.Parse(h.oidc.PostLoginURL)
+	if err != nil {
+		return response.Err(c, fiber.StatusUnauthorized, code, "sso login failed")
+	}
+	q := u.Query()
+	q.Set("sso_error", code)
+	u.RawQuery = q.Encode()
+	return c.Redirect(u.String(), fiber.StatusFound)
+}
+
+// oidcMFA ส่งกลับ frontend ด้วย ?mfa=verify|enroll และ mfa_token ใน fragment
+// (fragment ไม่ถูกส่งไป server/log) จากนั้น frontend เรียก /auth/mfa/verify หรือ /auth/mfa/setup
+func (h *HTTPHandler) oidcMFA(c *fiber.Ctx, res *auth.LoginResult) error {
+	u, err := url.Parse(h.oidc.PostLoginURL)
+	if err != nil {
+		return response.Err(c, fiber.StatusUnauthorized, "MFA_REQUIRED", "mfa required")
+	}
+	step := "verify"
+	if res.MFAEnrollRequired {
+		step = "enroll"
+	}
+	q := u.Query()
+	q.Set("mfa", step)
+	u.RawQuery = q.Encode()
+	u.Fragment = url.Values{"mfa_token": {res.MFAToken}}.Encode()
+	return c.Redirect(u.String(), fiber.StatusFound)
+}
+
+func readOIDCFlow(v string) auth.OIDCFlow {
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return auth.OIDCFlow{}
+	}
+	return auth.OIDCFlow{State: parts[0], Nonce: parts[1], Verifier: parts[2]}
+}
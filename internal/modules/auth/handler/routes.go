@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,6 +10,54 @@ func Register(r fiber.Router, h *HTTPHandler) {
 	g := r.Group("/auth")
 	g.Post("/login", h.Login)
 	g.Post("/refresh", h.Refresh)
+	g.Post("/forgot-password", h.ForgotPassword)
+	g.Post("/reset-password", h.ResetPassword)
+	g.Get("/oidc/login", h.OIDCLogin)
+	g.Get("/oidc/callback", h.OIDCCallback)
+
+	// MFA ขั้นที่สองของ login (ยืนยันด้วย mfa_token)
+	g.Post("/mfa/verify", h.VerifyMFA)
+	g.Post("/mfa/setup", h.SetupMFA)
+	g.Post("/mfa/setup/confirm", h.ConfirmSetupMFA)
 	g.Post("/logout", middleware.AuthRequired(), h.Logout)
 	g.Get("/me", middleware.AuthRequired(), h.Me)
+	g.Delete("/impersonation", middleware.AuthRequired(), h.EndImpersonation)
+
+	// ความปลอดภัยของ account ทำแทนกันไม่ได้ (impersonation token ถูกปฏิเสธ)
+	self := g.Group("", middleware.AuthRequired(), middleware.DenyImpersonation())
+	self.Patch("/me", h.UpdateMe) // email/เบอร์โทรใช้กู้ account ได้
+	self.Post("/me/password", h.ChangeMyPassword)
+
+	// sessions ของตัวเอง
+	self.Get("/sessions", h.ListMySessions)
+	self.Delete("/sessions", h.RevokeMySessions)
+	self.Delete("/sessions/:id", h.RevokeMySession)
+
+	// MFA ของตัวเอง
+	self.Get("/mfa", h.MFAStatus)
+	self.Post("/mfa/enroll", h.EnrollMFA)
+	self.Post("/mfa/confirm", h.ConfirmMFA)
+	self.Post("/mfa/recovery-codes", h.RegenerateRecoveryCodes)
+	self.Post("/mfa/disable", h.DisableMFA)
+}
+
+// RegisterWellKnown ลงที่ root ของ app (ไม่อยู่ใต้ /api/v1)
+func RegisterWellKnown(r fiber.Router, h *HTTPHandler) {
+	r.Get("/.well-known/jwks.json", h.JWKS)
+}
+
+// RegisterAdminRoutes sessions/MFA ของ user อื่น ใต้ /users/:id (ต้องมี user.security)
+// ทุก route ต้องเป็นคนตัวจริง: API key ของ service account ใช้ไม่ได้
+func RegisterAdminRoutes(r fiber.Router, h *HTTPHandler) {
+	// ใส่ middleware ราย route: Group("/users", mw) จะไปครอบ route อื่นของ /users ด้วย
+	human := []fiber.Handler{middleware.RequireUser(), middleware.DenyImpersonation()}
+	security := []fiber.Handler{middleware.RequireUser(), middleware.DenyImpersonation(), middleware.RequirePermission(authz.PermUserSecurity)}
+	g := r.Group("/users")
+	g.Get("/:id/sessions", append(security, h.ListUserSessions)...)
+	g.Delete("/:id/sessions", append(security, h.RevokeUserSessions)...)
+	g.Delete("/:id/sessions/:sessionID", append(security, h.RevokeUserSession)...)
+	g.Delete("/:id/mfa", append(security, h.ResetUserMFA)...)
+
+	// act as user: คนตัวจริงที่มี user.impersonate เท่านั้น
+	g.Post("/:id/impersonate", append(human, middleware.RequirePermission(authz.PermUserImpersonate), h.Impersonate)...)
 }
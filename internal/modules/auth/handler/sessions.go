@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	authservice "github.com/Marugo/birdlax/internal/modules/auth/service"
+	"github.com/Marugo/birdlax/internal/shared/response"
+	"github.com/gofiber/fiber/v2"
+)
+
+type sessionResp struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Current   bool      `json:"current"`
+}
+
+func toSessionResp(items []auth.Session) []sessionResp {
+	out := make([]sessionResp, 0, len(items))
+	for _, s := range items {
+		out = append(out, sessionResp{
+			ID:        s.ID,
+			UserAgent: s.UserAgent,
+			IP:        s.IP,
+			CreatedAt: s.CreatedAt,
+			ExpiresAt: s.ExpiresAt,
+			Current:   s.Current,
+		})
+	}
+	return out
+}
+
+// GET /auth/sessions
+func (h *HTTPHandler) ListMySessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	items, err := h.svc.ListSessions(c.Context(), userID, c.Cookies("refresh_token"))
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, toSessionResp(items))
+}
+
+// DELETE /auth/sessions/:id
+func (h *HTTPHandler) RevokeMySession(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	return h.revokeSession(c, userID, c.Params("id"))
+}
+
+// DELETE /auth/sessions — log out everywhere (รวมเครื่องนี้ด้วย)
+func (h *HTTPHandler) RevokeMySessions(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if err := h.svc.RevokeAllSessions(c.Context(), userID); err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:   "refresh_token",
+		Value:  "",
+		MaxAge: -1, Path: "/",
+		HTTPOnly: true, Secure: false, SameSite: "Lax",
+	})
+	return response.OK(c, fiber.Map{"ok": true})
+}
+
+// GET /users/:id/sessions (admin, hr)
+func (h *HTTPHandler) ListUserSessions(c *fiber.Ctx) error {
+	items, err := h.svc.ListSessions(c.Context(), c.Params("id"), "")
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, toSessionResp(items))
+}
+
+// DELETE /users/:id/sessions/:sessionID (admin, hr)
+func (h *HTTPHandler) RevokeUserSession(c *fiber.Ctx) error {
+	return h.revokeSession(c, c.Params("id"), c.Params("sessionID"))
+}
+
+// DELETE /users/:id/sessions (admin, hr)
+func (h *HTTPHandler) RevokeUserSessions(c *fiber.Ctx) error {
+	if err := h.svc.RevokeAllSessions(c.Context(), c.Params("id")); err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
+
+func (h *HTTPHandler) revokeSession(c *fiber.Ctx, userID, sessionID string) error {
+	if err := h.svc.RevokeSession(c.Context(), userID, sessionID); err != nil {
+		if errors.Is(err, authservice.ErrSessionNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", err.Error())
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"ok": true})
+}
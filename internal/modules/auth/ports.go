@@ -1,15 +1,217 @@
 package auth
 
-import "context"
+import (
+	"context"
+	"time"
+
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// ClientInfo ข้อมูลฝั่ง client ที่ handler ส่งต่อให้ service
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// Session คือ refresh token ที่ยังใช้ได้หนึ่งตัว (หนึ่ง browser/device)
+type Session struct {
+	ID        string // jti ของ refresh token
+	FamilyID  string // rotation chain ที่ token นี้อยู่ (login ครั้งเดียวกัน)
+	UserID    string
+	UserAgent string
+	IP        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Current   bool // เป็น session ของ request นี้เอง
+}
+
+// RefreshRecord สถานะของ refresh token หนึ่งตัว ใช้ตรวจการ replay
+type RefreshRecord struct {
+	Session
+	Revoked    bool
+	ReplacedBy string // jti ที่มาแทนตอน rotate (ว่าง = ถูก revoke ตรงๆ เช่น logout)
+}
+
+// SecurityEvent เหตุการณ์ด้านความปลอดภัยที่ต้องเก็บไว้ตรวจสอบย้อนหลัง
+type SecurityEvent struct {
+	Type      string
+	UserID    string
+	IP        string
+	UserAgent string
+	Detail    string
+}
+
+// Identity ข้อมูลเพิ่มเติมจากแหล่งที่ยืนยันรหัสผ่าน
+type Identity struct {
+	Role     string // role ที่ map จาก group (ว่าง = ใช้ role เดิมใน DB)
+	Provider string // ชื่อ verifier ที่ยืนยันรหัสผ่าน (local, ldap)
+}
+
+// CredentialVerifier ตรวจรหัสผ่านของ user กับแหล่งหนึ่ง (local bcrypt, LDAP/AD ...)
+// svc.Login ลองตามลำดับที่ตั้งค่าไว้
+type CredentialVerifier interface {
+	Name() string
+	Verify(ctx context.Context, u *usermodels.User, password string) (*Identity, error)
+}
+
+// LDAPConfig ตั้งค่า LDAP/AD (โหลดจาก config.LDAP)
+type LDAPConfig struct {
+	URL                string // ldap://host:389 หรือ ldaps://host:636
+	StartTLS           bool
+	InsecureSkipVerify bool
+	BindDN             string // service account สำหรับค้นหา user
+	BindPassword       string
+	BaseDN             string
+	UserFilter         string            // %s = employee code (escape แล้ว)
+	GroupAttr          string            // เช่น memberOf
+	GroupRoles         map[string]string // group DN (ตัวพิมพ์เล็ก) -> role
+	DefaultRole        string            // role เมื่อไม่ match group ใด (ว่าง = ไม่เปลี่ยน)
+	Timeout            time.Duration
+}
+
+// OIDCConfig ตั้งค่า SSO (โหลดจาก config.OIDC); IssuerURL ว่าง = ปิด SSO
+type OIDCConfig struct {
+	IssuerURL       string
+	ClientID        string
+	ClientSecret    string
+	RedirectURL     string
+	Scopes          []string
+	EmployeeIDClaim string // claim ที่ map เป็น EmployeeCode (ถ้าไม่มีจะ map ด้วย email)
+	AutoCreate      bool   // สร้าง user ใหม่ตอน login ครั้งแรก (just-in-time)
+	DefaultRole     string // role ของ user ที่สร้างแบบ just-in-time
+	PostLoginURL    string // frontend ที่ redirect ไปหลัง login สำเร็จ
+}
+
+// OIDCFlow ค่าที่ต้องจำไว้ระหว่าง redirect ไป IdP และกลับมาที่ callback
+type OIDCFlow struct {
+	State    string
+	Nonce    string
+	Verifier string // PKCE code_verifier
+}
+
+// LoginResult ผลของ Login; ถ้า MFARequired จะยังไม่มี access/refresh แต่ได้ MFAToken ไปยืนยันขั้นที่สอง
+type LoginResult struct {
+	Access            string
+	Refresh           string
+	MFARequired       bool
+	MFAEnrollRequired bool   // policy บังคับ MFA แต่ยังไม่ได้ลงทะเบียน
+	MFAToken          string // challenge token อายุสั้น
+	RecoveryCodes     []string
+
+	PasswordChangeRequired bool // รหัสหมดอายุ: access token ใช้ได้แค่ route เปลี่ยนรหัส
+}
+
+// MFAFactor TOTP ของ user หนึ่งคน (Secret ที่ repo เก็บอาจถูกเข้ารหัส)
+type MFAFactor struct {
+	UserID       string
+	Secret       string
+	ConfirmedAt  *time.Time // nil = เริ่มลงทะเบียนแต่ยังไม่ยืนยัน
+	LastUsedStep int64      // TOTP step ล่าสุดที่ใช้ไปแล้ว (กัน replay)
+}
+
+type MFAStatus struct {
+	Enabled                bool
+	Required               bool // บังคับโดย policy ตาม role
+	RecoveryCodesRemaining int64
+}
+
+type MFAEnrollment struct {
+	Secret string
+	URI    string // otpauth:// สำหรับ QR
+}
+
+// Impersonation access token ที่ admin ใช้ "ดูแบบ user คนนั้น" (ไม่มี refresh)
+type Impersonation struct {
+	ID        string // jti ใช้ค้น audit log ของ session นี้
+	Access    string
+	SubjectID string
+	ReadOnly  bool
+	ExpiresAt time.Time
+}
+
+// Throttle สถานะ login ผิดของ key หนึ่ง (account หรือ IP)
+type Throttle struct {
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+}
 
 type Service interface {
-	Login(ctx context.Context, employeeCode, password string) (access, refresh string, err error)
-	Refresh(ctx context.Context, refreshToken string) (access, newRefresh string, err error)
+	Login(ctx context.Context, employeeCode, password string, client ClientInfo) (*LoginResult, error)
+	Refresh(ctx context.Context, refreshToken string, client ClientInfo) (access, newRefresh string, err error)
 	Logout(ctx context.Context, refreshToken string) error
+
+	// sessions ("log out everywhere")
+	ListSessions(ctx context.Context, userID, currentRefresh string) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+	RevokeAllSessions(ctx context.Context, userID string) error
+
+	// OIDC SSO (authorization code + PKCE)
+	OIDCAuthURL(ctx context.Context) (url string, flow OIDCFlow, err error)
+	OIDCLogin(ctx context.Context, code string, flow OIDCFlow, client ClientInfo) (*LoginResult, error)
+
+	// TOTP MFA
+	VerifyMFA(ctx context.Context, mfaToken, code string, client ClientInfo) (*LoginResult, error)
+	BeginMFASetup(ctx context.Context, mfaToken string) (*MFAEnrollment, error)
+	CompleteMFASetup(ctx context.Context, mfaToken, code string, client ClientInfo) (*LoginResult, error)
+	MFAStatus(ctx context.Context, userID string) (*MFAStatus, error)
+	BeginMFAEnrollment(ctx context.Context, userID string) (*MFAEnrollment, error)
+	ConfirmMFAEnrollment(ctx context.Context, userID, code string) (recoveryCodes []string, err error)
+	RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID, code string) error
+	ResetMFA(ctx context.Context, actorID, userID string) error // admin/hr: user ทำเครื่องหาย (admin/hr ด้วยกัน admin เท่านั้น)
+
+	// self-service password change (ตรวจรหัสเดิม, revoke session อื่น)
+	ChangePassword(ctx context.Context, userID, currentPassword, newPassword string, client ClientInfo) (*LoginResult, error)
+
+	// admin "act as user" (อายุสั้น, read-only เป็นค่าเริ่มต้น, ทุก request ลง audit log)
+	Impersonate(ctx context.Context, actorID, subjectID, reason string, allowWrite bool, client ClientInfo) (*Impersonation, error)
+	// EndImpersonation revoke token ของ session นี้ทันที (เรียกด้วย impersonation token เอง)
+	EndImpersonation(ctx context.Context, sessionID, actorID, subjectID string, client ClientInfo) error
+
+	// password recovery
+	// ForgotPassword คืน error เฉพาะ *ThrottledError ของ IP (ไม่ขึ้นกับว่ามี account หรือไม่)
+	ForgotPassword(ctx context.Context, identifier string, client ClientInfo) error
+	// ResetPassword ใช้ได้เฉพาะเมื่อ local เป็น verifier เดียว (ไม่งั้น ErrPasswordManagedExternally)
+	ResetPassword(ctx context.Context, token, newPassword string) error
 }
 
 type Repository interface {
-	SaveRefresh(ctx context.Context, userID, jti string, expiresAt int64) error
+	SaveRefresh(ctx context.Context, s Session) error
 	IsRevoked(ctx context.Context, jti string) (bool, error)
 	Revoke(ctx context.Context, jti string) error
+	RevokeForUser(ctx context.Context, userID, jti string) error
+	RevokeAllForUser(ctx context.Context, userID string) error
+	ListActiveSessions(ctx context.Context, userID string) ([]Session, error)
+
+	// refresh token families (rotation + reuse detection)
+	FindRefresh(ctx context.Context, jti string) (*RefreshRecord, error)
+	// RotateRefresh revoke oldJTI แล้วบันทึก next แบบ atomic; rotated=false ถ้า oldJTI ถูกใช้ไปแล้ว
+	RotateRefresh(ctx context.Context, oldJTI string, next Session) (rotated bool, err error)
+	RevokeFamily(ctx context.Context, familyID string) error
+	SaveSecurityEvent(ctx context.Context, e SecurityEvent) error
+
+	// per-IP login throttling (key = IP หรือ key ที่มี prefix เช่น "pwreset:" ของ forgot-password)
+	GetIPThrottle(ctx context.Context, ip string) (*Throttle, error)
+	// RecordIPFailure นับ login ผิดของ IP +1 แบบ atomic (upsert) กติกาเดียวกับ user.Repository.RecordLoginFailure
+	RecordIPFailure(ctx context.Context, ip string, now, staleBefore time.Time, threshold int, lockFor time.Duration) (*Throttle, error)
+
+	// MFA (recovery code เก็บเป็น hash)
+	GetMFA(ctx context.Context, userID string) (*MFAFactor, error) // ไม่มี = nil, nil
+	SaveMFA(ctx context.Context, f MFAFactor) error
+	ConfirmMFA(ctx context.Context, userID string, step int64, recoveryHashes []string) error
+	MarkMFAStep(ctx context.Context, userID string, step int64) (bool, error) // false = step นี้ถูกใช้ไปแล้ว
+	DeleteMFA(ctx context.Context, userID string) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error
+	UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID string) (int64, error)
+
+	// impersonation ที่จบก่อนหมดอายุ (middleware ปฏิเสธ token ของ session นี้)
+	RevokeImpersonation(ctx context.Context, sessionID string, expiresAt time.Time) error
+	ImpersonationRevoked(ctx context.Context, sessionID string) (bool, error)
+
+	// password reset tokens (เก็บเป็น hash)
+	SaveResetToken(ctx context.Context, userID, tokenHash string, expiresAt int64) error
+	FindResetToken(ctx context.Context, tokenHash string) (userID string, err error) // ตรวจเฉยๆ ไม่ mark ว่าใช้แล้ว
+	ConsumeResetToken(ctx context.Context, tokenHash string) (userID string, err error)
 }
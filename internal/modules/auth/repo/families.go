@@ -0,0 +1,85 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/auth"
+)
+
+// SecurityEvent log เหตุการณ์ เช่น refresh token ถูก replay
+type SecurityEvent struct {
+	ID        string `gorm:"type:char(36);primaryKey"`
+	Type      string `gorm:"size:64;index"`
+	UserID    string `gorm:"size:36;index"`
+	IP        string `gorm:"size:64"`
+	UserAgent string `gorm:"size:255"`
+	Detail    string `gorm:"type:text"`
+	CreatedAt time.Time
+}
+
+func (r *gormRepo) FindRefresh(ctx context.Context, jti string) (*auth.RefreshRecord, error) {
+	var rt RefreshToken
+	if err := r.db.WithContext(ctx).First(&rt, "jti = ?", jti).Error; err != nil {
+		return nil, err
+	}
+	family := rt.FamilyID
+	if family == "" { // token ที่ออกก่อนมี family
+		family = rt.JTI
+	}
+	return &auth.RefreshRecord{
+		Session: auth.Session{
+			ID:        rt.JTI,
+			FamilyID:  family,
+			UserID:    rt.UserID,
+			UserAgent: rt.UserAgent,
+			IP:        rt.IP,
+			CreatedAt: rt.CreatedAt,
+			ExpiresAt: time.Unix(rt.ExpiresAt, 0),
+		},
+		Revoked:    rt.Revoked || rt.ExpiresAt <= time.Now().Unix(),
+		ReplacedBy: rt.ReplacedBy,
+	}, nil
+}
+
+// RotateRefresh ใช้ conditional update (revoked = false) กัน request ซ้อนกัน rotate token เดียวกันได้สองครั้ง
+func (r *gormRepo) RotateRefresh(ctx context.Context, oldJTI string, next auth.Session) (bool, error) {
+	rotated := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&RefreshToken{}).
+			Where("jti = ? AND revoked = ?", oldJTI, false).
+			Updates(map[string]any{"revoked": true, "replaced_by": next.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(newRefreshRow(next)).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *gormRepo) RevokeFamily(ctx context.Context, familyID string) error {
+	return r.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("(family_id = ? OR jti = ?) AND revoked = ?", familyID, familyID, false).
+		Update("revoked", true).Error
+}
+
+func (r *gormRepo) SaveSecurityEvent(ctx context.Context, e auth.SecurityEvent) error {
+	return r.db.WithContext(ctx).Create(&SecurityEvent{
+		ID:        uuid.NewString(),
+		Type:      e.Type,
+		UserID:    e.UserID,
+		IP:        truncate(e.IP, 64),
+		UserAgent: truncate(e.UserAgent, 255),
+		Detail:    e.Detail,
+	}).Error
+}
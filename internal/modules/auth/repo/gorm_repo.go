@@ -2,9 +2,12 @@ package repo
 
 import (
 	"context"
+	"errors"
 	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/Marugo/birdlax/internal/modules/auth"
 )
@@ -14,16 +17,46 @@ type RefreshToken struct {
 	UserID    string `gorm:"size:36;index"`
 	ExpiresAt int64  `gorm:"index"`
 	Revoked   bool   `gorm:"index"`
-	CreatedAt time.Time
+	UserAgent string `gorm:"size:255"`
+	IP        string `gorm:"size:64"`
+
+	// rotation chain: ทุก token ที่ rotate ต่อกันมาจาก login เดียวกันมี FamilyID เดียวกัน
+	FamilyID   string `gorm:"size:64;index"`
+	ReplacedBy string `gorm:"size:64"` // jti ของ token ที่มาแทน (ว่าง = ยังไม่ถูก rotate)
+	CreatedAt  time.Time
+}
+
+// LoginThrottle นับจำนวน login ผิดต่อ IP
+type LoginThrottle struct {
+	IP           string `gorm:"primaryKey;size:64"`
+	Failures     int    `gorm:"not null;default:0"`
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
+	UpdatedAt    time.Time
 }
 
 type gormRepo struct{ db *gorm.DB }
 
 func NewGormRepository(db *gorm.DB) auth.Repository { return &gormRepo{db: db} }
 
-func (r *gormRepo) SaveRefresh(ctx context.Context, userID, jti string, expiresAt int64) error {
-	rt := &RefreshToken{JTI: jti, UserID: userID, ExpiresAt: expiresAt, Revoked: false}
-	return r.db.WithContext(ctx).Create(rt).Error
+func (r *gormRepo) SaveRefresh(ctx context.Context, s auth.Session) error {
+	return r.db.WithContext(ctx).Create(newRefreshRow(s)).Error
+}
+
+func newRefreshRow(s auth.Session) *RefreshToken {
+	family := s.FamilyID
+	if family == "" {
+		family = s.ID
+	}
+	return &RefreshToken{
+		JTI:       s.ID,
+		UserID:    s.UserID,
+		ExpiresAt: s.ExpiresAt.Unix(),
+		Revoked:   false,
+		UserAgent: truncate(s.UserAgent, 255),
+		IP:        truncate(s.IP, 64),
+		FamilyID:  family,
+	}
 }
 
 func (r *gormRepo) IsRevoked(ctx context.Context, jti string) (bool, error) {
@@ -42,3 +75,99 @@ func (r *gormRepo) Revoke(ctx context.Context, jti string) error {
 		Where("jti = ?", jti).
 		Update("revoked", true).Error
 }
+
+// RevokeForUser revoke เฉพาะ token ของ user นั้น (กันลบ session คนอื่นด้วย id)
+func (r *gormRepo) RevokeForUser(ctx context.Context, userID, jti string) error {
+	res := r.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("jti = ? AND user_id = ? AND revoked = ?", jti, userID, false).
+		Update("revoked", true)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *gormRepo) ListActiveSessions(ctx context.Context, userID string) ([]auth.Session, error) {
+	var rows []RefreshToken
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, time.Now().Unix()).
+		Order("created_at DESC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := make([]auth.Session, 0, len(rows))
+	for _, rt := range rows {
+		out = append(out, auth.Session{
+			ID:        rt.JTI,
+			FamilyID:  rt.FamilyID,
+			UserID:    rt.UserID,
+			UserAgent: rt.UserAgent,
+			IP:        rt.IP,
+			CreatedAt: rt.CreatedAt,
+			ExpiresAt: time.Unix(rt.ExpiresAt, 0),
+		})
+	}
+	return out, nil
+}
+
+func (r *gormRepo) RevokeAllForUser(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Model(&RefreshToken{}).
+		Where("user_id = ? AND revoked = ?", userID, false).
+		Update("revoked", true).Error
+}
+
+func (r *gormRepo) GetIPThrottle(ctx context.Context, ip string) (*auth.Throttle, error) {
+	var t LoginThrottle
+	if err := r.db.WithContext(ctx).First(&t, "ip = ?", ip).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &auth.Throttle{}, nil // ยังไม่เคยผิด
+		}
+		return nil, err
+	}
+	return &auth.Throttle{Failures: t.Failures, LastFailedAt: t.LastFailedAt, LockedUntil: t.LockedUntil}, nil
+}
+
+func (r *gormRepo) RecordIPFailure(ctx context.Context, ip string, now, staleBefore time.Time, threshold int, lockFor time.Duration) (*auth.Throttle, error) {
+	var t LoginThrottle
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// สร้างแถวถ้ายังไม่มี แล้ว +1 ใน UPDATE เดียว (ยิงพร้อมกันกี่ request ก็นับครบ)
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&LoginThrottle{IP: ip, LastFailedAt: now}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&LoginThrottle{}).Where("ip = ?", ip).Updates(map[string]any{
+			"failures": gorm.Expr(`CASE WHEN failures = 0
+				OR (locked_until IS NOT NULL AND locked_until <= ?)
+				OR (locked_until IS NULL AND last_failed_at < ?)
+				THEN 1 ELSE failures + 1 END`, now, staleBefore),
+			"locked_until":   gorm.Expr("CASE WHEN locked_until > ? THEN locked_until ELSE NULL END", now),
+			"last_failed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&t, "ip = ?", ip).Error; err != nil {
+			return err
+		}
+		if threshold > 0 && t.Failures >= threshold && t.LockedUntil == nil {
+			until := now.Add(lockFor)
+			t.LockedUntil = &until
+			return tx.Model(&LoginThrottle{}).Where("ip = ?", ip).UpdateColumn("locked_until", until).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Throttle{Failures: t.Failures, LastFailedAt: t.LastFailedAt, LockedUntil: t.LockedUntil}, nil
+}
+
+// truncate ตัดที่ n ตัวอักษร (ไม่ใช่ byte) ให้ตรงกับ varchar(n) และไม่ผ่ากลางตัวอักษรไทย
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
@@ -0,0 +1,33 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RevokedImpersonation impersonation token (jti) ที่ถูกจบก่อนหมดอายุ
+// เก็บไว้ถึง ExpiresAt ของ token เท่านั้น หลังจากนั้น token ใช้ไม่ได้เองอยู่แล้ว
+type RevokedImpersonation struct {
+	ID        string    `gorm:"size:64;primaryKey"`
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+func (r *gormRepo) RevokeImpersonation(ctx context.Context, sessionID string, expiresAt time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&RevokedImpersonation{}).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&RevokedImpersonation{ID: sessionID, ExpiresAt: expiresAt}).Error
+	})
+}
+
+func (r *gormRepo) ImpersonationRevoked(ctx context.Context, sessionID string) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&RevokedImpersonation{}).Where("id = ?", sessionID).Count(&n).Error
+	return n > 0, err
+}
@@ -0,0 +1,117 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Marugo/birdlax/internal/modules/auth"
+)
+
+// MFAFactor TOTP ของ user (หนึ่ง user มีได้หนึ่งตัว)
+type MFAFactor struct {
+	UserID       string `gorm:"type:char(36);primaryKey"`
+	Secret       string `gorm:"size:255;not null"`
+	ConfirmedAt  *time.Time
+	LastUsedStep int64 `gorm:"not null;default:0"`
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// MFARecoveryCode ใช้ได้ครั้งเดียว เก็บเฉพาะ hash
+type MFARecoveryCode struct {
+	ID        string `gorm:"type:char(36);primaryKey"`
+	UserID    string `gorm:"size:36;index"`
+	CodeHash  string `gorm:"size:64;index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (r *gormRepo) GetMFA(ctx context.Context, userID string) (*auth.MFAFactor, error) {
+	var f MFAFactor
+	if err := r.db.WithContext(ctx).First(&f, "user_id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &auth.MFAFactor{UserID: f.UserID, Secret: f.Secret, ConfirmedAt: f.ConfirmedAt, LastUsedStep: f.LastUsedStep}, nil
+}
+
+// SaveMFA เริ่มลงทะเบียนใหม่ (แทน factor เดิมที่ยังไม่ยืนยัน)
+func (r *gormRepo) SaveMFA(ctx context.Context, f auth.MFAFactor) error {
+	row := &MFAFactor{UserID: f.UserID, Secret: f.Secret, ConfirmedAt: f.ConfirmedAt, LastUsedStep: f.LastUsedStep}
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(row).Error
+}
+
+// ConfirmMFA เปิดใช้ factor และตั้ง recovery codes ชุดแรกใน transaction เดียว
+func (r *gormRepo) ConfirmMFA(ctx context.Context, userID string, step int64, recoveryHashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&MFAFactor{}).
+			Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]any{"confirmed_at": now, "last_used_step": step})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return replaceRecoveryCodes(tx, userID, recoveryHashes)
+	})
+}
+
+func (r *gormRepo) MarkMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&MFAFactor{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *gormRepo) DeleteMFA(ctx context.Context, userID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&MFARecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&MFAFactor{}).Error
+	})
+}
+
+func (r *gormRepo) ReplaceRecoveryCodes(ctx context.Context, userID string, hashes []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, hashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, hashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&MFARecoveryCode{}).Error; err != nil {
+		return err
+	}
+	rows := make([]MFARecoveryCode, 0, len(hashes))
+	for _, h := range hashes {
+		rows = append(rows, MFARecoveryCode{ID: uuid.NewString(), UserID: userID, CodeHash: h})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Create(&rows).Error
+}
+
+func (r *gormRepo) UseRecoveryCode(ctx context.Context, userID, hash string) (bool, error) {
+	res := r.db.WithContext(ctx).Model(&MFARecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *gormRepo) CountRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&n).Error
+	return n, err
+}
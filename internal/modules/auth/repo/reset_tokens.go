@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordResetToken เก็บเฉพาะ hash ของ token ที่ส่งไปทาง email (ใช้ได้ครั้งเดียว)
+type PasswordResetToken struct {
+	ID        string `gorm:"type:char(36);primaryKey"`
+	UserID    string `gorm:"size:36;index"`
+	TokenHash string `gorm:"size:64;uniqueIndex"`
+	ExpiresAt int64  `gorm:"index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+// SaveResetToken ลบ token เก่าที่ยังไม่ถูกใช้ของ user แล้วสร้างอันใหม่ (มีได้ทีละอัน)
+func (r *gormRepo) SaveResetToken(ctx context.Context, userID, tokenHash string, expiresAt int64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", userID).
+			Delete(&PasswordResetToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&PasswordResetToken{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			TokenHash: tokenHash,
+			ExpiresAt: expiresAt,
+		}).Error
+	})
+}
+
+// FindResetToken คืน user id ของ token ที่ยังใช้ได้ โดยยังไม่ mark ว่าใช้แล้ว
+func (r *gormRepo) FindResetToken(ctx context.Context, tokenHash string) (string, error) {
+	var t PasswordResetToken
+	if err := r.db.WithContext(ctx).First(&t, "token_hash = ?", tokenHash).Error; err != nil {
+		return "", err
+	}
+	if t.UsedAt != nil || t.ExpiresAt <= time.Now().Unix() {
+		return "", gorm.ErrRecordNotFound
+	}
+	return t.UserID, nil
+}
+
+// ConsumeResetToken mark token ว่าใช้แล้วและคืน user id;
+// token ที่ไม่มี/หมดอายุ/ใช้ไปแล้ว คืน gorm.ErrRecordNotFound
+func (r *gormRepo) ConsumeResetToken(ctx context.Context, tokenHash string) (string, error) {
+	var t PasswordResetToken
+	if err := r.db.WithContext(ctx).First(&t, "token_hash = ?", tokenHash).Error; err != nil {
+		return "", err
+	}
+	if t.UsedAt != nil || t.ExpiresAt <= time.Now().Unix() {
+		return "", gorm.ErrRecordNotFound
+	}
+	// conditional update กันการใช้ token เดียวกันพร้อมกันสองครั้ง
+	res := r.db.WithContext(ctx).Model(&PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", t.ID).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", gorm.ErrRecordNotFound
+	}
+	return t.UserID, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+var (
+	// ErrAuthUnavailable แหล่งยืนยันตัวตนภายนอก (เช่น AD) ติดต่อไม่ได้
+	ErrAuthUnavailable = errors.New("authentication provider unavailable")
+
+	// errUnknownIdentity verifier นี้ไม่รู้จัก user => ลองตัวถัดไป
+	errUnknownIdentity = errors.New("unknown identity")
+)
+
+// NewVerifiers สร้าง verifier ตามชื่อใน AUTH_PROVIDERS (local, ldap)
+func NewVerifiers(names []string, ldapCfg auth.LDAPConfig) ([]auth.CredentialVerifier, error) {
+	var out []auth.CredentialVerifier
+	for _, n := range names {
+		switch n {
+		case "local":
+			out = append(out, LocalVerifier{})
+		case "ldap":
+			v, err := NewLDAPVerifier(ldapCfg)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		default:
+			return nil, fmt.Errorf("unknown auth provider %q", n)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, LocalVerifier{})
+	}
+	return out, nil
+}
+
+// LocalVerifier ตรวจกับ bcrypt hash ใน users.password_hash
+type LocalVerifier struct{}
+
+func (LocalVerifier) Name() string { return "local" }
+
+func (LocalVerifier) Verify(_ context.Context, u *usermodels.User, pw string) (*auth.Identity, error) {
+	if !password.Verify(u.PasswordHash, pw) {
+		return nil, ErrInvalidCredentials
+	}
+	return &auth.Identity{}, nil
+}
+
+// localPasswordsOnly รหัสผ่านของทุก account อยู่ใน users.password_hash (ไม่มี directory verifier)
+// ถ้ามี LDAP ด้วย เราไม่รู้ว่ารหัสของใครอยู่ที่ไหน: ตั้งรหัส local ให้ account ของ directory
+// = สร้างรหัสสำรองที่ยังใช้ login ได้แม้ถูกเอาออกจาก AD แล้ว
+func (s *svc) localPasswordsOnly() bool {
+	for _, v := range s.verifiers {
+		if v.Name() != "local" {
+			return false
+		}
+	}
+	return true
+}
+
+// verifyPassword ลอง verifier ตามลำดับ; ตัวที่รู้จัก user เป็นคนตัดสิน (ไม่ fallback เมื่อรหัสผิด)
+func (s *svc) verifyPassword(ctx context.Context, u *usermodels.User, pw string) (*auth.Identity, error) {
+	if pw == "" {
+		return nil, ErrInvalidCredentials
+	}
+	for _, v := range s.verifiers {
+		id, err := v.Verify(ctx, u, pw)
+		if errors.Is(err, errUnknownIdentity) {
+			continue
+		}
+		if id != nil {
+			id.Provider = v.Name()
+		}
+		return id, err
+	}
+	return nil, ErrInvalidCredentials
+}
+
+// syncRole อัปเดต role ตาม group ของ directory แล้วทำให้ token เดิมใช้ไม่ได้
+func (s *svc) syncRole(ctx context.Context, u *usermodels.User, id *auth.Identity) error {
+	if id == nil || id.Role == "" {
+		return nil
+	}
+	r, ok := usermodels.ParseRole(id.Role)
+	if !ok || r == u.Role {
+		return nil
+	}
+	if err := s.users.UpdateRole(ctx, u.ID, r); err != nil {
+		return err
+	}
+	u.Role = r
+	if err := s.revokeAccess(ctx, u.ID); err != nil {
+		return err
+	}
+	u.TokenVersion++ // ให้ token ที่กำลังจะออกตรงกับ version ใหม่ใน DB
+	return nil
+}
@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+)
+
+var ErrRefreshReused = errors.New("refresh token reuse detected")
+
+const eventRefreshReuse = "refresh_token_reuse"
+
+// refreshReused เจอ refresh token ที่ถูก rotate ไปแล้วถูกนำกลับมาใช้:
+// revoke ทั้ง family (ทั้งฝั่งผู้ใช้จริงและผู้โจมตีต้อง login ใหม่) และเก็บ security event
+func (s *svc) refreshReused(ctx context.Context, rec *auth.RefreshRecord, client auth.ClientInfo) error {
+	if err := s.tokens.RevokeFamily(ctx, rec.FamilyID); err != nil {
+		return err
+	}
+	ev := auth.SecurityEvent{
+		Type:      eventRefreshReuse,
+		UserID:    rec.UserID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Detail:    fmt.Sprintf("jti=%s family=%s replaced_by=%s", rec.ID, rec.FamilyID, rec.ReplacedBy),
+	}
+	if err := s.tokens.SaveSecurityEvent(ctx, ev); err != nil {
+		log.Printf("refresh reuse: save security event for user %s: %v", rec.UserID, err)
+	}
+	return ErrRefreshReused
+}
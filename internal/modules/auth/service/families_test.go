@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func newFamilySvc() (*svc, *fakeTokens) {
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true}
+	tokens := &fakeTokens{}
+	return &svc{users: &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}}, tokens: tokens}, tokens
+}
+
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	s, tokens := newFamilySvc()
+	ctx := context.Background()
+	client := auth.ClientInfo{IP: "10.0.0.9", UserAgent: "test"}
+
+	_, first, err := s.issueTokens(ctx, &usermodels.User{ID: "u1", IsActive: true}, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, second, err := s.Refresh(ctx, first, client)
+	if err != nil {
+		t.Fatalf("rotate: %v", err)
+	}
+
+	// token ที่ rotate ไปแล้วถูกใช้ซ้ำ => ทั้ง family ใช้ไม่ได้
+	if _, _, err := s.Refresh(ctx, first, client); !errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("replay: err = %v", err)
+	}
+	if _, _, err := s.Refresh(ctx, second, client); err == nil {
+		t.Fatal("token from the revoked family still works")
+	}
+	if len(tokens.events) != 1 || tokens.events[0].Type != eventRefreshReuse || tokens.events[0].IP != "10.0.0.9" {
+		t.Fatalf("events = %+v", tokens.events)
+	}
+	for _, r := range tokens.refresh {
+		if !r.Revoked {
+			t.Fatalf("session %s not revoked", r.ID)
+		}
+	}
+}
+
+func TestLoggedOutRefreshIsNotReuse(t *testing.T) {
+	s, tokens := newFamilySvc()
+	ctx := context.Background()
+
+	_, refresh, err := s.issueTokens(ctx, &usermodels.User{ID: "u1", IsActive: true}, auth.ClientInfo{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Logout(ctx, refresh); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := s.Refresh(ctx, refresh, auth.ClientInfo{}); err == nil || errors.Is(err, ErrRefreshReused) {
+		t.Fatalf("err = %v", err)
+	}
+	if len(tokens.events) != 0 {
+		t.Fatalf("logout counted as reuse: %+v", tokens.events)
+	}
+}
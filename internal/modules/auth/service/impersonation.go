@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/security"
+)
+
+var (
+	ErrImpersonationNotAllowed     = errors.New("this user cannot be impersonated")
+	ErrImpersonationReasonRequired = errors.New("reason is required")
+	ErrNotImpersonating            = errors.New("not an impersonation session")
+)
+
+// Impersonate ออก access token อายุสั้นให้ admin "ดูแบบ user คนนั้น"
+// ไม่มี refresh token; ทุก request ที่ใช้ token นี้ถูกบันทึกลง audit log โดย middleware
+func (s *svc) Impersonate(ctx context.Context, actorID, subjectID, reason string, allowWrite bool, client auth.ClientInfo) (*auth.Impersonation, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrImpersonationReasonRequired
+	}
+	// สิทธิ์ user.impersonate ตรวจที่ route แล้ว
+	actor, err := s.users.FindByID(ctx, actorID)
+	if err != nil || actor == nil || !actor.IsActive {
+		return nil, ErrImpersonationNotAllowed
+	}
+	subject, err := s.users.FindByID(ctx, subjectID)
+	if err != nil {
+		return nil, err
+	}
+	// สวมรอยตัวเอง/admin คนอื่น/account ที่ปิดแล้ว ไม่ได้
+	if subject.ID == actor.ID || subject.Role == usermodels.RoleAdmin || !subject.IsActive {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	ttl := impersonationTTL()
+	id := newJTI()
+	claims := security.AccessClaims{
+		UserID: subject.ID,
+		Role:   string(subject.Role),
+		Emp:    subject.EmployeeCode,
+		Ver:    subject.TokenVersion,
+		Act: &security.Actor{
+			Sub: actor.ID,
+			Emp: actor.EmployeeCode,
+			Ver: actor.TokenVersion,
+		},
+		ReadOnly: !allowWrite,
+	}
+	claims.ID = id
+	access, err := security.SignAccessClaims(claims, ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := "read-only"
+	if allowWrite {
+		mode = "read-write"
+	}
+	if err := s.audits.Record(ctx, &auditmodels.AuditLog{
+		Action:    auditmodels.ActionImpersonationStart,
+		ActorID:   actor.ID,
+		SubjectID: subject.ID,
+		SessionID: id,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Detail:    fmt.Sprintf("%s, ttl %s: %s", mode, ttl, reason),
+	}); err != nil {
+		return nil, err // ไม่มี audit = ไม่ออก token
+	}
+
+	return &auth.Impersonation{
+		ID:        id,
+		Access:    access,
+		SubjectID: subject.ID,
+		ReadOnly:  !allowWrite,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// EndImpersonation จบ session ก่อนหมดอายุ; เก็บ jti ไว้นานเท่าอายุสูงสุดของ token
+func (s *svc) EndImpersonation(ctx context.Context, sessionID, actorID, subjectID string, client auth.ClientInfo) error {
+	if sessionID == "" || actorID == "" {
+		return ErrNotImpersonating
+	}
+	if err := s.tokens.RevokeImpersonation(ctx, sessionID, time.Now().Add(impersonationTTL())); err != nil {
+		return err
+	}
+	return s.audits.Record(ctx, &auditmodels.AuditLog{
+		Action:    auditmodels.ActionImpersonationEnd,
+		ActorID:   actorID,
+		SubjectID: subjectID,
+		SessionID: sessionID,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+	})
+}
+
+func impersonationTTL() time.Duration {
+	return parseDuration(os.Getenv("AUTH_IMPERSONATION_TTL"), 15*time.Minute)
+}
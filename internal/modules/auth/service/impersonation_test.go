@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	audit "github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+)
+
+type fakeAudits struct {
+	audit.Service
+	logs []auditmodels.AuditLog
+}
+
+func (f *fakeAudits) Record(_ context.Context, e *auditmodels.AuditLog) error {
+	f.logs = append(f.logs, *e)
+	return nil
+}
+
+func TestEndImpersonationRevokesSession(t *testing.T) {
+	tokens := &fakeTokens{}
+	audits := &fakeAudits{}
+	s := &svc{tokens: tokens, audits: audits}
+	ctx := context.Background()
+
+	if err := s.EndImpersonation(ctx, "", "", "u1", auth.ClientInfo{}); !errors.Is(err, ErrNotImpersonating) {
+		t.Fatalf("plain token: err = %v", err)
+	}
+	before := time.Now()
+	if err := s.EndImpersonation(ctx, "sess-1", "admin", "u1", auth.ClientInfo{IP: "10.0.0.1"}); err != nil {
+		t.Fatal(err)
+	}
+	until, ok := tokens.revokedImpersonations["sess-1"]
+	if !ok || until.Before(before.Add(impersonationTTL())) {
+		t.Fatalf("revoked = %v", tokens.revokedImpersonations)
+	}
+	if len(audits.logs) != 1 || audits.logs[0].Action != auditmodels.ActionImpersonationEnd || audits.logs[0].SessionID != "sess-1" {
+		t.Fatalf("audit = %+v", audits.logs)
+	}
+}
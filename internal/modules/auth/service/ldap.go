@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+
+	"github.com/go-ldap/ldap/v3"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// LDAPVerifier search หา user ด้วย service account แล้ว bind ด้วยรหัสของ user เอง
+type LDAPVerifier struct {
+	cfg auth.LDAPConfig
+}
+
+func NewLDAPVerifier(cfg auth.LDAPConfig) (*LDAPVerifier, error) {
+	if cfg.URL == "" || cfg.BaseDN == "" {
+		return nil, errors.New("ldap: LDAP_URL and LDAP_BASE_DN are required")
+	}
+	if !strings.Contains(cfg.UserFilter, "%s") {
+		return nil, errors.New("ldap: LDAP_USER_FILTER must contain %s")
+	}
+	return &LDAPVerifier{cfg: cfg}, nil
+}
+
+func (v *LDAPVerifier) Name() string { return "ldap" }
+
+func (v *LDAPVerifier) Verify(ctx context.Context, u *usermodels.User, pw string) (*auth.Identity, error) {
+	if pw == "" {
+		// bind ด้วยรหัสว่าง = unauthenticated bind ซึ่ง server หลายตัวตอบสำเร็จ
+		return nil, ErrInvalidCredentials
+	}
+	conn, err := v.dial()
+	if err != nil {
+		log.Printf("ldap: connect %s: %v", v.cfg.URL, err)
+		return nil, ErrAuthUnavailable
+	}
+	defer conn.Close()
+
+	if v.cfg.BindDN != "" {
+		if err := conn.Bind(v.cfg.BindDN, v.cfg.BindPassword); err != nil {
+			log.Printf("ldap: service bind: %v", err)
+			return nil, ErrAuthUnavailable
+		}
+	}
+
+	req := ldap.NewSearchRequest(
+		v.cfg.BaseDN, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases,
+		2, int(v.cfg.Timeout.Seconds()), false,
+		fmt.Sprintf(v.cfg.UserFilter, ldap.EscapeFilter(u.EmployeeCode)),
+		[]string{"dn", v.cfg.GroupAttr},
+		nil,
+	)
+	res, err := conn.Search(req)
+	if err != nil {
+		log.Printf("ldap: search %s: %v", u.EmployeeCode, err)
+		return nil, ErrAuthUnavailable
+	}
+	if len(res.Entries) != 1 {
+		return nil, errUnknownIdentity // ไม่มีใน directory (หรือซ้ำ) => ให้ verifier ถัดไปตัดสิน
+	}
+	entry := res.Entries[0]
+
+	if err := conn.Bind(entry.DN, pw); err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		log.Printf("ldap: user bind %s: %v", u.EmployeeCode, err)
+		return nil, ErrAuthUnavailable
+	}
+	return &auth.Identity{Role: v.mapRole(entry.GetAttributeValues(v.cfg.GroupAttr))}, nil
+}
+
+// mapRole เลือก role ที่สิทธิ์สูงสุดจาก group ที่ match
+func (v *LDAPVerifier) mapRole(groups []string) string {
+	var best usermodels.Role
+	for _, g := range groups {
+		role, ok := v.cfg.GroupRoles[strings.ToLower(g)]
+		if !ok {
+			continue
+		}
+		r, ok := usermodels.ParseRole(role)
+		if !ok {
+			continue
+		}
+		if best == "" || !usermodels.IsAtLeast(best, r) {
+			best = r
+		}
+	}
+	if best == "" {
+		return v.cfg.DefaultRole
+	}
+	return string(best)
+}
+
+func (v *LDAPVerifier) dial() (*ldap.Conn, error) {
+	tlsCfg := &tls.Config{InsecureSkipVerify: v.cfg.InsecureSkipVerify} // เปิดเฉพาะ dev/self-signed
+	conn, err := ldap.DialURL(v.cfg.URL,
+		ldap.DialWithTLSConfig(tlsCfg),
+		ldap.DialWithDialer(&net.Dialer{Timeout: v.cfg.Timeout}))
+	if err != nil {
+		return nil, err
+	}
+	conn.SetTimeout(v.cfg.Timeout)
+	if v.cfg.StartTLS {
+		if err := conn.StartTLS(tlsCfg); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
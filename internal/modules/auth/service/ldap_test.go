@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+// fakeDirectory เป็น LDAP server ในตัว test: รองรับแค่ bind (simple), search และ unbind
+// พอสำหรับ flow ของ LDAPVerifier (service bind -> search -> user bind)
+type fakeDirectory struct {
+	ln      net.Listener
+	entries map[string]ldapEntry // DN -> entry
+}
+
+type ldapEntry struct {
+	uid      string
+	password string
+	groups   []string
+}
+
+const (
+	ldapServiceDN = "cn=svc,dc=example,dc=com"
+	ldapServicePW = "svc-secret"
+)
+
+func newFakeDirectory(t *testing.T, entries map[string]ldapEntry) *fakeDirectory {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &fakeDirectory{ln: ln, entries: entries}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go d.serve(conn)
+		}
+	}()
+	return d
+}
+
+func (d *fakeDirectory) url() string { return "ldap://" + d.ln.Addr().String() }
+
+func (d *fakeDirectory) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		p, err := ber.ReadPacket(conn)
+		if err != nil || len(p.Children) < 2 {
+			return
+		}
+		id, _ := p.Children[0].Value.(int64)
+		op := p.Children[1]
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			dn, _ := op.Children[1].Value.(string)
+			pw := op.Children[2].Data.String()
+			code := uint16(ldap.LDAPResultInvalidCredentials)
+			if e, ok := d.entries[dn]; (ok && e.password == pw) || (dn == ldapServiceDN && pw == ldapServicePW) {
+				code = ldap.LDAPResultSuccess
+			}
+			d.reply(conn, id, ldapResult(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			filter, _ := ldap.DecompileFilter(op.Children[6])
+			for dn, e := range d.entries {
+				if !strings.Contains(filter, "uid="+e.uid+")") {
+					continue
+				}
+				entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+				entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, dn, ""))
+				attrs := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				attr := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+				attr.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "memberOf", ""))
+				vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+				for _, g := range e.groups {
+					vals.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, g, ""))
+				}
+				attr.AppendChild(vals)
+				attrs.AppendChild(attr)
+				entry.AppendChild(attrs)
+				d.reply(conn, id, entry)
+			}
+			d.reply(conn, id, ldapResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func ldapResult(tag ber.Tag, code uint16) *ber.Packet {
+	op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	return op
+}
+
+func (d *fakeDirectory) reply(conn net.Conn, id int64, op *ber.Packet) {
+	env := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "")
+	env.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, ""))
+	env.AppendChild(op)
+	_, _ = conn.Write(env.Bytes())
+}
+
+func newTestLDAPVerifier(t *testing.T, url string) *LDAPVerifier {
+	t.Helper()
+	v, err := NewLDAPVerifier(auth.LDAPConfig{
+		URL:          url,
+		BindDN:       ldapServiceDN,
+		BindPassword: ldapServicePW,
+		BaseDN:       "dc=example,dc=com",
+		UserFilter:   "(uid=%s)",
+		GroupAttr:    "memberOf",
+		GroupRoles: map[string]string{
+			"cn=hr,dc=example,dc=com":     "hr",
+			"cn=admins,dc=example,dc=com": "admin",
+		},
+		Timeout: 2 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLDAPVerifier(t *testing.T) {
+	dir := newFakeDirectory(t, map[string]ldapEntry{
+		"uid=E1,dc=example,dc=com": {uid: "E1", password: "right", groups: []string{"CN=HR,dc=example,dc=com", "cn=other,dc=example,dc=com"}},
+		"uid=E2,dc=example,dc=com": {uid: "E2", password: "right", groups: []string{"cn=hr,dc=example,dc=com", "cn=admins,dc=example,dc=com"}},
+	})
+	v := newTestLDAPVerifier(t, dir.url())
+	ctx := context.Background()
+
+	id, err := v.Verify(ctx, &usermodels.User{EmployeeCode: "E1"}, "right")
+	if err != nil || id.Role != "hr" {
+		t.Fatalf("E1: %+v, %v", id, err)
+	}
+	// role สูงสุดจาก group ที่ match
+	if id, err := v.Verify(ctx, &usermodels.User{EmployeeCode: "E2"}, "right"); err != nil || id.Role != "admin" {
+		t.Fatalf("E2: %+v, %v", id, err)
+	}
+	if _, err := v.Verify(ctx, &usermodels.User{EmployeeCode: "E1"}, "wrong"); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("wrong password: %v", err)
+	}
+	if _, err := v.Verify(ctx, &usermodels.User{EmployeeCode: "E1"}, ""); !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("empty password: %v", err)
+	}
+	if _, err := v.Verify(ctx, &usermodels.User{EmployeeCode: "NOPE"}, "right"); !errors.Is(err, errUnknownIdentity) {
+		t.Fatalf("unknown user: %v", err)
+	}
+}
+
+func TestLDAPVerifierUnavailable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // ไม่มีใครฟังที่ port นี้แล้ว
+
+	v := newTestLDAPVerifier(t, "ldap://"+addr)
+	if _, err := v.Verify(context.Background(), &usermodels.User{EmployeeCode: "E1"}, "right"); !errors.Is(err, ErrAuthUnavailable) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestLoginSyncsDirectoryRoleColumnOnly(t *testing.T) {
+	dir := newFakeDirectory(t, map[string]ldapEntry{
+		"uid=E1,dc=example,dc=com": {uid: "E1", password: "right", groups: []string{"cn=hr,dc=example,dc=com"}},
+	})
+	localHash, err := password.Hash("local-pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", Role: usermodels.RoleEmployee, IsActive: true}
+	local := &usermodels.User{ID: "u2", EmployeeCode: "L1", Role: usermodels.RoleEmployee, IsActive: true, PasswordHash: localHash}
+	users := &fakeUsers{byCode: map[string]*usermodels.User{"E1": u, "L1": local}}
+	p := testPolicy()
+	p.baseDelay = 0
+	s := &svc{
+		users:     users,
+		tokens:    &fakeTokens{},
+		lockout:   p,
+		verifiers: []auth.CredentialVerifier{newTestLDAPVerifier(t, dir.url()), LocalVerifier{}},
+	}
+	ctx := context.Background()
+
+	res, err := s.Login(ctx, "E1", "right", auth.ClientInfo{IP: "10.0.0.1"})
+	if err != nil || res.Access == "" {
+		t.Fatalf("login: %+v, %v", res, err)
+	}
+	if len(users.roleUpdates) != 1 || users.roleUpdates[0] != usermodels.RoleHR || u.Role != usermodels.RoleHR {
+		t.Fatalf("role updates = %v, role = %s", users.roleUpdates, u.Role)
+	}
+	if users.tokenBumps != 1 || u.TokenVersion != 1 {
+		t.Fatalf("token version not bumped: bumps=%d ver=%d", users.tokenBumps, u.TokenVersion)
+	}
+
+	// ไม่อยู่ใน directory => verifier ถัดไป (local) ตัดสิน
+	if _, err := s.Login(ctx, "L1", "local-pw", auth.ClientInfo{IP: "10.0.0.1"}); err != nil {
+		t.Fatalf("local fallback: %v", err)
+	}
+	if len(users.roleUpdates) != 1 {
+		t.Fatalf("local login changed role: %v", users.roleUpdates)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ThrottledError คืนเมื่อ account หรือ IP ยังอยู่ในช่วงหน่วง/ถูกล็อก
+type ThrottledError struct {
+	Locked     bool
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	if e.Locked {
+		return "account temporarily locked"
+	}
+	return "too many failed attempts, try again later"
+}
+
+type lockoutPolicy struct {
+	accountThreshold int           // ผิดกี่ครั้งถึงล็อก account
+	ipThreshold      int           // ผิดกี่ครั้ง (รวมทุก account) ถึงล็อก IP
+	lockDuration     time.Duration // ระยะล็อก และอายุของตัวนับ
+	baseDelay        time.Duration // หน่วงเริ่มต้น เพิ่มเท่าตัวทุกครั้งที่ผิด
+	maxDelay         time.Duration
+
+	// forgot-password: ขอได้กี่ครั้งต่อ IP / ต่อ account ภายใน lockDuration
+	resetIPThreshold      int
+	resetAccountThreshold int
+}
+
+func loadLockoutPolicy() lockoutPolicy {
+	return lockoutPolicy{
+		accountThreshold: parseInt(os.Getenv("AUTH_LOCKOUT_THRESHOLD"), 5),
+		ipThreshold:      parseInt(os.Getenv("AUTH_IP_LOCKOUT_THRESHOLD"), 20),
+		lockDuration:     parseDuration(os.Getenv("AUTH_LOCKOUT_DURATION"), 15*time.Minute),
+		baseDelay:        parseDuration(os.Getenv("AUTH_THROTTLE_BASE_DELAY"), time.Second),
+		maxDelay:         parseDuration(os.Getenv("AUTH_THROTTLE_MAX_DELAY"), 30*time.Second),
+
+		resetIPThreshold:      parseInt(os.Getenv("AUTH_RESET_IP_LIMIT"), 10),
+		resetAccountThreshold: parseInt(os.Getenv("AUTH_RESET_ACCOUNT_LIMIT"), 3),
+	}
+}
+
+// delay หลังผิดครั้งที่ n: ครั้งแรกไม่หน่วง จากนั้น base, 2*base, 4*base ... ไม่เกิน maxDelay
+func (p lockoutPolicy) delay(failures int) time.Duration {
+	if failures < 2 || p.baseDelay <= 0 {
+		return 0
+	}
+	d := p.baseDelay
+	for i := 2; i < failures && d < p.maxDelay; i++ {
+		d *= 2
+	}
+	if d > p.maxDelay {
+		return p.maxDelay
+	}
+	return d
+}
+
+// stale = ไม่มีความผิดค้างอยู่ หรือผิดครั้งล่าสุดเก่าเกิน lockDuration / lock หมดอายุแล้ว
+func (p lockoutPolicy) stale(t auth.Throttle, now time.Time) bool {
+	if t.Failures == 0 {
+		return true
+	}
+	if t.LockedUntil != nil {
+		return !now.Before(*t.LockedUntil)
+	}
+	return now.Sub(t.LastFailedAt) > p.lockDuration
+}
+
+func (p lockoutPolicy) check(t auth.Throttle, now time.Time) error {
+	if t.LockedUntil != nil && now.Before(*t.LockedUntil) {
+		return &ThrottledError{Locked: true, RetryAfter: t.LockedUntil.Sub(now)}
+	}
+	if p.stale(t, now) {
+		return nil
+	}
+	if wait := t.LastFailedAt.Add(p.delay(t.Failures)).Sub(now); wait > 0 {
+		return &ThrottledError{RetryAfter: wait}
+	}
+	return nil
+}
+
+func accountThrottle(u *usermodels.User) auth.Throttle {
+	t := auth.Throttle{Failures: u.FailedLoginCount, LockedUntil: u.LockedUntil}
+	if u.LastFailedLoginAt != nil {
+		t.LastFailedAt = *u.LastFailedLoginAt
+	}
+	return t
+}
+
+func (s *svc) ipThrottle(ctx context.Context, ip string) (auth.Throttle, error) {
+	if ip == "" {
+		return auth.Throttle{}, nil
+	}
+	t, err := s.tokens.GetIPThrottle(ctx, ip)
+	if err != nil {
+		return auth.Throttle{}, err
+	}
+	return *t, nil
+}
+
+// ตัวนับเพิ่มใน DB แบบ atomic (ดู RecordIPFailure/RecordLoginFailure) ไม่ใช่จากค่าที่อ่านไว้ก่อน
+func (s *svc) recordIPFailure(ctx context.Context, ip string, now time.Time) error {
+	if ip == "" {
+		return nil
+	}
+	_, err := s.tokens.RecordIPFailure(ctx, ip, now, now.Add(-s.lockout.lockDuration), s.lockout.ipThreshold, s.lockout.lockDuration)
+	return err
+}
+
+// recordAccountFailure คืน ThrottledError ถ้าครั้งนี้ทำให้ account ถูกล็อก
+func (s *svc) recordAccountFailure(ctx context.Context, u *usermodels.User, now time.Time) error {
+	_, lockedUntil, err := s.users.RecordLoginFailure(ctx, u.ID, now, now.Add(-s.lockout.lockDuration),
+		s.lockout.accountThreshold, s.lockout.lockDuration)
+	if err != nil {
+		return err
+	}
+	if lockedUntil != nil {
+		return &ThrottledError{Locked: true, RetryAfter: lockedUntil.Sub(now)}
+	}
+	return ErrInvalidCredentials
+}
+
+// hideAccountState ตอน login ด้วยรหัส: account ที่ถูกล็อก/หน่วงตอบเหมือนรหัสผิด
+// ไม่งั้นคนนอกแยกได้ว่า employee code ไหนมีอยู่จริง (code ที่ไม่มีไม่มีวันถูกล็อก)
+func hideAccountState(err error) error {
+	var te *ThrottledError
+	if errors.As(err, &te) {
+		return ErrInvalidCredentials
+	}
+	return err
+}
+
+func parseInt(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	user "github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/password"
+	"gorm.io/gorm"
+)
+
+// fakeUsers / fakeTokens implement เฉพาะเมธอดที่ test ใช้ (ที่เหลือ panic ผ่าน interface nil)
+type fakeUsers struct {
+	user.Repository
+	byCode   map[string]*usermodels.User
+	byEmail  map[string]*usermodels.User
+	created  []*usermodels.User
+	failures int
+
+	roleUpdates  []usermodels.Role
+	tokenBumps   int
+	saved        []usermodels.User // snapshot ของทุก Update
+	mustChange   map[string]bool
+	lastLogins   int
+	lastLoginErr error
+}
+
+func (f *fakeUsers) SetMustChangePassword(_ context.Context, id string, must bool) error {
+	if f.mustChange == nil {
+		f.mustChange = map[string]bool{}
+	}
+	f.mustChange[id] = must
+	return nil
+}
+
+func (f *fakeUsers) Update(_ context.Context, u *usermodels.User, _ ...string) error {
+	f.saved = append(f.saved, *u)
+	return nil
+}
+
+func (f *fakeUsers) UpdateRole(_ context.Context, _ string, role usermodels.Role) error {
+	f.roleUpdates = append(f.roleUpdates, role)
+	return nil
+}
+
+func (f *fakeUsers) BumpTokenVersion(context.Context, string) error {
+	f.tokenBumps++
+	return nil
+}
+
+func (f *fakeUsers) FindByID(_ context.Context, id string) (*usermodels.User, error) {
+	for _, m := range []map[string]*usermodels.User{f.byCode, f.byEmail} {
+		for _, u := range m {
+			if u.ID == id {
+				return u, nil
+			}
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUsers) FindByEmail(_ context.Context, email string) (*usermodels.User, error) {
+	if u, ok := f.byEmail[email]; ok {
+		return u, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUsers) Create(_ context.Context, u *usermodels.User) error {
+	u.ID = "new-" + u.EmployeeCode
+	f.created = append(f.created, u)
+	return nil
+}
+
+func (f *fakeUsers) UpdateLastLogin(context.Context, string, time.Time) error {
+	f.lastLogins++
+	return f.lastLoginErr
+}
+
+func (f *fakeUsers) FindByEmployeeCode(_ context.Context, code string) (*usermodels.User, error) {
+	if u, ok := f.byCode[code]; ok {
+		return u, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUsers) RecordLoginFailure(_ context.Context, _ string, now, _ time.Time, threshold int, lockFor time.Duration) (int, *time.Time, error) {
+	f.failures++
+	if f.failures >= threshold {
+		until := now.Add(lockFor)
+		return f.failures, &until, nil
+	}
+	return f.failures, nil, nil
+}
+
+type fakeTokens struct {
+	auth.Repository
+	throttles   map[string]*auth.Throttle
+	resetTokens int
+	saveErr     error
+	sessions    []auth.Session
+	refresh     map[string]*auth.RefreshRecord
+	events      []auth.SecurityEvent
+	mfa         map[string]*auth.MFAFactor
+
+	revokedImpersonations map[string]time.Time
+}
+
+func (f *fakeTokens) RevokeImpersonation(_ context.Context, id string, expiresAt time.Time) error {
+	if f.revokedImpersonations == nil {
+		f.revokedImpersonations = map[string]time.Time{}
+	}
+	f.revokedImpersonations[id] = expiresAt
+	return nil
+}
+
+func (f *fakeTokens) SaveRefresh(_ context.Context, sess auth.Session) error {
+	f.sessions = append(f.sessions, sess)
+	if f.refresh == nil {
+		f.refresh = map[string]*auth.RefreshRecord{}
+	}
+	f.refresh[sess.ID] = &auth.RefreshRecord{Session: sess}
+	return nil
+}
+
+func (f *fakeTokens) FindRefresh(_ context.Context, jti string) (*auth.RefreshRecord, error) {
+	if r, ok := f.refresh[jti]; ok {
+		c := *r
+		return &c, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeTokens) RotateRefresh(ctx context.Context, oldJTI string, next auth.Session) (bool, error) {
+	old, ok := f.refresh[oldJTI]
+	if !ok || old.Revoked {
+		return false, nil
+	}
+	old.Revoked, old.ReplacedBy = true, next.ID
+	return true, f.SaveRefresh(ctx, next)
+}
+
+func (f *fakeTokens) Revoke(_ context.Context, jti string) error {
+	if r, ok := f.refresh[jti]; ok {
+		r.Revoked = true
+	}
+	return nil
+}
+
+func (f *fakeTokens) RevokeFamily(_ context.Context, familyID string) error {
+	for _, r := range f.refresh {
+		if r.FamilyID == familyID {
+			r.Revoked = true
+		}
+	}
+	return nil
+}
+
+func (f *fakeTokens) SaveSecurityEvent(_ context.Context, e auth.SecurityEvent) error {
+	f.events = append(f.events, e)
+	return nil
+}
+
+func (f *fakeTokens) RevokeAllForUser(context.Context, string) error { return nil }
+
+func (f *fakeTokens) GetMFA(_ context.Context, userID string) (*auth.MFAFactor, error) {
+	return f.mfa[userID], nil
+}
+
+func (f *fakeTokens) DeleteMFA(_ context.Context, userID string) error {
+	delete(f.mfa, userID)
+	return nil
+}
+
+func (f *fakeTokens) GetIPThrottle(_ context.Context, key string) (*auth.Throttle, error) {
+	if t, ok := f.throttles[key]; ok {
+		c := *t
+		return &c, nil
+	}
+	return &auth.Throttle{}, nil
+}
+
+func (f *fakeTokens) RecordIPFailure(_ context.Context, key string, now, _ time.Time, threshold int, lockFor time.Duration) (*auth.Throttle, error) {
+	if f.throttles == nil {
+		f.throttles = map[string]*auth.Throttle{}
+	}
+	t, ok := f.throttles[key]
+	if !ok {
+		t = &auth.Throttle{}
+		f.throttles[key] = t
+	}
+	t.Failures++
+	t.LastFailedAt = now
+	if threshold > 0 && t.Failures >= threshold {
+		until := now.Add(lockFor)
+		t.LockedUntil = &until
+	}
+	return t, nil
+}
+
+func (f *fakeTokens) SaveResetToken(context.Context, string, string, int64) error {
+	f.resetTokens++
+	return f.saveErr
+}
+
+func testPolicy() lockoutPolicy {
+	return lockoutPolicy{accountThreshold: 3, ipThreshold: 100, lockDuration: 15 * time.Minute, baseDelay: time.Second, maxDelay: 4 * time.Second}
+}
+
+func TestLockoutDelay(t *testing.T) {
+	p := testPolicy()
+	want := map[int]time.Duration{0: 0, 1: 0, 2: time.Second, 3: 2 * time.Second, 4: 4 * time.Second, 10: 4 * time.Second}
+	for n, d := range want {
+		if got := p.delay(n); got != d {
+			t.Errorf("delay(%d) = %v, want %v", n, got, d)
+		}
+	}
+}
+
+func TestLockoutCheck(t *testing.T) {
+	p := testPolicy()
+	now := time.Now()
+	until := now.Add(time.Minute)
+	var te *ThrottledError
+
+	if err := p.check(auth.Throttle{Failures: 5, LastFailedAt: now, LockedUntil: &until}, now); !errors.As(err, &te) || !te.Locked {
+		t.Fatalf("locked throttle: got %v", err)
+	}
+	expired := now.Add(-time.Second)
+	if err := p.check(auth.Throttle{Failures: 5, LastFailedAt: now.Add(-time.Hour), LockedUntil: &expired}, now); err != nil {
+		t.Fatalf("expired lock should pass, got %v", err)
+	}
+	if err := p.check(auth.Throttle{Failures: 2, LastFailedAt: now}, now); !errors.As(err, &te) || te.Locked {
+		t.Fatalf("delay: got %v", err)
+	}
+	if err := p.check(auth.Throttle{Failures: 2, LastFailedAt: now.Add(-time.Hour)}, now); err != nil {
+		t.Fatalf("stale failures should pass, got %v", err)
+	}
+}
+
+// account ที่ถูกล็อก/ล็อกเพราะครั้งนี้ ต้องตอบเหมือน employee code ที่ไม่มีอยู่
+func TestLoginDoesNotRevealLockedAccounts(t *testing.T) {
+	now := time.Now()
+	until := now.Add(time.Hour)
+	locked := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true, FailedLoginCount: 3, LastFailedLoginAt: &now, LockedUntil: &until}
+	almost := &usermodels.User{ID: "u2", EmployeeCode: "E2", IsActive: true, PasswordHash: "!"}
+	users := &fakeUsers{byCode: map[string]*usermodels.User{"E1": locked, "E2": almost}, failures: 2}
+	tokens := &fakeTokens{}
+	p := testPolicy()
+	p.baseDelay = 0 // ไม่ให้ IP โดนหน่วงระหว่าง test
+	s := &svc{users: users, tokens: tokens, lockout: p, verifiers: []auth.CredentialVerifier{LocalVerifier{}}}
+	client := auth.ClientInfo{IP: "10.0.0.1"}
+
+	for _, code := range []string{"NOPE", "E1", "E2"} {
+		_, err := s.Login(context.Background(), code, "wrong", client)
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("Login(%s) = %v, want ErrInvalidCredentials", code, err)
+		}
+	}
+	if n := tokens.throttles["10.0.0.1"].Failures; n != 3 {
+		t.Errorf("ip failures = %d, want 3", n)
+	}
+	if users.failures != 3 {
+		t.Errorf("account failures = %d, want 3 (E2 locked on this attempt)", users.failures)
+	}
+}
+
+func TestLoginSurvivesLastLoginFailure(t *testing.T) {
+	hash, err := password.Hash("right-pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true, PasswordHash: hash}
+	users := &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}, lastLoginErr: errors.New("db down")}
+	p := testPolicy()
+	p.baseDelay = 0
+	s := &svc{users: users, tokens: &fakeTokens{}, lockout: p, verifiers: []auth.CredentialVerifier{LocalVerifier{}}}
+
+	res, err := s.Login(context.Background(), "E1", "right-pw", auth.ClientInfo{IP: "10.0.0.1"})
+	if err != nil || res.Access == "" || res.Refresh == "" {
+		t.Fatalf("login: %+v, %v", res, err)
+	}
+	if users.lastLogins != 1 {
+		t.Fatalf("last login attempts = %d", users.lastLogins)
+	}
+}
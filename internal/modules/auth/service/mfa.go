@@ -0,0 +1,387 @@
+package service
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/security"
+	"github.com/Marugo/birdlax/internal/shared/totp"
+)
+
+var (
+	ErrMFAInvalidCode      = errors.New("invalid verification code")
+	ErrMFAInvalidChallenge = errors.New("invalid or expired mfa challenge")
+	ErrMFAAlreadyEnabled   = errors.New("mfa is already enabled")
+	ErrMFANotEnabled       = errors.New("mfa is not enabled")
+	ErrMFARequiredByPolicy = errors.New("mfa is required for this role")
+	ErrMFAResetNotAllowed  = errors.New("only an admin can reset mfa of a privileged account")
+)
+
+const recoveryCodeCount = 10
+
+type mfaPolicy struct {
+	enforcePrivileged bool          // MFA_ENFORCE_PRIVILEGED_ROLES: admin/hr ต้องใช้ MFA
+	issuer            string        // ชื่อที่แสดงในแอป authenticator
+	challengeTTL      time.Duration // อายุ MFA challenge token
+	key               []byte        // AES-256 key สำหรับเข้ารหัส secret (nil = เก็บตรงๆ)
+}
+
+func loadMFAPolicy() mfaPolicy {
+	p := mfaPolicy{
+		enforcePrivileged: os.Getenv("MFA_ENFORCE_PRIVILEGED_ROLES") == "true",
+		issuer:            os.Getenv("MFA_ISSUER"),
+		challengeTTL:      parseDuration(os.Getenv("MFA_CHALLENGE_TTL"), 5*time.Minute),
+	}
+	if p.issuer == "" {
+		p.issuer = "Birdlax"
+	}
+	if k := os.Getenv("MFA_SECRET_KEY"); k != "" {
+		sum := sha256.Sum256([]byte(k))
+		p.key = sum[:]
+	}
+	return p
+}
+
+func (p mfaPolicy) required(r usermodels.Role) bool {
+	return p.enforcePrivileged && (r == usermodels.RoleAdmin || r == usermodels.RoleHR)
+}
+
+// seal/open เข้ารหัส TOTP secret ก่อนลง DB (AES-GCM) ถ้าตั้ง MFA_SECRET_KEY
+func (p mfaPolicy) seal(secret string) (string, error) {
+	if p.key == nil {
+		return secret, nil
+	}
+	gcm, err := p.gcm()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(nonce)
+	return "enc:" + base64.RawStdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+func (p mfaPolicy) open(stored string) (string, error) {
+	raw, ok := strings.CutPrefix(stored, "enc:")
+	if !ok {
+		return stored, nil
+	}
+	if p.key == nil {
+		return "", errors.New("mfa secret is encrypted but MFA_SECRET_KEY is not set")
+	}
+	b, err := base64.RawStdEncoding.DecodeString(raw)
+	if err != nil {
+		return "", err
+	}
+	gcm, err := p.gcm()
+	if err != nil {
+		return "", err
+	}
+	if len(b) < gcm.NonceSize() {
+		return "", errors.New("mfa secret is corrupted")
+	}
+	plain, err := gcm.Open(nil, b[:gcm.NonceSize()], b[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func (p mfaPolicy) gcm() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(p.key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// mfaChallenge คืน challenge ถ้า user ต้องยืนยันขั้นที่สอง (nil = ไม่ต้อง)
+func (s *svc) mfaChallenge(ctx context.Context, u *usermodels.User) (*auth.LoginResult, error) {
+	f, err := s.tokens.GetMFA(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := f != nil && f.ConfirmedAt != nil
+	if !enabled && !s.mfa.required(u.Role) {
+		return nil, nil
+	}
+	tok, err := security.SignMFAChallenge(u.ID, u.TokenVersion, !enabled, s.mfa.challengeTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.LoginResult{MFARequired: true, MFAEnrollRequired: !enabled, MFAToken: tok}, nil
+}
+
+// challengeUser ตรวจ challenge token; enroll ต้องตรงกับชนิดของ challenge
+func (s *svc) challengeUser(ctx context.Context, mfaToken string, enroll bool) (*usermodels.User, error) {
+	c, err := security.ParseMFAChallenge(mfaToken)
+	if err != nil || c.Enroll != enroll {
+		return nil, ErrMFAInvalidChallenge
+	}
+	u, err := s.users.FindByID(ctx, c.UserID)
+	if err != nil || u == nil || !u.IsActive || u.TokenVersion != c.Ver {
+		return nil, ErrMFAInvalidChallenge
+	}
+	if err := s.lockout.check(accountThrottle(u), time.Now()); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+func (s *svc) VerifyMFA(ctx context.Context, mfaToken, code string, client auth.ClientInfo) (*auth.LoginResult, error) {
+	now := time.Now()
+	ipState, err := s.ipThrottle(ctx, client.IP)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lockout.check(ipState, now); err != nil {
+		return nil, err
+	}
+	u, err := s.challengeUser(ctx, mfaToken, false)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.checkSecondFactor(ctx, u, code, true)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// รหัส MFA ผิดนับรวมกับ login ผิด => ล็อกตาม policy เดียวกัน
+		if err := s.recordIPFailure(ctx, client.IP, now); err != nil {
+			return nil, err
+		}
+		if err := s.recordAccountFailure(ctx, u, now); !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		return nil, ErrMFAInvalidCode
+	}
+	return s.completeLogin(ctx, u, client)
+}
+
+func (s *svc) BeginMFASetup(ctx context.Context, mfaToken string) (*auth.MFAEnrollment, error) {
+	u, err := s.challengeUser(ctx, mfaToken, true)
+	if err != nil {
+		return nil, err
+	}
+	return s.BeginMFAEnrollment(ctx, u.ID)
+}
+
+// CompleteMFASetup ลงทะเบียน MFA ตอน login (policy บังคับ) แล้วออก token ให้เลย
+func (s *svc) CompleteMFASetup(ctx context.Context, mfaToken, code string, client auth.ClientInfo) (*auth.LoginResult, error) {
+	u, err := s.challengeUser(ctx, mfaToken, true)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.ConfirmMFAEnrollment(ctx, u.ID, code)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.completeLogin(ctx, u, client)
+	if err != nil {
+		return nil, err
+	}
+	res.RecoveryCodes = codes
+	return res, nil
+}
+
+func (s *svc) MFAStatus(ctx context.Context, userID string) (*auth.MFAStatus, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.tokens.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	st := &auth.MFAStatus{Enabled: f != nil && f.ConfirmedAt != nil, Required: s.mfa.required(u.Role)}
+	if st.Enabled {
+		if st.RecoveryCodesRemaining, err = s.tokens.CountRecoveryCodes(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	return st, nil
+}
+
+func (s *svc) BeginMFAEnrollment(ctx context.Context, userID string) (*auth.MFAEnrollment, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	f, err := s.tokens.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if f != nil && f.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret := totp.GenerateSecret()
+	sealed, err := s.mfa.seal(secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokens.SaveMFA(ctx, auth.MFAFactor{UserID: userID, Secret: sealed}); err != nil {
+		return nil, err
+	}
+	return &auth.MFAEnrollment{Secret: secret, URI: totp.ProvisioningURI(s.mfa.issuer, u.EmployeeCode, secret)}, nil
+}
+
+func (s *svc) ConfirmMFAEnrollment(ctx context.Context, userID, code string) ([]string, error) {
+	f, err := s.tokens.GetMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if f == nil {
+		return nil, ErrMFANotEnabled
+	}
+	if f.ConfirmedAt != nil {
+		return nil, ErrMFAAlreadyEnabled
+	}
+	secret, err := s.mfa.open(f.Secret)
+	if err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, code, time.Now(), 1)
+	if !ok {
+		return nil, ErrMFAInvalidCode
+	}
+	codes, hashes := newRecoveryCodes()
+	if err := s.tokens.ConfirmMFA(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *svc) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyAccountFactor(ctx, u, code, false); err != nil {
+		return nil, err
+	}
+	codes, hashes := newRecoveryCodes()
+	if err := s.tokens.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *svc) DisableMFA(ctx context.Context, userID, code string) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if s.mfa.required(u.Role) {
+		return ErrMFARequiredByPolicy
+	}
+	if err := s.verifyAccountFactor(ctx, u, code, true); err != nil {
+		return err
+	}
+	return s.tokens.DeleteMFA(ctx, userID)
+}
+
+// ResetMFA ล้าง MFA ของ account privileged (admin/hr) ได้เฉพาะ admin
+// กัน hr reset MFA ของ admin แล้วยึด account ต่อ; role ของ actor ดูจาก DB ไม่เชื่อ token
+func (s *svc) ResetMFA(ctx context.Context, actorID, userID string) error {
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if usermodels.IsAtLeast(u.Role, usermodels.RoleHR) {
+		actor, err := s.users.FindByID(ctx, actorID)
+		if err != nil || actor == nil || !actor.IsActive || actor.Role != usermodels.RoleAdmin {
+			return ErrMFAResetNotAllowed
+		}
+	}
+	return s.tokens.DeleteMFA(ctx, userID)
+}
+
+// verifyAccountFactor ตรวจรหัส MFA ของ endpoint ที่ใช้ access token (ปิด MFA, ออก recovery code ใหม่)
+// รหัสผิดนับเป็น login ผิดเหมือน ChangePassword: คนที่ได้ token ไปจะเดารหัสจนปิด MFA ไม่ได้
+func (s *svc) verifyAccountFactor(ctx context.Context, u *usermodels.User, code string, allowRecovery bool) error {
+	now := time.Now()
+	if err := s.lockout.check(accountThrottle(u), now); err != nil {
+		return err
+	}
+	ok, err := s.checkSecondFactor(ctx, u, code, allowRecovery)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+	if err := s.recordAccountFailure(ctx, u, now); !errors.Is(err, ErrInvalidCredentials) {
+		return err
+	}
+	return ErrMFAInvalidCode
+}
+
+// checkSecondFactor รับรหัส 6 หลักจากแอป หรือ recovery code (ถ้า allowRecovery)
+func (s *svc) checkSecondFactor(ctx context.Context, u *usermodels.User, code string, allowRecovery bool) (bool, error) {
+	f, err := s.tokens.GetMFA(ctx, u.ID)
+	if err != nil {
+		return false, err
+	}
+	if f == nil || f.ConfirmedAt == nil {
+		return false, ErrMFANotEnabled
+	}
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits && strings.Trim(code, "0123456789") == "" {
+		secret, err := s.mfa.open(f.Secret)
+		if err != nil {
+			return false, err
+		}
+		step, ok := totp.Validate(secret, code, time.Now(), 1)
+		if !ok {
+			return false, nil
+		}
+		return s.tokens.MarkMFAStep(ctx, u.ID, step) // ใช้รหัสเดิมซ้ำไม่ได้
+	}
+	if !allowRecovery {
+		return false, nil
+	}
+	return s.tokens.UseRecoveryCode(ctx, u.ID, hashToken(normalizeRecoveryCode(code)))
+}
+
+// completeLogin ผ่านทุกขั้นแล้ว: ล้างตัวนับ login ผิดและออก token
+func (s *svc) completeLogin(ctx context.Context, u *usermodels.User, client auth.ClientInfo) (*auth.LoginResult, error) {
+	if u.FailedLoginCount > 0 || u.LockedUntil != nil {
+		if err := s.users.UpdateLoginState(ctx, u.ID, 0, nil, nil); err != nil {
+			return nil, err
+		}
+	}
+	access, refresh, err := s.issueTokens(ctx, u, client)
+	if err != nil {
+		return nil, err
+	}
+	return &auth.LoginResult{Access: access, Refresh: refresh, PasswordChangeRequired: u.MustChangePassword}, nil
+}
+
+// newRecoveryCodes รูปแบบ xxxxx-xxxxx (base32 ตัวเล็ก)
+func newRecoveryCodes() (codes, hashes []string) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		_, _ = rand.Read(b)
+		c := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes = append(codes, c[:5]+"-"+c[5:])
+		hashes = append(hashes, hashToken(c))
+	}
+	return codes, hashes
+}
+
+func normalizeRecoveryCode(c string) string {
+	c = strings.ToLower(strings.TrimSpace(c))
+	return strings.NewReplacer("-", "", " ", "").Replace(c)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/totp"
+)
+
+func TestResetMFAPrivilegedNeedsAdmin(t *testing.T) {
+	now := time.Now()
+	users := map[string]*usermodels.User{
+		"A1": {ID: "a1", Role: usermodels.RoleAdmin, IsActive: true},
+		"A2": {ID: "a2", Role: usermodels.RoleAdmin, IsActive: true},
+		"H1": {ID: "h1", Role: usermodels.RoleHR, IsActive: true},
+		"H2": {ID: "h2", Role: usermodels.RoleHR, IsActive: true},
+		"E1": {ID: "e1", Role: usermodels.RoleEmployee, IsActive: true},
+	}
+	cases := []struct {
+		actor, subject string
+		wantErr        error
+	}{
+		{"h1", "e1", nil},
+		{"h1", "a1", ErrMFAResetNotAllowed},
+		{"h1", "h2", ErrMFAResetNotAllowed},
+		{"a1", "a2", nil},
+		{"a1", "h1", nil},
+	}
+	for _, tc := range cases {
+		tokens := &fakeTokens{mfa: map[string]*auth.MFAFactor{tc.subject: {UserID: tc.subject, ConfirmedAt: &now}}}
+		s := &svc{users: &fakeUsers{byCode: users}, tokens: tokens}
+
+		err := s.ResetMFA(context.Background(), tc.actor, tc.subject)
+		if !errors.Is(err, tc.wantErr) {
+			t.Fatalf("%s -> %s: err = %v, want %v", tc.actor, tc.subject, err, tc.wantErr)
+		}
+		if _, kept := tokens.mfa[tc.subject]; kept != (tc.wantErr != nil) {
+			t.Fatalf("%s -> %s: factor kept = %v", tc.actor, tc.subject, kept)
+		}
+	}
+}
+
+// wrongTOTP รหัส 6 หลักที่ไม่ตรงกับช่วงเวลาไหนที่ Validate ยอมรับ
+func wrongTOTP(t *testing.T, secret string) string {
+	t.Helper()
+	valid := map[string]bool{}
+	for d := int64(-1); d <= 1; d++ {
+		c, err := totp.Code(secret, totp.Step(time.Now())+d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		valid[c] = true
+	}
+	for _, c := range []string{"000000", "111111", "222222", "333333"} {
+		if !valid[c] {
+			return c
+		}
+	}
+	t.Fatal("no wrong code available")
+	return ""
+}
+
+// ปิด MFA / ออก recovery code ใหม่ด้วย access token: รหัสผิดนับ lockout เหมือน login
+func TestAccountMFAEndpointsCountFailures(t *testing.T) {
+	now := time.Now()
+	secret := totp.GenerateSecret()
+	u := &usermodels.User{ID: "e1", Role: usermodels.RoleEmployee, IsActive: true}
+	users := &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}}
+	tokens := &fakeTokens{mfa: map[string]*auth.MFAFactor{"e1": {UserID: "e1", Secret: secret, ConfirmedAt: &now}}}
+	s := &svc{users: users, tokens: tokens, lockout: testPolicy()}
+	ctx := context.Background()
+	wrong := wrongTOTP(t, secret)
+
+	if _, err := s.RegenerateRecoveryCodes(ctx, "e1", wrong); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Fatalf("regenerate: err = %v", err)
+	}
+	if err := s.DisableMFA(ctx, "e1", wrong); !errors.Is(err, ErrMFAInvalidCode) {
+		t.Fatalf("disable: err = %v", err)
+	}
+	var te *ThrottledError
+	if err := s.DisableMFA(ctx, "e1", wrong); !errors.As(err, &te) || !te.Locked {
+		t.Fatalf("third failure should lock, got %v", err)
+	}
+	if users.failures != 3 {
+		t.Fatalf("account failures = %d, want 3", users.failures)
+	}
+
+	// ล็อกอยู่ = ไม่ตรวจรหัสเลย แม้รหัสถูก
+	until := now.Add(time.Hour)
+	u.FailedLoginCount, u.LastFailedLoginAt, u.LockedUntil = 3, &now, &until
+	code, err := totp.Code(secret, totp.Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DisableMFA(ctx, "e1", code); !errors.As(err, &te) || !te.Locked {
+		t.Fatalf("locked account: err = %v", err)
+	}
+	if _, kept := tokens.mfa["e1"]; !kept {
+		t.Fatal("MFA disabled while account locked")
+	}
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+var (
+	ErrOIDCDisabled       = errors.New("sso is not configured")
+	ErrOIDCInvalidFlow    = errors.New("invalid or expired sso login")
+	ErrOIDCNotProvisioned = errors.New("no account is linked to this identity")
+)
+
+// oidcClient ทำ discovery ตอนใช้งานครั้งแรก (ไม่ให้ server start ไม่ขึ้นเพราะ IdP ล่ม)
+type oidcClient struct {
+	cfg auth.OIDCConfig
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+func newOIDCClient(cfg auth.OIDCConfig) *oidcClient {
+	if cfg.IssuerURL == "" || cfg.ClientID == "" {
+		return nil
+	}
+	return &oidcClient{cfg: cfg}
+}
+
+func (o *oidcClient) setup(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.oauth != nil {
+		return o.oauth, o.verifier, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	p, err := oidc.NewProvider(ctx, o.cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	o.oauth = &oauth2.Config{
+		ClientID:     o.cfg.ClientID,
+		ClientSecret: o.cfg.ClientSecret,
+		RedirectURL:  o.cfg.RedirectURL,
+		Endpoint:     p.Endpoint(),
+		Scopes:       o.cfg.Scopes,
+	}
+	o.verifier = p.Verifier(&oidc.Config{ClientID: o.cfg.ClientID})
+	return o.oauth, o.verifier, nil
+}
+
+func (s *svc) OIDCAuthURL(ctx context.Context) (string, auth.OIDCFlow, error) {
+	if s.oidc == nil {
+		return "", auth.OIDCFlow{}, ErrOIDCDisabled
+	}
+	oc, _, err := s.oidc.setup(ctx)
+	if err != nil {
+		return "", auth.OIDCFlow{}, err
+	}
+	flow := auth.OIDCFlow{State: randomString(), Nonce: randomString(), Verifier: oauth2.GenerateVerifier()}
+	url := oc.AuthCodeURL(flow.State, oidc.Nonce(flow.Nonce), oauth2.S256ChallengeOption(flow.Verifier))
+	return url, flow, nil
+}
+
+// OIDCLogin แลก code เป็น ID token, ตรวจ nonce แล้ว map claims เป็น user ของระบบ
+// state ถูกเทียบที่ handler แล้ว (ต้องตรงกับ cookie)
+// SSO ไม่ข้าม MFA: ผ่าน mfaChallenge เหมือน Login (policy บังคับ MFA ของ admin/hr ด้วย)
+func (s *svc) OIDCLogin(ctx context.Context, code string, flow auth.OIDCFlow, client auth.ClientInfo) (*auth.LoginResult, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+	if code == "" || flow.Nonce == "" || flow.Verifier == "" {
+		return nil, ErrOIDCInvalidFlow
+	}
+	oc, verifier, err := s.oidc.setup(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tok, err := oc.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		return nil, ErrOIDCInvalidFlow
+	}
+	raw, _ := tok.Extra("id_token").(string)
+	idt, err := verifier.Verify(ctx, raw)
+	if err != nil || idt.Nonce != flow.Nonce {
+		return nil, ErrOIDCInvalidFlow
+	}
+	var claims map[string]any
+	if err := idt.Claims(&claims); err != nil {
+		return nil, err
+	}
+
+	u, err := s.oidcUser(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if !u.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+	if res, err := s.mfaChallenge(ctx, u); err != nil || res != nil {
+		return res, err
+	}
+	return s.completeLogin(ctx, u, client)
+}
+
+// oidcUser หา user จาก employee id claim ก่อน แล้วค่อย email (เฉพาะที่ IdP ยืนยันแล้ว)
+func (s *svc) oidcUser(ctx context.Context, claims map[string]any) (*usermodels.User, error) {
+	cfg := s.oidc.cfg
+	empCode := claimString(claims, cfg.EmployeeIDClaim)
+	email := strings.ToLower(claimString(claims, "email"))
+	if !emailVerified(claims) {
+		email = "" // IdP ไม่ยืนยัน (หรือไม่ส่ง claim มา) = ห้ามใช้ email ผูก/สร้าง account
+	}
+
+	if empCode != "" {
+		u, err := s.users.FindByEmployeeCode(ctx, empCode)
+		if err == nil {
+			return u, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	if email != "" {
+		u, err := s.users.FindByEmail(ctx, email)
+		if err == nil {
+			return u, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+
+	if !cfg.AutoCreate || empCode == "" || email == "" {
+		return nil, ErrOIDCNotProvisioned
+	}
+	return s.createOIDCUser(ctx, claims, empCode, email)
+}
+
+func (s *svc) createOIDCUser(ctx context.Context, claims map[string]any, empCode, email string) (*usermodels.User, error) {
+	role, ok := usermodels.ParseRole(s.oidc.cfg.DefaultRole)
+	if !ok {
+		role = usermodels.RoleEmployee
+	}
+	first, last := claimString(claims, "given_name"), claimString(claims, "family_name")
+	if first == "" {
+		first = strings.SplitN(email, "@", 2)[0]
+	}
+	if last == "" {
+		last = "-"
+	}
+	// user ที่มาจาก SSO ไม่มีรหัสผ่านที่ใช้ได้ (ตั้ง hash ของค่าสุ่มที่ไม่มีใครรู้)
+	hash, err := password.Hash(randomString())
+	if err != nil {
+		return nil, err
+	}
+	u := &usermodels.User{
+		EmployeeCode: empCode,
+		Email:        email,
+		FirstName:    first,
+		LastName:     last,
+		Role:         role,
+		IsActive:     true,
+		PasswordHash: hash,
+	}
+	if err := s.users.Create(ctx, u); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// emailVerified ต้องมี email_verified = true ชัดเจน (บาง IdP ส่งเป็น string "true")
+func emailVerified(claims map[string]any) bool {
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
+
+func claimString(claims map[string]any, name string) string {
+	if name == "" {
+		return ""
+	}
+	switch v := claims[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64: // JSON number เช่น employee id เป็นตัวเลข
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+func randomString() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
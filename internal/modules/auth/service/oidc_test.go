@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func TestMain(m *testing.M) {
+	// access/refresh ของ test ใช้ HS256 (ไม่มี JWT_KEYS_DIR)
+	os.Setenv("JWT_ACCESS_SECRET", "test-access-secret")
+	os.Setenv("JWT_REFRESH_SECRET", "test-refresh-secret")
+	os.Exit(m.Run())
+}
+
+// mockIdP เป็น OIDC provider จำลอง: discovery, JWKS และ token endpoint
+// id token ที่ออกใช้ claims ที่ test ตั้งไว้ (+ iss/aud/exp/nonce)
+type mockIdP struct {
+	srv    *httptest.Server
+	key    *rsa.PrivateKey
+	claims jwt.MapClaims
+	nonce  string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                                idp.srv.URL,
+			"authorization_endpoint":                idp.srv.URL + "/authorize",
+			"token_endpoint":                        idp.srv.URL + "/token",
+			"jwks_uri":                              idp.srv.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"keys": []map[string]string{{
+			"kty": "RSA", "alg": "RS256", "use": "sig", "kid": "k1",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": "invalid_grant"})
+			return
+		}
+		claims := jwt.MapClaims{
+			"iss":   idp.srv.URL,
+			"aud":   "birdlax",
+			"sub":   "idp-user-1",
+			"iat":   time.Now().Unix(),
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+			"nonce": idp.nonce,
+		}
+		for k, v := range idp.claims {
+			claims[k] = v
+		}
+		tok := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		tok.Header["kid"] = "k1"
+		raw, err := tok.SignedString(key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, map[string]any{"access_token": "at", "token_type": "Bearer", "expires_in": 300, "id_token": raw})
+	})
+	idp.srv = httptest.NewServer(mux)
+	t.Cleanup(idp.srv.Close)
+	return idp
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func newOIDCSvc(idp *mockIdP, users *fakeUsers, autoCreate bool) *svc {
+	return &svc{
+		users:  users,
+		tokens: &fakeTokens{},
+		oidc: newOIDCClient(auth.OIDCConfig{
+			IssuerURL:       idp.srv.URL,
+			ClientID:        "birdlax",
+			ClientSecret:    "secret",
+			RedirectURL:     "http://localhost/callback",
+			EmployeeIDClaim: "employee_id",
+			AutoCreate:      autoCreate,
+		}),
+	}
+}
+
+func (idp *mockIdP) login(s *svc) (*auth.LoginResult, error) {
+	flow := auth.OIDCFlow{State: "st", Nonce: "n-123", Verifier: "verifier-verifier-verifier-verifier-verifier"}
+	idp.nonce = flow.Nonce
+	return s.OIDCLogin(context.Background(), "good-code", flow, auth.ClientInfo{IP: "10.0.0.1"})
+}
+
+func TestOIDCLoginByEmployeeID(t *testing.T) {
+	idp := newMockIdP(t)
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true}
+	s := newOIDCSvc(idp, &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}}, false)
+	idp.claims = jwt.MapClaims{"employee_id": "E1"}
+
+	res, err := idp.login(s)
+	if err != nil || res.MFARequired || res.Access == "" || res.Refresh == "" {
+		t.Fatalf("login: %+v, %v", res, err)
+	}
+}
+
+func TestOIDCEmailLinkRequiresVerifiedClaim(t *testing.T) {
+	cases := []struct {
+		name     string
+		verified any // nil = IdP ไม่ส่ง claim มา
+		wantErr  error
+	}{
+		{"missing", nil, ErrOIDCNotProvisioned},
+		{"false", false, ErrOIDCNotProvisioned},
+		{"string false", "false", ErrOIDCNotProvisioned},
+		{"true", true, nil},
+		{"string true", "true", nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			idp := newMockIdP(t)
+			u := &usermodels.User{ID: "u1", EmployeeCode: "E1", Email: "a@example.com", IsActive: true}
+			s := newOIDCSvc(idp, &fakeUsers{byEmail: map[string]*usermodels.User{"a@example.com": u}}, false)
+			idp.claims = jwt.MapClaims{"email": "A@example.com"}
+			if tc.verified != nil {
+				idp.claims["email_verified"] = tc.verified
+			}
+
+			_, err := idp.login(s)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCAutoCreateRequiresVerifiedEmail(t *testing.T) {
+	idp := newMockIdP(t)
+	users := &fakeUsers{}
+	s := newOIDCSvc(idp, users, true)
+
+	idp.claims = jwt.MapClaims{"employee_id": "E9", "email": "new@example.com"}
+	if _, err := idp.login(s); !errors.Is(err, ErrOIDCNotProvisioned) {
+		t.Fatalf("unverified: err = %v", err)
+	}
+	if len(users.created) != 0 {
+		t.Fatalf("created unverified user: %+v", users.created[0])
+	}
+
+	idp.claims["email_verified"] = true
+	if _, err := idp.login(s); err != nil {
+		t.Fatalf("verified: %v", err)
+	}
+	if len(users.created) != 1 || users.created[0].Email != "new@example.com" || users.created[0].Role != usermodels.RoleEmployee {
+		t.Fatalf("created = %+v", users.created)
+	}
+}
+
+func TestOIDCRejectsBadCodeAndNonce(t *testing.T) {
+	idp := newMockIdP(t)
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true}
+	s := newOIDCSvc(idp, &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}}, false)
+	idp.claims = jwt.MapClaims{"employee_id": "E1"}
+	ctx := context.Background()
+	flow := auth.OIDCFlow{Nonce: "n-123", Verifier: "verifier-verifier-verifier-verifier-verifier"}
+
+	idp.nonce = flow.Nonce
+	if _, err := s.OIDCLogin(ctx, "bad-code", flow, auth.ClientInfo{}); !errors.Is(err, ErrOIDCInvalidFlow) {
+		t.Fatalf("bad code: err = %v", err)
+	}
+	idp.nonce = "someone-else"
+	if _, err := s.OIDCLogin(ctx, "good-code", flow, auth.ClientInfo{}); !errors.Is(err, ErrOIDCInvalidFlow) {
+		t.Fatalf("nonce mismatch: err = %v", err)
+	}
+}
+
+func TestOIDCLoginGoesThroughMFA(t *testing.T) {
+	idp := newMockIdP(t)
+	admin := &usermodels.User{ID: "a1", EmployeeCode: "A1", Role: usermodels.RoleAdmin, IsActive: true}
+	emp := &usermodels.User{ID: "e1", EmployeeCode: "E1", Role: usermodels.RoleEmployee, IsActive: true}
+	s := newOIDCSvc(idp, &fakeUsers{byCode: map[string]*usermodels.User{"A1": admin, "E1": emp}}, false)
+	s.mfa = mfaPolicy{enforcePrivileged: true, challengeTTL: time.Minute}
+	confirmed := time.Now()
+	s.tokens = &fakeTokens{mfa: map[string]*auth.MFAFactor{"e1": {UserID: "e1", ConfirmedAt: &confirmed}}}
+
+	// admin ยังไม่ลงทะเบียน แต่ policy บังคับ
+	idp.claims = jwt.MapClaims{"employee_id": "A1"}
+	res, err := idp.login(s)
+	if err != nil || !res.MFARequired || !res.MFAEnrollRequired || res.MFAToken == "" || res.Access != "" {
+		t.Fatalf("admin: %+v, %v", res, err)
+	}
+
+	// employee เปิด MFA เองแล้ว
+	idp.claims = jwt.MapClaims{"employee_id": "E1"}
+	res, err = idp.login(s)
+	if err != nil || !res.MFARequired || res.MFAEnrollRequired || res.Refresh != "" {
+		t.Fatalf("employee: %+v, %v", res, err)
+	}
+}
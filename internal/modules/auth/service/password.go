@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+var (
+	ErrCurrentPasswordInvalid = errors.New("current password is incorrect")
+	// ErrPasswordManagedExternally รหัสของ account นี้อยู่ที่ directory (เช่น AD) เปลี่ยนที่นี่ไม่มีผล
+	ErrPasswordManagedExternally = errors.New("password is managed by the directory")
+)
+
+// ChangePassword เปลี่ยนรหัสของตัวเอง: ตรวจรหัสเดิม, เตะทุก session ออก แล้วออก token ใหม่ให้เครื่องนี้
+func (s *svc) ChangePassword(ctx context.Context, userID, current, newPassword string, client auth.ClientInfo) (*auth.LoginResult, error) {
+	now := time.Now()
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil || u == nil || !u.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+	if err := s.lockout.check(accountThrottle(u), now); err != nil {
+		return nil, err
+	}
+	// รหัสเดิมผิดนับเป็น login ผิด (กันคนที่ได้ access token ไปเดารหัส)
+	id, err := s.verifyPassword(ctx, u, current)
+	if err != nil {
+		if !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		if err := s.recordAccountFailure(ctx, u, now); !errors.Is(err, ErrInvalidCredentials) {
+			return nil, err
+		}
+		return nil, ErrCurrentPasswordInvalid
+	}
+	// รหัสที่ยืนยันกับ LDAP ไม่ได้เก็บที่นี่: เขียน hash local ไปก็ไม่ถูกใช้ (และกลายเป็นรหัสสำรองที่ไม่มีใครรู้)
+	if id == nil || id.Provider != "local" {
+		return nil, ErrPasswordManagedExternally
+	}
+	if err := s.checkPassword(ctx, u, newPassword); err != nil {
+		return nil, err
+	}
+	if err := s.savePassword(ctx, u, newPassword); err != nil {
+		return nil, err
+	}
+	if err := s.RevokeAllSessions(ctx, u.ID); err != nil {
+		return nil, err
+	}
+	u.TokenVersion++ // RevokeAllSessions bump version ใน DB แล้ว
+	return s.completeLogin(ctx, u, client)
+}
+
+// checkPassword ตรวจ password policy (คืน *password.PolicyError ถ้าไม่ผ่าน)
+func (s *svc) checkPassword(ctx context.Context, u *usermodels.User, pw string) error {
+	return s.pwPolicy.Check(ctx, s.users, password.Subject{
+		UserID:      u.ID,
+		CurrentHash: u.PasswordHash,
+		Personal:    []string{u.EmployeeCode, u.Email, u.FirstName, u.LastName},
+	}, pw)
+}
+
+// savePassword hash แล้วบันทึก พร้อมเก็บลง history และล้างสถานะบังคับเปลี่ยนรหัส
+func (s *svc) savePassword(ctx context.Context, u *usermodels.User, pw string) error {
+	hash, err := password.Hash(pw)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	u.PasswordHash = hash
+	u.PasswordChangedAt = &now
+	u.MustChangePassword = false
+	if err := s.users.Update(ctx, u, "password_hash", "password_changed_at", "must_change_password"); err != nil {
+		return err
+	}
+	if s.pwPolicy.History <= 0 {
+		return nil
+	}
+	return s.users.AddPasswordHistory(ctx, u.ID, hash, s.pwPolicy.History)
+}
+
+// expirePassword รหัส local ที่เกิน PASSWORD_MAX_AGE => บังคับเปลี่ยนก่อนใช้งานอย่างอื่น
+// token ที่ออกไปก่อนหน้าไม่มีธง pwd_change จึงต้องทำให้ใช้ไม่ได้ด้วย
+func (s *svc) expirePassword(ctx context.Context, u *usermodels.User, id *auth.Identity, now time.Time) error {
+	if u.MustChangePassword || id == nil || id.Provider != "local" {
+		return nil
+	}
+	changedAt := u.CreatedAt
+	if u.PasswordChangedAt != nil {
+		changedAt = *u.PasswordChangedAt
+	}
+	if !s.pwPolicy.Expired(changedAt, now) {
+		return nil
+	}
+	if err := s.users.SetMustChangePassword(ctx, u.ID, true); err != nil {
+		return err
+	}
+	if err := s.revokeAccess(ctx, u.ID); err != nil {
+		return err
+	}
+	u.MustChangePassword = true
+	u.TokenVersion++ // ให้ token ที่กำลังจะออกตรงกับ version ใหม่ใน DB
+	return nil
+}
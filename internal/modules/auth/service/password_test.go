@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+func TestChangePasswordOnlyForLocalIdentities(t *testing.T) {
+	dir := newFakeDirectory(t, map[string]ldapEntry{
+		"uid=E1,dc=example,dc=com": {uid: "E1", password: "ad-password"},
+	})
+	localHash, err := password.Hash("Old-password-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ad := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true}
+	local := &usermodels.User{ID: "u2", EmployeeCode: "L1", IsActive: true, PasswordHash: localHash}
+	users := &fakeUsers{byCode: map[string]*usermodels.User{"E1": ad, "L1": local}}
+	s := &svc{
+		users:     users,
+		tokens:    &fakeTokens{},
+		lockout:   testPolicy(),
+		verifiers: []auth.CredentialVerifier{newTestLDAPVerifier(t, dir.url()), LocalVerifier{}},
+	}
+	ctx := context.Background()
+
+	if _, err := s.ChangePassword(ctx, "u1", "ad-password", "New-password-1", auth.ClientInfo{}); !errors.Is(err, ErrPasswordManagedExternally) {
+		t.Fatalf("ldap user: err = %v", err)
+	}
+	if len(users.saved) != 0 {
+		t.Fatalf("ldap user got a local hash: %+v", users.saved)
+	}
+
+	res, err := s.ChangePassword(ctx, "u2", "Old-password-1", "New-password-1", auth.ClientInfo{})
+	if err != nil || res.Access == "" {
+		t.Fatalf("local user: %+v, %v", res, err)
+	}
+	if len(users.saved) != 1 || !password.Verify(users.saved[0].PasswordHash, "New-password-1") {
+		t.Fatalf("local user not saved: %+v", users.saved)
+	}
+}
+
+func TestLoginExpiresOldPasswordByColumn(t *testing.T) {
+	hash, err := password.Hash("Old-password-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := time.Now().Add(-100 * 24 * time.Hour)
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", IsActive: true, PasswordHash: hash, PasswordChangedAt: &changed}
+	users := &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}}
+	p := testPolicy()
+	p.baseDelay = 0
+	s := &svc{
+		users:     users,
+		tokens:    &fakeTokens{},
+		lockout:   p,
+		pwPolicy:  password.Policy{MaxAge: 90 * 24 * time.Hour},
+		verifiers: []auth.CredentialVerifier{LocalVerifier{}},
+	}
+
+	res, err := s.Login(context.Background(), "E1", "Old-password-1", auth.ClientInfo{})
+	if err != nil || !res.PasswordChangeRequired {
+		t.Fatalf("login: %+v, %v", res, err)
+	}
+	if !users.mustChange["u1"] || len(users.saved) != 0 {
+		t.Fatalf("must_change = %v, full saves = %d", users.mustChange, len(users.saved))
+	}
+	// token เดิมที่ไม่มีธง pwd_change ต้องใช้ไม่ได้
+	if users.tokenBumps != 1 || u.TokenVersion != 1 {
+		t.Fatalf("token version not bumped: bumps=%d ver=%d", users.tokenBumps, u.TokenVersion)
+	}
+}
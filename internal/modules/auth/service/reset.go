@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/notify"
+)
+
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// ForgotPassword ส่งลิงก์ reset ไปทาง email ของ user
+// ไม่ว่าจะหา user เจอหรือไม่ก็คืน nil เสมอ เพื่อไม่ให้ใช้เดา employee code/email ได้
+// (error ระหว่างทางแค่ log, email ส่งผ่านคิวเบื้องหลัง) ยกเว้น IP ขอถี่เกินจะได้ *ThrottledError
+// ถ้าเปิด directory verifier (LDAP) ด้วย จะไม่ส่งลิงก์ให้ใครเลย (ดู localPasswordsOnly)
+func (s *svc) ForgotPassword(ctx context.Context, identifier string, client auth.ClientInfo) error {
+	now := time.Now()
+	if client.IP != "" {
+		if err := s.resetThrottle(ctx, resetIPKey+client.IP, s.lockout.resetIPThreshold, now); err != nil {
+			return err
+		}
+	}
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" || !s.localPasswordsOnly() {
+		return nil
+	}
+	var (
+		u   *usermodels.User
+		err error
+	)
+	if strings.Contains(identifier, "@") {
+		u, err = s.users.FindByEmail(ctx, strings.ToLower(identifier))
+	} else {
+		u, err = s.users.FindByEmployeeCode(ctx, identifier)
+	}
+	if err != nil || u == nil || !u.IsActive {
+		return nil
+	}
+	// ต่อ account: กันยิงลิงก์ถล่ม inbox ของคนเดียว (เงียบไว้ ไม่บอกว่าโดนจำกัด)
+	if err := s.resetThrottle(ctx, resetUserKey+u.ID, s.lockout.resetAccountThreshold, now); err != nil {
+		log.Printf("forgot-password: user %s: %v", u.ID, err)
+		return nil
+	}
+
+	token := newResetToken()
+	ttl := parseDuration(os.Getenv("PASSWORD_RESET_TTL"), 30*time.Minute)
+	if err := s.tokens.SaveResetToken(ctx, u.ID, hashToken(token), now.Add(ttl).Unix()); err != nil {
+		log.Printf("forgot-password: save token for user %s: %v", u.ID, err)
+		return nil
+	}
+
+	msg := notify.Message{
+		To:      u.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hello %s,\n\nUse the link below to set a new password. "+
+			"The link can be used once and expires in %s.\n\n%s\n\n"+
+			"If you did not request this, you can ignore this email.\n",
+			u.FirstName, ttl, resetLink(token)),
+	}
+	if err := s.notifier.Send(ctx, msg); err != nil {
+		// ไม่คืน error ให้ client (จะกลายเป็นช่องให้เดาว่า account มีอยู่จริง)
+		log.Printf("forgot-password: notify user %s: %v", u.ID, err)
+	}
+	return nil
+}
+
+// key ของตัวนับใน login_throttles (ตารางเดียวกับ IP ของ login แต่แยก namespace)
+const (
+	resetIPKey   = "pwreset:"
+	resetUserKey = "pwreset:u:"
+)
+
+// resetThrottle ใช้กติกาหน่วง/ล็อกเดียวกับ login; ทุกคำขอนับ +1 (ไม่ใช่แค่ครั้งที่ผิด)
+func (s *svc) resetThrottle(ctx context.Context, key string, threshold int, now time.Time) error {
+	t, err := s.tokens.GetIPThrottle(ctx, key)
+	if err != nil {
+		return err
+	}
+	if err := s.lockout.check(*t, now); err != nil {
+		return err
+	}
+	_, err = s.tokens.RecordIPFailure(ctx, key, now, now.Add(-s.lockout.lockDuration), threshold, s.lockout.lockDuration)
+	return err
+}
+
+func (s *svc) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// ลิงก์ที่ออกไปก่อนเปิด LDAP ก็ใช้ไม่ได้แล้ว
+	if !s.localPasswordsOnly() {
+		return ErrPasswordManagedExternally
+	}
+	tokenHash := hashToken(strings.TrimSpace(token))
+	userID, err := s.tokens.FindResetToken(ctx, tokenHash)
+	if err != nil {
+		return ErrInvalidResetToken
+	}
+	u, err := s.users.FindByID(ctx, userID)
+	if err != nil || u == nil || !u.IsActive {
+		return ErrInvalidResetToken
+	}
+	// ตรวจ policy ก่อน consume token — รหัสไม่ผ่านจะได้ลองใหม่ด้วยลิงก์เดิม
+	if err := s.checkPassword(ctx, u, newPassword); err != nil {
+		return err
+	}
+	if _, err := s.tokens.ConsumeResetToken(ctx, tokenHash); err != nil {
+		return ErrInvalidResetToken
+	}
+
+	if err := s.savePassword(ctx, u, newPassword); err != nil {
+		return err
+	}
+	// reset สำเร็จ = ปลดล็อกด้วย และเตะทุก session เดิมออก
+	if err := s.users.UpdateLoginState(ctx, u.ID, 0, nil, nil); err != nil {
+		return err
+	}
+	return s.RevokeAllSessions(ctx, u.ID)
+}
+
+func newResetToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
+
+// resetLink ใช้ PASSWORD_RESET_URL (frontend) โดยแทน {token} ด้วย token จริง
+func resetLink(token string) string {
+	u := os.Getenv("PASSWORD_RESET_URL")
+	if u == "" {
+		u = "http://localhost:8000/reset-password?token={token}"
+	}
+	if !strings.Contains(u, "{token}") {
+		return u + token
+	}
+	return strings.ReplaceAll(u, "{token}", token)
+}
@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/notify"
+)
+
+type fakeNotifier struct{ sent []notify.Message }
+
+func (f *fakeNotifier) Send(_ context.Context, m notify.Message) error {
+	f.sent = append(f.sent, m)
+	return nil
+}
+
+func newResetSvc() (*svc, *fakeTokens, *fakeNotifier) {
+	u := &usermodels.User{ID: "u1", EmployeeCode: "E1", Email: "e1@example.com", IsActive: true}
+	tokens := &fakeTokens{}
+	n := &fakeNotifier{}
+	p := testPolicy()
+	p.baseDelay = 0
+	p.resetIPThreshold = 5
+	p.resetAccountThreshold = 2
+	return &svc{
+		users:    &fakeUsers{byCode: map[string]*usermodels.User{"E1": u}},
+		tokens:   tokens,
+		notifier: n,
+		lockout:  p,
+	}, tokens, n
+}
+
+func TestForgotPasswordSameAnswerForUnknownAndKnown(t *testing.T) {
+	s, tokens, n := newResetSvc()
+	ctx := context.Background()
+
+	if err := s.ForgotPassword(ctx, "NOPE", auth.ClientInfo{IP: "1.1.1.1"}); err != nil {
+		t.Fatalf("unknown: %v", err)
+	}
+	if err := s.ForgotPassword(ctx, "E1", auth.ClientInfo{IP: "1.1.1.2"}); err != nil {
+		t.Fatalf("known: %v", err)
+	}
+	if len(n.sent) != 1 || n.sent[0].To != "e1@example.com" {
+		t.Fatalf("sent = %+v", n.sent)
+	}
+
+	// บันทึก token พังก็ยังตอบเหมือนเดิม
+	tokens.saveErr = errors.New("db down")
+	if err := s.ForgotPassword(ctx, "E1", auth.ClientInfo{IP: "1.1.1.3"}); err != nil {
+		t.Fatalf("save error leaked: %v", err)
+	}
+}
+
+func TestForgotPasswordAccountLimitIsSilent(t *testing.T) {
+	s, tokens, n := newResetSvc()
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		if err := s.ForgotPassword(ctx, "E1", auth.ClientInfo{IP: "2.2.2.2"}); err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+	}
+	// limit 2 ต่อ account: ครั้งที่ 3-4 ไม่ส่ง แต่ตอบ nil เหมือนเดิม
+	if len(n.sent) != 2 || tokens.resetTokens != 2 {
+		t.Fatalf("sent %d emails, %d tokens; want 2", len(n.sent), tokens.resetTokens)
+	}
+}
+
+func TestForgotPasswordIPLimit(t *testing.T) {
+	s, _, _ := newResetSvc()
+	ctx := context.Background()
+	client := auth.ClientInfo{IP: "3.3.3.3"}
+	var err error
+	for i := 0; i < 6 && err == nil; i++ {
+		err = s.ForgotPassword(ctx, "NOPE", client)
+	}
+	var te *ThrottledError
+	if !errors.As(err, &te) || te.RetryAfter <= 0 || te.RetryAfter > 15*time.Minute {
+		t.Fatalf("want ThrottledError after IP limit, got %v", err)
+	}
+}
+
+// เปิด LDAP ด้วย = รหัสของ account อาจอยู่ที่ AD: ห้ามสร้างรหัส local ผ่านลิงก์ reset
+func TestResetDisabledWithDirectoryVerifier(t *testing.T) {
+	s, tokens, n := newResetSvc()
+	s.verifiers = []auth.CredentialVerifier{&LDAPVerifier{}, LocalVerifier{}}
+	ctx := context.Background()
+
+	if err := s.ForgotPassword(ctx, "E1", auth.ClientInfo{IP: "4.4.4.4"}); err != nil {
+		t.Fatalf("forgot: %v", err)
+	}
+	if len(n.sent) != 0 || tokens.resetTokens != 0 {
+		t.Fatalf("sent %d emails, %d tokens; want none", len(n.sent), tokens.resetTokens)
+	}
+	if err := s.ResetPassword(ctx, "any-token", "N3w-Passw0rd!"); !errors.Is(err, ErrPasswordManagedExternally) {
+		t.Fatalf("reset: err = %v", err)
+	}
+}
@@ -5,59 +5,117 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"log"
 	"os"
 	"strconv"
 	"time"
 
+	"github.com/Marugo/birdlax/internal/modules/audit"
 	auth "github.com/Marugo/birdlax/internal/modules/auth"
 	user "github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/notify"
 	"github.com/Marugo/birdlax/internal/shared/password"
 	"github.com/Marugo/birdlax/internal/shared/security"
 )
 
 type svc struct {
-	users  user.Repository // ต้องมี FindByEmployeeCode
-	tokens auth.Repository // ต้องมี SaveRefresh/IsRevoked/Revoke
+	users     user.Repository // ต้องมี FindByEmployeeCode
+	tokens    auth.Repository // ต้องมี SaveRefresh/IsRevoked/Revoke
+	notifier  notify.Notifier // ส่งลิงก์ reset password
+	audits    audit.Service   // impersonation
+	lockout   lockoutPolicy
+	mfa       mfaPolicy
+	pwPolicy  password.Policy
+	oidc      *oidcClient               // nil = ไม่ได้เปิด SSO
+	verifiers []auth.CredentialVerifier // ลำดับการตรวจรหัสผ่าน (default: local)
 }
 
-func New(users user.Repository, tokens auth.Repository) auth.Service { return &svc{users, tokens} }
-
-func (s *svc) Login(ctx context.Context, employeeCode, pw string) (string, string, error) {
-	u, err := s.users.FindByEmployeeCode(ctx, employeeCode) // ✅ เมธอดนี้ต้องมีแล้วใน user repo
-	if err != nil || u == nil || !u.IsActive {
-		return "", "", errors.New("invalid credentials")
+func New(users user.Repository, tokens auth.Repository, notifier notify.Notifier, audits audit.Service,
+	oidcCfg auth.OIDCConfig, verifiers []auth.CredentialVerifier) auth.Service {
+	if len(verifiers) == 0 {
+		verifiers = []auth.CredentialVerifier{LocalVerifier{}}
 	}
-	if !password.Verify(u.PasswordHash, pw) {
-		return "", "", errors.New("invalid credentials")
+	return &svc{
+		users:     users,
+		tokens:    tokens,
+		notifier:  notifier,
+		audits:    audits,
+		lockout:   loadLockoutPolicy(),
+		mfa:       loadMFAPolicy(),
+		pwPolicy:  password.PolicyFromEnv(),
+		oidc:      newOIDCClient(oidcCfg),
+		verifiers: verifiers,
 	}
-	accessTTL := parseDuration(os.Getenv("JWT_ACCESS_TTL_MIN"), 60*time.Minute)
-	refreshTTL := parseDuration(os.Getenv("JWT_REFRESH_TTL_H"), 7*24*time.Hour)
+}
+
+func (s *svc) Login(ctx context.Context, employeeCode, pw string, client auth.ClientInfo) (*auth.LoginResult, error) {
+	now := time.Now()
 
-	access, err := security.SignAccess(u.ID, string(u.Role), u.EmployeeCode, accessTTL)
+	// 1) IP ที่ยิงผิดรัวๆ โดนหน่วง/ล็อกก่อน ไม่ว่าจะลอง account ไหน
+	ipState, err := s.ipThrottle(ctx, client.IP)
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	if err := s.lockout.check(ipState, now); err != nil {
+		return nil, err
 	}
 
-	jti := newJTI()
-	refresh, err := security.SignRefresh(u.ID, jti, refreshTTL)
+	u, err := s.users.FindByEmployeeCode(ctx, employeeCode) // ✅ เมธอดนี้ต้องมีแล้วใน user repo
+	if err != nil || u == nil || !u.IsActive {
+		if err := s.recordIPFailure(ctx, client.IP, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	// 2) account ที่ถูกล็อก ปฏิเสธก่อนเช็ครหัส (แม้รหัสถูกก็ไม่ให้เข้า)
+	// ตอบเหมือน employee code ที่ไม่มีอยู่ และนับเป็นความผิดของ IP เหมือนกัน
+	if err := s.lockout.check(accountThrottle(u), now); err != nil {
+		if err := s.recordIPFailure(ctx, client.IP, now); err != nil {
+			return nil, err
+		}
+		return nil, hideAccountState(err)
+	}
+	id, err := s.verifyPassword(ctx, u, pw)
+	if errors.Is(err, ErrInvalidCredentials) {
+		if err := s.recordIPFailure(ctx, client.IP, now); err != nil {
+			return nil, err
+		}
+		return nil, hideAccountState(s.recordAccountFailure(ctx, u, now))
+	}
 	if err != nil {
-		return "", "", err
+		return nil, err
+	}
+	if err := s.syncRole(ctx, u, id); err != nil {
+		return nil, err
+	}
+	if err := s.expirePassword(ctx, u, id, now); err != nil {
+		return nil, err
 	}
 
-	if err := s.tokens.SaveRefresh(ctx, u.ID, jti, time.Now().Add(refreshTTL).Unix()); err != nil {
-		return "", "", err
+	// 3) MFA: ยังไม่ล้างตัวนับ login ผิดจนกว่าจะผ่านขั้นที่สอง (กันเดารหัส MFA ไปเรื่อยๆ)
+	if res, err := s.mfaChallenge(ctx, u); err != nil || res != nil {
+		return res, err
 	}
-	return access, refresh, nil
+	return s.completeLogin(ctx, u, client)
 }
 
-func (s *svc) Refresh(ctx context.Context, refreshToken string) (string, string, error) {
+func (s *svc) Refresh(ctx context.Context, refreshToken string, client auth.ClientInfo) (string, string, error) {
 	c, err := security.ParseRefresh(refreshToken)
 	if err != nil {
 		return "", "", errors.New("invalid refresh")
 	}
 
-	revoked, err := s.tokens.IsRevoked(ctx, c.JTI)
-	if err != nil || revoked {
+	rec, err := s.tokens.FindRefresh(ctx, c.JTI)
+	if err != nil || rec.UserID != c.UserID {
+		return "", "", errors.New("invalid refresh")
+	}
+	if rec.Revoked {
+		if rec.ReplacedBy != "" {
+			// token นี้ถูก rotate ไปแล้ว แต่มีคนเอามาใช้อีก = น่าจะถูกขโมย
+			return "", "", s.refreshReused(ctx, rec, client)
+		}
 		return "", "", errors.New("refresh revoked")
 	}
 
@@ -66,25 +124,70 @@ func (s *svc) Refresh(ctx context.Context, refreshToken string) (string, string,
 		return "", "", errors.New("invalid user")
 	}
 
-	_ = s.tokens.Revoke(ctx, c.JTI) // rotate
+	access, refresh, next, err := s.signTokens(u, rec.FamilyID, client)
+	if err != nil {
+		return "", "", err
+	}
+	rotated, err := s.tokens.RotateRefresh(ctx, c.JTI, next)
+	if err != nil {
+		return "", "", err
+	}
+	if !rotated {
+		// แพ้ race กับ request อื่นที่ใช้ token เดียวกัน
+		return "", "", s.refreshReused(ctx, rec, client)
+	}
+	return access, refresh, nil
+}
 
+// issueTokens ออก access + refresh ใหม่ (family ใหม่) และบันทึก refresh เป็น session
+func (s *svc) issueTokens(ctx context.Context, u *usermodels.User, client auth.ClientInfo) (string, string, error) {
+	access, refresh, sess, err := s.signTokens(u, "", client)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.tokens.SaveRefresh(ctx, sess); err != nil {
+		return "", "", err
+	}
+	// ทุกทางที่ login สำเร็จ (รหัสผ่าน/MFA/OIDC) มาจบที่นี่; last login เป็นแค่ข้อมูลประกอบ บันทึกไม่ได้ก็ไม่ให้ login พัง
+	if err := s.users.UpdateLastLogin(ctx, u.ID, time.Now()); err != nil {
+		log.Printf("update last login for user %s: %v", u.ID, err)
+	}
+	return access, refresh, nil
+}
+
+// signTokens เซ็น access + refresh; familyID ว่าง = เริ่ม family ใหม่โดยใช้ jti ตัวแรก
+func (s *svc) signTokens(u *usermodels.User, familyID string, client auth.ClientInfo) (string, string, auth.Session, error) {
 	accessTTL := parseDuration(os.Getenv("JWT_ACCESS_TTL_MIN"), 60*time.Minute)
 	refreshTTL := parseDuration(os.Getenv("JWT_REFRESH_TTL_H"), 7*24*time.Hour)
 
-	access, err := security.SignAccess(u.ID, string(u.Role), u.EmployeeCode, accessTTL)
+	access, err := security.SignAccessClaims(security.AccessClaims{
+		UserID:    u.ID,
+		Role:      string(u.Role),
+		Emp:       u.EmployeeCode,
+		Ver:       u.TokenVersion,
+		PwdChange: u.MustChangePassword,
+	}, accessTTL)
 	if err != nil {
-		return "", "", err
+		return "", "", auth.Session{}, err
 	}
 
-	newJti := newJTI()
-	newRefresh, err := security.SignRefresh(u.ID, newJti, refreshTTL)
+	jti := newJTI()
+	refresh, err := security.SignRefresh(u.ID, jti, refreshTTL)
 	if err != nil {
-		return "", "", err
+		return "", "", auth.Session{}, err
 	}
-	if err := s.tokens.SaveRefresh(ctx, u.ID, newJti, time.Now().Add(refreshTTL).Unix()); err != nil {
-		return "", "", err
+	if familyID == "" {
+		familyID = jti
 	}
-	return access, newRefresh, nil
+
+	return access, refresh, auth.Session{
+		ID:        jti,
+		FamilyID:  familyID,
+		UserID:    u.ID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		ExpiresAt: time.Now().Add(refreshTTL),
+	}, nil
 }
 
 func (s *svc) Logout(ctx context.Context, refreshToken string) error {
@@ -95,6 +198,15 @@ func (s *svc) Logout(ctx context.Context, refreshToken string) error {
 	return s.tokens.Revoke(ctx, c.JTI)
 }
 
+// revokeAccess ทำให้ access token ที่ออกไปแล้วของ user ใช้ไม่ได้ทันที
+func (s *svc) revokeAccess(ctx context.Context, userID string) error {
+	if err := s.users.BumpTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+	security.InvalidateTokenState(userID)
+	return nil
+}
+
 func newJTI() string { b := make([]byte, 16); _, _ = rand.Read(b); return hex.EncodeToString(b) }
 func parseDuration(s string, def time.Duration) time.Duration {
 	d, err := time.ParseDuration(s)
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	auth "github.com/Marugo/birdlax/internal/modules/auth"
+	"github.com/Marugo/birdlax/internal/shared/security"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// ListSessions คืน session ที่ยัง active ของ user; currentRefresh (ถ้ามี) ใช้ mark ว่าอันไหนคือเครื่องนี้
+func (s *svc) ListSessions(ctx context.Context, userID, currentRefresh string) ([]auth.Session, error) {
+	items, err := s.tokens.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if currentRefresh != "" {
+		if c, err := security.ParseRefresh(currentRefresh); err == nil {
+			for i := range items {
+				items[i].Current = items[i].ID == c.JTI
+			}
+		}
+	}
+	return items, nil
+}
+
+func (s *svc) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.tokens.RevokeForUser(ctx, userID, sessionID); err != nil {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeAllSessions revoke refresh ทั้งหมด และ bump token version ให้ access token ที่ค้างอยู่ตายด้วย
+func (s *svc) RevokeAllSessions(ctx context.Context, userID string) error {
+	if err := s.tokens.RevokeAllForUser(ctx, userID); err != nil {
+		return err
+	}
+	return s.revokeAccess(ctx, userID)
+}
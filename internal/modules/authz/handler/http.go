@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	authzservice "github.com/Marugo/birdlax/internal/modules/authz/service"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/Marugo/birdlax/internal/shared/response"
+)
+
+type HTTPHandler struct {
+	svc       authz.Service
+	validator *validator.Validate
+}
+
+func NewHTTPHandler(s authz.Service) *HTTPHandler {
+	return &HTTPHandler{svc: s, validator: validator.New()}
+}
+
+type setRolePermissionsReq struct {
+	Permissions []string `json:"permissions" validate:"required"`
+}
+
+// GET /authz/permissions
+func (h *HTTPHandler) Catalog(c *fiber.Ctx) error {
+	return response.OK(c, h.svc.Catalog())
+}
+
+// GET /authz/roles
+func (h *HTTPHandler) ListRoles(c *fiber.Ctx) error {
+	out, err := h.svc.RolePermissions(c.Context())
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, out)
+}
+
+// PUT /authz/roles/:role — แทนที่ permission ทั้งชุดของ role
+func (h *HTTPHandler) SetRole(c *fiber.Ctx) error {
+	actorID, _ := c.Locals("user_id").(string)
+	var req setRolePermissionsReq
+	if err := c.BodyParser(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	perms, err := h.svc.SetRolePermissions(c.Context(), actorID, c.Params("role"), req.Permissions)
+	if err != nil {
+		switch {
+		case errors.Is(err, authzservice.ErrInvalidRole):
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", err.Error())
+		case errors.Is(err, authzservice.ErrSuperRole):
+			return response.Err(c, fiber.StatusForbidden, "ROLE_NOT_EDITABLE", err.Error())
+		case errors.Is(err, authzservice.ErrUnknownPermission):
+			return response.Err(c, fiber.StatusBadRequest, "UNKNOWN_PERMISSION", err.Error())
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"role": c.Params("role"), "permissions": perms})
+}
+
+// GET /auth/me/permissions — ให้ frontend ซ่อนปุ่มที่ไม่มีสิทธิ์
+func (h *HTTPHandler) MyPermissions(c *fiber.Ctx) error {
+	perms, err := middleware.Permissions(c)
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	out := make([]string, 0, len(perms))
+	for p := range perms {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	role, _ := c.Locals("role").(string)
+	return response.OK(c, fiber.Map{"role": role, "permissions": out})
+}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+func Register(r fiber.Router, svc authz.Service) {
+	h := NewHTTPHandler(svc)
+
+	r.Get("/auth/me/permissions", h.MyPermissions)
+
+	// ===== role -> permission (แก้ได้เฉพาะคนที่มี authz.manage ตัวจริง) =====
+	g := r.Group("/authz", middleware.RequireUser(), middleware.DenyImpersonation(), middleware.RequirePermission(authz.PermAuthzManage))
+	g.Get("/permissions", h.Catalog)
+	g.Get("/roles", h.ListRoles)
+	g.Put("/roles/:role", h.SetRole)
+}
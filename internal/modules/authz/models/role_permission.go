@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// RolePermission role หนึ่งได้ permission หนึ่ง
+type RolePermission struct {
+	Role       usermodels.Role `gorm:"type:varchar(32);primaryKey" json:"role"`
+	Permission string          `gorm:"size:64;primaryKey" json:"permission"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
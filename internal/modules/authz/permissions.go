@@ -0,0 +1,89 @@
+package authz
+
+import usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+
+// ชื่อ permission ที่ route ใช้ (ผูกกับ role ผ่านตาราง role_permissions)
+const (
+	PermUserRead        = "user.read"
+	PermUserWrite       = "user.write"
+	PermUserAssignRole  = "user.assign_role"
+	PermUserSecurity    = "user.security"
+	PermUserImpersonate = "user.impersonate"
+	PermUserPrivacy     = "user.privacy"
+
+	PermDepartmentRead  = "department.read"
+	PermDepartmentWrite = "department.write"
+
+	PermCourseRead  = "course.read"
+	PermCourseWrite = "course.write"
+
+	PermAssessmentWrite = "assessment.write"
+	PermAssessmentGrade = "assessment.grade"
+
+	PermAnalyticsRead = "analytics.read"
+	PermAPIKeyManage  = "apikey.manage"
+	PermAuditRead     = "audit.read"
+	PermAuthzManage   = "authz.manage"
+)
+
+type Permission struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+var catalog = []Permission{
+	{PermUserRead, "List and view users"},
+	{PermUserWrite, "Create, edit and delete users and their department roles"},
+	{PermUserAssignRole, "Set a user's system role"},
+	{PermUserSecurity, "Unlock accounts, revoke sessions and reset MFA of other users"},
+	{PermUserImpersonate, "Act as another user (audited)"},
+	{PermUserPrivacy, "Export a user's personal data and anonymize departed users (audited)"},
+	{PermDepartmentRead, "List and view departments"},
+	{PermDepartmentWrite, "Create, edit and delete departments"},
+	{PermCourseRead, "List all courses including inactive ones"},
+	{PermCourseWrite, "Author courses, modules, lessons, assets and categories"},
+	{PermAssessmentWrite, "Author assessments, questions and choices"},
+	{PermAssessmentGrade, "Review learners' assessment attempts"},
+	{PermAnalyticsRead, "View learning analytics"},
+	{PermAPIKeyManage, "Manage service accounts and API keys"},
+	{PermAuditRead, "Read the audit log"},
+	{PermAuthzManage, "Edit role permissions"},
+}
+
+// Catalog permission ทั้งหมดที่ระบบรู้จัก
+func Catalog() []Permission {
+	return append([]Permission(nil), catalog...)
+}
+
+func IsKnown(name string) bool {
+	for _, p := range catalog {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HumanOnly permission ที่ service account (API key) ไม่ได้ แม้ role ของมันจะมี
+// (กัน key scope "*" ของ role admin ไปยึด account คนอื่น/แก้สิทธิ์)
+func HumanOnly() []string {
+	return []string{
+		PermUserAssignRole, PermUserSecurity, PermUserImpersonate, PermUserPrivacy,
+		PermAPIKeyManage, PermAuditRead, PermAuthzManage,
+	}
+}
+
+// SuperRole ได้ทุก permission เสมอ (แก้ไม่ได้ กันล็อกตัวเองออกจากระบบ)
+const SuperRole = usermodels.RoleAdmin
+
+// DefaultGrants ใส่ให้ตอนตาราง role_permissions ยังว่าง (ติดตั้งครั้งแรก)
+var DefaultGrants = map[usermodels.Role][]string{
+	usermodels.RoleHR: {
+		PermUserRead, PermUserWrite, PermUserAssignRole, PermUserSecurity,
+		PermDepartmentRead, PermDepartmentWrite,
+		PermCourseRead, PermCourseWrite,
+		PermAssessmentWrite, PermAssessmentGrade,
+		PermAnalyticsRead,
+	},
+	usermodels.RoleEmployee: {},
+}
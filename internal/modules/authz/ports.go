@@ -0,0 +1,26 @@
+package authz
+
+import (
+	"context"
+
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+type Repository interface {
+	ListByRole(ctx context.Context, role usermodels.Role) ([]string, error)
+	ListAll(ctx context.Context) (map[usermodels.Role][]string, error)
+	ReplaceRole(ctx context.Context, role usermodels.Role, perms []string) error
+	Count(ctx context.Context) (int64, error)
+}
+
+type Service interface {
+	Catalog() []Permission
+	RolePermissions(ctx context.Context) (map[usermodels.Role][]string, error)
+	SetRolePermissions(ctx context.Context, actorID, role string, perms []string) ([]string, error)
+
+	// Permissions ของ role (มี cache); ใช้โดย middleware.RequirePermission
+	Permissions(ctx context.Context, role string) ([]string, error)
+
+	// EnsureDefaults ใส่ DefaultGrants ถ้ายังไม่เคยตั้งค่า
+	EnsureDefaults(ctx context.Context) error
+}
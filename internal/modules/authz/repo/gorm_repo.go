@@ -0,0 +1,59 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	authzmodels "github.com/Marugo/birdlax/internal/modules/authz/models"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+type gormRepo struct{ db *gorm.DB }
+
+func NewGormRepository(db *gorm.DB) authz.Repository { return &gormRepo{db: db} }
+
+func (r *gormRepo) ListByRole(ctx context.Context, role usermodels.Role) ([]string, error) {
+	var perms []string
+	err := r.db.WithContext(ctx).Model(&authzmodels.RolePermission{}).
+		Where("role = ?", role).
+		Order("permission ASC").
+		Pluck("permission", &perms).Error
+	return perms, err
+}
+
+func (r *gormRepo) ListAll(ctx context.Context) (map[usermodels.Role][]string, error) {
+	var rows []authzmodels.RolePermission
+	if err := r.db.WithContext(ctx).Order("role ASC, permission ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	out := map[usermodels.Role][]string{}
+	for _, row := range rows {
+		out[row.Role] = append(out[row.Role], row.Permission)
+	}
+	return out, nil
+}
+
+// ReplaceRole ลบของเดิมทั้งหมดของ role แล้วใส่ชุดใหม่ใน transaction เดียว
+func (r *gormRepo) ReplaceRole(ctx context.Context, role usermodels.Role, perms []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role).Delete(&authzmodels.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(perms) == 0 {
+			return nil
+		}
+		rows := make([]authzmodels.RolePermission, 0, len(perms))
+		for _, p := range perms {
+			rows = append(rows, authzmodels.RolePermission{Role: role, Permission: p})
+		}
+		return tx.Create(&rows).Error
+	})
+}
+
+func (r *gormRepo) Count(ctx context.Context) (int64, error) {
+	var n int64
+	err := r.db.WithContext(ctx).Model(&authzmodels.RolePermission{}).Count(&n).Error
+	return n, err
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+var (
+	ErrInvalidRole       = errors.New("invalid role")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrSuperRole         = errors.New("permissions of this role cannot be changed")
+)
+
+type cachedPerms struct {
+	perms   []string
+	expires time.Time
+}
+
+type svc struct {
+	repo   authz.Repository
+	audits audit.Service
+
+	mu    sync.Mutex
+	ttl   time.Duration // instance อื่นเห็นการแก้ไขช้าสุดไม่เกินนี้
+	cache map[usermodels.Role]cachedPerms
+}
+
+func NewService(r authz.Repository, audits audit.Service) authz.Service {
+	ttl := 30 * time.Second
+	if d, err := time.ParseDuration(os.Getenv("AUTHZ_CACHE_TTL")); err == nil && d >= 0 {
+		ttl = d
+	}
+	return &svc{repo: r, audits: audits, ttl: ttl, cache: map[usermodels.Role]cachedPerms{}}
+}
+
+func (s *svc) Catalog() []authz.Permission { return authz.Catalog() }
+
+func (s *svc) RolePermissions(ctx context.Context) (map[usermodels.Role][]string, error) {
+	all, err := s.repo.ListAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := map[usermodels.Role][]string{}
+	for _, r := range usermodels.AllRoles() {
+		out[r] = all[r]
+		if out[r] == nil {
+			out[r] = []string{}
+		}
+	}
+	out[authz.SuperRole] = allPermissions()
+	return out, nil
+}
+
+func (s *svc) SetRolePermissions(ctx context.Context, actorID, role string, perms []string) ([]string, error) {
+	r, ok := usermodels.ParseRole(role)
+	if !ok {
+		return nil, ErrInvalidRole
+	}
+	if r == authz.SuperRole {
+		return nil, ErrSuperRole
+	}
+	set := map[string]struct{}{}
+	for _, p := range perms {
+		p = strings.TrimSpace(p)
+		if !authz.IsKnown(p) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, p)
+		}
+		set[p] = struct{}{}
+	}
+	clean := make([]string, 0, len(set))
+	for p := range set {
+		clean = append(clean, p)
+	}
+	sort.Strings(clean)
+
+	before, err := s.repo.ListByRole(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReplaceRole(ctx, r, clean); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	delete(s.cache, r)
+	s.mu.Unlock()
+
+	if err := s.audits.Record(ctx, &auditmodels.AuditLog{
+		Action:  auditmodels.ActionRolePermissionsUpdate,
+		ActorID: actorID,
+		Detail:  fmt.Sprintf("role %s: [%s] -> [%s]", r, strings.Join(before, ","), strings.Join(clean, ",")),
+	}); err != nil {
+		return nil, err
+	}
+	return clean, nil
+}
+
+func (s *svc) Permissions(ctx context.Context, role string) ([]string, error) {
+	r, ok := usermodels.ParseRole(role)
+	if !ok {
+		return nil, nil // role แปลก = ไม่มีสิทธิ์อะไรเลย
+	}
+	if r == authz.SuperRole {
+		return allPermissions(), nil
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if c, ok := s.cache[r]; ok && now.Before(c.expires) {
+		s.mu.Unlock()
+		return c.perms, nil
+	}
+	s.mu.Unlock()
+
+	perms, err := s.repo.ListByRole(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.cache[r] = cachedPerms{perms: perms, expires: now.Add(s.ttl)}
+	s.mu.Unlock()
+	return perms, nil
+}
+
+// EnsureDefaults ใส่ DefaultGrants ครั้งแรกครั้งเดียว; แถว "*" ของ SuperRole เป็นตัวบอกว่า seed แล้ว
+// (ถ้า admin ลบสิทธิ์ role อื่นจนหมด restart แล้วจะไม่ถูกใส่กลับมา)
+func (s *svc) EnsureDefaults(ctx context.Context) error {
+	n, err := s.repo.Count(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	for role, perms := range authz.DefaultGrants {
+		if err := s.repo.ReplaceRole(ctx, role, perms); err != nil {
+			return err
+		}
+	}
+	return s.repo.ReplaceRole(ctx, authz.SuperRole, []string{"*"})
+}
+
+func allPermissions() []string {
+	out := make([]string, 0, len(authz.Catalog()))
+	for _, p := range authz.Catalog() {
+		out = append(out, p.Name)
+	}
+	return out
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+type fakeRepo struct {
+	grants map[usermodels.Role][]string
+	reads  int
+}
+
+func (f *fakeRepo) ListByRole(_ context.Context, r usermodels.Role) ([]string, error) {
+	f.reads++
+	return append([]string(nil), f.grants[r]...), nil
+}
+
+func (f *fakeRepo) ListAll(context.Context) (map[usermodels.Role][]string, error) {
+	return f.grants, nil
+}
+
+func (f *fakeRepo) ReplaceRole(_ context.Context, r usermodels.Role, perms []string) error {
+	if f.grants == nil {
+		f.grants = map[usermodels.Role][]string{}
+	}
+	f.grants[r] = append([]string(nil), perms...)
+	return nil
+}
+
+func (f *fakeRepo) Count(context.Context) (int64, error) {
+	var n int64
+	for _, p := range f.grants {
+		n += int64(len(p))
+	}
+	return n, nil
+}
+
+type fakeAudits struct {
+	audit.Service
+	logs []auditmodels.AuditLog
+}
+
+func (f *fakeAudits) Record(_ context.Context, e *auditmodels.AuditLog) error {
+	f.logs = append(f.logs, *e)
+	return nil
+}
+
+func newTestSvc(repo *fakeRepo, audits *fakeAudits) *svc {
+	return &svc{repo: repo, audits: audits, ttl: time.Minute, cache: map[usermodels.Role]cachedPerms{}}
+}
+
+func TestEnsureDefaultsSeedsOnce(t *testing.T) {
+	repo := &fakeRepo{}
+	s := newTestSvc(repo, &fakeAudits{})
+	ctx := context.Background()
+
+	if err := s.EnsureDefaults(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(repo.grants[usermodels.RoleHR], authz.DefaultGrants[usermodels.RoleHR]) {
+		t.Fatalf("hr = %v", repo.grants[usermodels.RoleHR])
+	}
+	// admin เอาสิทธิ์ของ hr ออกหมดแล้ว restart: ต้องไม่ใส่กลับมา
+	repo.grants[usermodels.RoleHR] = nil
+	if err := s.EnsureDefaults(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.grants[usermodels.RoleHR]) != 0 {
+		t.Fatalf("defaults re-seeded: %v", repo.grants[usermodels.RoleHR])
+	}
+}
+
+func TestSetRolePermissions(t *testing.T) {
+	repo := &fakeRepo{grants: map[usermodels.Role][]string{usermodels.RoleHR: {authz.PermUserRead}}}
+	audits := &fakeAudits{}
+	s := newTestSvc(repo, audits)
+	ctx := context.Background()
+
+	if _, err := s.SetRolePermissions(ctx, "a1", "admin", nil); !errors.Is(err, ErrSuperRole) {
+		t.Fatalf("super role: err = %v", err)
+	}
+	if _, err := s.SetRolePermissions(ctx, "a1", "nope", nil); !errors.Is(err, ErrInvalidRole) {
+		t.Fatalf("bad role: err = %v", err)
+	}
+	if _, err := s.SetRolePermissions(ctx, "a1", "hr", []string{"user.fly"}); !errors.Is(err, ErrUnknownPermission) {
+		t.Fatalf("unknown permission: err = %v", err)
+	}
+
+	// อ่านครั้งแรกเข้า cache
+	if perms, _ := s.Permissions(ctx, "hr"); !reflect.DeepEqual(perms, []string{authz.PermUserRead}) {
+		t.Fatalf("before = %v", perms)
+	}
+	got, err := s.SetRolePermissions(ctx, "a1", "HR", []string{authz.PermCourseWrite, " " + authz.PermCourseRead, authz.PermCourseWrite})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{authz.PermCourseRead, authz.PermCourseWrite}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("saved = %v", got)
+	}
+	// cache ของ role ที่แก้ถูกล้างทันที
+	if perms, _ := s.Permissions(ctx, "hr"); !reflect.DeepEqual(perms, want) {
+		t.Fatalf("after = %v", perms)
+	}
+	if len(audits.logs) != 1 || audits.logs[0].Action != auditmodels.ActionRolePermissionsUpdate || audits.logs[0].ActorID != "a1" {
+		t.Fatalf("audit = %+v", audits.logs)
+	}
+}
+
+func TestPermissionsCacheAndSuperRole(t *testing.T) {
+	repo := &fakeRepo{grants: map[usermodels.Role][]string{usermodels.RoleEmployee: {}}}
+	s := newTestSvc(repo, &fakeAudits{})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.Permissions(ctx, "employee"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if repo.reads != 1 {
+		t.Fatalf("repo reads = %d, want 1 (cached)", repo.reads)
+	}
+	admin, _ := s.Permissions(ctx, "admin")
+	if len(admin) != len(authz.Catalog()) {
+		t.Fatalf("admin has %d of %d permissions", len(admin), len(authz.Catalog()))
+	}
+	if perms, err := s.Permissions(ctx, "ghost"); err != nil || len(perms) != 0 {
+		t.Fatalf("unknown role: %v, %v", perms, err)
+	}
+}
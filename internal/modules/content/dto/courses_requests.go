@@ -7,7 +7,11 @@ type CreateCourseReq struct {
 	IsActive         *bool    `json:"is_active"`
 	EstimatedMinutes *int     `json:"estimated_minutes"`
 	CategoryID       *string  `json:"category_id"`
+	Visibility       *string  `json:"visibility"` // public|department|invite|hidden (default: department ถ้าส่ง targets มา, ไม่งั้น public)
 	DepartmentIDs    []string `json:"department_ids"`
+
+	// Targets ใช้แทน department_ids เมื่อต้องกำหนดคอร์สบังคับ/กำหนดส่ง
+	Targets []CourseTargetReq `json:"targets"`
 }
 
 type UpdateCourseReq struct {
@@ -16,7 +20,22 @@ type UpdateCourseReq struct {
 	IsActive         *bool     `json:"is_active"`
 	EstimatedMinutes *int      `json:"estimated_minutes"`
 	CategoryID       *string   `json:"category_id"`
+	Visibility       *string   `json:"visibility"`
 	DepartmentIDs    *[]string `json:"department_ids"`
+
+	Targets *[]CourseTargetReq `json:"targets"`
+}
+
+type CourseTargetReq struct {
+	DepartmentID string `json:"department_id"`
+	IsMandatory  bool   `json:"is_mandatory"`
+	DueDays      *int   `json:"due_days"`
+
+	IncludeSubDepartments bool `json:"include_sub_departments"`
+}
+
+type InviteUsersReq struct {
+	UserIDs []string `json:"user_ids"`
 }
 
 type CreateModuleReq struct {
@@ -3,16 +3,39 @@ package dto
 import "github.com/Marugo/birdlax/internal/modules/content/models"
 
 type CourseResp struct {
-	ID               string   `json:"id"`
-	Code             string   `json:"code"`
-	Title            string   `json:"title"`
-	Description      *string  `json:"description,omitempty"`
-	IsActive         bool     `json:"is_active"`
-	EstimatedMinutes *int     `json:"estimated_minutes,omitempty"`
-	CategoryID       *string  `json:"category_id"`
-	DepartmentIDs    []string `json:"department_ids,omitempty"`
-	CreatedAt        string   `json:"CreatedAt"`
-	UpdatedAt        string   `json:"UpdatedAt"`
+	ID               string             `json:"id"`
+	Code             string             `json:"code"`
+	Title            string             `json:"title"`
+	Description      *string            `json:"description,omitempty"`
+	IsActive         bool               `json:"is_active"`
+	EstimatedMinutes *int               `json:"estimated_minutes,omitempty"`
+	CategoryID       *string            `json:"category_id"`
+	Visibility       string             `json:"visibility"`
+	DepartmentIDs    []string           `json:"department_ids,omitempty"`
+	Targets          []CourseTargetResp `json:"targets,omitempty"`
+	CreatedAt        string             `json:"CreatedAt"`
+	UpdatedAt        string             `json:"UpdatedAt"`
+}
+
+type CourseTargetResp struct {
+	DepartmentID string `json:"department_id"`
+	IsMandatory  bool   `json:"is_mandatory"`
+	DueDays      *int   `json:"due_days,omitempty"`
+
+	IncludeSubDepartments bool `json:"include_sub_departments"`
+}
+
+func FromCourseTargets(ts []models.CourseDepartmentTarget) []CourseTargetResp {
+	out := make([]CourseTargetResp, 0, len(ts))
+	for _, t := range ts {
+		out = append(out, CourseTargetResp{
+			DepartmentID:          t.DepartmentID,
+			IsMandatory:           t.IsMandatory,
+			DueDays:               t.DueDays,
+			IncludeSubDepartments: t.IncludeSubDepartments,
+		})
+	}
+	return out
 }
 
 type ModuleResp struct {
@@ -47,6 +70,7 @@ func FromCourseModel(c *models.Course, deptIDs []string) *CourseResp {
 		IsActive:         c.IsActive,
 		EstimatedMinutes: c.EstimatedMinutes,
 		CategoryID:       c.CategoryID,
+		Visibility:       c.Visibility,
 		DepartmentIDs:    deptIDs,
 		CreatedAt:        c.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:        c.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/Marugo/birdlax/internal/modules/content/dto"
+	"github.com/Marugo/birdlax/internal/modules/content/models"
 	"github.com/Marugo/birdlax/internal/modules/content/service"
 	"github.com/gofiber/fiber/v2"
 )
@@ -81,7 +82,16 @@ func (h *CategoryHandler) ListCoursesOfCategory(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	per, _ := strconv.Atoi(c.Query("per_page", "20"))
 
-	rows, total, err := h.svc.ListCoursesOfCategory(id, q, page, per)
+	var (
+		rows  []models.Course
+		total int64
+		err   error
+	)
+	if userID, ok := learnerID(c); ok {
+		rows, total, err = h.svc.ListVisibleCoursesOfCategory(id, userID, q, page, per)
+	} else {
+		rows, total, err = h.svc.ListCoursesOfCategory(id, q, page, per)
+	}
 	if err != nil {
 		if err.Error() == "category not found" {
 			return fiber.NewError(fiber.StatusNotFound, err.Error())
@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
@@ -13,8 +13,8 @@ func RegisterCategoryRoutes(r fiber.Router, h *CategoryHandler) {
 	r.Get("/categories/:id/courses", h.ListCoursesOfCategory)
 
 	// CREATE/UPDATE/DELETE
-	r.Post("/categories", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.CreateCategory)
-	r.Patch("/categories/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.UpdateCategory)
+	r.Post("/categories", middleware.RequirePermission(authz.PermCourseWrite), h.CreateCategory)
+	r.Patch("/categories/:id", middleware.RequirePermission(authz.PermCourseWrite), h.UpdateCategory)
 	// (ถ้าชอบ PUT ก็ทำเพิ่มได้)
-	r.Delete("/categories/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.DeleteCategory)
+	r.Delete("/categories/:id", middleware.RequirePermission(authz.PermCourseWrite), h.DeleteCategory)
 }
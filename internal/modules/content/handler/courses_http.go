@@ -1,18 +1,50 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/modules/content/dto"
 	"github.com/Marugo/birdlax/internal/modules/content/service"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 type CourseHandler struct {
-	svc service.CourseService
+	svc    service.CourseService
+	access service.AccessService
 }
 
-func NewCourseHandler(s service.CourseService) *CourseHandler { return &CourseHandler{svc: s} }
+func NewCourseHandler(s service.CourseService, a service.AccessService) *CourseHandler {
+	return &CourseHandler{svc: s, access: a}
+}
+
+// learnerID ผู้ดูแลคอร์ส (course.write) เห็นทุกอย่าง (ok=false); ผู้เรียนต้องผ่านกติกา visibility
+func learnerID(c *fiber.Ctx) (userID string, ok bool) {
+	if middleware.HasPermission(c, authz.PermCourseWrite) {
+		return "", false
+	}
+	userID, _ = c.Locals("user_id").(string)
+	return userID, true
+}
+
+func (h *CourseHandler) canView(c *fiber.Ctx, courseID string) error {
+	userID, ok := learnerID(c)
+	if !ok {
+		return nil
+	}
+	return h.access.CanView(userID, courseID)
+}
+
+// accessError ตอบ reason code ให้ frontend อธิบายได้ว่าทำไมเข้าคอร์สไม่ได้
+func accessError(c *fiber.Ctx, err error) error {
+	var ee *service.EligibilityError
+	if errors.As(err, &ee) {
+		return c.Status(ee.HTTPStatus()).JSON(fiber.Map{"error": ee.Error(), "reason": ee.Reason})
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
 
 /********* Courses *********/
 func (h *CourseHandler) CreateCourse(c *fiber.Ctx) error {
@@ -68,6 +100,9 @@ func (h *CourseHandler) DeleteCourse(c *fiber.Ctx) error {
 }
 func (h *CourseHandler) GetCourse(c *fiber.Ctx) error {
 	id := c.Params("id")
+	if err := h.canView(c, id); err != nil {
+		return accessError(c, err)
+	}
 
 	course, err := h.svc.GetCourse(id)
 	if err != nil {
@@ -81,7 +116,13 @@ func (h *CourseHandler) GetCourse(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
 	}
 
+	targets, err := h.svc.ListCourseTargets(id)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+
 	resp := dto.FromCourseModel(course, deptIDs)
+	resp.Targets = dto.FromCourseTargets(targets)
 	return c.JSON(resp)
 }
 func (h *CourseHandler) ListCourses(c *fiber.Ctx) error {
@@ -97,7 +138,7 @@ func (h *CourseHandler) ListCourses(c *fiber.Ctx) error {
 		out = append(out, dto.CourseResp{
 			ID: x.ID, Code: x.Code, Title: x.Title, Description: x.Description,
 			IsActive: x.IsActive, EstimatedMinutes: x.EstimatedMinutes,
-			CategoryID: x.CategoryID,
+			CategoryID: x.CategoryID, Visibility: x.Visibility,
 		})
 	}
 	return c.JSON(dto.PagedCourses{
@@ -137,6 +178,9 @@ func (h *CourseHandler) DeleteModule(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 func (h *CourseHandler) ListModules(c *fiber.Ctx) error {
+	if err := h.canView(c, c.Params("id")); err != nil {
+		return accessError(c, err)
+	}
 	rows, err := h.svc.ListModules(c.Params("id"))
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
@@ -151,9 +195,44 @@ func (h *CourseHandler) ListModules(c *fiber.Ctx) error {
 	return c.JSON(out)
 }
 func (h *CourseHandler) ListLessonsOfModule(c *fiber.Ctx) error {
-	rows, err := h.svc.ListLessons(c.Params("id"))
+	m, err := h.svc.GetModule(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusNotFound, "module not found")
+	}
+	if err := h.canView(c, m.CourseID); err != nil {
+		return accessError(c, err)
+	}
+	rows, err := h.svc.ListLessons(m.ID)
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	return c.JSON(rows) // ใช้ struct Lesson ตรงๆ (มี id, module_id, title, content_type, seq, ...)
 }
+
+/********* Invitations (visibility = invite) *********/
+func (h *CourseHandler) ListInvitations(c *fiber.Ctx) error {
+	rows, err := h.access.ListInvitations(c.Params("id"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"items": rows})
+}
+
+func (h *CourseHandler) InviteUsers(c *fiber.Ctx) error {
+	var req dto.InviteUsersReq
+	if err := c.BodyParser(&req); err != nil || len(req.UserIDs) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "user_ids required")
+	}
+	actorID, _ := c.Locals("user_id").(string)
+	if err := h.access.Invite(c.Params("id"), req.UserIDs, actorID); err != nil {
+		return accessError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CourseHandler) RevokeInvitation(c *fiber.Ctx) error {
+	if err := h.access.RevokeInvitation(c.Params("id"), c.Params("userID")); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
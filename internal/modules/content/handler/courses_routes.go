@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
@@ -10,17 +10,22 @@ func RegisterCourseRoutes(r fiber.Router, h *CourseHandler) {
 	g := r.Group("")
 
 	// Courses (Admin CRUD + Learner list/get)
-	g.Get("/courses", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.ListCourses)
+	g.Get("/courses", middleware.RequirePermission(authz.PermCourseRead), h.ListCourses)
 	g.Get("/courses/:id", h.GetCourse)
-	g.Post("/courses", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.CreateCourse)
-	g.Put("/courses/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.UpdateCourse)
-	g.Delete("/courses/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.DeleteCourse)
+	g.Post("/courses", middleware.RequirePermission(authz.PermCourseWrite), h.CreateCourse)
+	g.Put("/courses/:id", middleware.RequirePermission(authz.PermCourseWrite), h.UpdateCourse)
+	g.Delete("/courses/:id", middleware.RequirePermission(authz.PermCourseWrite), h.DeleteCourse)
+
+	// Invitations (คอร์ส invite-only)
+	g.Get("/courses/:id/invitations", middleware.RequirePermission(authz.PermCourseWrite), h.ListInvitations)
+	g.Post("/courses/:id/invitations", middleware.RequirePermission(authz.PermCourseWrite), h.InviteUsers)
+	g.Delete("/courses/:id/invitations/:userID", middleware.RequirePermission(authz.PermCourseWrite), h.RevokeInvitation)
 
 	// Modules
 	g.Get("/courses/:id/modules", h.ListModules) // by course
-	g.Post("/courses/:id/modules", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.CreateModule)
+	g.Post("/courses/:id/modules", middleware.RequirePermission(authz.PermCourseWrite), h.CreateModule)
 
 	g.Get("/modules/:id/lessons", h.ListLessonsOfModule)
-	g.Put("/modules/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.UpdateModule)
-	g.Delete("/modules/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.DeleteModule)
+	g.Put("/modules/:id", middleware.RequirePermission(authz.PermCourseWrite), h.UpdateModule)
+	g.Delete("/modules/:id", middleware.RequirePermission(authz.PermCourseWrite), h.DeleteModule)
 }
@@ -9,10 +9,11 @@ import (
 )
 
 type Handler struct {
-	svc service.Service
+	svc    service.Service
+	access service.AccessService
 }
 
-func New(s service.Service) *Handler { return &Handler{svc: s} }
+func New(s service.Service, a service.AccessService) *Handler { return &Handler{svc: s, access: a} }
 
 func (h *Handler) UploadVideo(c *fiber.Ctx) error {
 	fileHeader, err := c.FormFile("file")
@@ -45,6 +46,11 @@ func (h *Handler) GetLesson(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "lesson not found")
 	}
+	if userID, ok := learnerID(c); ok {
+		if err := h.access.CanViewModule(userID, l.ModuleID); err != nil {
+			return accessError(c, err)
+		}
+	}
 	return c.JSON(l)
 }
 
@@ -53,6 +59,15 @@ func (h *Handler) ListLessons(c *fiber.Ctx) error {
 	moduleID := c.Query("module_id", "")
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	per, _ := strconv.Atoi(c.Query("per_page", "20"))
+	// ผู้เรียนต้องระบุโมดูล (ไม่ให้ไล่ดูบทเรียนข้ามคอร์ส)
+	if userID, ok := learnerID(c); ok {
+		if moduleID == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "module_id required")
+		}
+		if err := h.access.CanViewModule(userID, moduleID); err != nil {
+			return accessError(c, err)
+		}
+	}
 	rows, total, err := h.svc.ListLessons(moduleID, page, per)
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
@@ -70,6 +85,21 @@ func (h *Handler) GetAsset(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusNotFound, "asset not found")
 	}
+	if userID, ok := learnerID(c); ok {
+		if err := h.access.CanViewAsset(userID, a.ID); err != nil {
+			return accessError(c, err)
+		}
+	}
+	// URL ถาวรเฉพาะ asset ของคอร์ส public; นอกนั้นได้ URL ชั่วคราว (แชร์ต่อไปก็หมดอายุ)
+	public, err := h.access.IsPublicAsset(a.ID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	if !public {
+		if err := h.svc.SignAssetURL(a); err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+		}
+	}
 	return c.JSON(a)
 }
 
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+	"github.com/Marugo/birdlax/internal/modules/content/service"
+)
+
+type fakeContent struct {
+	service.Service
+}
+
+func (fakeContent) GetLesson(id string) (*models.Lesson, error) {
+	return &models.Lesson{ID: id, ModuleID: "m-" + id}, nil
+}
+
+func (fakeContent) ListLessons(string, int, int) ([]models.Lesson, int64, error) {
+	return nil, 0, nil
+}
+
+func (fakeContent) GetAsset(id string) (*models.Asset, error) {
+	return &models.Asset{ID: id, Storage: "s3", Filename: "videos/" + id, URL: "https://cdn/videos/" + id}, nil
+}
+
+func (fakeContent) SignAssetURL(a *models.Asset) error {
+	a.URL = "https://bucket/" + a.Filename + "?X-Amz-Signature=x"
+	return nil
+}
+
+// fakeGate เห็นได้เฉพาะโมดูล/asset ที่ชื่อขึ้นต้นด้วย open
+type fakeGate struct {
+	service.AccessService
+}
+
+func (fakeGate) CanViewModule(_, moduleID string) error {
+	if moduleID == "m-open" {
+		return nil
+	}
+	return &service.EligibilityError{Reason: service.ReasonNotInTargetDept}
+}
+
+func (fakeGate) CanViewAsset(_, assetID string) error {
+	if strings.HasSuffix(assetID, "open") {
+		return nil
+	}
+	return &service.EligibilityError{Reason: service.ReasonNotInvited}
+}
+
+// public-open อยู่ในคอร์ส public; open อยู่ในคอร์สที่ผู้เรียนเห็นแต่ไม่ public
+func (fakeGate) IsPublicAsset(assetID string) (bool, error) { return assetID == "public-open", nil }
+
+func newContentApp(perms ...string) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		set := map[string]struct{}{}
+		for _, p := range perms {
+			set[p] = struct{}{}
+		}
+		c.Locals("user_id", "u1")
+		c.Locals("permissions", set)
+		return c.Next()
+	})
+	Register(app, New(fakeContent{}, fakeGate{}))
+	return app
+}
+
+func TestContentReadsFollowCourseVisibility(t *testing.T) {
+	cases := []struct {
+		path    string
+		learner int
+		editor  int
+	}{
+		{"/lessons/open", 200, 200},
+		{"/lessons/closed", 403, 200},
+		{"/lessons?module_id=m-open", 200, 200},
+		{"/lessons?module_id=m-closed", 403, 200},
+		{"/lessons", 400, 200},
+		{"/assets/open", 200, 200},
+		{"/assets/closed", 403, 200},
+	}
+	learner, editor := newContentApp(), newContentApp(authz.PermCourseWrite)
+	for _, tc := range cases {
+		for _, x := range []struct {
+			app  *fiber.App
+			want int
+		}{{learner, tc.learner}, {editor, tc.editor}} {
+			resp, err := x.app.Test(httptest.NewRequest("GET", tc.path, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != x.want {
+				t.Errorf("GET %s = %d, want %d", tc.path, resp.StatusCode, x.want)
+			}
+		}
+	}
+}
+
+func TestGetAssetSignsNonPublicURL(t *testing.T) {
+	for _, tc := range []struct {
+		id, want string
+	}{
+		{"public-open", "https://cdn/videos/public-open"},
+		{"open", "https://bucket/videos/open?X-Amz-Signature=x"},
+	} {
+		for _, app := range []*fiber.App{newContentApp(), newContentApp(authz.PermCourseWrite)} {
+			resp, err := app.Test(httptest.NewRequest("GET", "/assets/"+tc.id, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var a models.Asset
+			if err := json.NewDecoder(resp.Body).Decode(&a); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != 200 || a.URL != tc.want {
+				t.Errorf("GET /assets/%s = %d %s, want %s", tc.id, resp.StatusCode, a.URL, tc.want)
+			}
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package handler
 
 import (
-	"github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
@@ -9,12 +9,12 @@ import (
 func Register(r fiber.Router, h *Handler) {
 	g := r.Group("")
 	// POST
-	g.Post("/assets/video", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.UploadVideo)
-	g.Post("/lessons", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.CreateLesson)
+	g.Post("/assets/video", middleware.RequirePermission(authz.PermCourseWrite), h.UploadVideo)
+	g.Post("/lessons", middleware.RequirePermission(authz.PermCourseWrite), h.CreateLesson)
 
 	// PUT / PATCH / DELETE
-	g.Put("/lessons/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.UpdateLesson)
-	g.Delete("/lessons/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleHR), h.DeleteLesson)
+	g.Put("/lessons/:id", middleware.RequirePermission(authz.PermCourseWrite), h.UpdateLesson)
+	g.Delete("/lessons/:id", middleware.RequirePermission(authz.PermCourseWrite), h.DeleteLesson)
 
 	// GET
 	g.Get("/assets/:id", h.GetAsset)
@@ -2,6 +2,39 @@ package models
 
 import "time"
 
+// ใครเห็น/ลงทะเบียนคอร์สได้ (ผู้มีสิทธิ์ course.write เห็นทุกคอร์ส)
+const (
+	VisibilityPublic     = "public"     // พนักงานทุกคน
+	VisibilityDepartment = "department" // เฉพาะแผนกที่อยู่ใน course_department_targets
+	VisibilityInvite     = "invite"     // เฉพาะคนที่ถูกเชิญ (course_invitations)
+	VisibilityHidden     = "hidden"     // ไม่แสดงให้ผู้เรียน (เช่น ระหว่างเตรียมเนื้อหา)
+)
+
+func ValidVisibility(v string) bool {
+	switch v {
+	case VisibilityPublic, VisibilityDepartment, VisibilityInvite, VisibilityHidden:
+		return true
+	}
+	return false
+}
+
+// CourseVisibleSQL เงื่อนไข SQL แบบเดียวกับ AccessService.CanView สำหรับ query รายการคอร์ส (alias courses)
+// ต้องส่ง user id สามครั้ง: enrollment, แผนกที่ target, คำเชิญ
+func CourseVisibleSQL() string {
+	return `(EXISTS (
+		SELECT 1 FROM enrollments e
+		WHERE e.course_id = courses.id AND e.user_id = ? AND e.deleted_at IS NULL AND e.status <> 'dropped')
+	OR (courses.is_active = 1 AND (courses.visibility = '` + VisibilityPublic + `'
+		OR (courses.visibility = '` + VisibilityDepartment + `' AND EXISTS (
+			SELECT 1 FROM course_department_targets t
+			JOIN user_department_roles udr ON ` + TargetCoversSQL("udr.department_id") + `
+			WHERE t.course_id = courses.id AND udr.user_id = ?
+			AND t.deleted_at IS NULL AND udr.deleted_at IS NULL))
+		OR (courses.visibility = '` + VisibilityInvite + `' AND EXISTS (
+			SELECT 1 FROM course_invitations i
+			WHERE i.course_id = courses.id AND i.user_id = ?)))))`
+}
+
 type Course struct {
 	ID               string `gorm:"type:char(36);primaryKey"`
 	Code             string `gorm:"size:50;uniqueIndex;not null"`
@@ -10,6 +43,7 @@ type Course struct {
 	IsActive         bool `gorm:"not null;default:1"`
 	EstimatedMinutes *int
 	CategoryID       *string `gorm:"type:char(36)" json:"category_id"`
+	Visibility       string  `gorm:"type:enum('public','department','invite','hidden');not null;default:'public'" json:"visibility"` // แถวเดิมก่อนมี visibility = public (เห็นเหมือนเดิม)
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 	DeletedAt        *time.Time `gorm:"index"`
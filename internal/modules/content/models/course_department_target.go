@@ -13,9 +13,21 @@ type CourseDepartmentTarget struct {
 	CourseID     string         `gorm:"type:char(36);index;not null" json:"course_id"`
 	DepartmentID string         `gorm:"type:char(36);index;not null" json:"department_id"`
 	IsMandatory  bool           `gorm:"type:tinyint(1);default:0" json:"is_mandatory"`
+	DueDays      *int           `json:"due_days,omitempty"` // ต้องเรียนจบภายในกี่วันนับจากถูก assign (nil = ไม่กำหนด)
 	CreatedAt    time.Time      `json:"created_at"`
 	UpdatedAt    time.Time      `json:"updated_at"`
 	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// ครอบแผนกลูกทุกระดับด้วย (ตาม departments.path)
+	IncludeSubDepartments bool `gorm:"type:tinyint(1);not null;default:0" json:"include_sub_departments"`
+}
+
+// TargetCoversSQL เงื่อนไข SQL ว่า target (alias t) ใช้กับแผนก dept หรือไม่
+// dept เป็น column เช่น "udr.department_id" หรือ "?" (ต้องส่ง department id สองครั้ง)
+func TargetCoversSQL(dept string) string {
+	return `(t.department_id = ` + dept + ` OR (t.include_sub_departments = 1 AND EXISTS (
+		SELECT 1 FROM departments sd JOIN departments td ON td.id = t.department_id
+		WHERE sd.id = ` + dept + ` AND sd.path LIKE CONCAT(td.path, '%'))))`
 }
 
 func (m *CourseDepartmentTarget) BeforeCreate(tx *gorm.DB) error {
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CourseInvitation ผู้เรียนที่ได้รับเชิญเข้าคอร์ส visibility = invite
+type CourseInvitation struct {
+	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
+	CourseID  string    `gorm:"type:char(36);not null;uniqueIndex:uq_course_invitation" json:"course_id"`
+	UserID    string    `gorm:"type:char(36);not null;uniqueIndex:uq_course_invitation;index" json:"user_id"`
+	InvitedBy *string   `gorm:"type:char(36)" json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *CourseInvitation) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == "" {
+		m.ID = uuid.NewString()
+	}
+	return nil
+}
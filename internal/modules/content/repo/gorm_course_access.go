@@ -0,0 +1,104 @@
+package repo
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+)
+
+// CourseAccessRepo ข้อมูลที่ใช้ตัดสินว่า user เห็น/ลงทะเบียนคอร์สได้หรือไม่
+type CourseAccessRepo struct{ db *gorm.DB }
+
+func NewCourseAccessRepo(db *gorm.DB) *CourseAccessRepo { return &CourseAccessRepo{db: db} }
+
+// InTargetDepartment true ถ้า user อยู่ในแผนกใดแผนกหนึ่งที่คอร์สนี้ target (รวมแผนกลูกถ้า target ระบุ)
+func (r *CourseAccessRepo) InTargetDepartment(userID, courseID string) (bool, error) {
+	var n int64
+	err := r.db.Table("course_department_targets AS t").
+		Joins("JOIN user_department_roles udr ON "+models.TargetCoversSQL("udr.department_id")).
+		Where("t.course_id = ? AND udr.user_id = ?", courseID, userID).
+		Where("t.deleted_at IS NULL AND udr.deleted_at IS NULL").
+		Count(&n).Error
+	return n > 0, err
+}
+
+func (r *CourseAccessRepo) IsInvited(userID, courseID string) (bool, error) {
+	var n int64
+	err := r.db.Model(&models.CourseInvitation{}).
+		Where("course_id = ? AND user_id = ?", courseID, userID).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// IsEnrolled true ถ้ามี enrollment ที่ยังไม่ dropped (เรียนอยู่/เรียนจบแล้วยังกลับมาดูได้)
+func (r *CourseAccessRepo) IsEnrolled(userID, courseID string) (bool, error) {
+	var n int64
+	err := r.db.Table("enrollments").
+		Where("user_id = ? AND course_id = ? AND deleted_at IS NULL", userID, courseID).
+		Where("status <> ?", "dropped").
+		Count(&n).Error
+	return n > 0, err
+}
+
+// CourseOfModule คอร์สที่โมดูลนี้อยู่
+func (r *CourseAccessRepo) CourseOfModule(moduleID string) (string, error) {
+	var m models.CourseModule
+	err := r.db.Select("course_id").Where("id = ?", moduleID).First(&m).Error
+	return m.CourseID, err
+}
+
+// CourseOfLesson คอร์สที่บทเรียนนี้อยู่ (ผ่านโมดูล)
+func (r *CourseAccessRepo) CourseOfLesson(lessonID string) (string, error) {
+	var m models.CourseModule
+	err := r.db.Select("course_modules.course_id").
+		Joins("JOIN lessons l ON l.module_id = course_modules.id AND l.deleted_at IS NULL").
+		Where("l.id = ?", lessonID).First(&m).Error
+	return m.CourseID, err
+}
+
+// CoursesOfAsset คอร์สทั้งหมดที่มีบทเรียนใช้ asset นี้
+func (r *CourseAccessRepo) CoursesOfAsset(assetID string) ([]string, error) {
+	var ids []string
+	err := r.db.Table("lessons AS l").
+		Joins("JOIN course_modules m ON m.id = l.module_id AND m.deleted_at IS NULL").
+		Where("l.asset_id = ? AND l.deleted_at IS NULL", assetID).
+		Distinct().Pluck("m.course_id", &ids).Error
+	return ids, err
+}
+
+func (r *CourseAccessRepo) ListInvitations(courseID string) ([]models.CourseInvitation, error) {
+	var rows []models.CourseInvitation
+	err := r.db.Where("course_id = ?", courseID).
+		Order("created_at DESC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// Invite เชิญหลายคนพร้อมกัน; คนที่ถูกเชิญอยู่แล้วข้ามไป
+func (r *CourseAccessRepo) Invite(courseID string, userIDs []string, invitedBy *string) error {
+	rows := make([]models.CourseInvitation, 0, len(userIDs))
+	seen := map[string]struct{}{}
+	for _, u := range userIDs {
+		u = strings.TrimSpace(u)
+		if u == "" {
+			continue
+		}
+		if _, ok := seen[u]; ok {
+			continue
+		}
+		seen[u] = struct{}{}
+		rows = append(rows, models.CourseInvitation{CourseID: courseID, UserID: u, InvitedBy: invitedBy})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func (r *CourseAccessRepo) RevokeInvitation(courseID, userID string) error {
+	return r.db.Where("course_id = ? AND user_id = ?", courseID, userID).
+		Delete(&models.CourseInvitation{}).Error
+}
@@ -2,6 +2,7 @@ package repo
 
 import (
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -17,15 +18,25 @@ func NewCourseDeptRepo(db *gorm.DB) *gormCourseDeptRepo {
 }
 
 // ReplaceTargets: ลบ mapping เดิมของ course แล้วใส่ชุดใหม่
-func (r *gormCourseDeptRepo) ReplaceTargets(courseID string, deptIDs []string) error {
+func (r *gormCourseDeptRepo) ReplaceTargets(courseID string, targets []models.CourseDepartmentTarget) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
+		// เก็บวันที่ assign เดิมไว้ (กำหนดส่งของคอร์สบังคับนับจากวันนี้) ไม่ให้เลื่อนทุกครั้งที่แก้คอร์ส
+		var prev []models.CourseDepartmentTarget
+		if err := tx.Where("course_id = ?", courseID).Find(&prev).Error; err != nil {
+			return err
+		}
+		assigned := make(map[string]time.Time, len(prev))
+		for _, p := range prev {
+			assigned[p.DepartmentID] = p.CreatedAt
+		}
+
 		if err := tx.Where("course_id = ?", courseID).
 			Delete(&models.CourseDepartmentTarget{}).Error; err != nil {
 			return err
 		}
 		seen := map[string]struct{}{}
-		for _, d := range deptIDs {
-			d = strings.TrimSpace(d)
+		for _, t := range targets {
+			d := strings.TrimSpace(t.DepartmentID)
 			if d == "" {
 				continue
 			}
@@ -36,7 +47,11 @@ func (r *gormCourseDeptRepo) ReplaceTargets(courseID string, deptIDs []string) e
 			link := &models.CourseDepartmentTarget{
 				CourseID:     courseID,
 				DepartmentID: d,
-				IsMandatory:  false,
+				IsMandatory:  t.IsMandatory,
+				DueDays:      t.DueDays,
+				CreatedAt:    assigned[d], // zero = ให้ gorm ใส่เวลาปัจจุบัน
+
+				IncludeSubDepartments: t.IncludeSubDepartments,
 			}
 			if err := tx.Create(link).Error; err != nil {
 				return err
@@ -47,10 +62,8 @@ func (r *gormCourseDeptRepo) ReplaceTargets(courseID string, deptIDs []string) e
 }
 
 func (r *gormCourseDeptRepo) ListDepartmentIDs(courseID string) ([]string, error) {
-	var rows []models.CourseDepartmentTarget
-	if err := r.db.
-		Where("course_id = ?", courseID).
-		Find(&rows).Error; err != nil {
+	rows, err := r.ListTargets(courseID)
+	if err != nil {
 		return nil, err
 	}
 	out := make([]string, 0, len(rows))
@@ -59,3 +72,13 @@ func (r *gormCourseDeptRepo) ListDepartmentIDs(courseID string) ([]string, error
 	}
 	return out, nil
 }
+
+func (r *gormCourseDeptRepo) ListTargets(courseID string) ([]models.CourseDepartmentTarget, error) {
+	var rows []models.CourseDepartmentTarget
+	if err := r.db.
+		Where("course_id = ?", courseID).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
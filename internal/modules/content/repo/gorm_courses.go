@@ -44,7 +44,7 @@ func (r *CourseRepo) List(q string, page, per int) ([]models.Course, int64, erro
 
 	tx := r.db.Model(&models.Course{})
 	if s := strings.TrimSpace(q); s != "" {
-		tx = tx.Where("code LIKE ? OR title LIKE ?", "%"+s+"%", "%"+s+"%")
+		tx = tx.Where("(code LIKE ? OR title LIKE ?)", "%"+s+"%", "%"+s+"%")
 	}
 	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, err
@@ -79,15 +79,25 @@ func (r *ModuleRepo) ListByCourse(courseID string) ([]models.CourseModule, error
 }
 
 func (r *CourseRepo) ListByCategory(categoryID, q string, page, per int) ([]models.Course, int64, error) {
+	return r.listByCategory(r.db.Model(&models.Course{}), categoryID, q, page, per)
+}
+
+// ListVisibleByCategory เหมือน ListByCategory แต่เฉพาะคอร์สที่ user เปิดดูได้ตามกติกา visibility
+func (r *CourseRepo) ListVisibleByCategory(categoryID, userID, q string, page, per int) ([]models.Course, int64, error) {
+	tx := r.db.Model(&models.Course{}).Where(models.CourseVisibleSQL(), userID, userID, userID)
+	return r.listByCategory(tx, categoryID, q, page, per)
+}
+
+func (r *CourseRepo) listByCategory(tx *gorm.DB, categoryID, q string, page, per int) ([]models.Course, int64, error) {
 	if page < 1 {
 		page = 1
 	}
 	if per <= 0 || per > 100 {
 		per = 20
 	}
-	tx := r.db.Model(&models.Course{}).Where("category_id = ?", categoryID)
+	tx = tx.Where("category_id = ?", categoryID)
 	if s := strings.TrimSpace(q); s != "" {
-		tx = tx.Where("code LIKE ? OR title LIKE ?", "%"+s+"%", "%"+s+"%")
+		tx = tx.Where("(code LIKE ? OR title LIKE ?)", "%"+s+"%", "%"+s+"%")
 	}
 	var total int64
 	if err := tx.Count(&total).Error; err != nil {
@@ -65,6 +65,30 @@ func (r *AssetRepo) GetByID(id string) (*models.Asset, error) {
 	return &a, nil
 }
 
+// ListByStorage asset ที่ยังอยู่ใน backend นั้น เรียงตาม id (afterID = keyset ของหน้าก่อน)
+func (r *AssetRepo) ListByStorage(storage, afterID string, limit int) ([]models.Asset, error) {
+	var rows []models.Asset
+	err := r.db.Where("storage = ? AND id > ? AND deleted_at IS NULL", storage, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error
+	return rows, err
+}
+
+// MoveStorage เปลี่ยนที่เก็บของ asset (เฉพาะเมื่อยังอยู่ที่ from กันเขียนทับงานที่รันซ้อน)
+func (r *AssetRepo) MoveStorage(id, from, to, filename, url string) error {
+	res := r.db.Model(&models.Asset{}).
+		Where("id = ? AND storage = ?", id, from).
+		Updates(map[string]any{"storage": to, "filename": filename, "url": url})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func (r *LessonRepo) UpdateLesson(l *models.Lesson) error {
 	return r.db.Save(l).Error
 }
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+)
+
+// reason code ที่ตอบกลับ client เมื่อเข้าคอร์สไม่ได้
+const (
+	ReasonCourseNotFound  = "COURSE_NOT_FOUND"
+	ReasonCourseInactive  = "COURSE_INACTIVE"
+	ReasonCourseHidden    = "COURSE_HIDDEN"
+	ReasonNotInTargetDept = "NOT_IN_TARGET_DEPARTMENT"
+	ReasonNotInvited      = "NOT_INVITED"
+)
+
+// EligibilityError user เห็น/ลงทะเบียนคอร์สนี้ไม่ได้ (Reason = code ข้างบน)
+type EligibilityError struct {
+	Reason string
+}
+
+func (e *EligibilityError) Error() string {
+	switch e.Reason {
+	case ReasonCourseNotFound:
+		return "course not found"
+	case ReasonCourseInactive:
+		return "course is not active"
+	case ReasonCourseHidden:
+		return "course is not available"
+	case ReasonNotInTargetDept:
+		return "course is not offered to your department"
+	case ReasonNotInvited:
+		return "course is invite-only"
+	}
+	return "course not accessible"
+}
+
+// HTTPStatus: คอร์สไม่มีจริง = 404 นอกนั้น 403
+func (e *EligibilityError) HTTPStatus() int {
+	if e.Reason == ReasonCourseNotFound {
+		return 404
+	}
+	return 403
+}
+
+type CourseAccessRepo interface {
+	InTargetDepartment(userID, courseID string) (bool, error)
+	IsInvited(userID, courseID string) (bool, error)
+	IsEnrolled(userID, courseID string) (bool, error)
+	CourseOfModule(moduleID string) (string, error)
+	CourseOfLesson(lessonID string) (string, error)
+	CoursesOfAsset(assetID string) ([]string, error)
+
+	ListInvitations(courseID string) ([]models.CourseInvitation, error)
+	Invite(courseID string, userIDs []string, invitedBy *string) error
+	RevokeInvitation(courseID, userID string) error
+}
+
+// AccessService กติกา visibility ของคอร์ส (ใช้ทั้งฝั่ง catalog และ learning)
+type AccessService interface {
+	// CanView ผู้เรียนเปิดดูรายละเอียด/โมดูลได้ไหม (คนที่ลงทะเบียนไว้แล้วดูต่อได้เสมอ)
+	CanView(userID, courseID string) error
+	// CanEnroll ผู้เรียนลงทะเบียนได้ไหม
+	CanEnroll(userID, courseID string) error
+	// CanViewModule / CanViewLesson / CanViewAsset กติกาเดียวกับ CanView ของคอร์สที่เนื้อหานั้นอยู่
+	CanViewModule(userID, moduleID string) error
+	CanViewLesson(userID, lessonID string) error
+	CanViewAsset(userID, assetID string) error
+	// IsPublicAsset asset อยู่ในคอร์ส public ที่เปิดใช้อยู่ (ใช้ URL ถาวรได้); ไม่ใช่ = ต้องใช้ URL ชั่วคราว
+	IsPublicAsset(assetID string) (bool, error)
+
+	ListInvitations(courseID string) ([]models.CourseInvitation, error)
+	Invite(courseID string, userIDs []string, invitedBy string) error
+	RevokeInvitation(courseID, userID string) error
+}
+
+type accessSvc struct {
+	courses CourseRepo
+	repo    CourseAccessRepo
+}
+
+func NewAccessService(cr CourseRepo, ar CourseAccessRepo) AccessService {
+	return &accessSvc{courses: cr, repo: ar}
+}
+
+func (s *accessSvc) CanView(userID, courseID string) error {
+	c, err := s.course(courseID)
+	if err != nil {
+		return err
+	}
+	enrolled, err := s.repo.IsEnrolled(userID, courseID)
+	if err != nil {
+		return err
+	}
+	if enrolled {
+		return nil
+	}
+	return s.check(userID, c)
+}
+
+func (s *accessSvc) CanEnroll(userID, courseID string) error {
+	c, err := s.course(courseID)
+	if err != nil {
+		return err
+	}
+	return s.check(userID, c)
+}
+
+func (s *accessSvc) CanViewModule(userID, moduleID string) error {
+	courseID, err := s.repo.CourseOfModule(moduleID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &EligibilityError{Reason: ReasonCourseNotFound}
+	}
+	if err != nil {
+		return err
+	}
+	return s.CanView(userID, courseID)
+}
+
+func (s *accessSvc) CanViewLesson(userID, lessonID string) error {
+	courseID, err := s.repo.CourseOfLesson(lessonID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &EligibilityError{Reason: ReasonCourseNotFound}
+	}
+	if err != nil {
+		return err
+	}
+	return s.CanView(userID, courseID)
+}
+
+// CanViewAsset ผ่านถ้าเห็นคอร์สใดคอร์สหนึ่งที่ใช้ asset นี้; asset ที่ยังไม่ผูกบทเรียนเห็นได้เฉพาะผู้ดูแล
+func (s *accessSvc) CanViewAsset(userID, assetID string) error {
+	courseIDs, err := s.repo.CoursesOfAsset(assetID)
+	if err != nil {
+		return err
+	}
+	denied := error(&EligibilityError{Reason: ReasonCourseHidden})
+	for _, id := range courseIDs {
+		err := s.CanView(userID, id)
+		if err == nil {
+			return nil
+		}
+		var ee *EligibilityError
+		if !errors.As(err, &ee) {
+			return err
+		}
+		denied = err
+	}
+	return denied
+}
+
+func (s *accessSvc) IsPublicAsset(assetID string) (bool, error) {
+	courseIDs, err := s.repo.CoursesOfAsset(assetID)
+	if err != nil {
+		return false, err
+	}
+	for _, id := range courseIDs {
+		c, err := s.courses.GetByID(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if c.IsActive && c.Visibility == models.VisibilityPublic {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *accessSvc) check(userID string, c *models.Course) error {
+	if !c.IsActive {
+		return &EligibilityError{Reason: ReasonCourseInactive}
+	}
+	switch c.Visibility {
+	case models.VisibilityPublic:
+		return nil
+	case models.VisibilityDepartment:
+		ok, err := s.repo.InTargetDepartment(userID, c.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &EligibilityError{Reason: ReasonNotInTargetDept}
+		}
+		return nil
+	case models.VisibilityInvite:
+		ok, err := s.repo.IsInvited(userID, c.ID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return &EligibilityError{Reason: ReasonNotInvited}
+		}
+		return nil
+	}
+	// hidden (หรือค่าที่ไม่รู้จัก) = ปิดไว้ก่อน
+	return &EligibilityError{Reason: ReasonCourseHidden}
+}
+
+func (s *accessSvc) course(courseID string) (*models.Course, error) {
+	c, err := s.courses.GetByID(courseID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && c.DeletedAt != nil) {
+		return nil, &EligibilityError{Reason: ReasonCourseNotFound}
+	}
+	return c, err
+}
+
+func (s *accessSvc) ListInvitations(courseID string) ([]models.CourseInvitation, error) {
+	return s.repo.ListInvitations(courseID)
+}
+
+func (s *accessSvc) Invite(courseID string, userIDs []string, invitedBy string) error {
+	if _, err := s.course(courseID); err != nil {
+		return err
+	}
+	var by *string
+	if invitedBy != "" {
+		by = &invitedBy
+	}
+	return s.repo.Invite(courseID, userIDs, by)
+}
+
+func (s *accessSvc) RevokeInvitation(courseID, userID string) error {
+	return s.repo.RevokeInvitation(courseID, userID)
+}
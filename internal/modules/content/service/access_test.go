@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/content/dto"
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+)
+
+type fakeCourses struct {
+	CourseRepo
+	byID    map[string]*models.Course
+	created []*models.Course
+}
+
+func (f *fakeCourses) GetByID(id string) (*models.Course, error) {
+	c, ok := f.byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return c, nil
+}
+
+func (f *fakeCourses) Create(c *models.Course) error {
+	f.created = append(f.created, c)
+	return nil
+}
+
+type fakeAccess struct {
+	CourseAccessRepo
+	inDept   map[string]bool // course id
+	enrolled map[string]bool
+	modules  map[string]string   // module id -> course id
+	lessons  map[string]string   // lesson id -> course id
+	assets   map[string][]string // asset id -> course ids
+}
+
+func (f *fakeAccess) InTargetDepartment(_, courseID string) (bool, error) {
+	return f.inDept[courseID], nil
+}
+func (f *fakeAccess) IsInvited(string, string) (bool, error)      { return false, nil }
+func (f *fakeAccess) IsEnrolled(_, courseID string) (bool, error) { return f.enrolled[courseID], nil }
+
+func (f *fakeAccess) CourseOfModule(moduleID string) (string, error) {
+	id, ok := f.modules[moduleID]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return id, nil
+}
+
+func (f *fakeAccess) CourseOfLesson(lessonID string) (string, error) {
+	id, ok := f.lessons[lessonID]
+	if !ok {
+		return "", gorm.ErrRecordNotFound
+	}
+	return id, nil
+}
+
+func (f *fakeAccess) CoursesOfAsset(assetID string) ([]string, error) { return f.assets[assetID], nil }
+
+func newAccessFixture() AccessService {
+	courses := &fakeCourses{byID: map[string]*models.Course{
+		"pub":  {ID: "pub", IsActive: true, Visibility: models.VisibilityPublic},
+		"dept": {ID: "dept", IsActive: true, Visibility: models.VisibilityDepartment},
+		"hid":  {ID: "hid", IsActive: true, Visibility: models.VisibilityHidden},
+	}}
+	ar := &fakeAccess{
+		inDept:   map[string]bool{},
+		enrolled: map[string]bool{},
+		modules:  map[string]string{"m-pub": "pub", "m-dept": "dept", "m-hid": "hid"},
+		lessons:  map[string]string{"l-pub": "pub", "l-dept": "dept"},
+		assets: map[string][]string{
+			"a-pub":    {"pub"},
+			"a-dept":   {"dept"},
+			"a-shared": {"dept", "pub"},
+		},
+	}
+	return NewAccessService(courses, ar)
+}
+
+func reason(err error) string {
+	var ee *EligibilityError
+	if errors.As(err, &ee) {
+		return ee.Reason
+	}
+	return ""
+}
+
+func TestCanViewModule(t *testing.T) {
+	s := newAccessFixture()
+	if err := s.CanViewModule("u1", "m-pub"); err != nil {
+		t.Fatalf("public: %v", err)
+	}
+	if err := s.CanViewModule("u1", "m-dept"); reason(err) != ReasonNotInTargetDept {
+		t.Fatalf("department: err = %v", err)
+	}
+	if err := s.CanViewModule("u1", "m-hid"); reason(err) != ReasonCourseHidden {
+		t.Fatalf("hidden: err = %v", err)
+	}
+	if err := s.CanViewModule("u1", "nope"); reason(err) != ReasonCourseNotFound {
+		t.Fatalf("missing module: err = %v", err)
+	}
+}
+
+func TestCanViewLesson(t *testing.T) {
+	s := newAccessFixture()
+	if err := s.CanViewLesson("u1", "l-pub"); err != nil {
+		t.Fatalf("public: %v", err)
+	}
+	if err := s.CanViewLesson("u1", "l-dept"); reason(err) != ReasonNotInTargetDept {
+		t.Fatalf("department: err = %v", err)
+	}
+	if err := s.CanViewLesson("u1", "nope"); reason(err) != ReasonCourseNotFound {
+		t.Fatalf("missing lesson: err = %v", err)
+	}
+}
+
+func TestCanViewAsset(t *testing.T) {
+	s := newAccessFixture()
+	if err := s.CanViewAsset("u1", "a-pub"); err != nil {
+		t.Fatalf("public: %v", err)
+	}
+	if err := s.CanViewAsset("u1", "a-dept"); reason(err) != ReasonNotInTargetDept {
+		t.Fatalf("department: err = %v", err)
+	}
+	// ใช้ร่วมหลายคอร์ส: เห็นคอร์สใดคอร์สหนึ่งก็พอ
+	if err := s.CanViewAsset("u1", "a-shared"); err != nil {
+		t.Fatalf("shared: %v", err)
+	}
+	// ยังไม่ผูกบทเรียน = เฉพาะผู้ดูแล
+	if err := s.CanViewAsset("u1", "a-orphan"); reason(err) != ReasonCourseHidden {
+		t.Fatalf("orphan: err = %v", err)
+	}
+}
+
+func TestIsPublicAsset(t *testing.T) {
+	s := newAccessFixture()
+	for id, want := range map[string]bool{"a-pub": true, "a-shared": true, "a-dept": false, "a-orphan": false} {
+		if got, err := s.IsPublicAsset(id); err != nil || got != want {
+			t.Errorf("%s: %v, %v; want %v", id, got, err, want)
+		}
+	}
+}
+
+func TestCreateCourseDefaultVisibility(t *testing.T) {
+	courses := &fakeCourses{}
+	s := NewCourseService(courses, nil, nil, nil, nil)
+
+	c, err := s.CreateCourse(dto.CreateCourseReq{Code: "C1", Title: "Open"})
+	if err != nil || c.Visibility != models.VisibilityPublic {
+		t.Fatalf("no targets: %+v, %v", c, err)
+	}
+	c, err = s.CreateCourse(dto.CreateCourseReq{Code: "C2", Title: "Dept", DepartmentIDs: []string{"d1"}})
+	if err != nil || c.Visibility != models.VisibilityDepartment {
+		t.Fatalf("with targets: %+v, %v", c, err)
+	}
+	hidden := models.VisibilityHidden
+	c, err = s.CreateCourse(dto.CreateCourseReq{Code: "C3", Title: "Prep", DepartmentIDs: []string{"d1"}, Visibility: &hidden})
+	if err != nil || c.Visibility != models.VisibilityHidden {
+		t.Fatalf("explicit: %+v, %v", c, err)
+	}
+}
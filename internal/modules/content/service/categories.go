@@ -27,6 +27,8 @@ type CategoryService interface {
 
 	// สำหรับ /categories/:id/courses
 	ListCoursesOfCategory(id, q string, page, per int) ([]models.Course, int64, error)
+	// ListVisibleCoursesOfCategory เฉพาะคอร์สที่ผู้เรียนเปิดดูได้ (กติกาเดียวกับ AccessService.CanView)
+	ListVisibleCoursesOfCategory(id, userID, q string, page, per int) ([]models.Course, int64, error)
 }
 
 type categorySvc struct {
@@ -81,13 +83,27 @@ func (s *categorySvc) ListCategories(q string, page, per int) ([]models.Category
 }
 
 func (s *categorySvc) ListCoursesOfCategory(id, q string, page, per int) ([]models.Course, int64, error) {
-	// optional: validate ว่าหมวดมีจริงก่อน
+	if err := s.mustExist(id); err != nil {
+		return nil, 0, err
+	}
+	return s.courseRepo.ListByCategory(id, q, page, per)
+}
+
+func (s *categorySvc) ListVisibleCoursesOfCategory(id, userID, q string, page, per int) ([]models.Course, int64, error) {
+	if err := s.mustExist(id); err != nil {
+		return nil, 0, err
+	}
+	return s.courseRepo.ListVisibleByCategory(id, userID, q, page, per)
+}
+
+// mustExist validate ว่าหมวดมีจริงก่อน
+func (s *categorySvc) mustExist(id string) error {
 	ok, err := s.catRepo.Exists(id)
 	if err != nil {
-		return nil, 0, err
+		return err
 	}
 	if !ok {
-		return nil, 0, errors.New("category not found")
+		return errors.New("category not found")
 	}
-	return s.courseRepo.ListByCategory(id, q, page, per)
+	return nil
 }
@@ -15,11 +15,13 @@ type CourseRepo interface {
 	GetByID(id string) (*models.Course, error)
 	List(q string, page, per int) ([]models.Course, int64, error)
 	ListByCategory(categoryID, q string, page, per int) ([]models.Course, int64, error)
+	ListVisibleByCategory(categoryID, userID, q string, page, per int) ([]models.Course, int64, error)
 }
 
 type CourseDeptRepo interface {
-	ReplaceTargets(courseID string, deptIDs []string) error
+	ReplaceTargets(courseID string, targets []models.CourseDepartmentTarget) error
 	ListDepartmentIDs(courseID string) ([]string, error)
+	ListTargets(courseID string) ([]models.CourseDepartmentTarget, error)
 }
 
 type ModuleRepo interface {
@@ -42,10 +44,12 @@ type CourseService interface {
 	ListCourses(q string, page, per int) ([]models.Course, int64, error)
 
 	ListCourseDepartments(courseID string) ([]string, error)
+	ListCourseTargets(courseID string) ([]models.CourseDepartmentTarget, error)
 
 	CreateModule(courseID string, req dto.CreateModuleReq) (*models.CourseModule, error)
 	UpdateModule(id string, req dto.UpdateModuleReq) (*models.CourseModule, error)
 	DeleteModule(id string) error
+	GetModule(id string) (*models.CourseModule, error)
 	ListModules(courseID string) ([]models.CourseModule, error)
 	ListLessons(moduleID string) ([]models.Lesson, error)
 }
@@ -91,10 +95,21 @@ func (s *courseSvc) CreateCourse(req dto.CreateCourseReq) (*models.Course, error
 		IsActive:         true,
 		EstimatedMinutes: req.EstimatedMinutes,
 		CategoryID:       req.CategoryID,
+		Visibility:       models.VisibilityPublic,
+	}
+	// ระบุแผนกมาแต่ไม่ระบุ visibility = ตั้งใจเปิดเฉพาะแผนกนั้น
+	if len(req.Targets) > 0 || len(req.DepartmentIDs) > 0 {
+		c.Visibility = models.VisibilityDepartment
 	}
 	if req.IsActive != nil {
 		c.IsActive = *req.IsActive
 	}
+	if req.Visibility != nil {
+		if !models.ValidVisibility(*req.Visibility) {
+			return nil, errors.New("invalid visibility")
+		}
+		c.Visibility = *req.Visibility
+	}
 
 	// ⛳ 1) เซฟตัว course ก่อน
 	if err := s.courseRepo.Create(c); err != nil {
@@ -102,8 +117,12 @@ func (s *courseSvc) CreateCourse(req dto.CreateCourseReq) (*models.Course, error
 	}
 
 	// ⛳ 2) ผูก department targets ถ้ามีส่งมา และมี repo
-	if s.deptRepo != nil && len(req.DepartmentIDs) > 0 {
-		if err := s.deptRepo.ReplaceTargets(c.ID, req.DepartmentIDs); err != nil {
+	if s.deptRepo != nil && (len(req.Targets) > 0 || len(req.DepartmentIDs) > 0) {
+		targets, err := courseTargets(req.Targets, req.DepartmentIDs)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.deptRepo.ReplaceTargets(c.ID, targets); err != nil {
 			return nil, err
 		}
 	}
@@ -128,6 +147,12 @@ func (s *courseSvc) UpdateCourse(id string, req dto.UpdateCourseReq) (*models.Co
 	if req.EstimatedMinutes != nil {
 		c.EstimatedMinutes = req.EstimatedMinutes
 	}
+	if req.Visibility != nil {
+		if !models.ValidVisibility(*req.Visibility) {
+			return nil, errors.New("invalid visibility")
+		}
+		c.Visibility = *req.Visibility
+	}
 	if req.CategoryID != nil {
 		if *req.CategoryID == "" {
 			c.CategoryID = nil // เคลียร์หมวด
@@ -155,8 +180,21 @@ func (s *courseSvc) UpdateCourse(id string, req dto.UpdateCourseReq) (*models.Co
 	// ใช้ pointer (*[]string) เพื่อแยกกรณี:
 	//   - nil  = ไม่แตะเรื่อง department
 	//   - []{} = เคลียร์ทุก department
-	if s.deptRepo != nil && req.DepartmentIDs != nil {
-		if err := s.deptRepo.ReplaceTargets(c.ID, *req.DepartmentIDs); err != nil {
+	// targets (ถ้าส่งมา) มาก่อน department_ids
+	if s.deptRepo != nil && (req.Targets != nil || req.DepartmentIDs != nil) {
+		var (
+			targets []models.CourseDepartmentTarget
+			err     error
+		)
+		if req.Targets != nil {
+			targets, err = courseTargets(*req.Targets, nil)
+		} else {
+			targets, err = courseTargets(nil, *req.DepartmentIDs)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := s.deptRepo.ReplaceTargets(c.ID, targets); err != nil {
 			return nil, err
 		}
 	}
@@ -177,6 +215,41 @@ func (s *courseSvc) ListCourseDepartments(courseID string) ([]string, error) {
 	return s.deptRepo.ListDepartmentIDs(courseID)
 }
 
+func (s *courseSvc) ListCourseTargets(courseID string) ([]models.CourseDepartmentTarget, error) {
+	if s.deptRepo == nil {
+		return []models.CourseDepartmentTarget{}, nil
+	}
+	return s.deptRepo.ListTargets(courseID)
+}
+
+// courseTargets แปลง request เป็น target; ถ้ามี targets จะไม่ใช้ deptIDs
+func courseTargets(reqs []dto.CourseTargetReq, deptIDs []string) ([]models.CourseDepartmentTarget, error) {
+	if len(reqs) == 0 {
+		out := make([]models.CourseDepartmentTarget, 0, len(deptIDs))
+		for _, d := range deptIDs {
+			out = append(out, models.CourseDepartmentTarget{DepartmentID: d})
+		}
+		return out, nil
+	}
+	out := make([]models.CourseDepartmentTarget, 0, len(reqs))
+	for _, t := range reqs {
+		if t.DueDays != nil && *t.DueDays < 1 {
+			return nil, errors.New("due_days must be at least 1")
+		}
+		if t.DueDays != nil && !t.IsMandatory {
+			return nil, errors.New("due_days requires is_mandatory")
+		}
+		out = append(out, models.CourseDepartmentTarget{
+			DepartmentID: t.DepartmentID,
+			IsMandatory:  t.IsMandatory,
+			DueDays:      t.DueDays,
+
+			IncludeSubDepartments: t.IncludeSubDepartments,
+		})
+	}
+	return out, nil
+}
+
 /******** Modules ********/
 func (s *courseSvc) CreateModule(courseID string, req dto.CreateModuleReq) (*models.CourseModule, error) {
 	if req.Title == "" || req.Seq < 1 {
@@ -215,6 +288,9 @@ func (s *courseSvc) UpdateModule(id string, req dto.UpdateModuleReq) (*models.Co
 	return m, s.moduleRepo.Update(m)
 }
 func (s *courseSvc) DeleteModule(id string) error { return s.moduleRepo.Delete(id) }
+func (s *courseSvc) GetModule(id string) (*models.CourseModule, error) {
+	return s.moduleRepo.GetByID(id)
+}
 func (s *courseSvc) ListModules(courseID string) ([]models.CourseModule, error) {
 	return s.moduleRepo.ListByCourse(courseID)
 }
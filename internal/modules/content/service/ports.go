@@ -22,6 +22,12 @@ type LessonRepo interface {
 
 type StorageUploader interface {
 	SaveVideo(file *multipart.FileHeader) (url, storedName string, size int64, mime string, err error)
+	Backend() string // "local" / "s3"
+}
+
+// URLSigner ออก presigned URL ของ object บน S3 (storage.S3)
+type URLSigner interface {
+	SignedURL(key string) (string, error)
 }
 
 type Service interface {
@@ -30,6 +36,8 @@ type Service interface {
 	ListLessons(moduleID string, page, per int) ([]models.Lesson, int64, error)
 	GetLesson(id string) (*models.Lesson, error)
 	GetAsset(id string) (*models.Asset, error)
+	// SignAssetURL แทน a.URL ด้วย URL ชั่วคราว (asset บน S3 เท่านั้น; local คงเดิม)
+	SignAssetURL(a *models.Asset) error
 
 	UpdateLesson(id string, req dto.UpdateLessonReq) (*models.Lesson, error)
 	DeleteLesson(id string) error
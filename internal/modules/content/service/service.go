@@ -6,6 +6,7 @@ import (
 
 	"github.com/Marugo/birdlax/internal/modules/content/dto"
 	"github.com/Marugo/birdlax/internal/modules/content/models"
+	"github.com/Marugo/birdlax/internal/modules/content/storage"
 	"github.com/google/uuid"
 )
 
@@ -13,10 +14,11 @@ type svc struct {
 	assetRepo  AssetRepo
 	lessonRepo LessonRepo
 	uploader   StorageUploader
+	signer     URLSigner // nil = ไม่ได้ตั้งค่า S3
 }
 
-func New(assetRepo AssetRepo, lessonRepo LessonRepo, uploader StorageUploader) Service {
-	return &svc{assetRepo: assetRepo, lessonRepo: lessonRepo, uploader: uploader}
+func New(assetRepo AssetRepo, lessonRepo LessonRepo, uploader StorageUploader, signer URLSigner) Service {
+	return &svc{assetRepo: assetRepo, lessonRepo: lessonRepo, uploader: uploader, signer: signer}
 }
 
 func (s *svc) UploadVideo(file *multipart.FileHeader) (*dto.UploadVideoResp, error) {
@@ -34,15 +36,20 @@ func (s *svc) UploadVideo(file *multipart.FileHeader) (*dto.UploadVideoResp, err
 		Filename:  stored,
 		MimeType:  mime,
 		SizeBytes: size,
-		Storage:   "local",
+		Storage:   s.uploader.Backend(),
 		URL:       url,
 	}
 	if err := s.assetRepo.CreateAsset(a); err != nil {
 		return nil, err
 	}
+	// asset ใหม่ยังไม่อยู่ในคอร์สไหน จึงยังไม่ public
+	view := *a
+	if err := s.SignAssetURL(&view); err != nil {
+		return nil, err
+	}
 	return &dto.UploadVideoResp{
 		AssetID:   a.ID,
-		URL:       a.URL,
+		URL:       view.URL,
 		Filename:  a.Filename,
 		MimeType:  a.MimeType,
 		SizeBytes: a.SizeBytes,
@@ -78,6 +85,21 @@ func (s *svc) GetAsset(id string) (*models.Asset, error) {
 	return s.assetRepo.GetByID(id)
 }
 
+func (s *svc) SignAssetURL(a *models.Asset) error {
+	if a.Storage != storage.BackendS3 {
+		return nil
+	}
+	if s.signer == nil {
+		return errors.New("s3 storage is not configured")
+	}
+	u, err := s.signer.SignedURL(a.Filename) // Filename ของ s3 = object key
+	if err != nil {
+		return err
+	}
+	a.URL = u
+	return nil
+}
+
 func (s *svc) UpdateLesson(id string, req dto.UpdateLessonReq) (*models.Lesson, error) {
 	l, err := s.lessonRepo.GetByID(id)
 	if err != nil {
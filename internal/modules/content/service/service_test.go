@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/Marugo/birdlax/internal/modules/content/models"
+)
+
+type fakeSigner struct{ keys []string }
+
+func (f *fakeSigner) SignedURL(key string) (string, error) {
+	f.keys = append(f.keys, key)
+	return "https://bucket/" + key + "?X-Amz-Signature=x", nil
+}
+
+func TestSignAssetURL(t *testing.T) {
+	sg := &fakeSigner{}
+	s := New(nil, nil, nil, sg)
+
+	local := &models.Asset{Storage: "local", Filename: "a.mp4", URL: "/static/videos/a.mp4"}
+	if err := s.SignAssetURL(local); err != nil || local.URL != "/static/videos/a.mp4" {
+		t.Fatalf("local: %s, %v", local.URL, err)
+	}
+	remote := &models.Asset{Storage: "s3", Filename: "videos/b.mp4", URL: "https://cdn/videos/b.mp4"}
+	if err := s.SignAssetURL(remote); err != nil || remote.URL != "https://bucket/videos/b.mp4?X-Amz-Signature=x" {
+		t.Fatalf("s3: %s, %v", remote.URL, err)
+	}
+
+	// asset บน S3 แต่ไม่ได้ตั้งค่า S3 => error ดีกว่าส่ง URL ถาวรออกไป
+	remote.URL = "https://cdn/videos/b.mp4"
+	if err := New(nil, nil, nil, nil).SignAssetURL(remote); err == nil || remote.URL != "https://cdn/videos/b.mp4" {
+		t.Fatalf("no signer: %s, %v", remote.URL, err)
+	}
+}
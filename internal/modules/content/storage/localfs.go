@@ -43,3 +43,5 @@ func (l *LocalFS) SaveVideo(file *multipart.FileHeader) (string, string, int64,
 	url := fmt.Sprintf("%s/%s?ts=%d", l.BaseURL, fn, time.Now().Unix())
 	return url, fn, n, file.Header.Get("Content-Type"), nil
 }
+
+func (l *LocalFS) Backend() string { return BackendLocal }
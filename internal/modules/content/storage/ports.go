@@ -1,7 +1,58 @@
 package storage
 
-import "mime/multipart"
+import (
+	"fmt"
+	"mime/multipart"
+)
+
+// ค่าใน models.Asset.Storage
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
 
 type Uploader interface {
 	SaveVideo(file *multipart.FileHeader) (url, storedName string, size int64, mime string, err error)
+	// Backend ชื่อที่บันทึกใน Asset.Storage ("local" / "s3")
+	Backend() string
+}
+
+// Signer ออก URL ชั่วคราวให้ไฟล์ที่ไม่ควรเปิดได้ด้วย URL ถาวร (asset ของคอร์สที่ไม่ public)
+type Signer interface {
+	SignedURL(key string) (string, error)
+}
+
+// Config เลือก backend ด้วย Driver; ไฟล์ local ยังเสิร์ฟจาก LocalDir เสมอ (asset เก่าที่ยังไม่ได้ย้าย)
+type Config struct {
+	Driver       string // "local" (default) หรือ "s3"
+	LocalDir     string
+	LocalBaseURL string
+	S3           S3Config
+}
+
+// New สร้าง Uploader ตาม cfg.Driver
+func New(cfg Config) (Uploader, error) {
+	switch cfg.Driver {
+	case "", BackendLocal:
+		return &LocalFS{BaseDir: cfg.LocalDir, BaseURL: cfg.LocalBaseURL}, nil
+	case BackendS3:
+		return NewS3(cfg.S3)
+	}
+	return nil, fmt.Errorf("storage: unknown driver %q", cfg.Driver)
+}
+
+// NewSigner สำหรับ asset ที่ storage = "s3" ไม่ว่า Driver จะเป็นอะไร (ระหว่างย้ายไฟล์ driver ยังเป็น local ได้)
+// ไม่ได้ตั้งค่า S3 = nil
+func NewSigner(cfg Config, up Uploader) (Signer, error) {
+	if s, ok := up.(*S3); ok {
+		return s, nil
+	}
+	if cfg.S3.Endpoint == "" || cfg.S3.Bucket == "" {
+		return nil, nil
+	}
+	s, err := NewS3(cfg.S3)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
 }
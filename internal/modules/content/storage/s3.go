@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Config ใช้ได้ทั้ง AWS S3 และ MinIO (หรือ S3-compatible อื่น)
+type S3Config struct {
+	Endpoint  string // "s3.ap-southeast-1.amazonaws.com" หรือ "minio:9000" (ไม่ใส่ scheme)
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+	PathStyle bool   // MinIO ต้องเปิด; AWS ใช้ virtual-host ได้
+	Prefix    string // โฟลเดอร์ใน bucket เช่น "videos"
+	// PublicBaseURL URL ที่ผู้เรียนใช้เปิดไฟล์ (CDN/bucket public) ว่าง = endpoint ตรงๆ
+	PublicBaseURL string
+	// PartSize ขนาดต่อ part ของ multipart upload (byte); ไฟล์ที่ใหญ่กว่านี้อัปโหลดเป็นหลาย part ขนานกัน
+	PartSize uint64
+	// SignedURLTTL อายุ presigned URL ของ asset คอร์สที่ไม่ public; 0 = 15 นาที
+	SignedURLTTL time.Duration
+}
+
+// minio-go บังคับ part ขั้นต่ำ 5 MiB
+const minPartSize = 5 << 20
+
+const defaultSignedURLTTL = 15 * time.Minute
+
+// S3 เก็บไฟล์ใน bucket; object key = Prefix/<uuid><ext> และเก็บ key นี้ไว้ใน Asset.Filename
+type S3 struct {
+	client        *minio.Client
+	bucket        string
+	prefix        string
+	publicBaseURL string
+	partSize      uint64
+	signedTTL     time.Duration
+}
+
+func NewS3(cfg S3Config) (*S3, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, errors.New("storage: S3 endpoint and bucket are required")
+	}
+	lookup := minio.BucketLookupAuto
+	if cfg.PathStyle {
+		lookup = minio.BucketLookupPath
+	}
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure:       cfg.UseSSL,
+		Region:       cfg.Region,
+		BucketLookup: lookup,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("storage: s3 client: %w", err)
+	}
+
+	base := strings.TrimRight(cfg.PublicBaseURL, "/")
+	if base == "" {
+		scheme := "http"
+		if cfg.UseSSL {
+			scheme = "https"
+		}
+		base = scheme + "://" + cfg.Endpoint + "/" + cfg.Bucket
+	}
+	partSize := cfg.PartSize
+	if partSize < minPartSize {
+		partSize = minPartSize
+	}
+	ttl := cfg.SignedURLTTL
+	if ttl <= 0 {
+		ttl = defaultSignedURLTTL
+	}
+	return &S3{
+		client:        client,
+		bucket:        cfg.Bucket,
+		prefix:        strings.Trim(cfg.Prefix, "/"),
+		publicBaseURL: base,
+		partSize:      partSize,
+		signedTTL:     ttl,
+	}, nil
+}
+
+func (s *S3) Backend() string { return BackendS3 }
+
+// Check ตรวจว่าเข้าถึง bucket ได้ (ใช้ตอนเริ่ม command ย้ายไฟล์ จะได้ไม่พังกลางทาง)
+func (s *S3) Check(ctx context.Context) error {
+	ok, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("storage: s3 bucket %q: %w", s.bucket, err)
+	}
+	if !ok {
+		return fmt.Errorf("storage: s3 bucket %q does not exist", s.bucket)
+	}
+	return nil
+}
+
+func (s *S3) SaveVideo(file *multipart.FileHeader) (string, string, int64, string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", "", 0, "", err
+	}
+	defer src.Close()
+
+	key := s.Key(uuid.NewString() + filepath.Ext(file.Filename))
+	mime := file.Header.Get("Content-Type")
+	n, err := s.Put(context.Background(), key, src, file.Size, mime)
+	if err != nil {
+		return "", "", 0, "", err
+	}
+	return s.URL(key), key, n, mime, nil
+}
+
+// Key ต่อ prefix หน้าชื่อไฟล์
+func (s *S3) Key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+// Put อัปโหลด r ไปที่ key; size < 0 = ไม่รู้ขนาด (stream ทีละ part)
+// ไฟล์ใหญ่กว่า partSize ใช้ multipart upload อัตโนมัติ และ abort ให้เองถ้าพังกลางทาง
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, mime string) (int64, error) {
+	if mime == "" {
+		mime = "application/octet-stream"
+	}
+	info, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: mime,
+		PartSize:    s.partSize,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("storage: s3 put %q: %w", key, err)
+	}
+	return info.Size, nil
+}
+
+// URL public URL ของ object (escape ทีละ segment ของ key)
+func (s *S3) URL(key string) string {
+	parts := strings.Split(key, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return s.publicBaseURL + "/" + path.Join(parts...)
+}
+
+// SignedURL presigned GET URL ของ object อายุ SignedURLTTL (ไม่ผ่าน PublicBaseURL เพราะลายเซ็นผูกกับ host ของ bucket)
+func (s *S3) SignedURL(key string) (string, error) {
+	u, err := s.client.PresignedGetObject(context.Background(), s.bucket, key, s.signedTTL, nil)
+	if err != nil {
+		return "", fmt.Errorf("storage: s3 presign %q: %w", key, err)
+	}
+	return u.String(), nil
+}
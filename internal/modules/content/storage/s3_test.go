@@ -0,0 +1,278 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/minio/minio-go/v7"
+)
+
+// fakeS3 S3 ในหน่วยความจำ (gofakes3) + นับ request แบบ multipart
+type fakeS3 struct {
+	srv *httptest.Server
+
+	mu    sync.Mutex
+	parts int // UploadPart
+	done  int // CompleteMultipartUpload
+}
+
+func newFakeS3(t *testing.T, buckets ...string) *fakeS3 {
+	t.Helper()
+	backend := s3mem.New()
+	for _, b := range buckets {
+		if err := backend.CreateBucket(b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f := &fakeS3{}
+	h := gofakes3.New(backend).Server()
+	f.srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f.mu.Lock()
+		switch {
+		case r.Method == http.MethodPut && q.Has("partNumber"):
+			f.parts++
+		case r.Method == http.MethodPost && q.Has("uploadId"):
+			f.done++
+		}
+		f.mu.Unlock()
+		if r.Header.Get("X-Amz-Content-Sha256") == streamingPayload && q.Has("partNumber") {
+			// gofakes3 ถอด aws-chunked ให้แค่ PutObject ปกติ ส่วน UploadPart ต้องถอดเอง
+			body, err := decodeChunked(r.Body)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.ContentLength = int64(len(body))
+			r.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+			r.Header.Del("Content-Encoding")
+		}
+		h.ServeHTTP(w, r)
+	}))
+	t.Cleanup(f.srv.Close)
+	return f
+}
+
+const streamingPayload = "STREAMING-AWS4-HMAC-SHA256-PAYLOAD"
+
+// decodeChunked ถอด body แบบ "<hex size>;chunk-signature=...\r\n<data>\r\n" จนเจอ chunk ขนาด 0
+func decodeChunked(r io.Reader) ([]byte, error) {
+	br := bufio.NewReader(r)
+	var out bytes.Buffer
+	for {
+		line, err := br.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		n, err := strconv.ParseInt(size, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if n == 0 {
+			return out.Bytes(), nil
+		}
+		if _, err := io.CopyN(&out, br, n); err != nil {
+			return nil, err
+		}
+		if _, err := br.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (f *fakeS3) endpoint() string { return strings.TrimPrefix(f.srv.URL, "http://") }
+
+func (f *fakeS3) config() S3Config {
+	return S3Config{
+		Endpoint:  f.endpoint(),
+		Region:    "us-east-1",
+		Bucket:    "assets",
+		AccessKey: "key",
+		SecretKey: "secret",
+		PathStyle: true,
+		Prefix:    "/videos/",
+	}
+}
+
+func newTestS3(t *testing.T, cfg S3Config) *S3 {
+	t.Helper()
+	s, err := NewS3(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func readObject(t *testing.T, s *S3, key string) []byte {
+	t.Helper()
+	obj, err := s.client.GetObject(context.Background(), s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer obj.Close()
+	b, err := io.ReadAll(obj)
+	if err != nil {
+		t.Fatalf("read %s: %v", key, err)
+	}
+	return b
+}
+
+func TestS3Check(t *testing.T) {
+	f := newFakeS3(t, "assets")
+	if err := newTestS3(t, f.config()).Check(context.Background()); err != nil {
+		t.Fatalf("existing bucket: %v", err)
+	}
+	cfg := f.config()
+	cfg.Bucket = "nope"
+	if err := newTestS3(t, cfg).Check(context.Background()); err == nil {
+		t.Fatal("missing bucket: want error")
+	}
+}
+
+func TestS3PutSmallObject(t *testing.T) {
+	f := newFakeS3(t, "assets")
+	s := newTestS3(t, f.config())
+
+	data := []byte("tiny video")
+	n, err := s.Put(context.Background(), s.Key("a.mp4"), bytes.NewReader(data), int64(len(data)), "video/mp4")
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("put: n=%d err=%v", n, err)
+	}
+	if got := readObject(t, s, "videos/a.mp4"); !bytes.Equal(got, data) {
+		t.Fatalf("object = %q", got)
+	}
+	if f.parts != 0 {
+		t.Fatalf("small object used multipart: parts=%d", f.parts)
+	}
+}
+
+func TestS3PutMultipart(t *testing.T) {
+	f := newFakeS3(t, "assets")
+	s := newTestS3(t, f.config()) // PartSize 0 => ขั้นต่ำ 5 MiB
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), (minPartSize*2+minPartSize/2)/16)
+	n, err := s.Put(context.Background(), s.Key("big.mp4"), bytes.NewReader(data), int64(len(data)), "")
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("put: n=%d err=%v", n, err)
+	}
+	if f.parts != 3 || f.done != 1 {
+		t.Fatalf("multipart: parts=%d complete=%d", f.parts, f.done)
+	}
+	if got := readObject(t, s, "videos/big.mp4"); !bytes.Equal(got, data) {
+		t.Fatalf("object differs: %d bytes", len(got))
+	}
+}
+
+func TestS3SaveVideo(t *testing.T) {
+	f := newFakeS3(t, "assets")
+	s := newTestS3(t, f.config())
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	hdr := textproto.MIMEHeader{}
+	hdr.Set("Content-Disposition", `form-data; name="file"; filename="intro.mp4"`)
+	hdr.Set("Content-Type", "video/mp4")
+	part, _ := mw.CreatePart(hdr)
+	part.Write([]byte("frames"))
+	mw.Close()
+	form, err := multipart.NewReader(&body, mw.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, key, n, mime, err := s.SaveVideo(form.File["file"][0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, "videos/") || !strings.HasSuffix(key, ".mp4") || n != 6 || mime != "video/mp4" {
+		t.Fatalf("key=%s n=%d mime=%s", key, n, mime)
+	}
+	if u != s.URL(key) {
+		t.Fatalf("url = %s", u)
+	}
+	if got := readObject(t, s, key); string(got) != "frames" {
+		t.Fatalf("object = %q", got)
+	}
+}
+
+func TestS3URL(t *testing.T) {
+	f := newFakeS3(t)
+	s := newTestS3(t, f.config())
+	if got, want := s.URL("videos/a b#1.mp4"), "http://"+f.endpoint()+"/assets/videos/a%20b%231.mp4"; got != want {
+		t.Fatalf("endpoint url = %s, want %s", got, want)
+	}
+
+	cfg := f.config()
+	cfg.PublicBaseURL = "https://cdn.example.com/media/"
+	s = newTestS3(t, cfg)
+	if got, want := s.URL("videos/x.mp4"), "https://cdn.example.com/media/videos/x.mp4"; got != want {
+		t.Fatalf("cdn url = %s, want %s", got, want)
+	}
+}
+
+func TestS3SignedURL(t *testing.T) {
+	f := newFakeS3(t, "assets")
+	cfg := f.config()
+	cfg.PublicBaseURL = "https://cdn.example.com"
+	cfg.SignedURLTTL = 5 * time.Minute
+	s := newTestS3(t, cfg)
+
+	data := []byte("members only")
+	if _, err := s.Put(context.Background(), "videos/p.mp4", bytes.NewReader(data), int64(len(data)), "video/mp4"); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := s.SignedURL("videos/p.mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	if u.Host != f.endpoint() || q.Get("X-Amz-Expires") != "300" || q.Get("X-Amz-Signature") == "" {
+		t.Fatalf("signed url = %s", raw)
+	}
+
+	resp, err := http.Get(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || !bytes.Equal(got, data) {
+		t.Fatalf("GET signed url = %d %q", resp.StatusCode, got)
+	}
+}
+
+func TestNewSigner(t *testing.T) {
+	f := newFakeS3(t)
+	s := newTestS3(t, f.config())
+
+	if sg, err := NewSigner(Config{S3: f.config()}, s); err != nil || sg != Signer(s) {
+		t.Fatalf("s3 driver: %v, %v", sg, err)
+	}
+	// driver local แต่ตั้ง S3 ไว้ (ระหว่างย้ายไฟล์) ยังเซ็นได้
+	if sg, err := NewSigner(Config{S3: f.config()}, &LocalFS{}); err != nil || sg == nil {
+		t.Fatalf("local driver with s3: %v, %v", sg, err)
+	}
+	if sg, err := NewSigner(Config{}, &LocalFS{}); err != nil || sg != nil {
+		t.Fatalf("no s3: %v, %v", sg, err)
+	}
+}
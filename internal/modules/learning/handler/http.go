@@ -1,6 +1,9 @@
 package handler
 
 import (
+	"errors"
+
+	contentservice "github.com/Marugo/birdlax/internal/modules/content/service"
 	"github.com/Marugo/birdlax/internal/modules/learning/dto"
 	"github.com/Marugo/birdlax/internal/modules/learning/service"
 	"github.com/gofiber/fiber/v2"
@@ -25,6 +28,11 @@ func (h *Handler) EnrollCourse(c *fiber.Ctx) error {
 	courseID := c.Params("courseID")
 	e, err := h.svc.EnrollCourse(uid, courseID)
 	if err != nil {
+		// ไม่มีสิทธิ์ลงทะเบียน: ตอบ reason code (COURSE_INACTIVE, NOT_IN_TARGET_DEPARTMENT, ...)
+		var ee *contentservice.EligibilityError
+		if errors.As(err, &ee) {
+			return c.Status(ee.HTTPStatus()).JSON(fiber.Map{"error": ee.Error(), "reason": ee.Reason})
+		}
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
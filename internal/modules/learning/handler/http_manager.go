@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+
+	learningrepo "github.com/Marugo/birdlax/internal/modules/learning/repo"
+	learningservice "github.com/Marugo/birdlax/internal/modules/learning/service"
+)
+
+type ManagerHandler struct {
+	svc learningservice.ManagerService
+}
+
+func NewManagerHandler(s learningservice.ManagerService) *ManagerHandler {
+	return &ManagerHandler{svc: s}
+}
+
+// GET /api/v1/manager/departments
+func (h *ManagerHandler) Departments(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	items, err := h.svc.ListDepartments(c.Context(), userID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{"items": items})
+}
+
+// GET /api/v1/manager/departments/:deptID/members
+func (h *ManagerHandler) Members(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	page, per := pageQuery(c)
+	items, total, err := h.svc.ListMembers(c.Context(), userID, c.Params("deptID"), page, per)
+	if err != nil {
+		return managerError(err)
+	}
+	return c.JSON(fiber.Map{"items": items, "total": total, "page": page, "per_page": per})
+}
+
+// GET /api/v1/manager/departments/:deptID/enrollments?user_id=&course_id=&status=
+// คนที่ย้ายแผนกแล้ว: คอร์สที่จบไปแล้วยังนับให้แผนกเดิม, ที่ยังเรียนอยู่นับให้แผนกปัจจุบัน
+func (h *ManagerHandler) Enrollments(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	page, per := pageQuery(c)
+	items, total, err := h.svc.ListEnrollments(c.Context(), userID, c.Params("deptID"), teamFilter(c), page, per)
+	if err != nil {
+		return managerError(err)
+	}
+	return c.JSON(fiber.Map{"items": items, "total": total, "page": page, "per_page": per})
+}
+
+// GET /api/v1/manager/departments/:deptID/overdue
+func (h *ManagerHandler) Overdue(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	page, per := pageQuery(c)
+	items, total, err := h.svc.ListOverdue(c.Context(), userID, c.Params("deptID"), page, per)
+	if err != nil {
+		return managerError(err)
+	}
+	return c.JSON(fiber.Map{"items": items, "total": total, "page": page, "per_page": per})
+}
+
+// GET /api/v1/manager/departments/:deptID/attempts?user_id=&course_id=&status=
+func (h *ManagerHandler) Attempts(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	page, per := pageQuery(c)
+	items, total, err := h.svc.ListAttempts(c.Context(), userID, c.Params("deptID"), teamFilter(c), page, per)
+	if err != nil {
+		return managerError(err)
+	}
+	return c.JSON(fiber.Map{"items": items, "total": total, "page": page, "per_page": per})
+}
+
+// GET /api/v1/manager/departments/:deptID/members/:userID/courses/:courseID/progress
+func (h *ManagerHandler) MemberCourseProgress(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	res, err := h.svc.GetMemberCourseProgress(c.Context(), userID,
+		c.Params("deptID"), c.Params("userID"), c.Params("courseID"))
+	if err != nil {
+		return managerError(err)
+	}
+	return c.JSON(res)
+}
+
+func managerError(err error) error {
+	switch {
+	case errors.Is(err, learningservice.ErrNotDepartmentManager):
+		return fiber.NewError(fiber.StatusForbidden, err.Error())
+	case errors.Is(err, learningservice.ErrNotDepartmentMember):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	}
+	return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+}
+
+// pageQuery คืนค่าที่ normalize แล้ว เพื่อให้ page/per_page ใน response ตรงกับที่ query จริง
+func pageQuery(c *fiber.Ctx) (int, int) {
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	per, _ := strconv.Atoi(c.Query("per_page", "20"))
+	return learningservice.NormalizePage(page, per)
+}
+
+func teamFilter(c *fiber.Ctx) learningrepo.TeamFilter {
+	return learningrepo.TeamFilter{
+		UserID:   c.Query("user_id"),
+		CourseID: c.Query("course_id"),
+		Status:   c.Query("status"),
+	}
+}
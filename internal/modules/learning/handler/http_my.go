@@ -52,6 +52,32 @@ func (h *MyHandler) MyDepartmentCourses(c *fiber.Ctx) error {
 	})
 }
 
+// GET /api/v1/my/catalog?q=&category_id= — คอร์สที่ลงทะเบียนได้
+func (h *MyHandler) MyCatalog(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	per, _ := strconv.Atoi(c.Query("per_page", "20"))
+
+	var catPtr *string
+	if catID := c.Query("category_id", ""); catID != "" {
+		catPtr = &catID
+	}
+
+	items, total, err := h.mySvc.ListCatalog(c.Context(), userID, c.Query("q"), catPtr, page, per)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(fiber.Map{
+		"items":    items,
+		"total":    total,
+		"page":     page,
+		"per_page": per,
+	})
+}
+
 // GET /api/v1/my/courses
 func (h *MyHandler) MyCourses(c *fiber.Ctx) error {
 	userID, _ := c.Locals("user_id").(string)
@@ -1,23 +1,27 @@
 package handler
 
 import (
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 func Register(r fiber.Router, h *Handler) {
 	g := r.Group("")
+	// แถว enrollment/progress ผูกกับ user_id: ผู้เรียนต้องเป็นคน ไม่ใช่ API key
+	human := middleware.RequireUser()
 
-	g.Post("/courses/:courseID/enroll", h.EnrollCourse)
-	g.Get("/enrollments/:courseID", h.GetEnrollment)
+	g.Post("/courses/:courseID/enroll", human, h.EnrollCourse)
+	g.Get("/enrollments/:courseID", human, h.GetEnrollment)
 
-	g.Post("/lessons/:lessonID/start", h.StartLesson)
-	g.Post("/lessons/:lessonID/track", h.TrackLesson)
-	g.Post("/courses/:courseID/lessons/:lessonID/complete", h.CompleteLesson)
+	g.Post("/lessons/:lessonID/start", human, h.StartLesson)
+	g.Post("/lessons/:lessonID/track", human, h.TrackLesson)
+	g.Post("/courses/:courseID/lessons/:lessonID/complete", human, h.CompleteLesson)
 }
 
 // เรียกเพิ่มใน app.Register หลังเรียก Register(...)
 func RegisterAdminRoutes(r fiber.Router, analyticsHandler *AnalyticsHandler) {
-	admin := r.Group("/analytics")
+	admin := r.Group("/analytics", middleware.RequirePermission(authz.PermAnalyticsRead))
 	admin.Get("/users/:userID/metrics", analyticsHandler.GetUserMetric)
 	admin.Get("/courses/:courseID/outcome", analyticsHandler.GetCourseOutcome)
 }
@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ManagerRegister route ของหัวหน้าแผนก (สิทธิ์ตรวจจาก user_department_roles ใน service ไม่ใช่ permission)
+// user_id ต้องเป็นคนใน users: API key ของ service account ใช้ไม่ได้
+func ManagerRegister(r fiber.Router, mh *ManagerHandler) {
+	human := middleware.RequireUser()
+	g := r.Group("/manager")
+	g.Get("/departments", human, mh.Departments)
+
+	d := g.Group("/departments/:deptID")
+	d.Get("/members", human, mh.Members)
+	d.Get("/enrollments", human, mh.Enrollments)
+	d.Get("/overdue", human, mh.Overdue)
+	d.Get("/attempts", human, mh.Attempts)
+	d.Get("/members/:userID/courses/:courseID/progress", human, mh.MemberCourseProgress)
+}
@@ -1,12 +1,17 @@
 package handler
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/gofiber/fiber/v2"
+)
 
 func MyRegister(r fiber.Router, _ *Handler, my *MyHandler) {
-	myGroup := r.Group("/my") // ใช้ protected group มาจากข้างนอกแล้ว ไม่ต้อง Auth อีก
-	myGroup.Get("/department-courses", my.MyDepartmentCourses)
-	myGroup.Get("/courses", my.MyCourses)
+	human := middleware.RequireUser() // ข้อมูลของ "ฉัน" ไม่มีความหมายกับ service account
+	myGroup := r.Group("/my")         // ใช้ protected group มาจากข้างนอกแล้ว ไม่ต้อง Auth อีก
+	myGroup.Get("/department-courses", human, my.MyDepartmentCourses)
+	myGroup.Get("/catalog", human, my.MyCatalog)
+	myGroup.Get("/courses", human, my.MyCourses)
 
-	myGroup.Get("/courses/:courseID/progress", my.MyCourseProgress)
+	myGroup.Get("/courses/:courseID/progress", human, my.MyCourseProgress)
 
 }
@@ -0,0 +1,317 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	contentmodels "github.com/Marugo/birdlax/internal/modules/content/models"
+)
+
+// ManagerRepo query ข้อมูลการเรียนของทีม (ผูกกับ user_department_roles)
+// ทุก query รับ departmentID และกรองเฉพาะสมาชิกของแผนกนั้น — การตรวจว่าเป็น manager อยู่ที่ service
+//
+// กติกาเดียวทั้งไฟล์: ผลการเรียนเป็นของแผนกที่ user สังกัด "ตอนเรียนจบ" (ยังไม่จบ = ตอนนี้)
+// ดู memberOfDeptAt; รายชื่อทีม/งานค้างเป็นเรื่องของตอนนี้จึงใช้ NOW()
+type ManagerRepo struct {
+	db *gorm.DB
+}
+
+func NewManagerRepo(db *gorm.DB) *ManagerRepo {
+	return &ManagerRepo{db: db}
+}
+
+// memberOfDeptAt สมาชิกของแผนก ณ เวลา at (SQL expression) — ใช้กับรายงานย้อนหลัง
+// ผลการเรียนนับให้แผนกที่สังกัดตอนเรียนจบ ไม่ใช่แผนกปัจจุบัน (ย้ายแผนกแล้วประวัติไม่ตามไปด้วย)
+// ไม่กรอง deleted_at เพราะสังกัดที่จบแล้วถูก soft delete; ช่วงเวลาดูจาก effective_from/effective_to
+func memberOfDeptAt(at string) string {
+	return `EXISTS (SELECT 1 FROM user_department_roles udr
+	WHERE udr.user_id = u.id AND udr.department_id = ?
+	AND udr.effective_from <= ` + at + ` AND (udr.effective_to IS NULL OR udr.effective_to > ` + at + `))`
+}
+
+type ManagedDepartment struct {
+	ID          string `json:"id"`
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	MemberCount int64  `json:"member_count"`
+}
+
+// ListManagedDepartments แผนกที่ user เป็น manager อยู่
+func (r *ManagerRepo) ListManagedDepartments(ctx context.Context, userID string) ([]ManagedDepartment, error) {
+	var rows []ManagedDepartment
+	err := r.db.WithContext(ctx).
+		Table("departments AS d").
+		Select(`d.id, d.code, d.name,
+			(SELECT COUNT(DISTINCT m.user_id) FROM user_department_roles m
+				WHERE m.department_id = d.id AND m.effective_from <= NOW()
+				AND (m.effective_to IS NULL OR m.effective_to > NOW())) AS member_count`).
+		Joins("JOIN user_department_roles mgr ON mgr.department_id = d.id").
+		Where("mgr.user_id = ? AND mgr.role = ?", userID, "manager").
+		Where("mgr.deleted_at IS NULL AND d.deleted_at IS NULL").
+		Group("d.id, d.code, d.name").
+		Order("d.name ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// IsManager true ถ้า user มี role manager ในแผนกนี้
+func (r *ManagerRepo) IsManager(ctx context.Context, userID, departmentID string) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Table("user_department_roles AS udr").
+		Joins("JOIN departments d ON d.id = udr.department_id").
+		Where("udr.user_id = ? AND udr.department_id = ? AND udr.role = ?", userID, departmentID, "manager").
+		Where("udr.deleted_at IS NULL AND d.deleted_at IS NULL").
+		Count(&n).Error
+	return n > 0, err
+}
+
+// WasMember true ถ้า user เคยหรือยังสังกัดแผนกนี้ (รวมสังกัดที่จบแล้ว)
+// รายการที่กรองด้วย user นี้ยังกรองตาม memberOfDeptAt อีกชั้น จึงเห็นเฉพาะผลที่นับให้แผนกนี้
+func (r *ManagerRepo) WasMember(ctx context.Context, departmentID, userID string) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Table("user_department_roles").
+		Where("user_id = ? AND department_id = ?", userID, departmentID).
+		Count(&n).Error
+	return n > 0, err
+}
+
+// CourseAttributed true ถ้าคอร์สนี้ของ user นับให้แผนกนี้ (กติกาเดียวกับ ListEnrollments;
+// ยังไม่ลงทะเบียน = ดูสังกัดตอนนี้)
+func (r *ManagerRepo) CourseAttributed(ctx context.Context, departmentID, userID, courseID string) (bool, error) {
+	var n int64
+	err := r.db.WithContext(ctx).
+		Table("users AS u").
+		Joins("LEFT JOIN enrollments e ON e.user_id = u.id AND e.course_id = ? AND e.deleted_at IS NULL", courseID).
+		Where("u.id = ?", userID).
+		Where(memberOfDeptAt("COALESCE(e.completed_at, NOW())"), departmentID).
+		Count(&n).Error
+	return n > 0, err
+}
+
+type TeamMember struct {
+	UserID       string `json:"user_id"`
+	EmployeeCode string `json:"employee_code"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Email        string `json:"email"`
+	IsActive     bool   `json:"is_active"`
+
+	// สรุปการเรียน
+	Enrolled       int64 `json:"enrolled"`
+	Completed      int64 `json:"completed"`
+	MandatoryTotal int64 `json:"mandatory_total"`
+}
+
+// ListMembers สมาชิกปัจจุบัน; ยอดสรุปนับเฉพาะคอร์สที่นับให้แผนกนี้ (ตรงกับ ListEnrollments)
+func (r *ManagerRepo) ListMembers(ctx context.Context, departmentID string, limit, offset int) ([]TeamMember, int64, error) {
+	attributed := memberOfDeptAt("COALESCE(e.completed_at, NOW())")
+	tx := r.db.WithContext(ctx).
+		Table("users AS u").
+		Where(memberOfDeptAt("NOW()"), departmentID).
+		Where("u.deleted_at IS NULL")
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []TeamMember
+	err := tx.
+		Select(`u.id AS user_id, u.employee_code, u.first_name, u.last_name, u.email, u.is_active,
+			(SELECT COUNT(*) FROM enrollments e
+				WHERE e.user_id = u.id AND e.deleted_at IS NULL
+				AND `+attributed+`) AS enrolled,
+			(SELECT COUNT(*) FROM enrollments e
+				WHERE e.user_id = u.id AND e.deleted_at IS NULL
+				AND e.status IN ('completed','passed')
+				AND `+attributed+`) AS completed,
+			(SELECT COUNT(DISTINCT t.course_id) FROM course_department_targets t
+				JOIN courses c ON c.id = t.course_id
+				WHERE `+contentmodels.TargetCoversSQL("?")+` AND t.is_mandatory = 1
+				AND t.deleted_at IS NULL AND c.deleted_at IS NULL AND c.is_active = 1) AS mandatory_total`,
+			departmentID, departmentID, departmentID, departmentID).
+		Order("u.first_name ASC, u.last_name ASC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+type TeamEnrollment struct {
+	EnrollmentID    string     `json:"enrollment_id"`
+	UserID          string     `json:"user_id"`
+	EmployeeCode    string     `json:"employee_code"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	CourseID        string     `json:"course_id"`
+	CourseCode      string     `json:"course_code"`
+	CourseTitle     string     `json:"course_title"`
+	Status          string     `json:"status"`
+	ProgressPercent float64    `json:"progress_percent"`
+	StartedAt       *time.Time `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	LastAccessedAt  *time.Time `json:"last_accessed_at"`
+}
+
+// TeamFilter เงื่อนไขเพิ่มเติม (ว่าง = ไม่กรอง)
+type TeamFilter struct {
+	UserID   string
+	CourseID string
+	Status   string
+}
+
+func (r *ManagerRepo) ListEnrollments(ctx context.Context, departmentID string, f TeamFilter, limit, offset int) ([]TeamEnrollment, int64, error) {
+	tx := r.db.WithContext(ctx).
+		Table("enrollments AS e").
+		Joins("JOIN users u ON u.id = e.user_id").
+		Joins("JOIN courses c ON c.id = e.course_id").
+		Where(memberOfDeptAt("COALESCE(e.completed_at, NOW())"), departmentID).
+		Where("e.deleted_at IS NULL AND u.deleted_at IS NULL AND c.deleted_at IS NULL")
+	if f.UserID != "" {
+		tx = tx.Where("e.user_id = ?", f.UserID)
+	}
+	if f.CourseID != "" {
+		tx = tx.Where("e.course_id = ?", f.CourseID)
+	}
+	if f.Status != "" {
+		tx = tx.Where("e.status = ?", f.Status)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []TeamEnrollment
+	err := tx.
+		Select(`e.id AS enrollment_id, e.user_id, u.employee_code, u.first_name, u.last_name,
+			e.course_id, c.code AS course_code, c.title AS course_title,
+			e.status, e.progress_percent, e.started_at, e.completed_at, e.last_accessed_at`).
+		Order("e.updated_at DESC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+type OverdueCourse struct {
+	UserID          string    `json:"user_id"`
+	EmployeeCode    string    `json:"employee_code"`
+	FirstName       string    `json:"first_name"`
+	LastName        string    `json:"last_name"`
+	CourseID        string    `json:"course_id"`
+	CourseCode      string    `json:"course_code"`
+	CourseTitle     string    `json:"course_title"`
+	AssignedAt      time.Time `json:"assigned_at"`
+	DueAt           time.Time `json:"due_at"`
+	Status          *string   `json:"status"` // nil = ยังไม่ลงทะเบียน
+	ProgressPercent float64   `json:"progress_percent"`
+}
+
+// ListOverdue คอร์สบังคับของแผนกที่เลยกำหนดแล้วแต่สมาชิกยังเรียนไม่จบ
+// กำหนดส่ง = วันที่ถูก assign (target สร้าง หรือวันที่เริ่มสังกัดแผนก แล้วแต่อันไหนทีหลัง) + due_days
+// target ของแผนกแม่ที่ครอบแผนกลูกนับด้วย; ถ้าคอร์สเดียวกันมาจากหลาย target ใช้กำหนดส่งที่เร็วที่สุด
+func (r *ManagerRepo) ListOverdue(ctx context.Context, departmentID string, now time.Time, limit, offset int) ([]OverdueCourse, int64, error) {
+	const (
+		assignedAt = "MIN(GREATEST(t.created_at, udr.effective_from))"
+		dueAt      = "MIN(DATE_ADD(GREATEST(t.created_at, udr.effective_from), INTERVAL t.due_days DAY))"
+	)
+
+	tx := r.db.WithContext(ctx).
+		Table("course_department_targets AS t").
+		Joins("JOIN courses c ON c.id = t.course_id").
+		Joins("JOIN user_department_roles udr ON udr.department_id = ? AND udr.deleted_at IS NULL", departmentID).
+		Joins("JOIN users u ON u.id = udr.user_id").
+		Joins("LEFT JOIN enrollments e ON e.user_id = u.id AND e.course_id = t.course_id AND e.deleted_at IS NULL").
+		Where(contentmodels.TargetCoversSQL("udr.department_id")).
+		Where("t.is_mandatory = 1 AND t.due_days IS NOT NULL").
+		Where("t.deleted_at IS NULL AND c.deleted_at IS NULL AND c.is_active = 1").
+		Where("u.deleted_at IS NULL AND u.is_active = 1").
+		Where("(e.id IS NULL OR e.status NOT IN ('completed','passed'))").
+		Group("u.id, u.employee_code, u.first_name, u.last_name, t.course_id, c.code, c.title, e.status, e.progress_percent").
+		Having(dueAt+" < ?", now)
+
+	var total int64
+	if err := r.db.WithContext(ctx).Table("(?) AS x", tx.Select("u.id")).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []OverdueCourse
+	err := tx.
+		Select(`u.id AS user_id, u.employee_code, u.first_name, u.last_name,
+			t.course_id, c.code AS course_code, c.title AS course_title,
+			` + assignedAt + ` AS assigned_at,
+			` + dueAt + ` AS due_at,
+			e.status, COALESCE(e.progress_percent, 0) AS progress_percent`).
+		Order("due_at ASC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
+
+type TeamAttempt struct {
+	AttemptID       string     `json:"attempt_id"`
+	UserID          string     `json:"user_id"`
+	EmployeeCode    string     `json:"employee_code"`
+	FirstName       string     `json:"first_name"`
+	LastName        string     `json:"last_name"`
+	AssessmentID    string     `json:"assessment_id"`
+	AssessmentTitle string     `json:"assessment_title"`
+	AssessmentType  string     `json:"assessment_type"`
+	OwnerType       string     `json:"owner_type"`
+	OwnerID         string     `json:"owner_id"`
+	Status          string     `json:"status"`
+	ScorePercent    *float64   `json:"score_percent"`
+	IsPassed        *bool      `json:"is_passed"`
+	StartedAt       time.Time  `json:"started_at"`
+	SubmittedAt     *time.Time `json:"submitted_at"`
+}
+
+// ListAttempts คะแนนแบบทดสอบของสมาชิก (f.CourseID กรองเฉพาะ assessment ที่ผูกกับคอร์สโดยตรง)
+func (r *ManagerRepo) ListAttempts(ctx context.Context, departmentID string, f TeamFilter, limit, offset int) ([]TeamAttempt, int64, error) {
+	tx := r.db.WithContext(ctx).
+		Table("assessment_attempts AS at").
+		Joins("JOIN users u ON u.id = at.user_id").
+		Joins("JOIN assessments a ON a.id = at.assessment_id").
+		Where(memberOfDeptAt("COALESCE(at.submitted_at, NOW())"), departmentID).
+		Where("at.deleted_at IS NULL AND u.deleted_at IS NULL AND a.deleted_at IS NULL")
+	if f.UserID != "" {
+		tx = tx.Where("at.user_id = ?", f.UserID)
+	}
+	if f.CourseID != "" {
+		tx = tx.Where("a.owner_type = ? AND a.owner_id = ?", "course", f.CourseID)
+	}
+	if f.Status != "" {
+		tx = tx.Where("at.status = ?", f.Status)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var rows []TeamAttempt
+	err := tx.
+		Select(`at.id AS attempt_id, at.user_id, u.employee_code, u.first_name, u.last_name,
+			at.assessment_id, a.title AS assessment_title, a.type AS assessment_type,
+			a.owner_type, a.owner_id, at.status, at.score_percent, at.is_passed,
+			at.started_at, at.submitted_at`).
+		Order("at.started_at DESC").
+		Limit(limit).Offset(offset).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return rows, total, nil
+}
@@ -29,10 +29,12 @@ func (r *MyCoursesRepo) ListDepartmentCourses(
 	tx := r.db.WithContext(ctx).
 		Model(&contentmodels.Course{}).
 		Joins(`JOIN course_department_targets t ON t.course_id = courses.id`).
-		Joins(`JOIN user_department_roles udr ON udr.department_id = t.department_id`).
+		Joins(`JOIN user_department_roles udr ON `+contentmodels.TargetCoversSQL("udr.department_id")).
 		Where("udr.user_id = ?", userID).
 		Where("courses.is_active = 1").
 		Where("courses.deleted_at IS NULL").
+		Where("courses.visibility IN ?", []string{contentmodels.VisibilityPublic, contentmodels.VisibilityDepartment}).
+		Where("t.deleted_at IS NULL").
 		Where("udr.deleted_at IS NULL").
 		Group("courses.id")
 
@@ -57,6 +59,47 @@ func (r *MyCoursesRepo) ListDepartmentCourses(
 	return courses, total, nil
 }
 
+// ListCatalog คอร์สทั้งหมดที่ user ลงทะเบียนได้ตามกติกา visibility
+// (public, department ที่ target แผนกของ user, invite ที่ถูกเชิญ)
+func (r *MyCoursesRepo) ListCatalog(ctx context.Context, userID, q string, categoryID *string, limit, offset int) ([]contentmodels.Course, int64, error) {
+	tx := r.db.WithContext(ctx).
+		Model(&contentmodels.Course{}).
+		Where("courses.is_active = 1 AND courses.deleted_at IS NULL").
+		Where(`courses.visibility = ?
+			OR (courses.visibility = ? AND EXISTS (
+				SELECT 1 FROM course_department_targets t
+				JOIN user_department_roles udr ON `+contentmodels.TargetCoversSQL("udr.department_id")+`
+				WHERE t.course_id = courses.id AND udr.user_id = ?
+				AND t.deleted_at IS NULL AND udr.deleted_at IS NULL))
+			OR (courses.visibility = ? AND EXISTS (
+				SELECT 1 FROM course_invitations i
+				WHERE i.course_id = courses.id AND i.user_id = ?))`,
+			contentmodels.VisibilityPublic,
+			contentmodels.VisibilityDepartment, userID,
+			contentmodels.VisibilityInvite, userID)
+
+	if q != "" {
+		tx = tx.Where("(courses.code LIKE ? OR courses.title LIKE ?)", "%"+q+"%", "%"+q+"%")
+	}
+	if categoryID != nil && *categoryID != "" {
+		tx = tx.Where("courses.category_id = ?", *categoryID)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var courses []contentmodels.Course
+	if err := tx.
+		Order("courses.created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&courses).Error; err != nil {
+		return nil, 0, err
+	}
+	return courses, total, nil
+}
+
 // คอร์สที่ user ลงทะเบียนแล้ว (จาก enrollments)
 type MyEnrolledCourse struct {
 	contentmodels.Course
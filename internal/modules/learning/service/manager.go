@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	learningrepo "github.com/Marugo/birdlax/internal/modules/learning/repo"
+)
+
+var (
+	ErrNotDepartmentManager = errors.New("not a manager of this department")
+	ErrNotDepartmentMember  = errors.New("user is not a member of this department")
+)
+
+// ManagerService ข้อมูลการเรียนของทีม สำหรับ user ที่เป็น manager ของแผนก
+// ทุกเมธอดตรวจก่อนว่า managerID เป็น manager ของ departmentID (ไม่ใช่แค่ leader/member)
+type ManagerService interface {
+	ListDepartments(ctx context.Context, managerID string) ([]learningrepo.ManagedDepartment, error)
+	ListMembers(ctx context.Context, managerID, departmentID string, page, per int) ([]learningrepo.TeamMember, int64, error)
+	ListEnrollments(ctx context.Context, managerID, departmentID string, f learningrepo.TeamFilter, page, per int) ([]learningrepo.TeamEnrollment, int64, error)
+	ListOverdue(ctx context.Context, managerID, departmentID string, page, per int) ([]learningrepo.OverdueCourse, int64, error)
+	ListAttempts(ctx context.Context, managerID, departmentID string, f learningrepo.TeamFilter, page, per int) ([]learningrepo.TeamAttempt, int64, error)
+	GetMemberCourseProgress(ctx context.Context, managerID, departmentID, userID, courseID string) (*learningrepo.CourseProgress, error)
+}
+
+type managerSvc struct {
+	repo ManagerRepo
+	my   CourseProgressReader // ใช้ progress รายบทเรียนชุดเดียวกับ /my
+}
+
+func NewManagerService(r ManagerRepo, my CourseProgressReader) ManagerService {
+	return &managerSvc{repo: r, my: my}
+}
+
+func (s *managerSvc) ListDepartments(ctx context.Context, managerID string) ([]learningrepo.ManagedDepartment, error) {
+	return s.repo.ListManagedDepartments(ctx, managerID)
+}
+
+func (s *managerSvc) ListMembers(ctx context.Context, managerID, departmentID string, page, per int) ([]learningrepo.TeamMember, int64, error) {
+	if err := s.authorize(ctx, managerID, departmentID, ""); err != nil {
+		return nil, 0, err
+	}
+	limit, offset := pageWindow(page, per)
+	return s.repo.ListMembers(ctx, departmentID, limit, offset)
+}
+
+func (s *managerSvc) ListEnrollments(ctx context.Context, managerID, departmentID string, f learningrepo.TeamFilter, page, per int) ([]learningrepo.TeamEnrollment, int64, error) {
+	if err := s.authorize(ctx, managerID, departmentID, f.UserID); err != nil {
+		return nil, 0, err
+	}
+	limit, offset := pageWindow(page, per)
+	return s.repo.ListEnrollments(ctx, departmentID, f, limit, offset)
+}
+
+func (s *managerSvc) ListOverdue(ctx context.Context, managerID, departmentID string, page, per int) ([]learningrepo.OverdueCourse, int64, error) {
+	if err := s.authorize(ctx, managerID, departmentID, ""); err != nil {
+		return nil, 0, err
+	}
+	limit, offset := pageWindow(page, per)
+	return s.repo.ListOverdue(ctx, departmentID, time.Now(), limit, offset)
+}
+
+func (s *managerSvc) ListAttempts(ctx context.Context, managerID, departmentID string, f learningrepo.TeamFilter, page, per int) ([]learningrepo.TeamAttempt, int64, error) {
+	if err := s.authorize(ctx, managerID, departmentID, f.UserID); err != nil {
+		return nil, 0, err
+	}
+	limit, offset := pageWindow(page, per)
+	return s.repo.ListAttempts(ctx, departmentID, f, limit, offset)
+}
+
+func (s *managerSvc) GetMemberCourseProgress(ctx context.Context, managerID, departmentID, userID, courseID string) (*learningrepo.CourseProgress, error) {
+	if err := s.authorize(ctx, managerID, departmentID, userID); err != nil {
+		return nil, err
+	}
+	// คอร์สที่เรียนจบตอนอยู่แผนกอื่นเป็นของ manager แผนกนั้น (กติกาเดียวกับรายการ)
+	ok, err := s.repo.CourseAttributed(ctx, departmentID, userID, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrNotDepartmentMember
+	}
+	return s.my.GetCourseProgress(ctx, userID, courseID)
+}
+
+// authorize: managerID ต้องเป็น manager ของแผนก และ (ถ้าระบุ) userID ต้องเคยหรือยังสังกัดแผนกนั้น
+// ผลที่เห็นจริงถูกกรองตามสังกัด ณ เวลาที่เรียนจบอีกชั้น (memberOfDeptAt ใน repo)
+func (s *managerSvc) authorize(ctx context.Context, managerID, departmentID, userID string) error {
+	ok, err := s.repo.IsManager(ctx, managerID, departmentID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotDepartmentManager
+	}
+	if userID == "" {
+		return nil
+	}
+	ok, err = s.repo.WasMember(ctx, departmentID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotDepartmentMember
+	}
+	return nil
+}
+
+// NormalizePage ปรับ page/per_page ที่ client ส่งมาให้อยู่ในช่วงที่ใช้จริง (handler ใช้ค่านี้ตอบกลับด้วย)
+func NormalizePage(page, per int) (int, int) {
+	if per <= 0 || per > 200 {
+		per = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+	return page, per
+}
+
+func pageWindow(page, per int) (limit, offset int) {
+	page, per = NormalizePage(page, per)
+	return per, (page - 1) * per
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	contentmodels "github.com/Marugo/birdlax/internal/modules/content/models"
 	learningrepo "github.com/Marugo/birdlax/internal/modules/learning/repo"
@@ -9,6 +10,7 @@ import (
 
 type MyCoursesService interface {
 	ListDepartmentCourses(ctx context.Context, userID string, categoryID *string, page, per int) ([]contentmodels.Course, int64, error)
+	ListCatalog(ctx context.Context, userID, q string, categoryID *string, page, per int) ([]contentmodels.Course, int64, error)
 	ListMyCourses(ctx context.Context, userID string, page, per int) ([]learningrepo.MyEnrolledCourse, int64, error)
 	GetCourseProgress(ctx context.Context, userID, courseID string) (*learningrepo.CourseProgress, error)
 }
@@ -37,6 +39,17 @@ func (s *myCoursesSvc) ListDepartmentCourses(
 	return s.repo.ListDepartmentCourses(ctx, userID, categoryID, per, offset)
 }
 
+func (s *myCoursesSvc) ListCatalog(ctx context.Context, userID, q string, categoryID *string, page, per int) ([]contentmodels.Course, int64, error) {
+	if per <= 0 || per > 200 {
+		per = 20
+	}
+	if page <= 0 {
+		page = 1
+	}
+	offset := (page - 1) * per
+	return s.repo.ListCatalog(ctx, userID, strings.TrimSpace(q), categoryID, per, offset)
+}
+
 func (s *myCoursesSvc) ListMyCourses(ctx context.Context, userID string, page, per int) ([]learningrepo.MyEnrolledCourse, int64, error) {
 	if per <= 0 || per > 200 {
 		per = 20
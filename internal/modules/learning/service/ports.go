@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	content "github.com/Marugo/birdlax/internal/modules/content/models"
 	"github.com/Marugo/birdlax/internal/modules/learning/dto"
 	"github.com/Marugo/birdlax/internal/modules/learning/models"
+	learningrepo "github.com/Marugo/birdlax/internal/modules/learning/repo"
 )
 
 type Repo interface {
@@ -21,6 +25,11 @@ type Repo interface {
 	CountCompletedMandatoryLessons(userID, courseID string) (int64, error)
 }
 
+// EnrollmentPolicy กติกาว่า user ลงทะเบียนคอร์สได้ไหม (content.AccessService)
+type EnrollmentPolicy interface {
+	CanEnroll(userID, courseID string) error
+}
+
 type Service interface {
 	EnrollCourse(userID, courseID string) (*models.Enrollment, error)
 	GetEnrollment(userID, courseID string) (*models.Enrollment, error)
@@ -30,3 +39,20 @@ type Service interface {
 	CompleteLesson(userID, lessonID string, req dto.CompleteLessonReq) (*models.UserLessonProgress, error)
 	UpdateEnrollmentPercent(userID, courseID string) error
 }
+
+// ManagerRepo ข้อมูลทีมของ manager (implement โดย learningrepo.ManagerRepo)
+type ManagerRepo interface {
+	ListManagedDepartments(ctx context.Context, userID string) ([]learningrepo.ManagedDepartment, error)
+	IsManager(ctx context.Context, userID, departmentID string) (bool, error)
+	WasMember(ctx context.Context, departmentID, userID string) (bool, error)
+	CourseAttributed(ctx context.Context, departmentID, userID, courseID string) (bool, error)
+	ListMembers(ctx context.Context, departmentID string, limit, offset int) ([]learningrepo.TeamMember, int64, error)
+	ListEnrollments(ctx context.Context, departmentID string, f learningrepo.TeamFilter, limit, offset int) ([]learningrepo.TeamEnrollment, int64, error)
+	ListOverdue(ctx context.Context, departmentID string, now time.Time, limit, offset int) ([]learningrepo.OverdueCourse, int64, error)
+	ListAttempts(ctx context.Context, departmentID string, f learningrepo.TeamFilter, limit, offset int) ([]learningrepo.TeamAttempt, int64, error)
+}
+
+// CourseProgressReader progress รายบทเรียนของ user ในคอร์ส (implement โดย learningrepo.MyCoursesRepo)
+type CourseProgressReader interface {
+	GetCourseProgress(ctx context.Context, userID, courseID string) (*learningrepo.CourseProgress, error)
+}
@@ -12,11 +12,17 @@ import (
 type svc struct {
 	repo    Repo
 	metrics MetricsService
+	policy  EnrollmentPolicy
 }
 
-func New(r Repo, ms MetricsService) Service { return &svc{repo: r, metrics: ms} }
+func New(r Repo, ms MetricsService, p EnrollmentPolicy) Service {
+	return &svc{repo: r, metrics: ms, policy: p}
+}
 
 func (s *svc) EnrollCourse(userID, courseID string) (*models.Enrollment, error) {
+	if err := s.policy.CanEnroll(userID, courseID); err != nil {
+		return nil, err
+	}
 	now := time.Now()
 	e := &models.Enrollment{
 		ID:             uuid.NewString(),
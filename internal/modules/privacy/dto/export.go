@@ -0,0 +1,138 @@
+package dto
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	contentmodels "github.com/Marugo/birdlax/internal/modules/content/models"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// Export ข้อมูลทั้งหมดที่ระบบเก็บเกี่ยวกับ user หนึ่งคน (PDPA: สิทธิ์ขอรับสำเนาข้อมูล)
+// ไม่รวม secret (hash รหัสผ่าน, MFA secret, token) เพราะใช้ยืนยันตัวตน ไม่ใช่ข้อมูลของเจ้าของ
+type Export struct {
+	GeneratedAt time.Time `json:"generated_at"`
+
+	Profile           usermodels.User                  `json:"profile"`
+	DepartmentRoles   []usermodels.UserDepartmentRole  `json:"department_roles"`
+	PasswordChanges   []time.Time                      `json:"password_changes"`
+	MFA               *MFA                             `json:"mfa"`
+	Sessions          []Session                        `json:"sessions"`
+	SecurityEvents    []SecurityEvent                  `json:"security_events"`
+	AuditLogs         []auditmodels.AuditLog           `json:"audit_logs"`
+	CourseInvitations []contentmodels.CourseInvitation `json:"course_invitations"`
+
+	Enrollments     []Enrollment     `json:"enrollments"`
+	LessonProgress  []LessonProgress `json:"lesson_progress"`
+	Attempts        []Attempt        `json:"assessment_attempts"`
+	LearningMetrics []LearningMetric `json:"learning_metrics"`
+}
+
+type MFA struct {
+	Enabled       bool       `json:"enabled"`
+	ConfirmedAt   *time.Time `json:"confirmed_at"`
+	RecoveryCodes int        `json:"recovery_codes_remaining"`
+}
+
+type Session struct {
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Revoked   bool      `json:"revoked"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+}
+
+type SecurityEvent struct {
+	Type      string    `json:"type"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Detail    string    `json:"detail,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Enrollment struct {
+	ID              string     `json:"id"`
+	CourseID        string     `json:"course_id"`
+	CourseTitle     string     `json:"course_title"`
+	Status          string     `json:"status"`
+	ProgressPercent float64    `json:"progress_percent"`
+	StartedAt       *time.Time `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	LastAccessedAt  *time.Time `json:"last_accessed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	DeletedAt       *time.Time `json:"deleted_at,omitempty"`
+}
+
+type LessonProgress struct {
+	LessonID        string     `json:"lesson_id"`
+	ProgressPercent float64    `json:"progress_percent"`
+	CurrentPosition int64      `json:"current_position"`
+	StartedAt       *time.Time `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+type Attempt struct {
+	ID           string     `json:"id"`
+	AssessmentID string     `json:"assessment_id"`
+	Status       string     `json:"status"`
+	StartedAt    time.Time  `json:"started_at"`
+	SubmittedAt  *time.Time `json:"submitted_at"`
+	ScorePercent *float64   `json:"score_percent"`
+	IsPassed     *bool      `json:"is_passed"`
+	Answers      []Answer   `json:"answers"`
+}
+
+type Answer struct {
+	QuestionID        string  `json:"question_id"`
+	SelectedChoiceIDs *string `json:"selected_choice_ids,omitempty"`
+	TextAnswer        *string `json:"text_answer,omitempty"`
+	IsCorrect         *bool   `json:"is_correct"`
+}
+
+type LearningMetric struct {
+	CourseID         string   `json:"course_id"`
+	AvgScore         float64  `json:"avg_score"`
+	LastScore        *float64 `json:"last_score"`
+	AttemptsCount    int      `json:"attempts_count"`
+	PassCount        int      `json:"pass_count"`
+	TotalTimeSeconds int64    `json:"total_time_seconds"`
+	CompletionStatus string   `json:"completion_status"`
+}
+
+// WriteZip หนึ่งไฟล์ JSON ต่อหมวด (เปิดดูทีละส่วนได้โดยไม่ต้องมีเครื่องมือ)
+func (e *Export) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name string
+		v    any
+	}{
+		{"profile.json", e.Profile},
+		{"department_roles.json", e.DepartmentRoles},
+		{"security/password_changes.json", e.PasswordChanges},
+		{"security/mfa.json", e.MFA},
+		{"security/sessions.json", e.Sessions},
+		{"security/security_events.json", e.SecurityEvents},
+		{"audit_logs.json", e.AuditLogs},
+		{"learning/course_invitations.json", e.CourseInvitations},
+		{"learning/enrollments.json", e.Enrollments},
+		{"learning/lesson_progress.json", e.LessonProgress},
+		{"learning/assessment_attempts.json", e.Attempts},
+		{"learning/learning_metrics.json", e.LearningMetrics},
+	}
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: e.GeneratedAt})
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.v); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/privacy"
+	privacyservice "github.com/Marugo/birdlax/internal/modules/privacy/service"
+	"github.com/Marugo/birdlax/internal/shared/response"
+)
+
+type HTTPHandler struct {
+	svc privacy.Service
+}
+
+func NewHTTPHandler(s privacy.Service) *HTTPHandler {
+	return &HTTPHandler{svc: s}
+}
+
+// GET /users/:id/personal-data?format=json|zip (ดาวน์โหลดเป็นไฟล์)
+func (h *HTTPHandler) Export(c *fiber.Ctx) error {
+	format := c.Query("format", "json")
+	if format != "json" && format != "zip" {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "format must be json or zip")
+	}
+	id := c.Params("id")
+	e, err := h.svc.Export(c.Context(), actor(c), id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+
+	name := fmt.Sprintf("personal-data-%s-%s", id, e.GeneratedAt.Format("20060102"))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if format == "zip" {
+		var buf bytes.Buffer
+		if err := e.WriteZip(&buf); err != nil {
+			return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		}
+		c.Attachment(name + ".zip")
+		return c.Send(buf.Bytes())
+	}
+	c.Attachment(name + ".json")
+	return c.JSON(e)
+}
+
+// POST /users/:id/anonymize (ย้อนกลับไม่ได้)
+func (h *HTTPHandler) Anonymize(c *fiber.Ctx) error {
+	if err := h.svc.Anonymize(c.Context(), actor(c), c.Params("id")); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+		case errors.Is(err, privacyservice.ErrUserStillActive):
+			return response.Err(c, fiber.StatusConflict, "USER_STILL_ACTIVE", err.Error())
+		case errors.Is(err, privacyservice.ErrAlreadyAnonymized):
+			return response.Err(c, fiber.StatusConflict, "ALREADY_ANONYMIZED", err.Error())
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, fiber.Map{"anonymized": true})
+}
+
+func actor(c *fiber.Ctx) privacy.Actor {
+	id, _ := c.Locals("user_id").(string)
+	return privacy.Actor{ID: id, IP: c.IP(), UserAgent: c.Get(fiber.HeaderUserAgent)}
+}
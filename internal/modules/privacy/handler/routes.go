@@ -0,0 +1,21 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/authz"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+)
+
+// Register export/anonymize ใต้ /users/:id — คนตัวจริงเท่านั้น (ไม่ใช่ API key / impersonation)
+func Register(r fiber.Router, h *HTTPHandler) {
+	// ใส่ middleware ราย route: Group("/users", mw) จะไปครอบ route อื่นของ /users ด้วย
+	guard := []fiber.Handler{
+		middleware.RequireUser(),
+		middleware.DenyImpersonation(),
+		middleware.RequirePermission(authz.PermUserPrivacy),
+	}
+	g := r.Group("/users")
+	g.Get("/:id/personal-data", append(guard, h.Export)...)
+	g.Post("/:id/anonymize", append(guard, h.Anonymize)...)
+}
@@ -0,0 +1,41 @@
+package privacy
+
+import (
+	"context"
+	"time"
+
+	"github.com/Marugo/birdlax/internal/modules/privacy/dto"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// Actor คนที่สั่ง export/anonymize (ลง audit log)
+type Actor struct {
+	ID        string
+	IP        string
+	UserAgent string
+}
+
+// Pseudonym ค่าที่ใช้แทนข้อมูลระบุตัวตนตอน anonymize
+type Pseudonym struct {
+	EmployeeCode string
+	Email        string
+	FirstName    string
+	LastName     string
+}
+
+type Repository interface {
+	// FindUser รวม user ที่ถูก soft delete แล้ว
+	FindUser(ctx context.Context, userID string) (*usermodels.User, error)
+	// LoadExport รวมข้อมูลทุกตารางของ user (รวมแถวที่ soft delete แล้ว); ไม่พบ user = gorm.ErrRecordNotFound
+	LoadExport(ctx context.Context, userID string) (*dto.Export, error)
+	// Anonymize ลบ/แทนข้อมูลระบุตัวตนทุกตารางใน transaction เดียว
+	// ข้อมูลการเรียน (enrollments, attempts, metrics, course_outcomes) ยังผูกกับ user id เดิม
+	Anonymize(ctx context.Context, userID string, p Pseudonym, at time.Time) error
+}
+
+// Service สิทธิ์ของเจ้าของข้อมูลตาม PDPA: ขอรับสำเนา และขอให้ลบ/ทำให้ระบุตัวตนไม่ได้
+type Service interface {
+	Export(ctx context.Context, actor Actor, userID string) (*dto.Export, error)
+	// Anonymize ทำได้เฉพาะ user ที่ปิด account หรือถูกลบแล้ว (คนที่ออกไปแล้ว) และย้อนกลับไม่ได้
+	Anonymize(ctx context.Context, actor Actor, userID string) error
+}
@@ -0,0 +1,244 @@
+package repo
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	assessmentmodels "github.com/Marugo/birdlax/internal/modules/assessment/models"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	authrepo "github.com/Marugo/birdlax/internal/modules/auth/repo"
+	contentmodels "github.com/Marugo/birdlax/internal/modules/content/models"
+	learningmodels "github.com/Marugo/birdlax/internal/modules/learning/models"
+	"github.com/Marugo/birdlax/internal/modules/privacy"
+	"github.com/Marugo/birdlax/internal/modules/privacy/dto"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// gormRepo อ่าน/แก้ตารางของหลาย module ตรงๆ (export/anonymize ต้องเห็นทุกตารางที่มี user_id)
+// เพิ่มตารางใหม่ที่เก็บข้อมูลของ user ต้องมาเพิ่มที่นี่ทั้งสองฟังก์ชัน
+type gormRepo struct{ db *gorm.DB }
+
+func NewGormRepository(db *gorm.DB) privacy.Repository { return &gormRepo{db: db} }
+
+func (r *gormRepo) FindUser(ctx context.Context, userID string) (*usermodels.User, error) {
+	var u usermodels.User
+	if err := r.db.WithContext(ctx).Unscoped().First(&u, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *gormRepo) LoadExport(ctx context.Context, userID string) (*dto.Export, error) {
+	db := r.db.WithContext(ctx)
+	e := &dto.Export{GeneratedAt: time.Now()}
+
+	if err := db.Unscoped().First(&e.Profile, "id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Unscoped().Preload("Department", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("user_id = ?", userID).Order("created_at").
+		Find(&e.DepartmentRoles).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&usermodels.PasswordHistory{}).
+		Where("user_id = ?", userID).Order("created_at").
+		Pluck("created_at", &e.PasswordChanges).Error; err != nil {
+		return nil, err
+	}
+
+	// ===== security =====
+	var factors []authrepo.MFAFactor
+	if err := db.Where("user_id = ?", userID).Find(&factors).Error; err != nil {
+		return nil, err
+	}
+	e.MFA = &dto.MFA{}
+	if len(factors) > 0 {
+		e.MFA.Enabled = factors[0].ConfirmedAt != nil
+		e.MFA.ConfirmedAt = factors[0].ConfirmedAt
+	}
+	var codes int64
+	if err := db.Model(&authrepo.MFARecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).Count(&codes).Error; err != nil {
+		return nil, err
+	}
+	e.MFA.RecoveryCodes = int(codes)
+
+	var tokens []authrepo.RefreshToken
+	if err := db.Where("user_id = ?", userID).Order("created_at").Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+	e.Sessions = make([]dto.Session, 0, len(tokens))
+	for _, t := range tokens {
+		e.Sessions = append(e.Sessions, dto.Session{
+			CreatedAt: t.CreatedAt,
+			ExpiresAt: time.Unix(t.ExpiresAt, 0),
+			Revoked:   t.Revoked,
+			IP:        t.IP,
+			UserAgent: t.UserAgent,
+		})
+	}
+	if err := db.Model(&authrepo.SecurityEvent{}).
+		Select("type, ip, user_agent, detail, created_at").
+		Where("user_id = ?", userID).Order("created_at").
+		Scan(&e.SecurityEvents).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("actor_id = ? OR subject_id = ?", userID, userID).
+		Order("created_at").Find(&e.AuditLogs).Error; err != nil {
+		return nil, err
+	}
+
+	// ===== learning =====
+	if err := db.Where("user_id = ?", userID).Order("created_at").
+		Find(&e.CourseInvitations).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Table("enrollments AS e").
+		Select(`e.id, e.course_id, c.title AS course_title, e.status, e.progress_percent,
+			e.started_at, e.completed_at, e.last_accessed_at, e.created_at, e.deleted_at`).
+		Joins("LEFT JOIN courses c ON c.id = e.course_id").
+		Where("e.user_id = ?", userID).Order("e.created_at").
+		Scan(&e.Enrollments).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&learningmodels.UserLessonProgress{}).
+		Select("lesson_id, progress_percent, current_position, started_at, completed_at, updated_at").
+		Where("user_id = ?", userID).Order("created_at").
+		Scan(&e.LessonProgress).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&learningmodels.LearningMetric{}).
+		Select("course_id, avg_score, last_score, attempts_count, pass_count, total_time_seconds, completion_status").
+		Where("user_id = ?", userID).Order("created_at").
+		Scan(&e.LearningMetrics).Error; err != nil {
+		return nil, err
+	}
+
+	var attempts []assessmentmodels.Attempt
+	if err := db.Where("user_id = ?", userID).Order("started_at").Find(&attempts).Error; err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(attempts))
+	for _, a := range attempts {
+		ids = append(ids, a.ID)
+	}
+	var answers []assessmentmodels.Answer
+	if len(ids) > 0 {
+		if err := db.Where("attempt_id IN ?", ids).Order("created_at").Find(&answers).Error; err != nil {
+			return nil, err
+		}
+	}
+	byAttempt := map[string][]dto.Answer{}
+	for _, a := range answers {
+		byAttempt[a.AttemptID] = append(byAttempt[a.AttemptID], dto.Answer{
+			QuestionID:        a.QuestionID,
+			SelectedChoiceIDs: a.SelectedChoiceIDs,
+			TextAnswer:        a.TextAnswer,
+			IsCorrect:         a.IsCorrect,
+		})
+	}
+	e.Attempts = make([]dto.Attempt, 0, len(attempts))
+	for _, a := range attempts {
+		ans := byAttempt[a.ID]
+		if ans == nil {
+			ans = []dto.Answer{}
+		}
+		e.Attempts = append(e.Attempts, dto.Attempt{
+			ID:           a.ID,
+			AssessmentID: a.AssessmentID,
+			Status:       a.Status,
+			StartedAt:    a.StartedAt,
+			SubmittedAt:  a.SubmittedAt,
+			ScorePercent: a.ScorePercent,
+			IsPassed:     a.IsPassed,
+			Answers:      ans,
+		})
+	}
+	return e, nil
+}
+
+func (r *gormRepo) Anonymize(ctx context.Context, userID string, p privacy.Pseudonym, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// ตัว user: แทนข้อมูลระบุตัวตน, ปิด account ถาวร (hash "!" ไม่มีทาง match)
+		// ข้อมูลตำแหน่งงาน/HRIS ล้างด้วย: วันเริ่มงาน + ตำแหน่ง + site รวมกันชี้ตัวคนได้
+		res := tx.Unscoped().Model(&usermodels.User{}).
+			Where("id = ? AND anonymized_at IS NULL", userID).
+			Updates(map[string]any{
+				"employee_code":        p.EmployeeCode,
+				"email":                p.Email,
+				"first_name":           p.FirstName,
+				"last_name":            p.LastName,
+				"phone":                nil,
+				"external_id":          nil,
+				"manager_user_id":      nil,
+				"job_title":            nil,
+				"position_level":       nil,
+				"hire_date":            nil,
+				"location":             nil,
+				"employment_type":      nil,
+				"attributes":           nil,
+				"password_hash":        "!",
+				"is_active":            false,
+				"failed_login_count":   0,
+				"last_failed_login_at": nil,
+				"locked_until":         nil,
+				"last_login_at":        nil,
+				"password_changed_at":  nil,
+				"must_change_password": false,
+				"token_version":        gorm.Expr("token_version + 1"),
+				"anonymized_at":        at,
+				"deleted_at":           gorm.Expr("COALESCE(deleted_at, ?)", at),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Unscoped().Model(&usermodels.User{}).
+			Where("manager_user_id = ?", userID).
+			UpdateColumn("manager_user_id", nil).Error; err != nil {
+			return err
+		}
+
+		// credential/session ไม่มีประโยชน์ต่อสถิติ ลบทิ้ง
+		for _, m := range []any{
+			&usermodels.PasswordHistory{},
+			&authrepo.RefreshToken{},
+			&authrepo.PasswordResetToken{},
+			&authrepo.MFAFactor{},
+			&authrepo.MFARecoveryCode{},
+			&contentmodels.CourseInvitation{},
+		} {
+			if err := tx.Where("user_id = ?", userID).Delete(m).Error; err != nil {
+				return err
+			}
+		}
+
+		// log เก็บไว้ (ใคร/ทำอะไร/เมื่อไร ผูกกับ id) แต่ลบ IP/อุปกรณ์
+		if err := tx.Model(&authrepo.SecurityEvent{}).
+			Where("user_id = ?", userID).
+			Updates(map[string]any{"ip": "", "user_agent": "", "detail": ""}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&auditmodels.AuditLog{}).
+			Where("actor_id = ? OR subject_id = ?", userID, userID).
+			Updates(map[string]any{"ip": "", "user_agent": ""}).Error; err != nil {
+			return err
+		}
+
+		// จบทุกสังกัด ณ at (แถวเดิมยังอยู่ ให้รายงานย้อนหลังนับได้)
+		if err := tx.Model(&usermodels.UserDepartmentRole{}).
+			Where("user_id = ? AND effective_to IS NULL", userID).
+			Updates(map[string]any{"effective_to": at, "deleted_at": at}).Error; err != nil {
+			return err
+		}
+
+		// คำตอบแบบพิมพ์เองอาจมีข้อมูลส่วนตัว; คะแนน/ถูกผิดยังอยู่
+		return tx.Model(&assessmentmodels.Answer{}).
+			Where("attempt_id IN (?)", tx.Model(&assessmentmodels.Attempt{}).Select("id").Where("user_id = ?", userID)).
+			UpdateColumn("text_answer", nil).Error
+	})
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	"github.com/Marugo/birdlax/internal/modules/privacy"
+	"github.com/Marugo/birdlax/internal/modules/privacy/dto"
+)
+
+var (
+	ErrUserStillActive   = errors.New("user is still active; deactivate or delete the account first")
+	ErrAlreadyAnonymized = errors.New("user has already been anonymized")
+)
+
+type svc struct {
+	repo   privacy.Repository
+	audits audit.Service
+}
+
+func NewService(r privacy.Repository, audits audit.Service) privacy.Service {
+	return &svc{repo: r, audits: audits}
+}
+
+func (s *svc) Export(ctx context.Context, actor privacy.Actor, userID string) (*dto.Export, error) {
+	e, err := s.repo.LoadExport(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	// ไม่มี audit = ไม่ส่งข้อมูลออก
+	if err := s.record(ctx, auditmodels.ActionUserDataExport, actor, userID); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *svc) Anonymize(ctx context.Context, actor privacy.Actor, userID string) error {
+	u, err := s.repo.FindUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.AnonymizedAt != nil {
+		return ErrAlreadyAnonymized
+	}
+	if u.IsActive && !u.DeletedAt.Valid {
+		return ErrUserStillActive
+	}
+	p, err := newPseudonym()
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Anonymize(ctx, userID, p, time.Now()); err != nil {
+		return err
+	}
+	return s.record(ctx, auditmodels.ActionUserAnonymize, actor, userID)
+}
+
+func (s *svc) record(ctx context.Context, action string, actor privacy.Actor, subjectID string) error {
+	return s.audits.Record(ctx, &auditmodels.AuditLog{
+		Action:    action,
+		ActorID:   actor.ID,
+		SubjectID: subjectID,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+	})
+}
+
+// newPseudonym สุ่มใหม่ทุกครั้ง (ไม่ derive จาก id/email เดิม จะได้ย้อนกลับไม่ได้)
+// employee_code ยาว 29 ตัว ไม่เกินขนาดคอลัมน์ (30)
+func newPseudonym() (privacy.Pseudonym, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return privacy.Pseudonym{}, err
+	}
+	tag := "anon-" + hex.EncodeToString(b)
+	return privacy.Pseudonym{
+		EmployeeCode: tag,
+		Email:        tag + "@anonymized.invalid",
+		FirstName:    "Anonymized",
+		LastName:     "User",
+	}, nil
+}
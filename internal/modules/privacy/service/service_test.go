@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Marugo/birdlax/internal/modules/audit"
+	auditmodels "github.com/Marugo/birdlax/internal/modules/audit/models"
+	authrepo "github.com/Marugo/birdlax/internal/modules/auth/repo"
+	contentmodels "github.com/Marugo/birdlax/internal/modules/content/models"
+	"github.com/Marugo/birdlax/internal/modules/privacy"
+	"github.com/Marugo/birdlax/internal/modules/privacy/dto"
+	privacyrepo "github.com/Marugo/birdlax/internal/modules/privacy/repo"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+type fakeRepo struct {
+	privacy.Repository
+	users      map[string]*usermodels.User
+	pseudonyms []privacy.Pseudonym
+}
+
+func (f *fakeRepo) FindUser(_ context.Context, id string) (*usermodels.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return u, nil
+}
+
+func (f *fakeRepo) LoadExport(_ context.Context, id string) (*dto.Export, error) {
+	if _, ok := f.users[id]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &dto.Export{}, nil
+}
+
+func (f *fakeRepo) Anonymize(_ context.Context, id string, p privacy.Pseudonym, at time.Time) error {
+	f.pseudonyms = append(f.pseudonyms, p)
+	f.users[id].AnonymizedAt = &at
+	return nil
+}
+
+type fakeAudits struct {
+	audit.Service
+	logs []auditmodels.AuditLog
+	err  error
+}
+
+func (f *fakeAudits) Record(_ context.Context, l *auditmodels.AuditLog) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.logs = append(f.logs, *l)
+	return nil
+}
+
+func newFixture() (*svc, *fakeRepo, *fakeAudits) {
+	repo := &fakeRepo{users: map[string]*usermodels.User{
+		"active": {ID: "active", EmployeeCode: "E1", Email: "a@example.com", IsActive: true},
+		"left":   {ID: "left", EmployeeCode: "E2", Email: "b@example.com", IsActive: false},
+		"gone":   {ID: "gone", EmployeeCode: "E3", Email: "c@example.com", IsActive: true, DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}},
+	}}
+	auds := &fakeAudits{}
+	return &svc{repo: repo, audits: auds}, repo, auds
+}
+
+var actor = privacy.Actor{ID: "hr1", IP: "10.0.0.1", UserAgent: "test"}
+
+func TestExportIsAudited(t *testing.T) {
+	s, _, auds := newFixture()
+	ctx := context.Background()
+
+	if _, err := s.Export(ctx, actor, "active"); err != nil {
+		t.Fatal(err)
+	}
+	if len(auds.logs) != 1 || auds.logs[0].Action != auditmodels.ActionUserDataExport ||
+		auds.logs[0].ActorID != "hr1" || auds.logs[0].SubjectID != "active" {
+		t.Fatalf("audit = %+v", auds.logs)
+	}
+
+	// audit ไม่สำเร็จ = ไม่ส่งข้อมูลออก
+	auds.err = errors.New("audit down")
+	if e, err := s.Export(ctx, actor, "active"); err == nil || e != nil {
+		t.Fatalf("export without audit: %+v, %v", e, err)
+	}
+	if _, err := s.Export(ctx, actor, "nope"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("missing user: %v", err)
+	}
+}
+
+func TestAnonymizeRules(t *testing.T) {
+	s, repo, auds := newFixture()
+	ctx := context.Background()
+
+	if err := s.Anonymize(ctx, actor, "active"); !errors.Is(err, ErrUserStillActive) {
+		t.Fatalf("active: %v", err)
+	}
+	for _, id := range []string{"left", "gone"} {
+		if err := s.Anonymize(ctx, actor, id); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+	}
+	if err := s.Anonymize(ctx, actor, "left"); !errors.Is(err, ErrAlreadyAnonymized) {
+		t.Fatalf("again: %v", err)
+	}
+
+	if len(repo.pseudonyms) != 2 || len(auds.logs) != 2 || auds.logs[0].Action != auditmodels.ActionUserAnonymize {
+		t.Fatalf("pseudonyms = %+v, audit = %+v", repo.pseudonyms, auds.logs)
+	}
+	a, b := repo.pseudonyms[0], repo.pseudonyms[1]
+	if a.EmployeeCode == b.EmployeeCode || a.Email == b.Email {
+		t.Fatalf("pseudonyms not unique: %+v %+v", a, b)
+	}
+	for _, p := range repo.pseudonyms {
+		if len(p.EmployeeCode) > 30 || !strings.HasSuffix(p.Email, "@anonymized.invalid") ||
+			strings.Contains(p.Email, "example.com") || strings.Contains(p.EmployeeCode, "E2") {
+			t.Fatalf("bad pseudonym %+v", p)
+		}
+	}
+}
+
+// ใช้ repo จริงบน sqlite: ข้อมูลตำแหน่งงาน/HRIS (user-023) ต้องถูกล้างพร้อมชื่อ/email
+func TestAnonymizeClearsJobProfile(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(
+		&usermodels.User{}, &usermodels.PasswordHistory{}, &usermodels.UserDepartmentRole{},
+		&authrepo.RefreshToken{}, &authrepo.PasswordResetToken{}, &authrepo.MFAFactor{}, &authrepo.MFARecoveryCode{},
+		&authrepo.SecurityEvent{}, &auditmodels.AuditLog{}, &contentmodels.CourseInvitation{},
+	); err != nil {
+		t.Fatal(err)
+	}
+	// ตาราง assessment ใช้ชนิด enum ของ MySQL: สร้างเฉพาะคอลัมน์ที่ Anonymize ใช้
+	for _, ddl := range []string{
+		"CREATE TABLE assessment_attempts (id TEXT PRIMARY KEY, user_id TEXT, deleted_at DATETIME)",
+		"CREATE TABLE assessment_answers (id TEXT PRIMARY KEY, attempt_id TEXT, text_answer TEXT, updated_at DATETIME, deleted_at DATETIME)",
+	} {
+		if err := db.Exec(ddl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	title, level, site := "Line Supervisor", "L3", "Rayong"
+	et := usermodels.EmploymentFullTime
+	hired := time.Date(2019, 4, 1, 0, 0, 0, 0, time.UTC)
+	u := &usermodels.User{
+		EmployeeCode: "E1", Email: "e1@example.com", FirstName: "A", LastName: "B", PasswordHash: "x",
+		JobTitle: &title, PositionLevel: &level, HireDate: &hired, Location: &site, EmploymentType: &et,
+		Attributes: usermodels.Attributes{"cost_center": "CC-42"},
+	}
+	if err := db.Create(u).Error; err != nil {
+		t.Fatal(err)
+	}
+	// ลาออกแล้ว (is_active มี default 1 จึงต้องปิดหลังสร้าง)
+	if err := db.Model(u).Update("is_active", false).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	s := &svc{repo: privacyrepo.NewGormRepository(db), audits: &fakeAudits{}}
+	if err := s.Anonymize(context.Background(), actor, u.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	var got usermodels.User
+	if err := db.Unscoped().First(&got, "id = ?", u.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got.AnonymizedAt == nil || got.Email == "e1@example.com" {
+		t.Fatalf("not anonymized: %+v", got)
+	}
+	if got.JobTitle != nil || got.PositionLevel != nil || got.HireDate != nil ||
+		got.Location != nil || got.EmploymentType != nil || got.Attributes != nil {
+		t.Fatalf("job profile kept: title=%v level=%v hired=%v site=%v type=%v attrs=%v",
+			got.JobTitle, got.PositionLevel, got.HireDate, got.Location, got.EmploymentType, got.Attributes)
+	}
+}
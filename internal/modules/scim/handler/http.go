@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/scim"
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+	scimservice "github.com/Marugo/birdlax/internal/modules/scim/service"
+)
+
+const contentType = "application/scim+json"
+
+type HTTPHandler struct {
+	svc scim.Service
+}
+
+func NewHTTPHandler(s scim.Service) *HTTPHandler {
+	return &HTTPHandler{svc: s}
+}
+
+// ===================== Users =====================
+
+func (h *HTTPHandler) ListUsers(c *fiber.Ctx) error {
+	start, count := pageParams(c)
+	out, err := h.svc.ListUsers(c.Context(), c.Query("filter"), start, count)
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) GetUser(c *fiber.Ctx) error {
+	out, err := h.svc.GetUser(c.Context(), c.Params("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) CreateUser(c *fiber.Ctx) error {
+	var in dto.User
+	if err := decode(c, &in); err != nil {
+		return fail(c, err)
+	}
+	out, err := h.svc.CreateUser(c.Context(), &in)
+	if err != nil {
+		return fail(c, err)
+	}
+	c.Set(fiber.HeaderLocation, out.Meta.Location)
+	return send(c, fiber.StatusCreated, out)
+}
+
+func (h *HTTPHandler) ReplaceUser(c *fiber.Ctx) error {
+	var in dto.User
+	if err := decode(c, &in); err != nil {
+		return fail(c, err)
+	}
+	out, err := h.svc.ReplaceUser(c.Context(), c.Params("id"), &in)
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) PatchUser(c *fiber.Ctx) error {
+	var in dto.PatchRequest
+	if err := decode(c, &in); err != nil {
+		return fail(c, err)
+	}
+	out, err := h.svc.PatchUser(c.Context(), c.Params("id"), in.Operations)
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) DeleteUser(c *fiber.Ctx) error {
+	if err := h.svc.DeleteUser(c.Context(), c.Params("id")); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ===================== Groups =====================
+
+func (h *HTTPHandler) ListGroups(c *fiber.Ctx) error {
+	start, count := pageParams(c)
+	out, err := h.svc.ListGroups(c.Context(), c.Query("filter"), start, count, withMembers(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) GetGroup(c *fiber.Ctx) error {
+	out, err := h.svc.GetGroup(c.Context(), c.Params("id"), withMembers(c))
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) CreateGroup(c *fiber.Ctx) error {
+	var in dto.Group
+	if err := decode(c, &in); err != nil {
+		return fail(c, err)
+	}
+	out, err := h.svc.CreateGroup(c.Context(), &in)
+	if err != nil {
+		return fail(c, err)
+	}
+	c.Set(fiber.HeaderLocation, out.Meta.Location)
+	return send(c, fiber.StatusCreated, out)
+}
+
+func (h *HTTPHandler) ReplaceGroup(c *fiber.Ctx) error {
+	var in dto.Group
+	if err := decode(c, &in); err != nil {
+		return fail(c, err)
+	}
+	out, err := h.svc.ReplaceGroup(c.Context(), c.Params("id"), &in)
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) PatchGroup(c *fiber.Ctx) error {
+	var in dto.PatchRequest
+	if err := decode(c, &in); err != nil {
+		return fail(c, err)
+	}
+	out, err := h.svc.PatchGroup(c.Context(), c.Params("id"), in.Operations)
+	if err != nil {
+		return fail(c, err)
+	}
+	return send(c, fiber.StatusOK, out)
+}
+
+func (h *HTTPHandler) DeleteGroup(c *fiber.Ctx) error {
+	if err := h.svc.DeleteGroup(c.Context(), c.Params("id")); err != nil {
+		return fail(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ServiceProviderConfig บอก IdP ว่าเรารองรับอะไร (patch + filter แบบ eq, ไม่มี bulk/sort/etag)
+func (h *HTTPHandler) ServiceProviderConfig(c *fiber.Ctx) error {
+	return send(c, fiber.StatusOK, fiber.Map{
+		"schemas":        []string{dto.SchemaProviderConfig},
+		"patch":          fiber.Map{"supported": true},
+		"bulk":           fiber.Map{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         fiber.Map{"supported": true, "maxResults": 200},
+		"changePassword": fiber.Map{"supported": false},
+		"sort":           fiber.Map{"supported": false},
+		"etag":           fiber.Map{"supported": false},
+		"authenticationSchemes": []fiber.Map{{
+			"type":        "oauthbearertoken",
+			"name":        "Bearer Token",
+			"description": "Authorization: Bearer <SCIM token>",
+		}},
+	})
+}
+
+// ===================== helpers =====================
+
+// pageParams startIndex เริ่มที่ 1; ไม่ส่ง count = 100
+func pageParams(c *fiber.Ctx) (start, count int) {
+	start, _ = strconv.Atoi(c.Query("startIndex", "1"))
+	count, err := strconv.Atoi(c.Query("count", "100"))
+	if err != nil {
+		count = 100
+	}
+	return start, count
+}
+
+func withMembers(c *fiber.Ctx) bool {
+	return !strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+}
+
+// decode อ่าน body เอง เพราะ BodyParser ไม่รู้จัก application/scim+json
+func decode(c *fiber.Ctx, v any) error {
+	if err := json.Unmarshal(c.Body(), v); err != nil {
+		return &scimservice.Error{Status: fiber.StatusBadRequest, ScimType: "invalidSyntax", Detail: "invalid JSON body"}
+	}
+	return nil
+}
+
+func send(c *fiber.Ctx, status int, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	return c.Status(status).Send(b)
+}
+
+func sendError(c *fiber.Ctx, status int, scimType, detail string) error {
+	return send(c, status, dto.Error{
+		Schemas:  []string{dto.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
+
+func fail(c *fiber.Ctx, err error) error {
+	var se *scimservice.Error
+	if errors.As(err, &se) {
+		return sendError(c, se.Status, se.ScimType, se.Detail)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return sendError(c, fiber.StatusNotFound, "", "resource not found")
+	}
+	return sendError(c, fiber.StatusInternalServerError, "", err.Error())
+}
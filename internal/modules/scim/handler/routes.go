@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/scim"
+)
+
+// Register mount ที่ /scim/v2 (นอก /api/v1 เพราะ IdP/HRIS คาด path มาตรฐาน)
+// ไม่ได้ตั้ง token = ไม่เปิด endpoint
+func Register(r fiber.Router, h *HTTPHandler, cfg scim.Config) {
+	if len(cfg.Tokens) == 0 {
+		return
+	}
+	g := r.Group("/scim/v2", bearerAuth(cfg.Tokens))
+	g.Get("/ServiceProviderConfig", h.ServiceProviderConfig)
+
+	g.Get("/Users", h.ListUsers)
+	g.Post("/Users", h.CreateUser)
+	g.Get("/Users/:id", h.GetUser)
+	g.Put("/Users/:id", h.ReplaceUser)
+	g.Patch("/Users/:id", h.PatchUser)
+	g.Delete("/Users/:id", h.DeleteUser)
+
+	g.Get("/Groups", h.ListGroups)
+	g.Post("/Groups", h.CreateGroup)
+	g.Get("/Groups/:id", h.GetGroup)
+	g.Put("/Groups/:id", h.ReplaceGroup)
+	g.Patch("/Groups/:id", h.PatchGroup)
+	g.Delete("/Groups/:id", h.DeleteGroup)
+}
+
+// bearerAuth เทียบ Authorization: Bearer <token> กับ token ที่ตั้งไว้แบบ constant time
+func bearerAuth(tokens []string) fiber.Handler {
+	sums := make([][sha256.Size]byte, 0, len(tokens))
+	for _, t := range tokens {
+		sums = append(sums, sha256.Sum256([]byte(t)))
+	}
+	return func(c *fiber.Ctx) error {
+		if tok, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer "); ok && tok != "" {
+			got := sha256.Sum256([]byte(tok))
+			match := 0
+			for _, s := range sums {
+				match |= subtle.ConstantTimeCompare(got[:], s[:])
+			}
+			if match == 1 {
+				c.Locals("principal", "scim")
+				return c.Next()
+			}
+		}
+		c.Set(fiber.HeaderWWWAuthenticate, `Bearer realm="scim"`)
+		return sendError(c, fiber.StatusUnauthorized, "", "invalid or missing bearer token")
+	}
+}
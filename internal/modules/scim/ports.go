@@ -0,0 +1,37 @@
+package scim
+
+import (
+	"context"
+
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+)
+
+// Config ตั้งค่า SCIM (โหลดจาก config.SCIM); Tokens ว่าง = ปิด endpoint
+type Config struct {
+	Tokens  []string // bearer token ที่ระบบ HR ใช้ (หลายตัวได้ ไว้หมุน token)
+	BaseURL string   // prefix ของ meta.location เช่น https://lms.example.com/scim/v2
+}
+
+// SessionRevoker ปิด session ทั้งหมดของ user (implement โดย auth service)
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID string) error
+}
+
+// Service SCIM 2.0 (RFC 7643/7644) สำหรับ sync คน/แผนกจากระบบ HR
+// Users = user, Groups = department, สมาชิกของ group = UserDepartmentRole
+type Service interface {
+	ListUsers(ctx context.Context, filter string, startIndex, count int) (*dto.ListResponse, error)
+	GetUser(ctx context.Context, id string) (*dto.User, error)
+	CreateUser(ctx context.Context, in *dto.User) (*dto.User, error)
+	ReplaceUser(ctx context.Context, id string, in *dto.User) (*dto.User, error)
+	PatchUser(ctx context.Context, id string, ops []dto.PatchOperation) (*dto.User, error)
+	DeleteUser(ctx context.Context, id string) error
+
+	// withMembers=false เมื่อ client ส่ง excludedAttributes=members (ไม่ต้องโหลดสมาชิก)
+	ListGroups(ctx context.Context, filter string, startIndex, count int, withMembers bool) (*dto.ListResponse, error)
+	GetGroup(ctx context.Context, id string, withMembers bool) (*dto.Group, error)
+	CreateGroup(ctx context.Context, in *dto.Group) (*dto.Group, error)
+	ReplaceGroup(ctx context.Context, id string, in *dto.Group) (*dto.Group, error)
+	PatchGroup(ctx context.Context, id string, ops []dto.PatchOperation) (*dto.Group, error)
+	DeleteGroup(ctx context.Context, id string) error
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+)
+
+// Error error ตาม RFC 7644 §3.12 (handler แปลงเป็น dto.Error)
+type Error struct {
+	Status   int
+	ScimType string // invalidFilter, invalidValue, uniqueness, ... (ว่างได้)
+	Detail   string
+}
+
+func (e *Error) Error() string { return e.Detail }
+
+func badRequest(scimType, detail string) error {
+	return &Error{Status: 400, ScimType: scimType, Detail: detail}
+}
+
+func conflict(detail string) error {
+	return &Error{Status: 409, ScimType: "uniqueness", Detail: detail}
+}
+
+func forbidden(detail string) error {
+	return &Error{Status: 403, Detail: detail}
+}
+
+func notFound(resource, id string) error {
+	return &Error{Status: 404, Detail: fmt.Sprintf("%s %s not found", resource, id)}
+}
+
+// รองรับเฉพาะ `<attr> eq "<value>"` ซึ่งเป็นรูปแบบที่ IdP ใช้หา resource ก่อนสร้าง
+var eqFilter = regexp.MustCompile(`(?i)^\s*([a-z0-9_.:$-]+)\s+eq\s+"((?:[^"\\]|\\.)*)"\s*$`)
+
+// parseFilter คืน attribute (normalize แล้ว) กับค่า; filter ว่าง = attr ว่าง
+func parseFilter(filter string) (attr, value string, err error) {
+	if strings.TrimSpace(filter) == "" {
+		return "", "", nil
+	}
+	m := eqFilter.FindStringSubmatch(filter)
+	if m == nil {
+		return "", "", badRequest("invalidFilter", `only filters of the form <attribute> eq "<value>" are supported`)
+	}
+	value, err = strconv.Unquote(`"` + m[2] + `"`)
+	if err != nil {
+		return "", "", badRequest("invalidFilter", "invalid filter value")
+	}
+	return normalizeAttr(m[1]), value, nil
+}
+
+var (
+	coreSchemaPrefix = regexp.MustCompile(`^urn:ietf:params:scim:schemas:core:2\.0:(user|group):`)
+	valueFilter      = regexp.MustCompile(`\[[^\]]*\]`)
+	enterpriseURN    = strings.ToLower(dto.SchemaEnterpriseUser)
+)
+
+// normalizeAttr ตัด schema URN และ value filter ออก เช่น
+// emails[type eq "work"].value -> emails.value, urn:...:enterprise:2.0:User:employeeNumber -> employeenumber
+func normalizeAttr(p string) string {
+	p = strings.ToLower(strings.TrimSpace(p))
+	if p == enterpriseURN {
+		return p
+	}
+	p = strings.TrimPrefix(p, enterpriseURN+":")
+	p = coreSchemaPrefix.ReplaceAllString(p, "")
+	return valueFilter.ReplaceAllString(p, "")
+}
+
+// window แปลง startIndex (เริ่มที่ 1) / count เป็น limit/offset
+func window(startIndex, count int) (limit, offset, start int) {
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > 200 {
+		count = 200
+	}
+	return count, startIndex - 1, startIndex
+}
+
+func listResponse(total int64, start int, resources any, n int) *dto.ListResponse {
+	return &dto.ListResponse{
+		Schemas:      []string{dto.SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   start,
+		ItemsPerPage: n,
+		Resources:    resources,
+	}
+}
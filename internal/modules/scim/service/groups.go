@@ -0,0 +1,380 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	userservice "github.com/Marugo/birdlax/internal/modules/user/service"
+)
+
+func (s *svc) ListGroups(ctx context.Context, filter string, startIndex, count int, withMembers bool) (*dto.ListResponse, error) {
+	attr, value, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	switch attr {
+	case "", "displayname", "externalid", "id":
+	default:
+		return nil, badRequest("invalidFilter", "unsupported filter attribute: "+attr)
+	}
+	limit, offset, start := window(startIndex, count)
+
+	// จำนวนแผนกไม่มาก กรอง/แบ่งหน้าใน memory ได้
+	all, err := s.users.ListDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	var matched []usermodels.Department
+	for _, d := range all {
+		switch attr {
+		case "displayname":
+			if !strings.EqualFold(d.Name, value) {
+				continue
+			}
+		case "externalid":
+			if d.ExternalID == nil || *d.ExternalID != value {
+				continue
+			}
+		case "id":
+			if d.ID != value {
+				continue
+			}
+		}
+		matched = append(matched, d)
+	}
+
+	items := []*dto.Group{}
+	for i := offset; i < len(matched) && len(items) < limit; i++ {
+		g, err := s.groupResource(ctx, &matched[i], withMembers)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, g)
+	}
+	return listResponse(int64(len(matched)), start, items, len(items)), nil
+}
+
+func (s *svc) GetGroup(ctx context.Context, id string, withMembers bool) (*dto.Group, error) {
+	d, err := s.loadGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.groupResource(ctx, d, withMembers)
+}
+
+// CreateGroup แผนกใหม่ใช้ externalId เป็น code (ไม่มีก็ใช้ displayName)
+func (s *svc) CreateGroup(ctx context.Context, in *dto.Group) (*dto.Group, error) {
+	d := &usermodels.Department{IsActive: true}
+	if err := applyGroup(d, in); err != nil {
+		return nil, err
+	}
+	d.Code = d.Name
+	if d.ExternalID != nil {
+		d.Code = *d.ExternalID
+	}
+	if utf8.RuneCountInString(d.Code) > 50 {
+		d.Code = string([]rune(d.Code)[:50])
+	}
+	if err := s.checkGroupUnique(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := s.users.CreateDepartment(ctx, d); err != nil {
+		return nil, err
+	}
+	if len(in.Members) > 0 {
+		if err := s.syncMembers(ctx, d.ID, memberIDs(in.Members)); err != nil {
+			return nil, err
+		}
+	}
+	return s.groupResource(ctx, d, true)
+}
+
+// ReplaceGroup ไม่ส่ง members มา = ไม่แตะสมาชิก; ส่ง [] = ล้างสมาชิกทั้งหมด
+func (s *svc) ReplaceGroup(ctx context.Context, id string, in *dto.Group) (*dto.Group, error) {
+	d, err := s.loadGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyGroup(d, in); err != nil {
+		return nil, err
+	}
+	if err := s.checkGroupUnique(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := s.users.UpdateDepartment(ctx, d); err != nil {
+		return nil, err
+	}
+	if in.Members != nil {
+		if err := s.syncMembers(ctx, d.ID, memberIDs(in.Members)); err != nil {
+			return nil, err
+		}
+	}
+	return s.groupResource(ctx, d, true)
+}
+
+// members[value eq "<user id>"] ที่ Azure AD ใช้ตอน remove ทีละคน
+var memberPath = regexp.MustCompile(`(?i)^\s*members\[\s*value\s+eq\s+"([^"]+)"\s*\]\s*$`)
+
+func (s *svc) PatchGroup(ctx context.Context, id string, ops []dto.PatchOperation) (*dto.Group, error) {
+	d, err := s.loadGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := &dto.Group{DisplayName: d.Name}
+	if d.ExternalID != nil {
+		res.ExternalID = *d.ExternalID
+	}
+	for _, op := range ops {
+		err := eachPatchAttr(op, func(kind, path string, v json.RawMessage) error {
+			if m := memberPath.FindStringSubmatch(path); m != nil {
+				if kind != "remove" {
+					return badRequest("invalidPath", "member filter is only supported for remove")
+				}
+				return s.removeMembers(ctx, d.ID, []string{m[1]})
+			}
+			switch normalizeAttr(path) {
+			case "displayname":
+				return stringValue(v, kind == "remove", &res.DisplayName)
+			case "externalid":
+				return stringValue(v, kind == "remove", &res.ExternalID)
+			case "members":
+				var vals []dto.MultiValue
+				if len(v) > 0 {
+					if err := json.Unmarshal(v, &vals); err != nil {
+						return badRequest("invalidValue", "members must be an array")
+					}
+				}
+				switch {
+				case kind == "add":
+					return s.addMembers(ctx, d.ID, memberIDs(vals))
+				case kind == "remove" && len(vals) > 0:
+					return s.removeMembers(ctx, d.ID, memberIDs(vals))
+				default: // replace หรือ remove ทั้งหมด
+					return s.syncMembers(ctx, d.ID, memberIDs(vals))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyGroup(d, res); err != nil {
+		return nil, err
+	}
+	if err := s.checkGroupUnique(ctx, d); err != nil {
+		return nil, err
+	}
+	if err := s.users.UpdateDepartment(ctx, d); err != nil {
+		return nil, err
+	}
+	return s.groupResource(ctx, d, true)
+}
+
+// DeleteGroup ลบสมาชิกทั้งหมดก่อน แล้วค่อย soft delete แผนกผ่าน user service (แผนกที่ยังมีแผนกลูกลบไม่ได้)
+func (s *svc) DeleteGroup(ctx context.Context, id string) error {
+	d, err := s.loadGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+	// เช็กก่อนจบสังกัดสมาชิก ไม่งั้นลบไม่สำเร็จแต่สมาชิกหายไปแล้ว
+	children, err := s.userSvc.ListDepartmentDescendants(ctx, d.ID)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return groupHasChildren(d.ID)
+	}
+	if err := s.syncMembers(ctx, d.ID, nil); err != nil {
+		return err
+	}
+	if err := s.userSvc.DeleteDepartment(ctx, d.ID); err != nil {
+		if errors.Is(err, userservice.ErrDepartmentHasChildren) {
+			return groupHasChildren(d.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+func groupHasChildren(id string) error {
+	return &Error{Status: 409, Detail: "group " + id + " still has sub-groups"}
+}
+
+func (s *svc) loadGroup(ctx context.Context, id string) (*usermodels.Department, error) {
+	d, err := s.users.GetDepartmentByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound("Group", id)
+	}
+	return d, err
+}
+
+func applyGroup(d *usermodels.Department, in *dto.Group) error {
+	name := strings.TrimSpace(in.DisplayName)
+	if name == "" {
+		return badRequest("invalidValue", "displayName is required")
+	}
+	if utf8.RuneCountInString(name) > 100 {
+		return badRequest("invalidValue", "displayName is too long")
+	}
+	d.Name = name
+	d.ExternalID = nil
+	if ext := strings.TrimSpace(in.ExternalID); ext != "" {
+		d.ExternalID = &ext
+	}
+	return nil
+}
+
+func (s *svc) checkGroupUnique(ctx context.Context, d *usermodels.Department) error {
+	all, err := s.users.ListDepartments(ctx, "")
+	if err != nil {
+		return err
+	}
+	for _, o := range all {
+		if o.ID == d.ID {
+			continue
+		}
+		if strings.EqualFold(o.Code, d.Code) {
+			return conflict("group " + d.Code + " already exists")
+		}
+		if d.ExternalID != nil && o.ExternalID != nil && *o.ExternalID == *d.ExternalID {
+			return conflict("externalId " + *d.ExternalID + " is already in use")
+		}
+	}
+	return nil
+}
+
+// ===== membership (สมาชิก group = UserDepartmentRole) =====
+// คนที่เพิ่มผ่าน SCIM ได้ role member; role ที่ตั้งในระบบเอง (manager/leader) ไม่ถูกเปลี่ยน
+
+// memberRels user id -> relation ทั้งหมดของ user นั้นในแผนก
+func (s *svc) memberRels(ctx context.Context, departmentID string) (map[string][]string, error) {
+	rows, err := s.users.ListDepartmentMembers(ctx, departmentID)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string][]string{}
+	for _, r := range rows {
+		out[r.UserID] = append(out[r.UserID], r.ID)
+	}
+	return out, nil
+}
+
+func (s *svc) addMembers(ctx context.Context, departmentID string, userIDs []string) error {
+	cur, err := s.memberRels(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+	for _, id := range userIDs {
+		if _, ok := cur[id]; ok {
+			continue
+		}
+		rel, err := s.userSvc.AddUserDepartmentRole(ctx, id, departmentID, string(usermodels.DeptRoleMember), nil)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return badRequest("invalidValue", "member "+id+" does not exist")
+			}
+			return err
+		}
+		cur[id] = []string{rel.ID}
+	}
+	return nil
+}
+
+func (s *svc) removeMembers(ctx context.Context, departmentID string, userIDs []string) error {
+	cur, err := s.memberRels(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+	// จบสังกัด ณ ตอนที่ HR sync มา (ประวัติยังอยู่)
+	for _, id := range userIDs {
+		for _, relID := range cur[id] {
+			if err := s.userSvc.EndUserDepartmentRole(ctx, id, relID, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// syncMembers ให้สมาชิกเหลือเท่ากับ userIDs พอดี
+func (s *svc) syncMembers(ctx context.Context, departmentID string, userIDs []string) error {
+	cur, err := s.memberRels(ctx, departmentID)
+	if err != nil {
+		return err
+	}
+	want := map[string]bool{}
+	for _, id := range userIDs {
+		want[id] = true
+	}
+	var drop []string
+	for id := range cur {
+		if !want[id] {
+			drop = append(drop, id)
+		}
+	}
+	if err := s.removeMembers(ctx, departmentID, drop); err != nil {
+		return err
+	}
+	return s.addMembers(ctx, departmentID, userIDs)
+}
+
+func memberIDs(vals []dto.MultiValue) []string {
+	out := make([]string, 0, len(vals))
+	seen := map[string]bool{}
+	for _, v := range vals {
+		id := strings.TrimSpace(v.Value)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
+
+func (s *svc) groupResource(ctx context.Context, d *usermodels.Department, withMembers bool) (*dto.Group, error) {
+	g := &dto.Group{
+		Schemas:     []string{dto.SchemaGroup},
+		ID:          d.ID,
+		DisplayName: d.Name,
+		Meta: &dto.Meta{
+			ResourceType: "Group",
+			Created:      &d.CreatedAt,
+			LastModified: &d.UpdatedAt,
+			Location:     s.location("Groups", d.ID),
+		},
+	}
+	if d.ExternalID != nil {
+		g.ExternalID = *d.ExternalID
+	}
+	if !withMembers {
+		return g, nil
+	}
+	rows, err := s.users.ListDepartmentMembers(ctx, d.ID)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, r := range rows {
+		if seen[r.UserID] {
+			continue
+		}
+		seen[r.UserID] = true
+		m := dto.MultiValue{Value: r.UserID, Ref: s.location("Users", r.UserID)}
+		if r.User != nil {
+			m.Display = strings.TrimSpace(r.User.FirstName + " " + r.User.LastName)
+		}
+		g.Members = append(g.Members, m)
+	}
+	return g, nil
+}
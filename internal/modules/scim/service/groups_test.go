@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	userservice "github.com/Marugo/birdlax/internal/modules/user/service"
+)
+
+type fakeGroupRepo struct {
+	fakeUserRepo
+	depts   map[string]*usermodels.Department
+	members map[string][]usermodels.UserDepartmentRole // department id -> สังกัดปัจจุบัน
+}
+
+func (f *fakeGroupRepo) GetDepartmentByID(_ context.Context, id string) (*usermodels.Department, error) {
+	d, ok := f.depts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return d, nil
+}
+
+func (f *fakeGroupRepo) ListDepartmentMembers(_ context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error) {
+	return f.members[departmentID], nil
+}
+
+// fakeDepartments user service แค่ส่วนที่ group ใช้; parent id -> ลูก
+// สังกัดเปลี่ยนผ่านที่นี่เท่านั้น (repo ไม่มี Add/End ให้เรียกตรง)
+type fakeDepartments struct {
+	user.Service
+	repo     *fakeGroupRepo
+	children map[string][]usermodels.Department
+	deleted  []string
+	added    []usermodels.UserDepartmentRole
+	ended    []string
+}
+
+func (f *fakeDepartments) AddUserDepartmentRole(_ context.Context, userID, departmentID, role string, _ *time.Time) (*usermodels.UserDepartmentRole, error) {
+	if _, ok := f.repo.byID[userID]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	rel := usermodels.UserDepartmentRole{ID: "new-" + userID, UserID: userID, DepartmentID: departmentID, Role: usermodels.DepartmentRole(role)}
+	f.added = append(f.added, rel)
+	f.repo.members[departmentID] = append(f.repo.members[departmentID], rel)
+	return &rel, nil
+}
+
+func (f *fakeDepartments) EndUserDepartmentRole(_ context.Context, userID, relID string, at *time.Time) error {
+	if at != nil {
+		return errors.New("scim should end memberships now")
+	}
+	for dept, rels := range f.repo.members {
+		for i, r := range rels {
+			if r.ID == relID && r.UserID == userID {
+				f.repo.members[dept] = append(rels[:i:i], rels[i+1:]...)
+				f.ended = append(f.ended, relID)
+				return nil
+			}
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func (f *fakeDepartments) ListDepartmentDescendants(_ context.Context, id string) ([]usermodels.Department, error) {
+	return f.children[id], nil
+}
+
+func (f *fakeDepartments) DeleteDepartment(_ context.Context, id string) error {
+	if len(f.children[id]) > 0 {
+		return userservice.ErrDepartmentHasChildren
+	}
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func TestDeleteGroupGoesThroughUserService(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeGroupRepo{
+		depts: map[string]*usermodels.Department{
+			"parent": {ID: "parent", Code: "P", Name: "Parent"},
+			"leaf":   {ID: "leaf", Code: "L", Name: "Leaf"},
+		},
+		members: map[string][]usermodels.UserDepartmentRole{
+			"parent": {{ID: "r1", UserID: "u1", DepartmentID: "parent"}},
+			"leaf":   {{ID: "r2", UserID: "u2", DepartmentID: "leaf"}},
+		},
+	}
+	depts := &fakeDepartments{repo: repo, children: map[string][]usermodels.Department{"parent": {{ID: "leaf"}}}}
+	s := &svc{users: repo, userSvc: depts}
+
+	if err := s.DeleteGroup(ctx, "parent"); scimStatus(err) != 409 {
+		t.Fatalf("parent: err = %v", err)
+	}
+	if len(depts.ended) != 0 || len(depts.deleted) != 0 {
+		t.Fatalf("parent touched: ended=%v deleted=%v", depts.ended, depts.deleted)
+	}
+
+	if err := s.DeleteGroup(ctx, "leaf"); err != nil {
+		t.Fatalf("leaf: %v", err)
+	}
+	if len(depts.ended) != 1 || depts.ended[0] != "r2" || len(depts.deleted) != 1 || depts.deleted[0] != "leaf" {
+		t.Fatalf("leaf: ended=%v deleted=%v", depts.ended, depts.deleted)
+	}
+
+	if err := s.DeleteGroup(ctx, "nope"); scimStatus(err) != 404 {
+		t.Fatalf("missing: err = %v", err)
+	}
+}
+
+func TestGroupMembershipGoesThroughUserService(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeGroupRepo{
+		depts: map[string]*usermodels.Department{"d1": {ID: "d1", Code: "D1", Name: "Dept"}},
+		members: map[string][]usermodels.UserDepartmentRole{
+			"d1": {{ID: "r1", UserID: "u1", DepartmentID: "d1", Role: usermodels.DeptRoleManager}},
+		},
+	}
+	repo.byID = map[string]*usermodels.User{"u1": {ID: "u1"}, "u2": {ID: "u2"}}
+	depts := &fakeDepartments{repo: repo}
+	s := &svc{users: repo, userSvc: depts}
+
+	// u1 อยู่แล้ว (role เดิมไม่ถูกเปลี่ยน), u2 เพิ่มเป็น member
+	if err := s.addMembers(ctx, "d1", []string{"u1", "u2"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(depts.added) != 1 || depts.added[0].UserID != "u2" || depts.added[0].Role != usermodels.DeptRoleMember {
+		t.Fatalf("added = %+v", depts.added)
+	}
+	if err := s.addMembers(ctx, "d1", []string{"ghost"}); scimStatus(err) != 400 {
+		t.Fatalf("unknown member: err = %v", err)
+	}
+
+	if err := s.syncMembers(ctx, "d1", []string{"u2"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(depts.ended) != 1 || depts.ended[0] != "r1" {
+		t.Fatalf("ended = %v", depts.ended)
+	}
+	if got := repo.members["d1"]; len(got) != 1 || got[0].UserID != "u2" {
+		t.Fatalf("members = %+v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+)
+
+// opKind add/replace/remove (Azure AD ส่งมาเป็น "Replace")
+func opKind(op string) (string, error) {
+	k := strings.ToLower(strings.TrimSpace(op))
+	switch k {
+	case "add", "replace", "remove":
+		return k, nil
+	}
+	return "", badRequest("invalidSyntax", "unsupported patch op: "+op)
+}
+
+// eachPatchAttr เรียก fn ต่อ attribute: มี path = ตัวเดียว, ไม่มี path = value เป็น object {attr: value}
+func eachPatchAttr(op dto.PatchOperation, fn func(kind, path string, v json.RawMessage) error) error {
+	kind, err := opKind(op.Op)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(op.Path) != "" {
+		return fn(kind, op.Path, op.Value)
+	}
+	if kind == "remove" {
+		return badRequest("noTarget", "remove requires a path")
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(op.Value, &obj); err != nil {
+		return badRequest("invalidValue", "value must be an object when path is omitted")
+	}
+	for k, v := range obj {
+		if err := fn(kind, k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func patchUser(res *dto.User, op dto.PatchOperation) error {
+	return eachPatchAttr(op, func(kind, path string, v json.RawMessage) error {
+		return setUserAttr(res, kind, path, v)
+	})
+}
+
+// setUserAttr แก้ resource ตาม path; attribute ที่เราไม่ได้เก็บข้ามไปเฉยๆ
+// (IdP ส่ง title, addresses, ... มาด้วยเสมอ ถ้าตอบ error จะ sync ทั้งคนไม่ผ่าน)
+func setUserAttr(res *dto.User, kind, path string, v json.RawMessage) error {
+	remove := kind == "remove"
+	switch attr := normalizeAttr(path); attr {
+	case "active":
+		if remove {
+			return nil
+		}
+		b, err := boolValue(v)
+		if err != nil {
+			return err
+		}
+		res.Active = &b
+	case "username":
+		return stringValue(v, remove, &res.UserName)
+	case "externalid":
+		return stringValue(v, remove, &res.ExternalID)
+	case "displayname":
+		return stringValue(v, remove, &res.DisplayName)
+	case "name":
+		if remove {
+			res.Name = nil
+			return nil
+		}
+		var n dto.Name
+		if err := json.Unmarshal(v, &n); err != nil {
+			return badRequest("invalidValue", "name must be an object")
+		}
+		cur := userName(res)
+		if n.GivenName != "" || kind == "replace" {
+			cur.GivenName = n.GivenName
+		}
+		if n.FamilyName != "" || kind == "replace" {
+			cur.FamilyName = n.FamilyName
+		}
+		cur.Formatted = n.Formatted
+	case "name.givenname":
+		return stringValue(v, remove, &userName(res).GivenName)
+	case "name.familyname":
+		return stringValue(v, remove, &userName(res).FamilyName)
+	case "name.formatted":
+		return stringValue(v, remove, &userName(res).Formatted)
+	case "phonenumbers":
+		if remove {
+			res.PhoneNumbers = nil
+			return nil
+		}
+		var vals []dto.MultiValue
+		if err := json.Unmarshal(v, &vals); err != nil {
+			return badRequest("invalidValue", "phoneNumbers must be an array")
+		}
+		res.PhoneNumbers = vals
+	case "phonenumbers.value":
+		var phone string
+		if err := stringValue(v, remove, &phone); err != nil {
+			return err
+		}
+		res.PhoneNumbers = nil
+		if phone != "" {
+			res.PhoneNumbers = []dto.MultiValue{{Value: phone, Type: "work", Primary: true}}
+		}
+	case "employeenumber":
+		if res.Enterprise == nil {
+			res.Enterprise = &dto.EnterpriseUser{}
+		}
+		return stringValue(v, remove, &res.Enterprise.EmployeeNumber)
+	case enterpriseURN:
+		// value เป็น object ของ extension ทั้งก้อน {"employeeNumber": "..."}
+		if remove {
+			res.Enterprise = nil
+			return nil
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(v, &obj); err != nil {
+			return badRequest("invalidValue", dto.SchemaEnterpriseUser+" must be an object")
+		}
+		for k, sub := range obj {
+			if err := setUserAttr(res, kind, k, sub); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func userName(res *dto.User) *dto.Name {
+	if res.Name == nil {
+		res.Name = &dto.Name{}
+	}
+	return res.Name
+}
+
+// stringValue remove = ค่าว่าง; บาง IdP ห่อค่ามาเป็น [{"value": "..."}]
+func stringValue(v json.RawMessage, remove bool, dst *string) error {
+	if remove {
+		*dst = ""
+		return nil
+	}
+	if err := json.Unmarshal(v, dst); err == nil {
+		return nil
+	}
+	var vals []dto.MultiValue
+	if err := json.Unmarshal(v, &vals); err == nil && len(vals) > 0 {
+		*dst = vals[0].Value
+		return nil
+	}
+	return badRequest("invalidValue", "expected a string value")
+}
+
+// boolValue รับทั้ง true และ "True" (Azure AD ส่งเป็น string)
+func boolValue(v json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(v, &b); err == nil {
+		return b, nil
+	}
+	var s string
+	if err := json.Unmarshal(v, &s); err == nil {
+		if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+			return b, nil
+		}
+	}
+	return false, badRequest("invalidValue", "expected a boolean value")
+}
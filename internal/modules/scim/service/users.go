@@ -0,0 +1,378 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/scim"
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/identity"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+type svc struct {
+	users    user.Repository
+	userSvc  user.Service // กติกาของแผนก/สังกัด (ลบแผนก, ประวัติสมาชิก) ใช้ร่วมกับ API ปกติ
+	sessions scim.SessionRevoker
+	baseURL  string
+}
+
+func NewService(r user.Repository, us user.Service, sessions scim.SessionRevoker, baseURL string) scim.Service {
+	return &svc{users: r, userSvc: us, sessions: sessions, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (s *svc) ListUsers(ctx context.Context, filter string, startIndex, count int) (*dto.ListResponse, error) {
+	attr, value, err := parseFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	limit, offset, start := window(startIndex, count)
+
+	var rows []usermodels.User
+	var total int64
+	if attr == "" {
+		// count=0 ขอแค่ totalResults
+		rows, total, err = s.users.FindAll(ctx, user.ListFilter{Desc: true}, max(limit, 1), offset)
+		if err != nil {
+			return nil, err
+		}
+		if limit == 0 {
+			rows = nil
+		}
+	} else {
+		u, err := s.findUserBy(ctx, attr, value)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if u != nil {
+			total = 1
+			if offset == 0 && limit > 0 {
+				rows = []usermodels.User{*u}
+			}
+		}
+	}
+
+	items, err := s.toUsers(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+	return listResponse(total, start, items, len(items)), nil
+}
+
+func (s *svc) findUserBy(ctx context.Context, attr, value string) (*usermodels.User, error) {
+	switch attr {
+	case "username", "emails", "emails.value":
+		return s.users.FindByEmail(ctx, strings.ToLower(strings.TrimSpace(value)))
+	case "externalid":
+		return s.users.FindByExternalID(ctx, value)
+	case "employeenumber":
+		return s.users.FindByEmployeeCode(ctx, strings.TrimSpace(value))
+	case "id":
+		return s.users.FindByID(ctx, value)
+	}
+	return nil, badRequest("invalidFilter", "unsupported filter attribute: "+attr)
+}
+
+func (s *svc) GetUser(ctx context.Context, id string) (*dto.User, error) {
+	u, err := s.loadUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.userResource(ctx, u)
+}
+
+// CreateUser user ใหม่เป็น employee เสมอ (role จัดการในระบบเรา ไม่ใช่จาก HR)
+// และไม่มีรหัสผ่านที่ใช้ได้ ต้อง login ผ่าน SSO/LDAP หรือตั้งรหัสด้วย forgot-password
+func (s *svc) CreateUser(ctx context.Context, in *dto.User) (*dto.User, error) {
+	u := &usermodels.User{Role: usermodels.RoleEmployee, IsActive: true}
+	if err := applyUser(u, in); err != nil {
+		return nil, err
+	}
+	if err := s.checkUserUnique(ctx, u); err != nil {
+		return nil, err
+	}
+	hash, err := password.Hash(identity.RandomSecret())
+	if err != nil {
+		return nil, err
+	}
+	u.PasswordHash = hash
+	if err := s.users.Create(ctx, u); err != nil {
+		return nil, err
+	}
+	return s.toUser(u, nil), nil
+}
+
+func (s *svc) ReplaceUser(ctx context.Context, id string, in *dto.User) (*dto.User, error) {
+	u, err := s.loadUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveUser(ctx, u, in)
+}
+
+// PatchUser apply operation ลงบน resource ปัจจุบัน แล้วบันทึกแบบเดียวกับ PUT
+func (s *svc) PatchUser(ctx context.Context, id string, ops []dto.PatchOperation) (*dto.User, error) {
+	u, err := s.loadUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	res := s.toUser(u, nil)
+	for _, op := range ops {
+		if err := patchUser(res, op); err != nil {
+			return nil, err
+		}
+	}
+	return s.saveUser(ctx, u, res)
+}
+
+// DeleteUser = ปิด account + revoke session แล้ว soft delete (ประวัติการเรียนยังอยู่)
+func (s *svc) DeleteUser(ctx context.Context, id string) error {
+	u, err := s.loadUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	if u.IsActive {
+		u.IsActive = false
+		if err := s.users.Update(ctx, u, "is_active"); err != nil {
+			return err
+		}
+	}
+	if err := s.sessions.RevokeAllSessions(ctx, u.ID); err != nil {
+		return err
+	}
+	return s.users.Delete(ctx, u.ID)
+}
+
+func (s *svc) saveUser(ctx context.Context, u *usermodels.User, in *dto.User) (*dto.User, error) {
+	wasActive, oldEmail, oldCode, oldExternal := u.IsActive, u.Email, u.EmployeeCode, u.ExternalID
+	if err := applyUser(u, in); err != nil {
+		return nil, err
+	}
+	// email / employee code / externalId ใช้ผูก SSO และรีเซ็ตรหัสผ่าน: ถ้า HR เปลี่ยนของ admin/HR ได้
+	// = ยึดบัญชีสิทธิ์สูงได้ (เช่นชี้ employee code ไปที่ identity ใน IdP ที่ตัวเองคุม) ต้องแก้ในระบบเราเท่านั้น
+	if usermodels.IsAtLeast(u.Role, usermodels.RoleHR) {
+		switch {
+		case u.Email != oldEmail:
+			return nil, forbidden("userName of a privileged user cannot be changed via SCIM")
+		case u.EmployeeCode != oldCode:
+			return nil, forbidden("employeeNumber of a privileged user cannot be changed via SCIM")
+		case !sameString(u.ExternalID, oldExternal):
+			return nil, forbidden("externalId of a privileged user cannot be changed via SCIM")
+		}
+	}
+	if err := s.checkUserUnique(ctx, u); err != nil {
+		return nil, err
+	}
+	if err := s.users.Update(ctx, u, scimUserColumns...); err != nil {
+		return nil, err
+	}
+	// HR ปิด account (ลาออก/พักงาน) => refresh + access token เดิมต้องใช้ไม่ได้ทันที
+	if wasActive && !u.IsActive {
+		if err := s.sessions.RevokeAllSessions(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
+	return s.userResource(ctx, u)
+}
+
+func sameString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *svc) loadUser(ctx context.Context, id string) (*usermodels.User, error) {
+	u, err := s.users.FindByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, notFound("User", id)
+	}
+	return u, err
+}
+
+// checkUserUnique ตรวจ email/employee code/externalId ซ้ำกับคนอื่น (รวม user ที่ถูกลบแล้ว เพราะ unique index ยังชน)
+func (s *svc) checkUserUnique(ctx context.Context, u *usermodels.User) error {
+	byEmail, err := s.users.FindByEmails(ctx, []string{u.Email})
+	if err != nil {
+		return err
+	}
+	for _, o := range byEmail {
+		if o.ID != u.ID {
+			return conflict("userName " + u.Email + " is already in use")
+		}
+	}
+	byCode, err := s.users.FindByEmployeeCodes(ctx, []string{u.EmployeeCode})
+	if err != nil {
+		return err
+	}
+	for _, o := range byCode {
+		if o.ID != u.ID {
+			return conflict("employeeNumber " + u.EmployeeCode + " is already in use")
+		}
+	}
+	if u.ExternalID != nil {
+		o, err := s.users.FindByExternalID(ctx, *u.ExternalID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if o != nil && o.ID != u.ID {
+			return conflict("externalId " + *u.ExternalID + " is already in use")
+		}
+	}
+	return nil
+}
+
+// applyUser เขียนค่าจาก resource ลง model; userName = email, employeeNumber = employee_code
+// active ที่ไม่ได้ส่งมา = ไม่เปลี่ยน; attribute ที่เราไม่เก็บ (title, addresses, ...) ไม่สนใจ
+// scimUserColumns คอลัมน์ที่ applyUser เขียน (SCIM ไม่แตะ role/รหัสผ่าน/สถานะ login)
+var scimUserColumns = []string{"email", "employee_code", "first_name", "last_name", "phone", "external_id", "is_active"}
+
+func applyUser(u *usermodels.User, in *dto.User) error {
+	email := strings.ToLower(strings.TrimSpace(in.UserName))
+	if email == "" {
+		return badRequest("invalidValue", "userName is required")
+	}
+	if !identity.ValidEmail(email) {
+		return badRequest("invalidValue", "userName must be an email address")
+	}
+	var code string
+	if in.Enterprise != nil {
+		code = strings.TrimSpace(in.Enterprise.EmployeeNumber)
+	}
+	if code == "" {
+		return badRequest("invalidValue", "employeeNumber ("+dto.SchemaEnterpriseUser+") is required")
+	}
+
+	var first, last string
+	if in.Name != nil {
+		first, last = strings.TrimSpace(in.Name.GivenName), strings.TrimSpace(in.Name.FamilyName)
+		if first == "" && last == "" {
+			first, last = identity.SplitName(in.Name.Formatted)
+		}
+	}
+	if first == "" && last == "" {
+		first, last = identity.SplitName(in.DisplayName)
+	}
+	if first == "" {
+		return badRequest("invalidValue", "name.givenName is required")
+	}
+	if last == "" {
+		last = "-"
+	}
+
+	if len(email) > 190 || utf8.RuneCountInString(code) > 30 ||
+		utf8.RuneCountInString(first) > 100 || utf8.RuneCountInString(last) > 100 {
+		return badRequest("invalidValue", "attribute value is too long")
+	}
+
+	u.Email = email
+	u.EmployeeCode = code
+	u.FirstName = first
+	u.LastName = last
+	u.Phone = primaryValue(in.PhoneNumbers)
+	u.ExternalID = nil
+	if ext := strings.TrimSpace(in.ExternalID); ext != "" {
+		u.ExternalID = &ext
+	}
+	if in.Active != nil {
+		u.IsActive = *in.Active
+	}
+	return nil
+}
+
+func (s *svc) userResource(ctx context.Context, u *usermodels.User) (*dto.User, error) {
+	roles, err := s.users.ListUserDepartmentRoles(ctx, u.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toUser(u, roles), nil
+}
+
+// toUsers แปลงทั้งหน้า โดยโหลดแผนกของทุกคนใน query เดียว
+func (s *svc) toUsers(ctx context.Context, rows []usermodels.User) ([]*dto.User, error) {
+	ids := make([]string, 0, len(rows))
+	for _, u := range rows {
+		ids = append(ids, u.ID)
+	}
+	roles, err := s.users.ListDepartmentRolesForUsers(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byUser := map[string][]usermodels.UserDepartmentRole{}
+	for _, r := range roles {
+		byUser[r.UserID] = append(byUser[r.UserID], r)
+	}
+	out := make([]*dto.User, 0, len(rows))
+	for i := range rows {
+		out = append(out, s.toUser(&rows[i], byUser[rows[i].ID]))
+	}
+	return out, nil
+}
+
+func (s *svc) toUser(u *usermodels.User, roles []usermodels.UserDepartmentRole) *dto.User {
+	active := u.IsActive
+	full := strings.TrimSpace(u.FirstName + " " + u.LastName)
+	res := &dto.User{
+		Schemas:     []string{dto.SchemaUser, dto.SchemaEnterpriseUser},
+		ID:          u.ID,
+		UserName:    u.Email,
+		Name:        &dto.Name{Formatted: full, GivenName: u.FirstName, FamilyName: u.LastName},
+		DisplayName: full,
+		Active:      &active,
+		Emails:      []dto.MultiValue{{Value: u.Email, Type: "work", Primary: true}},
+		Enterprise:  &dto.EnterpriseUser{EmployeeNumber: u.EmployeeCode},
+		Meta: &dto.Meta{
+			ResourceType: "User",
+			Created:      &u.CreatedAt,
+			LastModified: &u.UpdatedAt,
+			Location:     s.location("Users", u.ID),
+		},
+	}
+	if u.ExternalID != nil {
+		res.ExternalID = *u.ExternalID
+	}
+	if u.Phone != nil && *u.Phone != "" {
+		res.PhoneNumbers = []dto.MultiValue{{Value: *u.Phone, Type: "work", Primary: true}}
+	}
+	seen := map[string]bool{}
+	for _, r := range roles {
+		if seen[r.DepartmentID] {
+			continue
+		}
+		seen[r.DepartmentID] = true
+		g := dto.MultiValue{Value: r.DepartmentID, Ref: s.location("Groups", r.DepartmentID)}
+		if r.Department != nil {
+			g.Display = r.Department.Name
+		}
+		res.Groups = append(res.Groups, g)
+	}
+	return res
+}
+
+func (s *svc) location(resource, id string) string {
+	return s.baseURL + "/" + resource + "/" + id
+}
+
+// primaryValue ค่าที่ primary=true หรือตัวแรกที่ไม่ว่าง; ไม่มี = nil
+func primaryValue(vals []dto.MultiValue) *string {
+	var out *string
+	for _, v := range vals {
+		val := strings.TrimSpace(v.Value)
+		if val == "" {
+			continue
+		}
+		if v.Primary {
+			return &val
+		}
+		if out == nil {
+			out = &val
+		}
+	}
+	return out
+}
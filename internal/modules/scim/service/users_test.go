@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/scim/dto"
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+type fakeUserRepo struct {
+	user.Repository
+	byID    map[string]*usermodels.User
+	updated []usermodels.User
+}
+
+func (f *fakeUserRepo) FindByID(_ context.Context, id string) (*usermodels.User, error) {
+	u, ok := f.byID[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *u // เหมือนโหลดจาก DB ใหม่ทุกครั้ง
+	return &cp, nil
+}
+
+func (f *fakeUserRepo) FindByEmails(context.Context, []string) ([]usermodels.User, error) {
+	return nil, nil
+}
+
+func (f *fakeUserRepo) FindByEmployeeCodes(context.Context, []string) ([]usermodels.User, error) {
+	return nil, nil
+}
+
+func (f *fakeUserRepo) FindByExternalID(context.Context, string) (*usermodels.User, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeUserRepo) Update(_ context.Context, u *usermodels.User, _ ...string) error {
+	f.updated = append(f.updated, *u)
+	return nil
+}
+
+func (f *fakeUserRepo) ListUserDepartmentRoles(context.Context, string) ([]usermodels.UserDepartmentRole, error) {
+	return nil, nil
+}
+
+func newUserFixture() (*svc, *fakeUserRepo) {
+	repo := &fakeUserRepo{byID: map[string]*usermodels.User{
+		"emp":   {ID: "emp", EmployeeCode: "E1", Email: "emp@example.com", FirstName: "E", LastName: "One", Role: usermodels.RoleEmployee, IsActive: true},
+		"hr":    {ID: "hr", EmployeeCode: "H1", Email: "hr@example.com", FirstName: "H", LastName: "One", Role: usermodels.RoleHR, IsActive: true},
+		"admin": {ID: "admin", EmployeeCode: "A1", Email: "admin@example.com", FirstName: "A", LastName: "One", Role: usermodels.RoleAdmin, IsActive: true},
+	}}
+	return &svc{users: repo}, repo
+}
+
+func scimStatus(err error) int {
+	var se *Error
+	if errors.As(err, &se) {
+		return se.Status
+	}
+	return 0
+}
+
+func TestPrivilegedEmailCannotChangeViaSCIM(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newUserFixture()
+	op := func(email string) []dto.PatchOperation {
+		v, _ := json.Marshal(email)
+		return []dto.PatchOperation{{Op: "replace", Path: "userName", Value: v}}
+	}
+
+	for _, id := range []string{"hr", "admin"} {
+		if _, err := s.PatchUser(ctx, id, op("attacker@example.com")); scimStatus(err) != 403 {
+			t.Fatalf("PATCH %s: err = %v", id, err)
+		}
+		u := repo.byID[id]
+		in := s.toUser(u, nil)
+		in.UserName = "attacker@example.com"
+		if _, err := s.ReplaceUser(ctx, id, in); scimStatus(err) != 403 {
+			t.Fatalf("PUT %s: err = %v", id, err)
+		}
+	}
+	if len(repo.updated) != 0 {
+		t.Fatalf("privileged user saved: %+v", repo.updated)
+	}
+
+	// แก้ field อื่นของ admin ได้ตามปกติ (email เดิม แค่ตัวพิมพ์ต่าง = ค่าเดิม)
+	in := s.toUser(repo.byID["admin"], nil)
+	in.UserName = "ADMIN@example.com"
+	in.Name = &dto.Name{GivenName: "Ada", FamilyName: "Min"}
+	if _, err := s.ReplaceUser(ctx, "admin", in); err != nil {
+		t.Fatalf("PUT admin name: %v", err)
+	}
+
+	// employee เปลี่ยน email ผ่าน SCIM ได้
+	res, err := s.PatchUser(ctx, "emp", op("new@example.com"))
+	if err != nil || res.UserName != "new@example.com" {
+		t.Fatalf("PATCH employee: %+v, %v", res, err)
+	}
+}
+
+// employee code ใช้จับคู่ SSO ก่อน email (oidcUser): เปลี่ยนของ admin ได้ = ยึดบัญชีผ่าน IdP ได้
+func TestPrivilegedEmployeeNumberCannotChangeViaSCIM(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newUserFixture()
+	v, _ := json.Marshal("X999")
+	op := []dto.PatchOperation{{Op: "replace", Path: "employeeNumber", Value: v}}
+
+	for _, id := range []string{"hr", "admin"} {
+		if _, err := s.PatchUser(ctx, id, op); scimStatus(err) != 403 {
+			t.Fatalf("PATCH %s: err = %v", id, err)
+		}
+		in := s.toUser(repo.byID[id], nil)
+		in.Enterprise = &dto.EnterpriseUser{EmployeeNumber: "X999"}
+		if _, err := s.ReplaceUser(ctx, id, in); scimStatus(err) != 403 {
+			t.Fatalf("PUT %s: err = %v", id, err)
+		}
+	}
+	if len(repo.updated) != 0 {
+		t.Fatalf("privileged user saved: %+v", repo.updated)
+	}
+	if res, err := s.PatchUser(ctx, "emp", op); err != nil || res.Enterprise.EmployeeNumber != "X999" {
+		t.Fatalf("PATCH employee: %+v, %v", res, err)
+	}
+}
+
+func TestPrivilegedExternalIDCannotChangeViaSCIM(t *testing.T) {
+	ctx := context.Background()
+	s, repo := newUserFixture()
+	ext := "hris-admin"
+	repo.byID["admin"].ExternalID = &ext
+	op := func(kind, value string) []dto.PatchOperation {
+		v, _ := json.Marshal(value)
+		return []dto.PatchOperation{{Op: kind, Path: "externalId", Value: v}}
+	}
+
+	for _, id := range []string{"hr", "admin"} {
+		// hr ยังไม่มี externalId: ผูกใหม่ก็นับเป็นการเปลี่ยน
+		if _, err := s.PatchUser(ctx, id, op("replace", "attacker")); scimStatus(err) != 403 {
+			t.Fatalf("PATCH %s: err = %v", id, err)
+		}
+		in := s.toUser(repo.byID[id], nil)
+		in.ExternalID = "attacker"
+		if _, err := s.ReplaceUser(ctx, id, in); scimStatus(err) != 403 {
+			t.Fatalf("PUT %s: err = %v", id, err)
+		}
+	}
+	if _, err := s.PatchUser(ctx, "admin", op("remove", "")); scimStatus(err) != 403 {
+		t.Fatalf("remove admin externalId: err = %v", err)
+	}
+	if len(repo.updated) != 0 {
+		t.Fatalf("privileged user saved: %+v", repo.updated)
+	}
+
+	// ส่งค่าเดิมกลับมา (PUT ทั้งก้อน) ได้
+	if _, err := s.ReplaceUser(ctx, "admin", s.toUser(repo.byID["admin"], nil)); err != nil {
+		t.Fatalf("PUT admin unchanged: %v", err)
+	}
+	if res, err := s.PatchUser(ctx, "emp", op("replace", "hris-emp")); err != nil || res.ExternalID != "hris-emp" {
+		t.Fatalf("PATCH employee: %+v, %v", res, err)
+	}
+}
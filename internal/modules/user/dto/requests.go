@@ -1,5 +1,7 @@
 package dto
 
+import "github.com/Marugo/birdlax/internal/modules/user"
+
 type UserCreateRequest struct {
 	EmployeeCode string  `json:"employee_code" validate:"required"`
 	Email        string  `json:"email"         validate:"required,email"`
@@ -7,7 +9,11 @@ type UserCreateRequest struct {
 	LastName     string  `json:"last_name"     validate:"required"`
 	Role         string  `json:"role"          validate:"omitempty,oneof=admin employee hr"`
 	Phone        *string `json:"phone"`
-	Password     string  `json:"password"      validate:"required,min=6"`
+	Password     string  `json:"password"      validate:"required"`
+
+	ManagerUserID *string `json:"manager_user_id"`
+
+	Profile
 }
 
 type UserUpdateRequest struct {
@@ -18,20 +24,47 @@ type UserUpdateRequest struct {
 	Role         *string `json:"role"          validate:"omitempty,oneof=admin employee hr"`
 	Phone        *string `json:"phone"`
 	IsActive     *bool   `json:"is_active"`
-	Password     *string `json:"password"      validate:"omitempty,min=6"`
+	Password     *string `json:"password"`
+
+	ManagerUserID *string `json:"manager_user_id"` // "" = ล้างหัวหน้า
+
+	Profile
+}
+
+// Profile ข้อมูลตำแหน่งงาน (ใช้ทั้ง create/update); ตอน update ไม่ส่ง = ไม่เปลี่ยน, "" = ล้างค่า
+type Profile struct {
+	JobTitle       *string           `json:"job_title"`
+	PositionLevel  *string           `json:"position_level"`
+	HireDate       *string           `json:"hire_date"` // YYYY-MM-DD
+	Location       *string           `json:"location"`
+	EmploymentType *string           `json:"employment_type"`
+	Attributes     map[string]string `json:"attributes"` // แทนทั้งชุด
+}
+
+func (p Profile) Input() user.ProfileInput {
+	return user.ProfileInput{
+		JobTitle:       p.JobTitle,
+		PositionLevel:  p.PositionLevel,
+		HireDate:       p.HireDate,
+		Location:       p.Location,
+		EmploymentType: p.EmploymentType,
+		Attributes:     p.Attributes,
+	}
 }
 
 // ===== Departments =====
 
 type DepartmentCreateRequest struct {
-	Code     string `json:"code"     validate:"required"`
-	Name     string `json:"name"     validate:"required"`
-	IsActive *bool  `json:"is_active"`
+	Code     string  `json:"code"     validate:"required"`
+	Name     string  `json:"name"     validate:"required"`
+	ParentID *string `json:"parent_id"`
+	IsActive *bool   `json:"is_active"`
 }
 
 type DepartmentUpdateRequest struct {
 	Code     *string `json:"code"`
 	Name     *string `json:"name"`
+	ParentID *string `json:"parent_id"` // "" = ขึ้นเป็นระดับบนสุด
 	IsActive *bool   `json:"is_active"`
 }
 
@@ -40,4 +73,18 @@ type DepartmentUpdateRequest struct {
 type AssignDepartmentRoleRequest struct {
 	DepartmentID string `json:"department_id" validate:"required"`
 	Role         string `json:"role"          validate:"required,oneof=manager leader member"`
+	// YYYY-MM-DD หรือ RFC3339; ว่าง = ตอนนี้ (ย้อนหลังได้)
+	EffectiveFrom string `json:"effective_from"`
+}
+
+type ChangeDepartmentRoleRequest struct {
+	Role          string `json:"role"           validate:"required,oneof=manager leader member"`
+	EffectiveDate string `json:"effective_date"` // YYYY-MM-DD หรือ RFC3339; ว่าง = ตอนนี้
+}
+
+type TransferRequest struct {
+	FromDepartmentID string  `json:"from_department_id" validate:"required"`
+	ToDepartmentID   string  `json:"to_department_id"   validate:"required"`
+	Role             *string `json:"role"               validate:"omitempty,oneof=manager leader member"` // ว่าง = role เดิม
+	EffectiveDate    string  `json:"effective_date"`                                                      // YYYY-MM-DD หรือ RFC3339; ว่าง = ตอนนี้
 }
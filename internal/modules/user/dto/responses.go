@@ -1,6 +1,11 @@
 package dto
 
-import usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+import (
+	"time"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
 
 type UserResponse struct {
 	ID           string  `json:"id"`
@@ -12,6 +17,24 @@ type UserResponse struct {
 	Phone        *string `json:"phone"`
 	IsActive     bool    `json:"is_active"`
 	FullName     string  `json:"full_name"`
+	ExternalID   *string `json:"external_id,omitempty"`
+
+	ManagerUserID *string `json:"manager_user_id"`
+
+	JobTitle       *string           `json:"job_title"`
+	PositionLevel  *string           `json:"position_level"`
+	HireDate       *string           `json:"hire_date"`
+	Location       *string           `json:"location"`
+	EmploymentType *string           `json:"employment_type"`
+	Attributes     map[string]string `json:"attributes"`
+
+	// lockout state (ให้ HR เห็นว่าทำไม user login ไม่ได้)
+	IsLocked         bool       `json:"is_locked"`
+	LockedUntil      *time.Time `json:"locked_until,omitempty"`
+	FailedLoginCount int        `json:"failed_login_count"`
+	LastLoginAt      *time.Time `json:"last_login_at"`
+
+	MustChangePassword bool `json:"must_change_password"`
 }
 
 func FromModel(u *usermodels.User) *UserResponse {
@@ -26,6 +49,15 @@ func FromModel(u *usermodels.User) *UserResponse {
 		}
 		full += ln
 	}
+	var hireDate *string
+	if u.HireDate != nil {
+		d := u.HireDate.Format("2006-01-02")
+		hireDate = &d
+	}
+	attrs := map[string]string(u.Attributes)
+	if attrs == nil {
+		attrs = map[string]string{}
+	}
 	return &UserResponse{
 		ID:           u.ID,
 		EmployeeCode: u.EmployeeCode,
@@ -36,9 +68,40 @@ func FromModel(u *usermodels.User) *UserResponse {
 		Phone:        u.Phone,
 		IsActive:     u.IsActive,
 		FullName:     full,
+		ExternalID:   u.ExternalID,
+
+		ManagerUserID: u.ManagerUserID,
+
+		JobTitle:       u.JobTitle,
+		PositionLevel:  u.PositionLevel,
+		HireDate:       hireDate,
+		Location:       u.Location,
+		EmploymentType: (*string)(u.EmploymentType),
+		Attributes:     attrs,
+
+		IsLocked:         u.LockedUntil != nil && u.LockedUntil.After(time.Now()),
+		LockedUntil:      u.LockedUntil,
+		FailedLoginCount: u.FailedLoginCount,
+		LastLoginAt:      u.LastLoginAt,
+
+		MustChangePassword: u.MustChangePassword,
 	}
 }
 
+// UserListItem แถวในรายชื่อ user (GET /users) พร้อมแผนกที่สังกัด
+type UserListItem struct {
+	*UserResponse
+	Departments []*UserDepartmentRoleResponse `json:"departments"`
+}
+
+func FromUserWithDepartments(in *user.UserWithDepartments) *UserListItem {
+	deps := make([]*UserDepartmentRoleResponse, 0, len(in.Departments))
+	for i := range in.Departments {
+		deps = append(deps, FromUserDepartmentRoleModel(&in.Departments[i]))
+	}
+	return &UserListItem{UserResponse: FromModel(&in.User), Departments: deps}
+}
+
 // ===== Departments =====
 
 type DepartmentResponse struct {
@@ -46,6 +109,9 @@ type DepartmentResponse struct {
 	Code     string `json:"code"`
 	Name     string `json:"name"`
 	IsActive bool   `json:"is_active"`
+
+	ParentID   *string `json:"parent_id"`
+	ExternalID *string `json:"external_id,omitempty"`
 }
 
 func FromDepartmentModel(d *usermodels.Department) *DepartmentResponse {
@@ -57,7 +123,35 @@ func FromDepartmentModel(d *usermodels.Department) *DepartmentResponse {
 		Code:     d.Code,
 		Name:     d.Name,
 		IsActive: d.IsActive,
+
+		ParentID:   d.ParentID,
+		ExternalID: d.ExternalID,
+	}
+}
+
+func FromDepartmentModels(items []usermodels.Department) []*DepartmentResponse {
+	out := make([]*DepartmentResponse, 0, len(items))
+	for i := range items {
+		out = append(out, FromDepartmentModel(&items[i]))
 	}
+	return out
+}
+
+// DepartmentTreeResponse แผนกพร้อมแผนกลูก (GET /departments/tree)
+type DepartmentTreeResponse struct {
+	DepartmentResponse
+	Children []*DepartmentTreeResponse `json:"children"`
+}
+
+func FromDepartmentTree(nodes []*user.DepartmentNode) []*DepartmentTreeResponse {
+	out := make([]*DepartmentTreeResponse, 0, len(nodes))
+	for _, n := range nodes {
+		out = append(out, &DepartmentTreeResponse{
+			DepartmentResponse: *FromDepartmentModel(&n.Department),
+			Children:           FromDepartmentTree(n.Children),
+		})
+	}
+	return out
 }
 
 // ===== User Department Roles =====
@@ -69,6 +163,11 @@ type UserDepartmentRoleResponse struct {
 	Role         string `json:"role"`
 	// optional: แถมชื่อแผนก
 	DepartmentName string `json:"department_name,omitempty"`
+	// ช่วงที่สังกัด; effective_to null = ยังสังกัดอยู่
+	EffectiveFrom time.Time  `json:"effective_from"`
+	EffectiveTo   *time.Time `json:"effective_to"`
+	// มีเมื่อ preload User (เช่น รายชื่อสมาชิก ณ วันที่)
+	User *UserResponse `json:"user,omitempty"`
 }
 
 func FromUserDepartmentRoleModel(m *usermodels.UserDepartmentRole) *UserDepartmentRoleResponse {
@@ -76,13 +175,18 @@ func FromUserDepartmentRoleModel(m *usermodels.UserDepartmentRole) *UserDepartme
 		return nil
 	}
 	resp := &UserDepartmentRoleResponse{
-		ID:           m.ID,
-		UserID:       m.UserID,
-		DepartmentID: m.DepartmentID,
-		Role:         string(m.Role),
+		ID:            m.ID,
+		UserID:        m.UserID,
+		DepartmentID:  m.DepartmentID,
+		Role:          string(m.Role),
+		EffectiveFrom: m.EffectiveFrom,
+		EffectiveTo:   m.EffectiveTo,
 	}
 	if m.Department != nil {
 		resp.DepartmentName = m.Department.Name
 	}
+	if m.User != nil {
+		resp.User = FromModel(m.User)
+	}
 	return resp
 }
@@ -4,13 +4,18 @@ import (
 	"errors"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/modules/user"
 	"github.com/Marugo/birdlax/internal/modules/user/dto"
 	userservice "github.com/Marugo/birdlax/internal/modules/user/service"
+	"github.com/Marugo/birdlax/internal/shared/middleware"
+	"github.com/Marugo/birdlax/internal/shared/password"
 	"github.com/Marugo/birdlax/internal/shared/response"
 )
 
@@ -25,17 +30,72 @@ func NewHTTPHandler(s user.Service) *HTTPHandler {
 
 // ===================== Users =====================
 
+// GET /users?q=&department_id=&sub_departments=&department_role=&role=&is_active=&created_from=&created_to=&sort=&order=
+// ข้อมูลตำแหน่งงาน: job_title=&position_level=&location=&employment_type=&attr.<key>=
+// sort: created_at (default) | name | employee_code | last_login
 func (h *HTTPHandler) List(c *fiber.Ctx) error {
 	page, _ := strconv.Atoi(c.Query("page", "1"))
 	perPage, _ := strconv.Atoi(c.Query("per_page", "20"))
-	q := c.Query("q", "")
-	items, total, err := h.svc.List(c.Context(), page, perPage, q)
+	f := user.ListFilter{
+		Q:              c.Query("q", ""),
+		DepartmentID:   c.Query("department_id"),
+		SubDepartments: c.QueryBool("sub_departments"),
+		DepartmentRole: c.Query("department_role"),
+		Role:           c.Query("role"),
+		JobTitle:       c.Query("job_title"),
+		PositionLevel:  c.Query("position_level"),
+		Location:       c.Query("location"),
+		EmploymentType: c.Query("employment_type"),
+		Sort:           c.Query("sort"),
+	}
+	// attr.<key>=<value> กรองตาม custom attribute (ได้หลายตัว)
+	c.Context().QueryArgs().VisitAll(func(k, v []byte) {
+		if key, ok := strings.CutPrefix(string(k), "attr."); ok {
+			if f.Attributes == nil {
+				f.Attributes = map[string]string{}
+			}
+			f.Attributes[key] = string(v)
+		}
+	})
+	if v := c.Query("is_active"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "is_active must be true or false")
+		}
+		f.IsActive = &b
+	}
+	for key, dst := range map[string]**time.Time{"created_from": &f.CreatedFrom, "created_to": &f.CreatedTo} {
+		v := c.Query(key)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", key+" must be RFC3339")
+		}
+		*dst = &t
+	}
+	// ค่าเริ่มต้น: ใหม่สุดก่อนเมื่อเรียงตามวันที่สร้าง, นอกนั้น A-Z
+	switch strings.ToLower(c.Query("order")) {
+	case "desc":
+		f.Desc = true
+	case "asc":
+	case "":
+		f.Desc = f.Sort == "" || f.Sort == user.SortCreatedAt
+	default:
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "order must be asc or desc")
+	}
+
+	items, total, err := h.svc.List(c.Context(), f, page, perPage)
 	if err != nil {
+		if errors.Is(err, userservice.ErrInvalidListFilter) || errors.Is(err, user.ErrAttributeFilterUnsupported) {
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+		}
 		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 	}
-	out := make([]*dto.UserResponse, 0, len(items))
+	out := make([]*dto.UserListItem, 0, len(items))
 	for i := range items {
-		out = append(out, dto.FromModel(&items[i]))
+		out = append(out, dto.FromUserWithDepartments(&items[i]))
 	}
 	return response.OK(c, fiber.Map{
 		"items":    out,
@@ -47,12 +107,10 @@ func (h *HTTPHandler) List(c *fiber.Ctx) error {
 
 func (h *HTTPHandler) Create(c *fiber.Ctx) error {
 	var req dto.UserCreateRequest
-	actorRole, _ := c.Locals("role").(string)
-
 	if err := c.BodyParser(&req); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
 	}
-	if req.Role != "" && actorRole != "admin" && actorRole != "hr" {
+	if req.Role != "" && !middleware.HasPermission(c, authz.PermUserAssignRole) {
 		return response.Err(c, fiber.StatusForbidden, "FORBIDDEN", "not allowed to set role")
 	}
 	if err := h.validator.Struct(&req); err != nil {
@@ -63,10 +121,17 @@ func (h *HTTPHandler) Create(c *fiber.Ctx) error {
 	if strings.TrimSpace(roleInput) == "" {
 		roleInput = "employee"
 	}
-	u, err := h.svc.Create(c.Context(),
-		req.EmployeeCode, req.Email, req.FirstName, req.LastName,
-		roleInput, req.Phone, req.Password,
-	)
+	u, err := h.svc.Create(c.Context(), user.CreateInput{
+		EmployeeCode:  req.EmployeeCode,
+		Email:         req.Email,
+		FirstName:     req.FirstName,
+		LastName:      req.LastName,
+		Role:          roleInput,
+		Phone:         req.Phone,
+		ManagerUserID: req.ManagerUserID,
+		Password:      req.Password,
+		Profile:       req.Profile.Input(),
+	})
 	if err != nil {
 		if errors.Is(err, userservice.ErrEmployeeCodeAlreadyExist) {
 			return response.Err(c, fiber.StatusConflict, "EMPLOYEE_CODE_ALREADY_EXISTS", "employee_code is already registered")
@@ -74,6 +139,20 @@ func (h *HTTPHandler) Create(c *fiber.Ctx) error {
 		if errors.Is(err, userservice.ErrEmailAlreadyExists) {
 			return response.Err(c, fiber.StatusConflict, "EMAIL_ALREADY_EXISTS", "email is already registered")
 		}
+		if errors.Is(err, userservice.ErrManagerNotFound) {
+			return response.Err(c, fiber.StatusBadRequest, "MANAGER_NOT_FOUND", err.Error())
+		}
+		if errors.Is(err, userservice.ErrManagerCycle) {
+			return response.Err(c, fiber.StatusConflict, "MANAGER_CYCLE", err.Error())
+		}
+		var pe *password.PolicyError
+		if errors.As(err, &pe) {
+			return passwordPolicyError(c, pe)
+		}
+		var profErr *userservice.ProfileError
+		if errors.As(err, &profErr) {
+			return response.Invalid(c, "INVALID_PROFILE", "invalid profile field", fiber.Map{profErr.Field: profErr.Message})
+		}
 		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
 	}
 	return response.OK(c, dto.FromModel(u))
@@ -90,23 +169,29 @@ func (h *HTTPHandler) Get(c *fiber.Ctx) error {
 
 func (h *HTTPHandler) Update(c *fiber.Ctx) error {
 	id := c.Params("id")
-	actorRole, _ := c.Locals("role").(string)
 	var req dto.UserUpdateRequest
 	if err := c.BodyParser(&req); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
 	}
-	// ถ้าไม่ใช่ admin/hr และมีการส่ง req.Role → ห้าม
-	if req.Role != nil && actorRole != "admin" && actorRole != "hr" {
+	// ไม่มีสิทธิ์ตั้ง role แต่ส่ง req.Role มา → ห้าม
+	if req.Role != nil && !middleware.HasPermission(c, authz.PermUserAssignRole) {
 		return response.Err(c, fiber.StatusForbidden, "FORBIDDEN", "not allowed to change role")
 	}
 	if err := h.validator.Struct(&req); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 	}
-	u, err := h.svc.Update(
-		c.Context(), id,
-		req.EmployeeCode, req.Email, req.FirstName, req.LastName, req.Role, req.Phone, req.IsActive,
-		req.Password,
-	)
+	u, err := h.svc.Update(c.Context(), id, user.UpdateInput{
+		EmployeeCode:  req.EmployeeCode,
+		Email:         req.Email,
+		FirstName:     req.FirstName,
+		LastName:      req.LastName,
+		Role:          req.Role,
+		Phone:         req.Phone,
+		ManagerUserID: req.ManagerUserID,
+		IsActive:      req.IsActive,
+		Password:      req.Password,
+		Profile:       req.Profile.Input(),
+	})
 	if err != nil {
 		if errors.Is(err, userservice.ErrEmployeeCodeAlreadyExist) {
 			return response.Err(c, fiber.StatusConflict, "EMPLOYEE_CODE_ALREADY_EXISTS", "employee_code is already registered")
@@ -114,6 +199,20 @@ func (h *HTTPHandler) Update(c *fiber.Ctx) error {
 		if errors.Is(err, userservice.ErrEmailAlreadyExists) {
 			return response.Err(c, fiber.StatusConflict, "EMAIL_ALREADY_EXISTS", "email is already registered")
 		}
+		if errors.Is(err, userservice.ErrManagerNotFound) {
+			return response.Err(c, fiber.StatusBadRequest, "MANAGER_NOT_FOUND", err.Error())
+		}
+		if errors.Is(err, userservice.ErrManagerCycle) {
+			return response.Err(c, fiber.StatusConflict, "MANAGER_CYCLE", err.Error())
+		}
+		var pe *password.PolicyError
+		if errors.As(err, &pe) {
+			return passwordPolicyError(c, pe)
+		}
+		var profErr *userservice.ProfileError
+		if errors.As(err, &profErr) {
+			return response.Invalid(c, "INVALID_PROFILE", "invalid profile field", fiber.Map{profErr.Field: profErr.Message})
+		}
 		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
 	}
 	return response.OK(c, dto.FromModel(u))
@@ -127,6 +226,70 @@ func (h *HTTPHandler) Delete(c *fiber.Ctx) error {
 	return response.OK(c, fiber.Map{"deleted": true})
 }
 
+// GET /users/:id/direct-reports
+func (h *HTTPHandler) ListDirectReports(c *fiber.Ctx) error {
+	items, err := h.svc.ListDirectReports(c.Context(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	out := make([]*dto.UserResponse, 0, len(items))
+	for i := range items {
+		out = append(out, dto.FromModel(&items[i]))
+	}
+	return response.OK(c, out)
+}
+
+// POST /users/:id/unlock
+func (h *HTTPHandler) Unlock(c *fiber.Ctx) error {
+	id := c.Params("id")
+	u, err := h.svc.Unlock(c.Context(), id)
+	if err != nil {
+		return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+	}
+	return response.OK(c, dto.FromModel(u))
+}
+
+// POST /users/import (multipart: file=.csv|.xlsx, dry_run=true|false)
+func (h *HTTPHandler) Import(c *fiber.Ctx) error {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "file is required")
+	}
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run", c.Query("dry_run", "false")))
+
+	f, err := fh.Open()
+	if err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "cannot read file")
+	}
+	defer f.Close()
+
+	report, err := h.svc.ImportUsers(c.Context(), fh.Filename, f, user.ImportOptions{
+		DryRun:    dryRun,
+		AllowRole: middleware.HasPermission(c, authz.PermUserAssignRole),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, userservice.ErrImportUnsupportedFormat):
+			return response.Err(c, fiber.StatusUnsupportedMediaType, "IMPORT_UNSUPPORTED_FORMAT", err.Error())
+		case errors.Is(err, userservice.ErrImportMissingColumns),
+			errors.Is(err, userservice.ErrImportEmpty),
+			errors.Is(err, userservice.ErrImportTooManyRows):
+			return response.Err(c, fiber.StatusUnprocessableEntity, "IMPORT_INVALID_FILE", err.Error())
+		}
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, report)
+}
+
+// passwordPolicyError 422 พร้อมรายการกฎที่ไม่ผ่านของฟิลด์ password
+func passwordPolicyError(c *fiber.Ctx, pe *password.PolicyError) error {
+	return response.Invalid(c, "PASSWORD_POLICY", "password does not meet the password policy",
+		map[string]interface{}{"password": pe.Violations})
+}
+
 // ===================== Departments =====================
 
 func (h *HTTPHandler) ListDepartments(c *fiber.Ctx) error {
@@ -150,9 +313,9 @@ func (h *HTTPHandler) CreateDepartment(c *fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 	}
-	d, err := h.svc.CreateDepartment(c.Context(), req.Code, req.Name, req.IsActive)
+	d, err := h.svc.CreateDepartment(c.Context(), req.Code, req.Name, req.ParentID, req.IsActive)
 	if err != nil {
-		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return departmentError(c, err)
 	}
 	return response.OK(c, dto.FromDepartmentModel(d)) // ✅
 }
@@ -175,9 +338,9 @@ func (h *HTTPHandler) UpdateDepartment(c *fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 	}
-	d, err := h.svc.UpdateDepartment(c.Context(), id, req.Code, req.Name, req.IsActive)
+	d, err := h.svc.UpdateDepartment(c.Context(), id, req.Code, req.Name, req.ParentID, req.IsActive)
 	if err != nil {
-		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return departmentError(c, err)
 	}
 	return response.OK(c, dto.FromDepartmentModel(d)) // ✅
 }
@@ -185,11 +348,54 @@ func (h *HTTPHandler) UpdateDepartment(c *fiber.Ctx) error {
 func (h *HTTPHandler) DeleteDepartment(c *fiber.Ctx) error {
 	id := c.Params("id")
 	if err := h.svc.DeleteDepartment(c.Context(), id); err != nil {
-		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+		return departmentError(c, err)
 	}
 	return response.OK(c, fiber.Map{"deleted": true})
 }
 
+// GET /departments/tree
+func (h *HTTPHandler) DepartmentTree(c *fiber.Ctx) error {
+	nodes, err := h.svc.DepartmentTree(c.Context())
+	if err != nil {
+		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
+	}
+	return response.OK(c, dto.FromDepartmentTree(nodes))
+}
+
+// GET /departments/:id/ancestors (ระดับบนสุดก่อน)
+func (h *HTTPHandler) ListDepartmentAncestors(c *fiber.Ctx) error {
+	items, err := h.svc.ListDepartmentAncestors(c.Context(), c.Params("id"))
+	if err != nil {
+		return departmentError(c, err)
+	}
+	return response.OK(c, dto.FromDepartmentModels(items))
+}
+
+// GET /departments/:id/descendants
+func (h *HTTPHandler) ListDepartmentDescendants(c *fiber.Ctx) error {
+	items, err := h.svc.ListDepartmentDescendants(c.Context(), c.Params("id"))
+	if err != nil {
+		return departmentError(c, err)
+	}
+	return response.OK(c, dto.FromDepartmentModels(items))
+}
+
+func departmentError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "department not found")
+	case errors.Is(err, userservice.ErrParentDepartmentNotFound):
+		return response.Err(c, fiber.StatusBadRequest, "PARENT_NOT_FOUND", err.Error())
+	case errors.Is(err, userservice.ErrDepartmentCycle):
+		return response.Err(c, fiber.StatusConflict, "DEPARTMENT_CYCLE", err.Error())
+	case errors.Is(err, userservice.ErrDepartmentTooDeep):
+		return response.Err(c, fiber.StatusConflict, "DEPARTMENT_TOO_DEEP", err.Error())
+	case errors.Is(err, userservice.ErrDepartmentHasChildren):
+		return response.Err(c, fiber.StatusConflict, "DEPARTMENT_HAS_CHILDREN", err.Error())
+	}
+	return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+}
+
 // GET /departments/:id/managers
 func (h *HTTPHandler) ListDepartmentManagers(c *fiber.Ctx) error {
 	id := c.Params("id")
@@ -230,18 +436,143 @@ func (h *HTTPHandler) AddUserDepartmentRole(c *fiber.Ctx) error {
 	if err := h.validator.Struct(&req); err != nil {
 		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
 	}
-	rel, err := h.svc.AddUserDepartmentRole(c.Context(), id, req.DepartmentID, req.Role)
+	from, err := parseEffectiveDate(req.EffectiveFrom)
 	if err != nil {
-		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "effective_from "+err.Error())
+	}
+	rel, err := h.svc.AddUserDepartmentRole(c.Context(), id, req.DepartmentID, req.Role, from)
+	if err != nil {
+		return membershipError(c, err)
 	}
 	return response.OK(c, dto.FromUserDepartmentRoleModel(rel)) // ✅
 }
 
-// DELETE /users/:id/department-roles/:relID
+// DELETE /users/:id/department-roles/:relID?effective_date=&purge=true
+// ปกติ = จบสังกัด (ประวัติยังอยู่); purge=true = ลบถาวร สำหรับสังกัดที่ผูกผิด
 func (h *HTTPHandler) DeleteUserDepartmentRole(c *fiber.Ctx) error {
-	relID := c.Params("relID")
-	if err := h.svc.DeleteUserDepartmentRole(c.Context(), relID); err != nil {
+	id, relID := c.Params("id"), c.Params("relID")
+	if c.QueryBool("purge") {
+		if err := h.svc.PurgeUserDepartmentRole(c.Context(), id, relID); err != nil {
+			return membershipError(c, err)
+		}
+		return response.OK(c, fiber.Map{"deleted": true})
+	}
+	at, err := parseEffectiveDate(c.Query("effective_date"))
+	if err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "effective_date "+err.Error())
+	}
+	if err := h.svc.EndUserDepartmentRole(c.Context(), id, relID, at); err != nil {
+		return membershipError(c, err)
+	}
+	return response.OK(c, fiber.Map{"deleted": true, "ended": true})
+}
+
+// PATCH /users/:id/department-roles/:relID — เปลี่ยน role ในแผนกเดิม (จบแถวเดิม เริ่มแถวใหม่ ประวัติยังอยู่)
+func (h *HTTPHandler) ChangeDepartmentRole(c *fiber.Ctx) error {
+	var req dto.ChangeDepartmentRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	at, err := parseEffectiveDate(req.EffectiveDate)
+	if err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "effective_date "+err.Error())
+	}
+	rel, err := h.svc.ChangeDepartmentRole(c.Context(), c.Params("id"), c.Params("relID"), req.Role, at)
+	if err != nil {
+		return membershipError(c, err)
+	}
+	return response.OK(c, dto.FromUserDepartmentRoleModel(rel))
+}
+
+// POST /users/:id/transfers
+func (h *HTTPHandler) TransferUser(c *fiber.Ctx) error {
+	var req dto.TransferRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", "invalid JSON")
+	}
+	if err := h.validator.Struct(&req); err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", err.Error())
+	}
+	at, err := parseEffectiveDate(req.EffectiveDate)
+	if err != nil {
+		return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "effective_date "+err.Error())
+	}
+	rel, err := h.svc.TransferUser(c.Context(), c.Params("id"), req.FromDepartmentID, req.ToDepartmentID, req.Role, at)
+	if err != nil {
+		return membershipError(c, err)
+	}
+	return response.OK(c, dto.FromUserDepartmentRoleModel(rel))
+}
+
+// GET /users/:id/department-history (รวมสังกัดที่จบแล้ว)
+func (h *HTTPHandler) ListUserDepartmentHistory(c *fiber.Ctx) error {
+	items, err := h.svc.ListUserDepartmentHistory(c.Context(), c.Params("id"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user not found")
+		}
 		return response.Err(c, fiber.StatusInternalServerError, "INTERNAL_ERROR", err.Error())
 	}
-	return response.OK(c, fiber.Map{"deleted": true})
+	out := make([]*dto.UserDepartmentRoleResponse, 0, len(items))
+	for i := range items {
+		out = append(out, dto.FromUserDepartmentRoleModel(&items[i]))
+	}
+	return response.OK(c, out)
+}
+
+// GET /departments/:id/members?at= (ว่าง = ตอนนี้)
+func (h *HTTPHandler) ListDepartmentMembersAt(c *fiber.Ctx) error {
+	at := time.Now()
+	if v := c.Query("at"); v != "" {
+		t, err := parseEffectiveDate(v)
+		if err != nil {
+			return response.Err(c, fiber.StatusBadRequest, "VALIDATION_ERROR", "at "+err.Error())
+		}
+		at = *t
+	}
+	items, err := h.svc.ListDepartmentMembersAt(c.Context(), c.Params("id"), at)
+	if err != nil {
+		return departmentError(c, err)
+	}
+	out := make([]*dto.UserDepartmentRoleResponse, 0, len(items))
+	for i := range items {
+		out = append(out, dto.FromUserDepartmentRoleModel(&items[i]))
+	}
+	return response.OK(c, fiber.Map{"at": at, "items": out})
+}
+
+// parseEffectiveDate รับ YYYY-MM-DD (เที่ยงคืนตามเวลา server) หรือ RFC3339; ว่าง = nil
+func parseEffectiveDate(v string) (*time.Time, error) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, errors.New("must be YYYY-MM-DD or RFC3339")
+	}
+	return &t, nil
+}
+
+func membershipError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return response.Err(c, fiber.StatusNotFound, "NOT_FOUND", "user, department or membership not found")
+	case errors.Is(err, userservice.ErrEffectiveDateInFuture),
+		errors.Is(err, userservice.ErrEffectiveDateBeforeStart):
+		return response.Err(c, fiber.StatusBadRequest, "INVALID_EFFECTIVE_DATE", err.Error())
+	case errors.Is(err, userservice.ErrNotDepartmentMember):
+		return response.Err(c, fiber.StatusConflict, "NOT_DEPARTMENT_MEMBER", err.Error())
+	case errors.Is(err, userservice.ErrSameDepartment):
+		return response.Err(c, fiber.StatusBadRequest, "SAME_DEPARTMENT", err.Error())
+	case errors.Is(err, userservice.ErrMembershipOverlap):
+		return response.Err(c, fiber.StatusConflict, "MEMBERSHIP_OVERLAP", err.Error())
+	}
+	return response.Err(c, fiber.StatusBadRequest, "BAD_REQUEST", err.Error())
 }
@@ -1,34 +1,48 @@
 package handler
 
 import (
+	"github.com/Marugo/birdlax/internal/modules/authz"
 	"github.com/Marugo/birdlax/internal/modules/user"
-	"github.com/Marugo/birdlax/internal/modules/user/models"
 	"github.com/Marugo/birdlax/internal/shared/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 func Register(r fiber.Router, svc user.Service) {
 	h := NewHTTPHandler(svc)
+	read := middleware.RequirePermission(authz.PermUserRead)
+	write := middleware.RequirePermission(authz.PermUserWrite)
 
-	// ===== Users CRUD (admin, hr เท่านั้น) =====
-	g := r.Group("/users", middleware.RequireRoles(models.RoleAdmin, models.RoleHR))
-	g.Get("/", h.List)
-	g.Post("/", h.Create)
-	g.Get("/:id", h.Get)
-	g.Put("/:id", h.Update)
-	g.Delete("/:id", h.Delete)
+	// ===== Users CRUD =====
+	g := r.Group("/users")
+	g.Get("/", read, h.List)
+	g.Post("/", write, h.Create)
+	g.Post("/import", middleware.RequireUser(), write, h.Import) // CSV/XLSX, dry_run ได้ (คนเท่านั้น)
+	g.Get("/:id", read, h.Get)
+	g.Put("/:id", write, h.Update)
+	g.Delete("/:id", write, h.Delete)
+	g.Post("/:id/unlock", middleware.RequireUser(), middleware.RequirePermission(authz.PermUserSecurity), h.Unlock)
+	g.Get("/:id/direct-reports", read, h.ListDirectReports)
 
 	// User department roles
-	g.Get("/:id/department-roles", h.ListUserDepartmentRoles)
-	g.Post("/:id/department-roles", h.AddUserDepartmentRole)
-	g.Delete("/:id/department-roles/:relID", h.DeleteUserDepartmentRole)
+	g.Get("/:id/department-roles", read, h.ListUserDepartmentRoles)
+	g.Post("/:id/department-roles", write, h.AddUserDepartmentRole)
+	g.Patch("/:id/department-roles/:relID", write, h.ChangeDepartmentRole) // เปลี่ยน role (เก็บประวัติ)
+	g.Delete("/:id/department-roles/:relID", write, h.DeleteUserDepartmentRole)
+	g.Post("/:id/transfers", write, h.TransferUser)
+	g.Get("/:id/department-history", read, h.ListUserDepartmentHistory)
 
 	// ===== Departments CRUD =====
-	dg := r.Group("/departments", middleware.RequireRoles(models.RoleAdmin, models.RoleHR))
-	dg.Get("/", h.ListDepartments)
-	dg.Post("/", h.CreateDepartment)
-	dg.Get("/:id", h.GetDepartment)
-	dg.Put("/:id", h.UpdateDepartment)
-	dg.Delete("/:id", h.DeleteDepartment)
-	dg.Get("/:id/managers", h.ListDepartmentManagers)
+	deptRead := middleware.RequirePermission(authz.PermDepartmentRead)
+	deptWrite := middleware.RequirePermission(authz.PermDepartmentWrite)
+	dg := r.Group("/departments")
+	dg.Get("/", deptRead, h.ListDepartments)
+	dg.Post("/", deptWrite, h.CreateDepartment)
+	dg.Get("/tree", deptRead, h.DepartmentTree)
+	dg.Get("/:id", deptRead, h.GetDepartment)
+	dg.Put("/:id", deptWrite, h.UpdateDepartment)
+	dg.Delete("/:id", deptWrite, h.DeleteDepartment)
+	dg.Get("/:id/managers", deptRead, h.ListDepartmentManagers)
+	dg.Get("/:id/members", deptRead, h.ListDepartmentMembersAt)
+	dg.Get("/:id/ancestors", deptRead, h.ListDepartmentAncestors)
+	dg.Get("/:id/descendants", deptRead, h.ListDepartmentDescendants)
 }
@@ -8,10 +8,16 @@ import (
 )
 
 type Department struct {
-	ID        string         `gorm:"type:char(36);primaryKey" json:"id"`
-	Code      string         `gorm:"size:50;uniqueIndex;not null" json:"code"`
-	Name      string         `gorm:"size:100;not null" json:"name"`
-	IsActive  bool           `gorm:"type:tinyint(1);default:1" json:"is_active"`
+	ID       string `gorm:"type:char(36);primaryKey" json:"id"`
+	Code     string `gorm:"size:50;uniqueIndex;not null" json:"code"`
+	Name     string `gorm:"size:100;not null" json:"name"`
+	IsActive bool   `gorm:"type:tinyint(1);default:1" json:"is_active"`
+	// id ของหน่วยงานในระบบ HR (SCIM Group externalId)
+	ExternalID *string `gorm:"size:190;uniqueIndex" json:"external_id,omitempty"`
+	// แผนกแม่ (nil = ระดับบนสุด เช่น division)
+	ParentID *string `gorm:"type:char(36);index" json:"parent_id"`
+	// materialized path "/<root id>/.../<id>/" ไว้หา ancestors/descendants ใน query เดียว (repo เป็นคนดูแล)
+	Path      string         `gorm:"size:1024;not null;default:''" json:"-"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
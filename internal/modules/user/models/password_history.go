@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PasswordHistory hash ของรหัสที่ user เคยตั้ง (ใช้กันตั้งรหัสเก่าซ้ำ)
+type PasswordHistory struct {
+	ID        string    `gorm:"type:char(36);primaryKey" json:"id"`
+	UserID    string    `gorm:"type:char(36);index;not null" json:"user_id"`
+	Hash      string    `gorm:"size:255;not null" json:"-"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (h *PasswordHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == "" {
+		h.ID = uuid.NewString()
+	}
+	return nil
+}
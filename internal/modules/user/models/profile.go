@@ -0,0 +1,75 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ประเภทการจ้างงาน
+type EmploymentType string
+
+const (
+	EmploymentFullTime  EmploymentType = "full_time"
+	EmploymentPartTime  EmploymentType = "part_time"
+	EmploymentContract  EmploymentType = "contract"
+	EmploymentTemporary EmploymentType = "temporary"
+	EmploymentIntern    EmploymentType = "intern"
+)
+
+func AllEmploymentTypes() []EmploymentType {
+	return []EmploymentType{EmploymentFullTime, EmploymentPartTime, EmploymentContract, EmploymentTemporary, EmploymentIntern}
+}
+
+// ParseEmploymentType รับทั้ง "full_time", "Full-Time", "full time"
+func ParseEmploymentType(s string) (EmploymentType, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.NewReplacer("-", "_", " ", "_").Replace(s)
+	for _, t := range AllEmploymentTypes() {
+		if EmploymentType(s) == t {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// Attributes ข้อมูลเพิ่มเติมแบบ key/value ที่แต่ละองค์กรกำหนดเอง (เช่น cost_center, shift)
+// เก็บเป็น JSON ในคอลัมน์ text; ใช้ได้ทั้ง Save และ Updates(map) เพราะ implement Valuer เอง
+type Attributes map[string]string
+
+func (a Attributes) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]string(a))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Attributes) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*a = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("attributes: unsupported type %T", src)
+	}
+	if len(b) == 0 {
+		*a = nil
+		return nil
+	}
+	m := map[string]string{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+	*a = m
+	return nil
+}
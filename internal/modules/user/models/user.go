@@ -32,6 +32,36 @@ type User struct {
 	PasswordHash string  `gorm:"size:255;not null" json:"-"`
 	IsActive     bool    `gorm:"type:tinyint(1);default:1" json:"is_active"`
 
+	// id ของ user ในระบบ HR (SCIM externalId); nil = ไม่ได้มาจาก HRIS
+	ExternalID *string `gorm:"size:190;uniqueIndex" json:"external_id,omitempty"`
+
+	// หัวหน้าสายตรง (reporting line) ไม่ผูกกับแผนก
+	ManagerUserID *string `gorm:"type:char(36);index" json:"manager_user_id"`
+
+	// ข้อมูลตำแหน่งงาน (ใช้ target คอร์สและจัดกลุ่มรายงาน นอกเหนือจากแผนก)
+	JobTitle       *string         `gorm:"size:150" json:"job_title"`
+	PositionLevel  *string         `gorm:"size:50;index" json:"position_level"`
+	HireDate       *time.Time      `gorm:"type:date" json:"hire_date"`
+	Location       *string         `gorm:"size:100;index" json:"location"`
+	EmploymentType *EmploymentType `gorm:"type:varchar(32);index" json:"employment_type"`
+	Attributes     Attributes      `gorm:"type:text" json:"attributes"`
+
+	// สถานะ login ผิด/ล็อก และ login สำเร็จล่าสุด (auth service เป็นคนอัปเดต)
+	FailedLoginCount  int        `gorm:"not null;default:0" json:"failed_login_count"`
+	LastFailedLoginAt *time.Time `json:"last_failed_login_at,omitempty"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	LastLoginAt       *time.Time `gorm:"index" json:"last_login_at,omitempty"`
+
+	// password policy: อายุรหัส และบังคับเปลี่ยนตอน login ครั้งถัดไป
+	PasswordChangedAt  *time.Time `json:"password_changed_at,omitempty"`
+	MustChangePassword bool       `gorm:"not null;default:0" json:"must_change_password"`
+
+	// เพิ่มทุกครั้งที่ต้องการให้ access token เดิมใช้ไม่ได้ทันที (ปิด account, เปลี่ยน role/รหัสผ่าน)
+	TokenVersion int `gorm:"not null;default:0" json:"-"`
+
+	// ลบข้อมูลส่วนบุคคลแล้ว (PDPA) เหลือแค่ id ไว้ผูกสถิติการเรียน
+	AnonymizedAt *time.Time `json:"anonymized_at,omitempty"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
@@ -26,6 +26,11 @@ type UserDepartmentRole struct {
 	DepartmentID string         `gorm:"type:char(36);index;not null" json:"department_id"`
 	Role         DepartmentRole `gorm:"type:varchar(32);not null" json:"role"`
 
+	// ช่วงที่สังกัดแผนกนี้ [EffectiveFrom, EffectiveTo); EffectiveTo nil = ยังสังกัดอยู่
+	// จบสังกัดแล้ว = มี EffectiveTo และถูก soft delete ด้วย (query "สมาชิกปัจจุบัน" ที่กรอง deleted_at ใช้ได้เหมือนเดิม)
+	EffectiveFrom time.Time  `gorm:"index" json:"effective_from"`
+	EffectiveTo   *time.Time `gorm:"index" json:"effective_to"`
+
 	User       *User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Department *Department `gorm:"foreignKey:DepartmentID" json:"department,omitempty"`
 
@@ -38,5 +43,8 @@ func (r *UserDepartmentRole) BeforeCreate(tx *gorm.DB) error {
 	if r.ID == "" {
 		r.ID = uuid.NewString()
 	}
+	if r.EffectiveFrom.IsZero() {
+		r.EffectiveFrom = time.Now()
+	}
 	return nil
 }
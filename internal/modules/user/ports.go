@@ -2,18 +2,115 @@ package user
 
 import (
 	"context"
+	"errors"
+	"io"
+	"time"
 
 	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
 )
 
+// ErrDepartmentCycle ย้ายแผนกไปอยู่ใต้ตัวเองหรือแผนกลูก (repo ตรวจซ้ำใน transaction ตอนย้าย)
+var ErrDepartmentCycle = errors.New("department cannot be moved under itself or its sub-departments")
+
+// ErrAttributeFilterUnsupported ฐานข้อมูลที่ใช้อยู่ไม่มีฟังก์ชัน JSON ที่ repo รู้จัก (กรอง attr.<key> ไม่ได้)
+var ErrAttributeFilterUnsupported = errors.New("attribute filter is not supported on this database")
+
+// ListFilter เงื่อนไขค้นหา user (ค่าว่าง/nil = ไม่กรอง)
+type ListFilter struct {
+	Q              string // employee_code, email, ชื่อ
+	DepartmentID   string
+	SubDepartments bool   // รวมแผนกลูกทุกระดับของ DepartmentID
+	DepartmentRole string // manager/leader/member (ใช้คู่กับ DepartmentID ได้)
+	Role           string
+	IsActive       *bool
+	CreatedFrom    *time.Time
+	CreatedTo      *time.Time
+
+	// ข้อมูลตำแหน่งงาน (ตรงตัว)
+	JobTitle       string
+	PositionLevel  string
+	Location       string
+	EmploymentType string
+	Attributes     map[string]string // ทุก key ต้องตรง
+
+	Sort string // ดู Sort*; ว่าง = created_at
+	Desc bool
+}
+
+// คอลัมน์ที่ใช้ sort รายชื่อ user ได้
+const (
+	SortCreatedAt    = "created_at"
+	SortName         = "name"
+	SortEmployeeCode = "employee_code"
+	SortLastLogin    = "last_login"
+)
+
+// ProfileInput ข้อมูลตำแหน่งงานตอนสร้าง/แก้ user; nil = ไม่เปลี่ยน, "" = ล้างค่า
+type ProfileInput struct {
+	JobTitle       *string
+	PositionLevel  *string
+	HireDate       *string // YYYY-MM-DD
+	Location       *string
+	EmploymentType *string
+	Attributes     map[string]string // nil = ไม่เปลี่ยน; ส่งมา = แทนทั้งชุด (value "" = ไม่เก็บ key นั้น)
+}
+
+// CreateInput ข้อมูลสร้าง user (EmployeeCode, Email, ชื่อ, Role, Password จำเป็น)
+type CreateInput struct {
+	EmployeeCode  string
+	Email         string
+	FirstName     string
+	LastName      string
+	Role          string
+	Phone         *string
+	ManagerUserID *string
+	Password      string
+	Profile       ProfileInput
+}
+
+// UpdateInput แก้ user; nil = ไม่เปลี่ยน
+type UpdateInput struct {
+	EmployeeCode  *string
+	Email         *string
+	FirstName     *string
+	LastName      *string
+	Role          *string
+	Phone         *string
+	ManagerUserID *string // "" = ล้างหัวหน้า
+	IsActive      *bool
+	Password      *string
+	Profile       ProfileInput
+}
+
+// UserWithDepartments user พร้อมแผนกที่สังกัด (preload Department)
+type UserWithDepartments struct {
+	User        usermodels.User
+	Departments []usermodels.UserDepartmentRole
+}
+
 type Repository interface {
 	// Users
-	FindAll(ctx context.Context, limit, offset int, q string) ([]usermodels.User, int64, error)
+	FindAll(ctx context.Context, f ListFilter, limit, offset int) ([]usermodels.User, int64, error)
 	FindByID(ctx context.Context, id string) (*usermodels.User, error)
 	FindByEmployeeCode(ctx context.Context, employeeCode string) (*usermodels.User, error)
+	FindByEmail(ctx context.Context, email string) (*usermodels.User, error)
 	Create(ctx context.Context, u *usermodels.User) error
-	Update(ctx context.Context, u *usermodels.User) error
+	// Update เขียนเฉพาะ columns ที่ระบุ (ชื่อคอลัมน์ใน DB) จากค่าใน u
+	Update(ctx context.Context, u *usermodels.User, columns ...string) error
 	Delete(ctx context.Context, id string) error
+	UpdateLoginState(ctx context.Context, id string, failedCount int, lastFailedAt, lockedUntil *time.Time) error
+	// RecordLoginFailure นับ login ผิด +1 แบบ atomic ใน DB; ตัวนับเริ่มใหม่ถ้าผิดครั้งก่อนเก่ากว่า staleBefore
+	// หรือ lock หมดอายุแล้ว, ครบ threshold = ล็อกถึง now+lockFor (คืนสถานะหลังนับ)
+	RecordLoginFailure(ctx context.Context, id string, now, staleBefore time.Time, threshold int, lockFor time.Duration) (failures int, lockedUntil *time.Time, err error)
+	BumpTokenVersion(ctx context.Context, id string) error
+	// UpdateRole เปลี่ยนเฉพาะคอลัมน์ role (ไม่ Save ทั้งแถวทับค่าที่ request อื่นเพิ่งแก้)
+	UpdateRole(ctx context.Context, id string, role usermodels.Role) error
+	SetMustChangePassword(ctx context.Context, id string, must bool) error
+	UpdateLastLogin(ctx context.Context, id string, at time.Time) error
+
+	// Password history (ใหม่สุดก่อน; keep = เก็บไว้กี่ตัวล่าสุด)
+	RecentPasswordHashes(ctx context.Context, userID string, n int) ([]string, error)
+	AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error
 
 	// Departments
 	ListDepartments(ctx context.Context, q string) ([]usermodels.Department, error)
@@ -22,33 +119,133 @@ type Repository interface {
 	UpdateDepartment(ctx context.Context, d *usermodels.Department) error
 	DeleteDepartment(ctx context.Context, id string) error
 
+	// Department hierarchy (repo ดูแล Path เอง; UpdateDepartment ไม่แตะ parent_id/path)
+	ListDepartmentsByIDs(ctx context.Context, ids []string) ([]usermodels.Department, error)
+	// ListDepartmentSubtree แผนกนี้และแผนกลูกทุกระดับ เรียงตาม path
+	ListDepartmentSubtree(ctx context.Context, id string) ([]usermodels.Department, error)
+	// MoveDepartment เปลี่ยนแผนกแม่ (nil = ขึ้นเป็นระดับบนสุด) แล้วแก้ path ของทั้ง subtree
+	// ย้ายไปใต้ตัวเอง/แผนกลูก = ErrDepartmentCycle
+	MoveDepartment(ctx context.Context, id string, parentID *string) error
+
+	// Reporting lines
+	ListDirectReports(ctx context.Context, managerID string) ([]usermodels.User, error)
+
 	// UserDepartmentRoles
 	ListUserDepartmentRoles(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error)
 	AddUserDepartmentRole(ctx context.Context, r *usermodels.UserDepartmentRole) error
-	DeleteUserDepartmentRole(ctx context.Context, relID string) error
+	// GetUserDepartmentRole เฉพาะสังกัดที่ยังไม่จบ
+	GetUserDepartmentRole(ctx context.Context, relID string) (*usermodels.UserDepartmentRole, error)
+	// EndUserDepartmentRole จบสังกัด ณ at (ตั้ง effective_to + soft delete); แถวยังอยู่ให้ดูย้อนหลังได้
+	EndUserDepartmentRole(ctx context.Context, relID string, at time.Time) error
+	// PurgeUserDepartmentRole ลบถาวร (ใช้กับสังกัดที่ผูกผิด ไม่ควรอยู่ในประวัติ)
+	PurgeUserDepartmentRole(ctx context.Context, relID string) error
+	// TransferUserDepartment จบสังกัด fromRelID แล้วสร้าง to ใน transaction เดียว ณ to.EffectiveFrom
+	TransferUserDepartment(ctx context.Context, fromRelID string, to *usermodels.UserDepartmentRole) error
+	// ListUserDepartmentHistory ทุกสังกัดรวมที่จบแล้ว เรียงตาม effective_from (preload Department)
+	ListUserDepartmentHistory(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error)
+	// ListDepartmentMembersAt คนที่สังกัดแผนก ณ เวลา at (preload User)
+	ListDepartmentMembersAt(ctx context.Context, departmentID string, at time.Time) ([]usermodels.UserDepartmentRole, error)
 	ListDepartmentManagers(ctx context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error)
+
+	// Bulk import (ค้นรวม user ที่ถูกลบแบบ soft delete ด้วย เพราะ unique index ยังชนกัน)
+	FindByEmployeeCodes(ctx context.Context, codes []string) ([]usermodels.User, error)
+	FindByEmails(ctx context.Context, emails []string) ([]usermodels.User, error)
+	// ApplyImport upsert user และสังกัดตาม ImportRecord.Membership ใน transaction เดียว (พังแถวเดียว = rollback ทั้งหมด)
+	ApplyImport(ctx context.Context, recs []ImportRecord) error
+
+	// SCIM provisioning (externalId = id ในระบบ HR)
+	FindByExternalID(ctx context.Context, externalID string) (*usermodels.User, error)
+	FindDepartmentByExternalID(ctx context.Context, externalID string) (*usermodels.Department, error)
+	// ListDepartmentMembers สมาชิกทุก role ของแผนก (preload User)
+	ListDepartmentMembers(ctx context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error)
+	// ListDepartmentRolesForUsers แผนกของ user หลายคนใน query เดียว (preload Department)
+	ListDepartmentRolesForUsers(ctx context.Context, userIDs []string) ([]usermodels.UserDepartmentRole, error)
+}
+
+// ImportRecord แถวที่ผ่าน validate แล้ว พร้อมเขียนลง DB
+type ImportRecord struct {
+	User           usermodels.User // ID ว่าง = สร้างใหม่; user เดิมอัปเดตแค่ email/ชื่อ/phone/ข้อมูลตำแหน่งงาน (+role ถ้า UpdateRole)
+	UpdateRole     bool
+	DepartmentID   string // ว่าง = ไม่ผูกแผนก
+	DepartmentRole usermodels.DepartmentRole
+
+	// Membership สังกัดที่ ApplyImport สร้างใน transaction เดียวกับ user (nil = ไม่ผูกแผนกหรือมีอยู่แล้ว)
+	// UserID ของ user ใหม่ repo เติมให้หลังสร้าง; EndRelID ไม่ว่าง = จบแถวนี้ ณ EffectiveFrom ก่อน (role เปลี่ยน)
+	Membership *usermodels.UserDepartmentRole
+	EndRelID   string
+}
+
+// ImportOptions ตัวเลือกของการ import จากไฟล์
+type ImportOptions struct {
+	DryRun    bool // validate อย่างเดียว ไม่เขียน DB
+	AllowRole bool // ผู้ import มีสิทธิ์ user.assign_role (ตั้ง/เปลี่ยน role ได้)
+}
+
+// ImportRowError ปัญหาของแถวหนึ่ง (Row นับแบบ spreadsheet: header = แถว 1)
+type ImportRowError struct {
+	Row          int    `json:"row"`
+	EmployeeCode string `json:"employee_code,omitempty"`
+	Field        string `json:"field"`
+	Code         string `json:"code"`
+	Message      string `json:"message"`
+}
+
+// ImportReport ผลการ import; ตอน dry run Created/Updated คือจำนวนที่ "จะ" เกิดขึ้น
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// DepartmentNode แผนกหนึ่งพร้อมแผนกลูก (tree API)
+type DepartmentNode struct {
+	Department usermodels.Department
+	Children   []*DepartmentNode
 }
 
 type Service interface {
 	// Users
-	List(ctx context.Context, page, perPage int, q string) ([]usermodels.User, int64, error)
+	List(ctx context.Context, f ListFilter, page, perPage int) ([]UserWithDepartments, int64, error)
 	Get(ctx context.Context, id string) (*usermodels.User, error)
-	Create(ctx context.Context, employeeCode, email, firstName, lastName, role string, phone *string, password string) (*usermodels.User, error)
-	Update(ctx context.Context, id string,
-		employeeCode, email, firstName, lastName, role *string,
-		phone *string, isActive *bool, password *string) (*usermodels.User, error)
+	Create(ctx context.Context, in CreateInput) (*usermodels.User, error)
+	Update(ctx context.Context, id string, in UpdateInput) (*usermodels.User, error)
 	Delete(ctx context.Context, id string) error
+	Unlock(ctx context.Context, id string) (*usermodels.User, error)
+	// ImportUsers นำเข้า user จาก CSV/XLSX (ดูคอลัมน์ใน service/import.go)
+	ImportUsers(ctx context.Context, filename string, r io.Reader, opts ImportOptions) (*ImportReport, error)
+	ListDirectReports(ctx context.Context, managerID string) ([]usermodels.User, error)
 
 	// Departments
 	ListDepartments(ctx context.Context, q string) ([]usermodels.Department, error)
 	GetDepartment(ctx context.Context, id string) (*usermodels.Department, error)
-	CreateDepartment(ctx context.Context, code, name string, isActive *bool) (*usermodels.Department, error)
-	UpdateDepartment(ctx context.Context, id string, code, name *string, isActive *bool) (*usermodels.Department, error)
-	DeleteDepartment(ctx context.Context, id string) error
+	CreateDepartment(ctx context.Context, code, name string, parentID *string, isActive *bool) (*usermodels.Department, error)
+	// parentID: nil = ไม่ย้าย, "" = ขึ้นเป็นระดับบนสุด
+	UpdateDepartment(ctx context.Context, id string, code, name, parentID *string, isActive *bool) (*usermodels.Department, error)
+	DeleteDepartment(ctx context.Context, id string) error // แผนกที่ยังมีแผนกลูกลบไม่ได้
+	DepartmentTree(ctx context.Context) ([]*DepartmentNode, error)
+	// ListDepartmentAncestors จากระดับบนสุดลงมาถึงแผนกแม่ (ไม่รวมตัวเอง)
+	ListDepartmentAncestors(ctx context.Context, id string) ([]usermodels.Department, error)
+	// ListDepartmentDescendants แผนกลูกทุกระดับ (ไม่รวมตัวเอง)
+	ListDepartmentDescendants(ctx context.Context, id string) ([]usermodels.Department, error)
 
 	// User department roles
 	ListUserDepartmentRoles(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error)
-	AddUserDepartmentRole(ctx context.Context, userID, departmentID, role string) (*usermodels.UserDepartmentRole, error)
-	DeleteUserDepartmentRole(ctx context.Context, relID string) error
+	// effectiveFrom nil = ตอนนี้; ย้อนหลังได้แต่ล่วงหน้าไม่ได้ และห้ามทับช่วงเดิมในแผนกเดียวกัน
+	// (เปลี่ยน role ใช้ ChangeDepartmentRole / PATCH /users/:id/department-roles/:relID)
+	AddUserDepartmentRole(ctx context.Context, userID, departmentID, role string, effectiveFrom *time.Time) (*usermodels.UserDepartmentRole, error)
+	// EndUserDepartmentRole จบสังกัด (at nil = ตอนนี้) ประวัติยังอยู่
+	EndUserDepartmentRole(ctx context.Context, userID, relID string, at *time.Time) error
+	// PurgeUserDepartmentRole ลบสังกัดที่ผูกผิดทิ้งถาวร ไม่เหลือในประวัติ
+	PurgeUserDepartmentRole(ctx context.Context, userID, relID string) error
+	// TransferUser ย้ายจาก fromDepartmentID ไป toDepartmentID (role nil = role เดิม, at nil = ตอนนี้)
+	TransferUser(ctx context.Context, userID, fromDepartmentID, toDepartmentID string, role *string, at *time.Time) (*usermodels.UserDepartmentRole, error)
+	// ChangeDepartmentRole เปลี่ยน role ของสังกัดปัจจุบัน relID: จบแถวเดิมแล้วเริ่มแถวใหม่ในแผนกเดิม (at nil = ตอนนี้)
+	ChangeDepartmentRole(ctx context.Context, userID, relID, role string, at *time.Time) (*usermodels.UserDepartmentRole, error)
+	ListUserDepartmentHistory(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error)
+	// ListDepartmentMembersAt "ใครอยู่แผนก X ณ วันที่ D"
+	ListDepartmentMembersAt(ctx context.Context, departmentID string, at time.Time) ([]usermodels.UserDepartmentRole, error)
 	ListDepartmentManagers(ctx context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error)
 }
@@ -0,0 +1,95 @@
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func (r *gormRepo) ListDepartmentsByIDs(ctx context.Context, ids []string) ([]usermodels.Department, error) {
+	var rows []usermodels.Department
+	if len(ids) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&rows).Error
+	return rows, err
+}
+
+func (r *gormRepo) ListDepartmentSubtree(ctx context.Context, id string) ([]usermodels.Department, error) {
+	d, err := r.GetDepartmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var rows []usermodels.Department
+	err = r.db.WithContext(ctx).
+		Where("path LIKE ?", d.Path+"%").
+		Order("path ASC").
+		Find(&rows).Error
+	return rows, err
+}
+
+// MoveDepartment ตรวจความลึกที่ service แล้ว; ที่นี่ล็อกแผนกที่ย้ายกับแผนกแม่ใหม่ (FOR UPDATE) แล้วตรวจ cycle ซ้ำ
+// กัน request ที่ย้ายสลับกันพร้อมกัน (A ไปใต้ B, B ไปใต้ A) ผ่านการตรวจของ service ทั้งคู่
+// จากนั้นเขียน parent และ path ใหม่ทั้ง subtree ใน transaction เดียวกัน
+func (r *gormRepo) MoveDepartment(ctx context.Context, id string, parentID *string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := []string{id}
+		if parentID != nil {
+			if *parentID == id {
+				return user.ErrDepartmentCycle
+			}
+			ids = append(ids, *parentID)
+		}
+		// ล็อกตามลำดับ id เสมอ กัน deadlock ระหว่างสอง transaction
+		var rows []usermodels.Department
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", ids).Order("id ASC").Find(&rows).Error; err != nil {
+			return err
+		}
+		var d, p *usermodels.Department
+		for i := range rows {
+			if rows[i].ID == id {
+				d = &rows[i]
+			} else {
+				p = &rows[i]
+			}
+		}
+		if d == nil || (parentID != nil && p == nil) {
+			return gorm.ErrRecordNotFound
+		}
+		prefix := "/"
+		if p != nil {
+			// path ที่อ่านหลังได้ lock เป็นค่าล่าสุดที่ commit แล้ว
+			if strings.Contains(p.Path, "/"+d.ID+"/") {
+				return user.ErrDepartmentCycle
+			}
+			prefix = p.Path
+		}
+		newPath := prefix + d.ID + "/"
+		if err := tx.Model(&usermodels.Department{}).Where("id = ?", d.ID).
+			Updates(map[string]any{"parent_id": parentID, "path": newPath}).Error; err != nil {
+			return err
+		}
+		if d.Path == "" || d.Path == newPath {
+			return nil
+		}
+		// แผนกลูก (รวมที่ถูกลบแล้ว) เปลี่ยน prefix จาก path เดิมเป็น path ใหม่
+		return tx.Unscoped().Model(&usermodels.Department{}).
+			Where("path LIKE ? AND id <> ?", d.Path+"%", d.ID).
+			UpdateColumn("path", gorm.Expr("CONCAT(?, SUBSTRING(path, ?))", newPath, len(d.Path)+1)).Error
+	})
+}
+
+func (r *gormRepo) ListDirectReports(ctx context.Context, managerID string) ([]usermodels.User, error) {
+	var rows []usermodels.User
+	err := r.db.WithContext(ctx).
+		Where("manager_user_id = ?", managerID).
+		Order("first_name ASC, last_name ASC").
+		Find(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func (r *gormRepo) FindByEmployeeCodes(ctx context.Context, codes []string) ([]usermodels.User, error) {
+	var rows []usermodels.User
+	if len(codes) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Where("employee_code IN ?", codes).Find(&rows).Error
+	return rows, err
+}
+
+func (r *gormRepo) FindByEmails(ctx context.Context, emails []string) ([]usermodels.User, error) {
+	var rows []usermodels.User
+	if len(emails) == 0 {
+		return rows, nil
+	}
+	err := r.db.WithContext(ctx).Unscoped().Where("email IN ?", emails).Find(&rows).Error
+	return rows, err
+}
+
+func (r *gormRepo) ApplyImport(ctx context.Context, recs []user.ImportRecord) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i := range recs {
+			rec := &recs[i]
+			u := &rec.User
+			if u.ID == "" {
+				if err := tx.Create(u).Error; err != nil {
+					return err
+				}
+			} else {
+				fields := map[string]any{
+					"email":      u.Email,
+					"first_name": u.FirstName,
+					"last_name":  u.LastName,
+				}
+				if u.Phone != nil {
+					fields["phone"] = u.Phone
+				}
+				if rec.UpdateRole {
+					fields["role"] = u.Role
+				}
+				// service รวมค่าเดิมกับค่าในไฟล์มาแล้ว เขียนทับได้ทั้งชุด
+				fields["job_title"] = u.JobTitle
+				fields["position_level"] = u.PositionLevel
+				fields["hire_date"] = u.HireDate
+				fields["location"] = u.Location
+				fields["employment_type"] = u.EmploymentType
+				fields["attributes"] = u.Attributes
+				if err := tx.Model(&usermodels.User{}).Where("id = ?", u.ID).Updates(fields).Error; err != nil {
+					return err
+				}
+			}
+			if m := rec.Membership; m != nil {
+				m.UserID = u.ID
+				if rec.EndRelID != "" {
+					if err := transferDepartmentRole(tx, rec.EndRelID, m); err != nil {
+						return err
+					}
+				} else if err := tx.Create(m).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -2,7 +2,9 @@ package repo
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/Marugo/birdlax/internal/modules/user"
@@ -15,27 +17,105 @@ func NewGormRepository(db *gorm.DB) user.Repository { return &gormRepo{db: db} }
 
 // ===== Users =====
 
-func (r *gormRepo) FindAll(ctx context.Context, limit, offset int, q string) ([]usermodels.User, int64, error) {
+func (r *gormRepo) FindAll(ctx context.Context, f user.ListFilter, limit, offset int) ([]usermodels.User, int64, error) {
 	var rows []usermodels.User
 	tx := r.db.WithContext(ctx).Model(&usermodels.User{})
-	if q != "" {
+	if q := f.Q; q != "" {
 		tx = tx.Where(`
 		employee_code LIKE ? OR email LIKE ? OR first_name LIKE ? OR last_name LIKE ? OR
 		CONCAT(first_name,' ',last_name) LIKE ?`,
 			"%"+q+"%", "%"+q+"%", "%"+q+"%", "%"+q+"%", "%"+q+"%",
 		)
 	}
+	if f.Role != "" {
+		tx = tx.Where("role = ?", f.Role)
+	}
+	if f.IsActive != nil {
+		tx = tx.Where("is_active = ?", *f.IsActive)
+	}
+	if f.CreatedFrom != nil {
+		tx = tx.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		tx = tx.Where("created_at < ?", *f.CreatedTo)
+	}
+	for col, v := range map[string]string{
+		"job_title":       f.JobTitle,
+		"position_level":  f.PositionLevel,
+		"location":        f.Location,
+		"employment_type": f.EmploymentType,
+	} {
+		if v != "" {
+			tx = tx.Where(col+" = ?", v)
+		}
+	}
+	for k, v := range f.Attributes {
+		// key ผ่าน validate ที่ service แล้ว ([a-z0-9_])
+		cond, arg, err := attributeCondition(r.db.Dialector.Name(), k)
+		if err != nil {
+			return nil, 0, err
+		}
+		tx = tx.Where(cond, arg, v)
+	}
+	if f.DepartmentID != "" || f.DepartmentRole != "" {
+		// แผนก + role ต้องอยู่ในแถวเดียวกัน (manager ของแผนก A ไม่ใช่ manager ของทุกแผนกที่สังกัด)
+		sub := r.db.WithContext(ctx).Table("user_department_roles AS udr").
+			Select("1").
+			Where("udr.user_id = users.id AND udr.deleted_at IS NULL")
+		switch {
+		case f.DepartmentID != "" && f.SubDepartments:
+			sub = sub.Joins("JOIN departments d ON d.id = udr.department_id AND d.deleted_at IS NULL").
+				Where("d.path LIKE CONCAT((SELECT p.path FROM departments p WHERE p.id = ?), '%')", f.DepartmentID)
+		case f.DepartmentID != "":
+			sub = sub.Where("udr.department_id = ?", f.DepartmentID)
+		}
+		if f.DepartmentRole != "" {
+			sub = sub.Where("udr.role = ?", f.DepartmentRole)
+		}
+		tx = tx.Where("EXISTS (?)", sub)
+	}
 
 	var total int64
 	if err := tx.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
-	if err := tx.Order("created_at DESC").Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
+	if err := tx.Order(userOrder(f.Sort, f.Desc)).Limit(limit).Offset(offset).Find(&rows).Error; err != nil {
 		return nil, 0, err
 	}
 	return rows, total, nil
 }
 
+// attributeCondition เงื่อนไข attributes.<key> = ? ตาม dialect (คอลัมน์เป็น text ที่เก็บ JSON)
+func attributeCondition(dialect, key string) (string, string, error) {
+	switch dialect {
+	case "mysql":
+		return "JSON_UNQUOTE(JSON_EXTRACT(attributes, ?)) = ?", `$."` + key + `"`, nil
+	case "postgres":
+		return "(attributes::jsonb ->> ?) = ?", key, nil
+	case "sqlite":
+		return "json_extract(attributes, ?) = ?", `$."` + key + `"`, nil
+	}
+	return "", "", user.ErrAttributeFilterUnsupported
+}
+
+// userOrder แปลง sort key เป็น ORDER BY (service ตรวจ key แล้ว); ปิดท้ายด้วย id ให้แบ่งหน้าได้นิ่ง
+func userOrder(sort string, desc bool) string {
+	dir := " ASC"
+	if desc {
+		dir = " DESC"
+	}
+	switch sort {
+	case user.SortName:
+		return "first_name" + dir + ", last_name" + dir + ", id"
+	case user.SortEmployeeCode:
+		return "employee_code" + dir + ", id"
+	case user.SortLastLogin:
+		// คนที่ยังไม่เคย login อยู่ท้ายเสมอ
+		return "last_login_at IS NULL, last_login_at" + dir + ", id"
+	}
+	return "created_at" + dir + ", id"
+}
+
 func (r *gormRepo) FindByID(ctx context.Context, id string) (*usermodels.User, error) {
 	var u usermodels.User
 	if err := r.db.WithContext(ctx).First(&u, "id = ?", id).Error; err != nil {
@@ -52,16 +132,127 @@ func (r *gormRepo) FindByEmployeeCode(ctx context.Context, code string) (*usermo
 	return &u, nil
 }
 
+func (r *gormRepo) FindByEmail(ctx context.Context, email string) (*usermodels.User, error) {
+	var u usermodels.User
+	if err := r.db.WithContext(ctx).First(&u, "email = ?", email).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
 func (r *gormRepo) Create(ctx context.Context, u *usermodels.User) error {
 	return r.db.WithContext(ctx).Create(u).Error
 }
-func (r *gormRepo) Update(ctx context.Context, u *usermodels.User) error {
-	return r.db.WithContext(ctx).Save(u).Error
+
+// Update เขียนเฉพาะคอลัมน์ที่ caller แก้ (+updated_at) ไม่ Save ทั้งแถว:
+// สถานะ lockout/last login/token_version มีที่อื่นอัปเดตแบบ atomic ถ้าเขียนค่าที่อ่านมาก่อนกลับไปจะทับของใหม่
+func (r *gormRepo) Update(ctx context.Context, u *usermodels.User, columns ...string) error {
+	if len(columns) == 0 {
+		return nil
+	}
+	cols := append([]string{"updated_at"}, columns...)
+	return r.db.WithContext(ctx).Model(u).Select(cols).Updates(u).Error
 }
 func (r *gormRepo) Delete(ctx context.Context, id string) error {
 	return r.db.WithContext(ctx).Delete(&usermodels.User{}, "id = ?", id).Error
 }
 
+// UpdateLoginState อัปเดตเฉพาะคอลัมน์ lockout (ไม่ใช้ Save เพื่อไม่ทับฟิลด์อื่น)
+func (r *gormRepo) UpdateLoginState(ctx context.Context, id string, failedCount int, lastFailedAt, lockedUntil *time.Time) error {
+	return r.db.WithContext(ctx).Model(&usermodels.User{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"failed_login_count":   failedCount,
+			"last_failed_login_at": lastFailedAt,
+			"locked_until":         lockedUntil,
+		}).Error
+}
+
+func (r *gormRepo) RecordLoginFailure(ctx context.Context, id string, now, staleBefore time.Time, threshold int, lockFor time.Duration) (int, *time.Time, error) {
+	var u usermodels.User
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// +1 ใน UPDATE เดียว (ไม่คำนวณจากค่าที่อ่านมาก่อน) request ที่ยิงพร้อมกันจึงไม่ทำให้นับหาย
+		if err := tx.Model(&usermodels.User{}).Where("id = ?", id).Updates(map[string]any{
+			"failed_login_count": gorm.Expr(`CASE WHEN failed_login_count = 0
+				OR (locked_until IS NOT NULL AND locked_until <= ?)
+				OR (locked_until IS NULL AND (last_failed_login_at IS NULL OR last_failed_login_at < ?))
+				THEN 1 ELSE failed_login_count + 1 END`, now, staleBefore),
+			"locked_until":         gorm.Expr("CASE WHEN locked_until > ? THEN locked_until ELSE NULL END", now),
+			"last_failed_login_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		// แถวถูก lock จาก UPDATE ข้างบนแล้วจนจบ transaction
+		if err := tx.Select("id", "failed_login_count", "locked_until").First(&u, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if threshold > 0 && u.FailedLoginCount >= threshold && u.LockedUntil == nil {
+			until := now.Add(lockFor)
+			u.LockedUntil = &until
+			return tx.Model(&usermodels.User{}).Where("id = ?", id).UpdateColumn("locked_until", until).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return u.FailedLoginCount, u.LockedUntil, nil
+}
+
+func (r *gormRepo) UpdateLastLogin(ctx context.Context, id string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&usermodels.User{}).
+		Where("id = ?", id).
+		UpdateColumn("last_login_at", at).Error
+}
+
+func (r *gormRepo) BumpTokenVersion(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Model(&usermodels.User{}).
+		Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
+func (r *gormRepo) UpdateRole(ctx context.Context, id string, role usermodels.Role) error {
+	return r.db.WithContext(ctx).Model(&usermodels.User{}).
+		Where("id = ?", id).
+		Update("role", role).Error
+}
+
+func (r *gormRepo) SetMustChangePassword(ctx context.Context, id string, must bool) error {
+	return r.db.WithContext(ctx).Model(&usermodels.User{}).
+		Where("id = ?", id).
+		Update("must_change_password", must).Error
+}
+
+func (r *gormRepo) RecentPasswordHashes(ctx context.Context, userID string, n int) ([]string, error) {
+	var hashes []string
+	err := r.db.WithContext(ctx).Model(&usermodels.PasswordHistory{}).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").Limit(n).
+		Pluck("hash", &hashes).Error
+	return hashes, err
+}
+
+// AddPasswordHistory บันทึก hash ใหม่แล้วลบตัวที่เก่ากว่า keep ตัวล่าสุดทิ้ง
+func (r *gormRepo) AddPasswordHistory(ctx context.Context, userID, hash string, keep int) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&usermodels.PasswordHistory{UserID: userID, Hash: hash}).Error; err != nil {
+			return err
+		}
+		var keepIDs []string
+		if err := tx.Model(&usermodels.PasswordHistory{}).
+			Where("user_id = ?", userID).
+			Order("created_at DESC").Limit(keep).
+			Pluck("id", &keepIDs).Error; err != nil {
+			return err
+		}
+		q := tx.Where("user_id = ?", userID)
+		if len(keepIDs) > 0 {
+			q = q.Where("id NOT IN ?", keepIDs)
+		}
+		return q.Delete(&usermodels.PasswordHistory{}).Error
+	})
+}
+
 // ===== Departments =====
 
 func (r *gormRepo) ListDepartments(ctx context.Context, q string) ([]usermodels.Department, error) {
@@ -84,12 +275,28 @@ func (r *gormRepo) GetDepartmentByID(ctx context.Context, id string) (*usermodel
 	return &d, nil
 }
 
+// CreateDepartment คำนวณ path จาก ParentID ให้ (parent ต้องมีอยู่จริง)
 func (r *gormRepo) CreateDepartment(ctx context.Context, d *usermodels.Department) error {
-	return r.db.WithContext(ctx).Create(d).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if d.ID == "" {
+			d.ID = uuid.NewString()
+		}
+		prefix := "/"
+		if d.ParentID != nil {
+			var p usermodels.Department
+			if err := tx.First(&p, "id = ?", *d.ParentID).Error; err != nil {
+				return err
+			}
+			prefix = p.Path
+		}
+		d.Path = prefix + d.ID + "/"
+		return tx.Create(d).Error
+	})
 }
 
+// UpdateDepartment ไม่แตะ parent_id/path (ย้ายแผนกผ่าน MoveDepartment เท่านั้น)
 func (r *gormRepo) UpdateDepartment(ctx context.Context, d *usermodels.Department) error {
-	return r.db.WithContext(ctx).Save(d).Error
+	return r.db.WithContext(ctx).Omit("parent_id", "path").Save(d).Error
 }
 
 func (r *gormRepo) DeleteDepartment(ctx context.Context, id string) error {
@@ -113,8 +320,80 @@ func (r *gormRepo) AddUserDepartmentRole(ctx context.Context, rel *usermodels.Us
 	return r.db.WithContext(ctx).Create(rel).Error
 }
 
-func (r *gormRepo) DeleteUserDepartmentRole(ctx context.Context, relID string) error {
-	return r.db.WithContext(ctx).Delete(&usermodels.UserDepartmentRole{}, "id = ?", relID).Error
+func (r *gormRepo) GetUserDepartmentRole(ctx context.Context, relID string) (*usermodels.UserDepartmentRole, error) {
+	var rel usermodels.UserDepartmentRole
+	if err := r.db.WithContext(ctx).First(&rel, "id = ?", relID).Error; err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+func (r *gormRepo) EndUserDepartmentRole(ctx context.Context, relID string, at time.Time) error {
+	return endDepartmentRole(r.db.WithContext(ctx), relID, at)
+}
+
+func endDepartmentRole(tx *gorm.DB, relID string, at time.Time) error {
+	res := tx.Model(&usermodels.UserDepartmentRole{}).
+		Where("id = ? AND effective_to IS NULL", relID).
+		Updates(map[string]any{"effective_to": at, "deleted_at": time.Now()})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *gormRepo) PurgeUserDepartmentRole(ctx context.Context, relID string) error {
+	res := r.db.WithContext(ctx).Unscoped().Delete(&usermodels.UserDepartmentRole{}, "id = ?", relID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *gormRepo) TransferUserDepartment(ctx context.Context, fromRelID string, to *usermodels.UserDepartmentRole) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return transferDepartmentRole(tx, fromRelID, to)
+	})
+}
+
+// transferDepartmentRole จบ fromRelID ณ to.EffectiveFrom แล้วสร้าง to (tx ต้องเป็น transaction อยู่แล้ว)
+func transferDepartmentRole(tx *gorm.DB, fromRelID string, to *usermodels.UserDepartmentRole) error {
+	if err := endDepartmentRole(tx, fromRelID, to.EffectiveFrom); err != nil {
+		return err
+	}
+	return tx.Create(to).Error
+}
+
+func (r *gormRepo) ListUserDepartmentHistory(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error) {
+	var rows []usermodels.UserDepartmentRole
+	if err := r.db.WithContext(ctx).Unscoped().
+		Preload("Department", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("user_id = ?", userID).
+		Order("effective_from ASC").Order("created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *gormRepo) ListDepartmentMembersAt(ctx context.Context, departmentID string, at time.Time) ([]usermodels.UserDepartmentRole, error) {
+	var rows []usermodels.UserDepartmentRole
+	// แถวที่จบแล้วถูก soft delete จึงต้อง Unscoped; ช่วงเวลาตัดสินจาก effective_from/effective_to
+	if err := r.db.WithContext(ctx).Unscoped().
+		Preload("User", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Where("department_id = ? AND effective_from <= ? AND (effective_to IS NULL OR effective_to > ?)",
+			departmentID, at, at).
+		Order("effective_from ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
 }
 
 func (r *gormRepo) ListDepartmentManagers(ctx context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error) {
@@ -0,0 +1,161 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&usermodels.User{}, &usermodels.Department{}, &usermodels.UserDepartmentRole{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// HR แก้เบอร์โทรจาก user ที่อ่านมาก่อนถูกล็อก ต้องไม่ปลดล็อก/ล้างตัวนับที่ RecordLoginFailure เพิ่งเขียน
+func TestUpdateKeepsConcurrentLockout(t *testing.T) {
+	ctx := context.Background()
+	r := NewGormRepository(newTestDB(t))
+	u := &usermodels.User{EmployeeCode: "E1", Email: "e1@example.com", FirstName: "A", LastName: "B", PasswordHash: "x", IsActive: true}
+	if err := r.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := r.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, _, err := r.RecordLoginFailure(ctx, u.ID, now, now.Add(-time.Hour), 3, 15*time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.BumpTokenVersion(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	phone := "0812345678"
+	stale.Phone = &phone
+	if err := r.Update(ctx, stale, "phone"); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := r.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Phone == nil || *got.Phone != phone {
+		t.Fatalf("phone = %v, want %s", got.Phone, phone)
+	}
+	if got.LockedUntil == nil || !got.LockedUntil.After(now) {
+		t.Fatalf("locked_until = %v, want a lock after %v", got.LockedUntil, now)
+	}
+	if got.FailedLoginCount != 3 || got.LastFailedLoginAt == nil {
+		t.Fatalf("failed_login_count = %d, last_failed_login_at = %v", got.FailedLoginCount, got.LastFailedLoginAt)
+	}
+	if got.TokenVersion != 1 {
+		t.Fatalf("token_version = %d, want 1", got.TokenVersion)
+	}
+}
+
+func TestFindAllFiltersByAttribute(t *testing.T) {
+	ctx := context.Background()
+	r := NewGormRepository(newTestDB(t))
+	for _, shift := range []string{"night", "day"} {
+		u := &usermodels.User{
+			EmployeeCode: "E" + shift, Email: shift + "@example.com", FirstName: "A", LastName: "B", PasswordHash: "x",
+			IsActive: true, Attributes: usermodels.Attributes{"shift": shift},
+		}
+		if err := r.Create(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rows, total, err := r.FindAll(ctx, user.ListFilter{Attributes: map[string]string{"shift": "night"}}, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(rows) != 1 || rows[0].EmployeeCode != "Enight" {
+		t.Fatalf("total = %d rows = %+v", total, rows)
+	}
+
+	if _, _, err := attributeCondition("sqlserver", "shift"); !errors.Is(err, user.ErrAttributeFilterUnsupported) {
+		t.Fatalf("unknown dialect: err = %v", err)
+	}
+}
+
+// สังกัดจาก import เขียนใน transaction เดียวกับ user: แถวถัดไปพัง = ไม่มีทั้ง user และสังกัดค้าง
+func TestApplyImportWritesMembershipsInTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := newTestDB(t)
+	r := NewGormRepository(db)
+	old := &usermodels.User{EmployeeCode: "E1", Email: "e1@example.com", FirstName: "A", LastName: "B", PasswordHash: "x", IsActive: true}
+	if err := r.Create(ctx, old); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now().Add(-24 * time.Hour)
+	rel := &usermodels.UserDepartmentRole{UserID: old.ID, DepartmentID: "d1", Role: usermodels.DeptRoleMember, EffectiveFrom: start}
+	if err := r.AddUserDepartmentRole(ctx, rel); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	recs := []user.ImportRecord{
+		{
+			User:       *old,
+			Membership: &usermodels.UserDepartmentRole{DepartmentID: "d1", Role: usermodels.DeptRoleManager, EffectiveFrom: now},
+			EndRelID:   rel.ID,
+		},
+		{
+			User:       usermodels.User{EmployeeCode: "E2", Email: "e2@example.com", FirstName: "C", LastName: "D", PasswordHash: "x"},
+			Membership: &usermodels.UserDepartmentRole{DepartmentID: "d1", Role: usermodels.DeptRoleMember, EffectiveFrom: now},
+		},
+		// email ซ้ำกับแถวแรก => ทั้ง transaction ต้อง rollback
+		{User: usermodels.User{EmployeeCode: "E3", Email: "e1@example.com", FirstName: "E", LastName: "F", PasswordHash: "x"}},
+	}
+	if err := r.ApplyImport(ctx, recs); err == nil {
+		t.Fatal("duplicate email: want error")
+	}
+	cur, err := r.ListUserDepartmentRoles(ctx, old.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cur) != 1 || cur[0].ID != rel.ID || cur[0].Role != usermodels.DeptRoleMember {
+		t.Fatalf("after rollback: %+v", cur)
+	}
+
+	recs = recs[:2]
+	recs[0].Membership.ID, recs[1].Membership.ID, recs[1].User.ID = "", "", ""
+	if err := r.ApplyImport(ctx, recs); err != nil {
+		t.Fatal(err)
+	}
+	hist, err := r.ListUserDepartmentHistory(ctx, old.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hist) != 2 || hist[0].EffectiveTo == nil || hist[1].Role != usermodels.DeptRoleManager || hist[1].EffectiveTo != nil {
+		t.Fatalf("history = %+v", hist)
+	}
+	created, err := r.ListUserDepartmentRoles(ctx, recs[1].User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0].UserID == "" || created[0].Role != usermodels.DeptRoleMember {
+		t.Fatalf("new user memberships = %+v", created)
+	}
+}
@@ -0,0 +1,49 @@
+package repo
+
+import (
+	"context"
+
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func (r *gormRepo) FindByExternalID(ctx context.Context, externalID string) (*usermodels.User, error) {
+	var u usermodels.User
+	if err := r.db.WithContext(ctx).First(&u, "external_id = ?", externalID).Error; err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *gormRepo) FindDepartmentByExternalID(ctx context.Context, externalID string) (*usermodels.Department, error) {
+	var d usermodels.Department
+	if err := r.db.WithContext(ctx).First(&d, "external_id = ?", externalID).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *gormRepo) ListDepartmentMembers(ctx context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error) {
+	var rows []usermodels.UserDepartmentRole
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("department_id = ?", departmentID).
+		Order("created_at ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (r *gormRepo) ListDepartmentRolesForUsers(ctx context.Context, userIDs []string) ([]usermodels.UserDepartmentRole, error) {
+	var rows []usermodels.UserDepartmentRole
+	if len(userIDs) == 0 {
+		return rows, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Preload("Department").
+		Where("user_id IN ?", userIDs).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// ความยาวสูงสุดของ departments.path (~27 ระดับ); แต่ละระดับกิน uuid + "/"
+const (
+	maxDepartmentPath     = 1024
+	departmentPathSegment = 37
+)
+
+// หยุดไล่สายบังคับบัญชาเมื่อเกินนี้ (กันข้อมูลเก่าที่วนอยู่แล้ว)
+const maxReportingDepth = 100
+
+func (s *svc) ListDirectReports(ctx context.Context, managerID string) ([]usermodels.User, error) {
+	if _, err := s.repo.FindByID(ctx, managerID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDirectReports(ctx, managerID)
+}
+
+// checkManager managerID ต้องมีอยู่จริง และไม่ใช่ตัวเองหรือลูกน้องในสายของ userID ("" = user ใหม่)
+func (s *svc) checkManager(ctx context.Context, userID, managerID string) error {
+	if managerID == userID {
+		return ErrManagerCycle
+	}
+	cur := managerID
+	for i := 0; i < maxReportingDepth; i++ {
+		m, err := s.repo.FindByID(ctx, cur)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if i == 0 {
+				return ErrManagerNotFound
+			}
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if m.ManagerUserID == nil {
+			return nil
+		}
+		if userID != "" && *m.ManagerUserID == userID {
+			return ErrManagerCycle
+		}
+		cur = *m.ManagerUserID
+	}
+	return nil
+}
+
+func (s *svc) parentDepartment(ctx context.Context, id string) (*usermodels.Department, error) {
+	p, err := s.repo.GetDepartmentByID(ctx, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrParentDepartmentNotFound
+	}
+	return p, err
+}
+
+type departmentMove struct {
+	parentID *string // nil = ระดับบนสุด
+}
+
+// planMove ตรวจว่าย้าย d ไปอยู่ใต้ parentID ได้ ("" = ระดับบนสุด); nil = ไม่ต้องย้าย
+func (s *svc) planMove(ctx context.Context, d *usermodels.Department, parentID string) (*departmentMove, error) {
+	var newParent *string
+	prefixLen := 1
+	if parentID != "" {
+		if parentID == d.ID {
+			return nil, ErrDepartmentCycle
+		}
+		p, err := s.parentDepartment(ctx, parentID)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(p.Path, "/"+d.ID+"/") {
+			return nil, ErrDepartmentCycle
+		}
+		newParent, prefixLen = &p.ID, len(p.Path)
+	}
+	if (d.ParentID == nil && newParent == nil) || (d.ParentID != nil && newParent != nil && *d.ParentID == *newParent) {
+		return nil, nil
+	}
+
+	// path ที่ยาวที่สุดใน subtree หลังย้ายต้องไม่เกินขนาดคอลัมน์
+	sub, err := s.repo.ListDepartmentSubtree(ctx, d.ID)
+	if err != nil {
+		return nil, err
+	}
+	deepest := len(d.Path)
+	for _, x := range sub {
+		if len(x.Path) > deepest {
+			deepest = len(x.Path)
+		}
+	}
+	if prefixLen+len(d.ID)+1+(deepest-len(d.Path)) > maxDepartmentPath {
+		return nil, ErrDepartmentTooDeep
+	}
+	return &departmentMove{parentID: newParent}, nil
+}
+
+// DepartmentTree ทุกแผนกเป็นต้นไม้ (ลูกเรียงตามชื่อ); แผนกที่แม่ถูกลบไปแล้วขึ้นเป็นระดับบนสุด
+func (s *svc) DepartmentTree(ctx context.Context) ([]*user.DepartmentNode, error) {
+	all, err := s.repo.ListDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	nodes := make(map[string]*user.DepartmentNode, len(all))
+	for _, d := range all {
+		nodes[d.ID] = &user.DepartmentNode{Department: d, Children: []*user.DepartmentNode{}}
+	}
+	roots := []*user.DepartmentNode{}
+	for _, d := range all {
+		n := nodes[d.ID]
+		if d.ParentID != nil {
+			if p, ok := nodes[*d.ParentID]; ok {
+				p.Children = append(p.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	return roots, nil
+}
+
+func (s *svc) ListDepartmentAncestors(ctx context.Context, id string) ([]usermodels.Department, error) {
+	d, err := s.repo.GetDepartmentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := strings.Split(strings.Trim(d.Path, "/"), "/")
+	if len(ids) <= 1 {
+		return []usermodels.Department{}, nil
+	}
+	ids = ids[:len(ids)-1]
+	rows, err := s.repo.ListDepartmentsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]usermodels.Department, len(rows))
+	for _, r := range rows {
+		byID[r.ID] = r
+	}
+	out := make([]usermodels.Department, 0, len(ids))
+	for _, aid := range ids {
+		if a, ok := byID[aid]; ok {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func (s *svc) ListDepartmentDescendants(ctx context.Context, id string) ([]usermodels.Department, error) {
+	sub, err := s.repo.ListDepartmentSubtree(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]usermodels.Department, 0, len(sub))
+	for _, d := range sub {
+		if d.ID != id {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
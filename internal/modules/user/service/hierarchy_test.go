@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// fakeHierarchy แผนก a > b > c และสายบังคับบัญชา u1 <- u2 <- u3 (u3 รายงาน u2, u2 รายงาน u1)
+type fakeHierarchy struct {
+	user.Repository
+	depts   map[string]*usermodels.Department
+	users   map[string]*usermodels.User
+	moves   int
+	updates int
+}
+
+func newFakeHierarchy() *fakeHierarchy {
+	a, b := "a", "b"
+	u1, u2 := "u1", "u2"
+	return &fakeHierarchy{
+		depts: map[string]*usermodels.Department{
+			"a": {ID: "a", Path: "/a/"},
+			"b": {ID: "b", ParentID: &a, Path: "/a/b/"},
+			"c": {ID: "c", ParentID: &b, Path: "/a/b/c/"},
+		},
+		users: map[string]*usermodels.User{
+			"u1": {ID: "u1"},
+			"u2": {ID: "u2", ManagerUserID: &u1},
+			"u3": {ID: "u3", ManagerUserID: &u2},
+		},
+	}
+}
+
+func (f *fakeHierarchy) GetDepartmentByID(_ context.Context, id string) (*usermodels.Department, error) {
+	d, ok := f.depts[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+func (f *fakeHierarchy) ListDepartmentSubtree(_ context.Context, id string) ([]usermodels.Department, error) {
+	var out []usermodels.Department
+	for _, d := range f.depts {
+		if strings.Contains(d.Path, "/"+id+"/") {
+			out = append(out, *d)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeHierarchy) UpdateDepartment(context.Context, *usermodels.Department) error { return nil }
+
+func (f *fakeHierarchy) MoveDepartment(context.Context, string, *string) error {
+	f.moves++
+	return nil
+}
+
+func (f *fakeHierarchy) FindByID(_ context.Context, id string) (*usermodels.User, error) {
+	u, ok := f.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	cp := *u
+	return &cp, nil
+}
+
+func (f *fakeHierarchy) Update(context.Context, *usermodels.User, ...string) error {
+	f.updates++
+	return nil
+}
+
+func TestMoveDepartmentRejectsCycles(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct{ name, id, parent string }{
+		{"under itself", "a", "a"},
+		{"a under b under a", "a", "b"},
+		{"under own grandchild", "a", "c"},
+		{"under own child", "b", "c"},
+	} {
+		repo := newFakeHierarchy()
+		s := &svc{repo: repo}
+		parent := tc.parent
+		if _, err := s.UpdateDepartment(ctx, tc.id, nil, nil, &parent, nil); !errors.Is(err, ErrDepartmentCycle) {
+			t.Fatalf("%s: err = %v", tc.name, err)
+		}
+		if repo.moves != 0 {
+			t.Fatalf("%s: MoveDepartment called", tc.name)
+		}
+	}
+}
+
+func TestMoveDepartmentAllowsSiblingBranch(t *testing.T) {
+	repo := newFakeHierarchy()
+	s := &svc{repo: repo}
+	top := ""
+	if _, err := s.UpdateDepartment(context.Background(), "c", nil, nil, &top, nil); err != nil {
+		t.Fatal(err)
+	}
+	a := "a"
+	if _, err := s.UpdateDepartment(context.Background(), "c", nil, nil, &a, nil); err != nil {
+		t.Fatal(err)
+	}
+	if repo.moves != 2 {
+		t.Fatalf("moves = %d", repo.moves)
+	}
+}
+
+func TestUpdateManagerRejectsCycles(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct{ name, id, manager string }{
+		{"self", "u1", "u1"},
+		{"u1 -> u2 -> u1", "u1", "u2"},
+		{"through the chain", "u1", "u3"},
+	} {
+		repo := newFakeHierarchy()
+		s := &svc{repo: repo}
+		mid := tc.manager
+		if _, err := s.Update(ctx, tc.id, user.UpdateInput{ManagerUserID: &mid}); !errors.Is(err, ErrManagerCycle) {
+			t.Fatalf("%s: err = %v", tc.name, err)
+		}
+		if repo.updates != 0 {
+			t.Fatalf("%s: user updated", tc.name)
+		}
+	}
+
+	repo := newFakeHierarchy()
+	s := &svc{repo: repo}
+	mid := "u1"
+	if _, err := s.Update(ctx, "u3", user.UpdateInput{ManagerUserID: &mid}); err != nil {
+		t.Fatalf("skip-level manager: %v", err)
+	}
+	missing := "nobody"
+	if _, err := s.Update(ctx, "u3", user.UpdateInput{ManagerUserID: &missing}); !errors.Is(err, ErrManagerNotFound) {
+		t.Fatalf("missing manager: err = %v", err)
+	}
+}
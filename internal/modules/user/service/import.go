@@ -0,0 +1,488 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+	"github.com/Marugo/birdlax/internal/shared/identity"
+	"github.com/Marugo/birdlax/internal/shared/password"
+)
+
+var (
+	ErrImportUnsupportedFormat = errors.New("unsupported file type (use .csv or .xlsx)")
+	ErrImportEmpty             = errors.New("file has no data rows")
+	ErrImportMissingColumns    = errors.New("missing required columns")
+	ErrImportTooManyRows       = fmt.Errorf("file has more than %d rows", importMaxRows)
+)
+
+const importMaxRows = 5000
+
+// รหัส error ต่อแถวใน ImportReport
+const (
+	importErrRequired       = "REQUIRED"
+	importErrInvalid        = "INVALID"
+	importErrDuplicate      = "DUPLICATE_IN_FILE"
+	importErrEmailTaken     = "EMAIL_TAKEN"
+	importErrUserDeleted    = "USER_DELETED"
+	importErrRoleNotAllowed = "ROLE_NOT_ALLOWED"
+	importErrUnknownDept    = "UNKNOWN_DEPARTMENT"
+)
+
+// ชื่อคอลัมน์ที่รับ (header ไม่สนตัวพิมพ์/ช่องว่าง) -> ชื่อภายใน
+var importColumns = map[string]string{
+	"employee_code":   "employee_code",
+	"emp_code":        "employee_code",
+	"email":           "email",
+	"first_name":      "first_name",
+	"last_name":       "last_name",
+	"name":            "name", // ใช้เมื่อไม่มี first_name/last_name: คำแรก = ชื่อ ที่เหลือ = นามสกุล
+	"phone":           "phone",
+	"role":            "role",
+	"department_code": "department_code",
+	"department":      "department_code",
+	"department_role": "department_role",
+	"job_title":       "job_title",
+	"title":           "job_title",
+	"position_level":  "position_level",
+	"level":           "position_level",
+	"hire_date":       "hire_date", // YYYY-MM-DD
+	"location":        "location",
+	"site":            "location",
+	"employment_type": "employment_type",
+}
+
+// คอลัมน์ custom attribute: "attr.<key>" เช่น attr.cost_center
+const importAttrPrefix = "attr."
+
+type importRow struct {
+	line   int
+	fields map[string]string
+}
+
+// ImportUsers อ่านไฟล์ทั้งไฟล์ validate ทุกแถวก่อน แล้ว (ถ้าไม่ใช่ dry run) เขียนเฉพาะแถวที่ผ่าน
+// พร้อมสังกัดแผนกใน transaction เดียว; import ซ้ำได้ (user เดิมอัปเดต สังกัดที่มีแล้วข้าม)
+// user ใหม่ไม่มีรหัสผ่านที่ใช้ได้ (เหมือน SSO just-in-time) ต้องตั้งผ่าน forgot-password หรือ login ด้วย SSO/LDAP
+func (s *svc) ImportUsers(ctx context.Context, filename string, r io.Reader, opts user.ImportOptions) (*user.ImportReport, error) {
+	rows, err := readImportRows(filename, r)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &user.ImportReport{DryRun: opts.DryRun, TotalRows: len(rows), Errors: []user.ImportRowError{}}
+	recs, err := s.validateImport(ctx, rows, opts, report)
+	if err != nil {
+		return nil, err
+	}
+	report.ValidRows = len(recs)
+	for _, rec := range recs {
+		if rec.User.ID == "" {
+			report.Created++
+		} else {
+			report.Updated++
+		}
+	}
+	if opts.DryRun || len(recs) == 0 {
+		return report, nil
+	}
+
+	// hash ครั้งเดียวต่อรอบ import (bcrypt ทีละแถวช้าเกินไปสำหรับหลายร้อยคน); ค่าสุ่มไม่มีใครรู้
+	hash, err := password.Hash(identity.RandomSecret())
+	if err != nil {
+		return nil, err
+	}
+	var roleChanged []string
+	for i := range recs {
+		if recs[i].User.ID == "" {
+			recs[i].User.PasswordHash = hash
+			recs[i].User.IsActive = true
+		} else if recs[i].UpdateRole {
+			roleChanged = append(roleChanged, recs[i].User.ID)
+		}
+	}
+	if err := s.planImportDepartments(ctx, recs); err != nil {
+		return nil, err
+	}
+	if err := s.repo.ApplyImport(ctx, recs); err != nil {
+		return nil, err
+	}
+	for _, id := range roleChanged {
+		if err := s.revokeTokens(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// planImportDepartments ยังไม่อยู่แผนก = เพิ่มสังกัด, อยู่แล้วแต่ role ต่าง = จบแถวเดิมแล้วเริ่มแถวใหม่
+// (ApplyImport เขียนพร้อม user) แผนกอื่นที่สังกัดอยู่ไม่ถูกแตะ (หนึ่งคนอยู่ได้หลายแผนก); ย้ายแผนกใช้ TransferUser
+func (s *svc) planImportDepartments(ctx context.Context, recs []user.ImportRecord) error {
+	var ids []string
+	for _, rec := range recs {
+		if rec.DepartmentID != "" && rec.User.ID != "" {
+			ids = append(ids, rec.User.ID)
+		}
+	}
+	type membership struct{ userID, departmentID string }
+	cur := map[membership]usermodels.UserDepartmentRole{}
+	if len(ids) > 0 {
+		rels, err := s.repo.ListDepartmentRolesForUsers(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, r := range rels {
+			cur[membership{r.UserID, r.DepartmentID}] = r
+		}
+	}
+	now := time.Now()
+	for i := range recs {
+		rec := &recs[i]
+		if rec.DepartmentID == "" {
+			continue
+		}
+		// user ใหม่ยังไม่มี id = ยังไม่มีสังกัดแน่นอน
+		if old, ok := cur[membership{rec.User.ID, rec.DepartmentID}]; ok && rec.User.ID != "" {
+			if old.Role == rec.DepartmentRole {
+				continue
+			}
+			rec.EndRelID = old.ID
+		}
+		rec.Membership = &usermodels.UserDepartmentRole{
+			DepartmentID:  rec.DepartmentID,
+			Role:          rec.DepartmentRole,
+			EffectiveFrom: now,
+		}
+	}
+	return nil
+}
+
+func (s *svc) validateImport(ctx context.Context, rows []importRow, opts user.ImportOptions, report *user.ImportReport) ([]user.ImportRecord, error) {
+	depts, err := s.repo.ListDepartments(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	deptByCode := make(map[string]usermodels.Department, len(depts))
+	for _, d := range depts {
+		deptByCode[strings.ToLower(d.Code)] = d
+	}
+
+	// ค้น user เดิมทีเดียวทั้งไฟล์ แทนการ query ทีละแถว
+	var codes, emails []string
+	for _, row := range rows {
+		if v := row.fields["employee_code"]; v != "" {
+			codes = append(codes, v)
+		}
+		if v := strings.ToLower(row.fields["email"]); v != "" {
+			emails = append(emails, v)
+		}
+	}
+	byCode, err := s.repo.FindByEmployeeCodes(ctx, codes)
+	if err != nil {
+		return nil, err
+	}
+	byEmail, err := s.repo.FindByEmails(ctx, emails)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]usermodels.User, len(byCode))
+	for _, u := range byCode {
+		existing[strings.ToLower(u.EmployeeCode)] = u
+	}
+	emailOwner := make(map[string]string, len(byEmail)) // email -> employee_code
+	for _, u := range byEmail {
+		emailOwner[strings.ToLower(u.Email)] = strings.ToLower(u.EmployeeCode)
+	}
+
+	seenCode := map[string]int{}
+	seenEmail := map[string]int{}
+	recs := make([]user.ImportRecord, 0, len(rows))
+
+	for _, row := range rows {
+		f := row.fields
+		code := f["employee_code"]
+		fail := func(field, errCode, msg string) {
+			report.Errors = append(report.Errors, user.ImportRowError{
+				Row: row.line, EmployeeCode: code, Field: field, Code: errCode, Message: msg,
+			})
+		}
+		before := len(report.Errors)
+
+		// employee_code
+		codeKey := strings.ToLower(code)
+		var cur *usermodels.User
+		switch {
+		case code == "":
+			fail("employee_code", importErrRequired, "employee_code is required")
+		case seenCode[codeKey] > 0:
+			fail("employee_code", importErrDuplicate, fmt.Sprintf("employee_code also appears on row %d", seenCode[codeKey]))
+		default:
+			seenCode[codeKey] = row.line
+			if u, ok := existing[codeKey]; ok {
+				if u.DeletedAt.Valid {
+					fail("employee_code", importErrUserDeleted, "employee_code belongs to a deleted user")
+				} else {
+					cur = &u
+				}
+			}
+		}
+
+		// email
+		email := strings.ToLower(f["email"])
+		switch {
+		case email == "":
+			fail("email", importErrRequired, "email is required")
+		case !identity.ValidEmail(email):
+			fail("email", importErrInvalid, "email is not a valid address")
+		case seenEmail[email] > 0:
+			fail("email", importErrDuplicate, fmt.Sprintf("email also appears on row %d", seenEmail[email]))
+		default:
+			seenEmail[email] = row.line
+			if owner, ok := emailOwner[email]; ok && owner != codeKey {
+				fail("email", importErrEmailTaken, "email is already used by another employee")
+			}
+		}
+
+		// ชื่อ
+		first, last := f["first_name"], f["last_name"]
+		if first == "" && last == "" && f["name"] != "" {
+			first, last = identity.SplitName(f["name"])
+			if last == "" {
+				last = "-"
+			}
+		}
+		if first == "" {
+			fail("first_name", importErrRequired, "first_name is required")
+		}
+		if last == "" {
+			fail("last_name", importErrRequired, "last_name is required")
+		}
+
+		var phone *string
+		if p := f["phone"]; p != "" {
+			if len(p) > 50 {
+				fail("phone", importErrInvalid, "phone must be at most 50 characters")
+			}
+			phone = &p
+		}
+
+		// role: ว่าง = employee (คนใหม่) / ไม่เปลี่ยน (คนเดิม)
+		role := usermodels.RoleEmployee
+		updateRole := false
+		if cur != nil {
+			role = cur.Role
+		}
+		if v := f["role"]; v != "" {
+			parsed, ok := usermodels.ParseRole(v)
+			switch {
+			case !ok:
+				fail("role", importErrInvalid, "role must be one of admin, hr, employee")
+			case parsed == role:
+			case !opts.AllowRole:
+				fail("role", importErrRoleNotAllowed, "not allowed to set role")
+			default:
+				role = parsed
+				updateRole = cur != nil
+			}
+		}
+
+		// department
+		var deptID string
+		deptRole := usermodels.DeptRoleMember
+		if v := f["department_code"]; v != "" {
+			d, ok := deptByCode[strings.ToLower(v)]
+			if !ok {
+				fail("department_code", importErrUnknownDept, "department_code does not exist")
+			}
+			deptID = d.ID
+		}
+		if v := f["department_role"]; v != "" {
+			parsed, ok := usermodels.ParseDepartmentRole(v)
+			switch {
+			case !ok:
+				fail("department_role", importErrInvalid, "department_role must be one of manager, leader, member")
+			case f["department_code"] == "":
+				fail("department_code", importErrRequired, "department_code is required with department_role")
+			default:
+				deptRole = parsed
+			}
+		}
+
+		// ข้อมูลตำแหน่งงาน: ช่องว่าง = ไม่เปลี่ยน; attribute รวมกับของเดิม
+		prof := usermodels.User{}
+		if cur != nil {
+			prof = *cur
+		}
+		if err := applyProfile(&prof, importProfile(f, prof.Attributes)); err != nil {
+			var pe *ProfileError
+			if !errors.As(err, &pe) {
+				return nil, err
+			}
+			fail(pe.Field, importErrInvalid, pe.Message)
+		}
+
+		if len(report.Errors) > before {
+			continue
+		}
+		rec := user.ImportRecord{
+			User: usermodels.User{
+				EmployeeCode: code,
+				Email:        email,
+				FirstName:    first,
+				LastName:     last,
+				Phone:        phone,
+				Role:         role,
+
+				JobTitle:       prof.JobTitle,
+				PositionLevel:  prof.PositionLevel,
+				HireDate:       prof.HireDate,
+				Location:       prof.Location,
+				EmploymentType: prof.EmploymentType,
+				Attributes:     prof.Attributes,
+			},
+			UpdateRole:     updateRole,
+			DepartmentID:   deptID,
+			DepartmentRole: deptRole,
+		}
+		if cur != nil {
+			rec.User.ID = cur.ID
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// importProfile เฉพาะช่องที่กรอก; attribute ใหม่ทับ key เดิม ส่วน key อื่นคงไว้
+func importProfile(f map[string]string, current usermodels.Attributes) user.ProfileInput {
+	var p user.ProfileInput
+	for col, dst := range map[string]**string{
+		"job_title":       &p.JobTitle,
+		"position_level":  &p.PositionLevel,
+		"hire_date":       &p.HireDate,
+		"location":        &p.Location,
+		"employment_type": &p.EmploymentType,
+	} {
+		if v, ok := f[col]; ok {
+			*dst = &v
+		}
+	}
+	for col, v := range f {
+		key, ok := strings.CutPrefix(col, importAttrPrefix)
+		if !ok {
+			continue
+		}
+		if p.Attributes == nil {
+			p.Attributes = make(map[string]string, len(current)+1)
+			for k, old := range current {
+				p.Attributes[k] = old
+			}
+		}
+		p.Attributes[key] = v
+	}
+	return p
+}
+
+// readImportRows อ่าน CSV/XLSX (sheet แรก) แถวแรกเป็น header; แถวว่างข้ามไป
+func readImportRows(filename string, r io.Reader) ([]importRow, error) {
+	var (
+		table [][]string
+		err   error
+	)
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		table, err = readCSV(r)
+	case ".xlsx":
+		table, err = readXLSX(r)
+	default:
+		return nil, ErrImportUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(table) < 2 {
+		return nil, ErrImportEmpty
+	}
+
+	cols := make([]string, len(table[0]))
+	has := map[string]bool{}
+	for i, h := range table[0] {
+		key := strings.ToLower(strings.TrimSpace(h))
+		key = strings.Join(strings.Fields(key), "_")
+		if name, ok := importColumns[key]; ok {
+			cols[i] = name
+			has[name] = true
+		} else if strings.HasPrefix(key, importAttrPrefix) || strings.HasPrefix(key, "attr:") {
+			cols[i] = importAttrPrefix + key[len(importAttrPrefix):]
+		}
+	}
+	var missing []string
+	for _, c := range []string{"employee_code", "email"} {
+		if !has[c] {
+			missing = append(missing, c)
+		}
+	}
+	if !has["name"] && (!has["first_name"] || !has["last_name"]) {
+		missing = append(missing, "first_name, last_name (or name)")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrImportMissingColumns, strings.Join(missing, ", "))
+	}
+
+	rows := make([]importRow, 0, len(table)-1)
+	for i, rec := range table[1:] {
+		fields := map[string]string{}
+		for j, v := range rec {
+			if j < len(cols) && cols[j] != "" {
+				if v = strings.TrimSpace(v); v != "" {
+					fields[cols[j]] = v
+				}
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		if len(rows) == importMaxRows {
+			return nil, ErrImportTooManyRows
+		}
+		rows = append(rows, importRow{line: i + 2, fields: fields})
+	}
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	return rows, nil
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	// Excel บันทึก CSV แบบ UTF-8 มักมี BOM นำหน้า
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	return cr.ReadAll()
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrImportUnsupportedFormat, err)
+	}
+	defer f.Close()
+	sheets := f.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, ErrImportEmpty
+	}
+	return f.GetRows(sheets[0])
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+// fakeMemberships เก็บสังกัดปัจจุบันในหน่วยความจำ (อ่านอย่างเดียว: เมธอดเขียนที่ไม่ได้ implement = panic)
+type fakeMemberships struct {
+	user.Repository
+	rels []usermodels.UserDepartmentRole
+}
+
+func (f *fakeMemberships) ListDepartmentRolesForUsers(_ context.Context, ids []string) ([]usermodels.UserDepartmentRole, error) {
+	want := map[string]bool{}
+	for _, id := range ids {
+		want[id] = true
+	}
+	var out []usermodels.UserDepartmentRole
+	for _, r := range f.rels {
+		if want[r.UserID] {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func TestImportDepartmentsUseMembershipHistory(t *testing.T) {
+	repo := &fakeMemberships{
+		rels: []usermodels.UserDepartmentRole{
+			{ID: "r2", UserID: "u2", DepartmentID: "d1", Role: usermodels.DeptRoleMember},
+			{ID: "r3", UserID: "u3", DepartmentID: "d1", Role: usermodels.DeptRoleLeader},
+			{ID: "r4", UserID: "u4", DepartmentID: "d2", Role: usermodels.DeptRoleMember},
+		},
+	}
+	s := &svc{repo: repo}
+	rec := func(userID, deptID string, role usermodels.DepartmentRole) user.ImportRecord {
+		return user.ImportRecord{User: usermodels.User{ID: userID}, DepartmentID: deptID, DepartmentRole: role}
+	}
+	recs := []user.ImportRecord{
+		rec("u1", "d1", usermodels.DeptRoleMember),  // ใหม่ในแผนก
+		rec("u2", "d1", usermodels.DeptRoleManager), // role เปลี่ยน
+		rec("u3", "d1", usermodels.DeptRoleLeader),  // เหมือนเดิม
+		rec("u4", "d1", usermodels.DeptRoleMember),  // อยู่แผนกอื่นด้วย: เพิ่ม ไม่ย้าย
+		rec("u5", "", ""),
+		rec("", "d1", usermodels.DeptRoleMember), // user ใหม่ในไฟล์
+	}
+	if err := s.planImportDepartments(context.Background(), recs); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, i := range []int{0, 3, 5} {
+		m := recs[i].Membership
+		if m == nil || m.DepartmentID != "d1" || m.Role != usermodels.DeptRoleMember || m.EffectiveFrom.IsZero() || recs[i].EndRelID != "" {
+			t.Fatalf("rec %d: membership = %+v end = %q", i, m, recs[i].EndRelID)
+		}
+	}
+	if m := recs[1].Membership; m == nil || m.Role != usermodels.DeptRoleManager || recs[1].EndRelID != "r2" {
+		t.Fatalf("role change: membership = %+v end = %q", m, recs[1].EndRelID)
+	}
+	if recs[2].Membership != nil || recs[4].Membership != nil {
+		t.Fatalf("unchanged rows planned: %+v / %+v", recs[2].Membership, recs[4].Membership)
+	}
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+const (
+	maxAttributes       = 50
+	maxAttributeValue   = 255
+	hireDateLayout      = "2006-01-02"
+	attributeKeyPattern = `^[a-z][a-z0-9_]{0,63}$`
+)
+
+var attributeKey = regexp.MustCompile(attributeKeyPattern)
+
+// ProfileError ค่าในข้อมูลตำแหน่งงานไม่ถูกต้อง (Field ใช้ชื่อเดียวกับ JSON/คอลัมน์ import)
+type ProfileError struct {
+	Field   string
+	Message string
+}
+
+func (e *ProfileError) Error() string { return e.Field + ": " + e.Message }
+
+func profileErr(field, format string, args ...any) error {
+	return &ProfileError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// applyProfile validate แล้วเขียนลง u; error ตัวแรกที่เจอคืนเป็น *ProfileError
+func applyProfile(u *usermodels.User, p user.ProfileInput) error {
+	texts := []struct {
+		field string
+		in    *string
+		max   int
+		dst   **string
+	}{
+		{"job_title", p.JobTitle, 150, &u.JobTitle},
+		{"position_level", p.PositionLevel, 50, &u.PositionLevel},
+		{"location", p.Location, 100, &u.Location},
+	}
+	for _, t := range texts {
+		if t.in == nil {
+			continue
+		}
+		v := strings.TrimSpace(*t.in)
+		if utf8.RuneCountInString(v) > t.max {
+			return profileErr(t.field, "must be at most %d characters", t.max)
+		}
+		*t.dst = nil
+		if v != "" {
+			*t.dst = &v
+		}
+	}
+
+	if p.EmploymentType != nil {
+		u.EmploymentType = nil
+		if v := strings.TrimSpace(*p.EmploymentType); v != "" {
+			et, ok := usermodels.ParseEmploymentType(v)
+			if !ok {
+				return profileErr("employment_type", "must be one of full_time, part_time, contract, temporary, intern")
+			}
+			u.EmploymentType = &et
+		}
+	}
+
+	if p.HireDate != nil {
+		u.HireDate = nil
+		if v := strings.TrimSpace(*p.HireDate); v != "" {
+			d, err := time.Parse(hireDateLayout, v)
+			if err != nil {
+				return profileErr("hire_date", "must be a date in YYYY-MM-DD format")
+			}
+			u.HireDate = &d
+		}
+	}
+
+	if p.Attributes != nil {
+		attrs, err := normalizeAttributes(p.Attributes)
+		if err != nil {
+			return err
+		}
+		u.Attributes = attrs
+	}
+	return nil
+}
+
+// profileColumns คอลัมน์ที่ applyProfile เขียนจาก p (ส่งต่อให้ repo.Update)
+func profileColumns(p user.ProfileInput) []string {
+	var cols []string
+	for _, f := range []struct {
+		col string
+		set bool
+	}{
+		{"job_title", p.JobTitle != nil},
+		{"position_level", p.PositionLevel != nil},
+		{"hire_date", p.HireDate != nil},
+		{"location", p.Location != nil},
+		{"employment_type", p.EmploymentType != nil},
+		{"attributes", p.Attributes != nil},
+	} {
+		if f.set {
+			cols = append(cols, f.col)
+		}
+	}
+	return cols
+}
+
+// normalizeAttributes key เป็นตัวเล็ก, ตัด value ว่างทิ้ง; ไม่เหลืออะไร = nil
+func normalizeAttributes(in map[string]string) (usermodels.Attributes, error) {
+	out := usermodels.Attributes{}
+	for k, v := range in {
+		key, err := attributeKeyOf(k)
+		if err != nil {
+			return nil, err
+		}
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if utf8.RuneCountInString(v) > maxAttributeValue {
+			return nil, profileErr("attributes."+key, "must be at most %d characters", maxAttributeValue)
+		}
+		out[key] = v
+	}
+	if len(out) > maxAttributes {
+		return nil, profileErr("attributes", "at most %d attributes are allowed", maxAttributes)
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return out, nil
+}
+
+func attributeKeyOf(k string) (string, error) {
+	key := strings.ToLower(strings.TrimSpace(k))
+	if !attributeKey.MatchString(key) {
+		return "", profileErr("attributes", "key %q must match %s", k, attributeKeyPattern)
+	}
+	return key, nil
+}
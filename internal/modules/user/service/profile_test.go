@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Marugo/birdlax/internal/modules/user"
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func strp(s string) *string { return &s }
+
+func TestApplyProfileValidation(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i <= maxAttributes; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+	for _, tc := range []struct {
+		name  string
+		in    user.ProfileInput
+		field string // "" = ผ่าน
+	}{
+		{"job_title at limit", user.ProfileInput{JobTitle: strp(strings.Repeat("ก", 150))}, ""},
+		{"job_title too long", user.ProfileInput{JobTitle: strp(strings.Repeat("a", 151))}, "job_title"},
+		{"position_level too long", user.ProfileInput{PositionLevel: strp(strings.Repeat("a", 51))}, "position_level"},
+		{"location too long", user.ProfileInput{Location: strp(strings.Repeat("a", 101))}, "location"},
+		{"hire_date ok", user.ProfileInput{HireDate: strp("2020-02-29")}, ""},
+		{"hire_date wrong format", user.ProfileInput{HireDate: strp("29/02/2020")}, "hire_date"},
+		{"hire_date impossible day", user.ProfileInput{HireDate: strp("2021-02-29")}, "hire_date"},
+		{"employment_type ok", user.ProfileInput{EmploymentType: strp("contract")}, ""},
+		{"employment_type unknown", user.ProfileInput{EmploymentType: strp("freelance")}, "employment_type"},
+		{"attribute key ok", user.ProfileInput{Attributes: map[string]string{"Cost_Center": "CC1"}}, ""},
+		{"attribute key starts with digit", user.ProfileInput{Attributes: map[string]string{"1shift": "a"}}, "attributes"},
+		{"attribute key with dash", user.ProfileInput{Attributes: map[string]string{"cost-center": "a"}}, "attributes"},
+		{"attribute key too long", user.ProfileInput{Attributes: map[string]string{"k" + strings.Repeat("x", 64): "a"}}, "attributes"},
+		{"attribute value too long", user.ProfileInput{Attributes: map[string]string{"shift": strings.Repeat("a", 256)}}, "attributes.shift"},
+		{"too many attributes", user.ProfileInput{Attributes: tooMany}, "attributes"},
+	} {
+		err := applyProfile(&usermodels.User{}, tc.in)
+		if tc.field == "" {
+			if err != nil {
+				t.Errorf("%s: %v", tc.name, err)
+			}
+			continue
+		}
+		var pe *ProfileError
+		if !errors.As(err, &pe) || pe.Field != tc.field {
+			t.Errorf("%s: err = %v, want field %s", tc.name, err, tc.field)
+		}
+	}
+}
+
+func TestApplyProfileNormalizes(t *testing.T) {
+	title, site := "Old title", "Old site"
+	u := &usermodels.User{JobTitle: &title, Location: &site, Attributes: usermodels.Attributes{"old": "x"}}
+	err := applyProfile(u, user.ProfileInput{
+		JobTitle:       strp("  Supervisor "),
+		Location:       strp("   "), // ว่าง = ล้าง
+		EmploymentType: strp(" intern "),
+		HireDate:       strp("2024-06-01"),
+		Attributes:     map[string]string{" Shift ": " night ", "empty": "  "},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.JobTitle == nil || *u.JobTitle != "Supervisor" || u.Location != nil {
+		t.Fatalf("job_title = %v location = %v", u.JobTitle, u.Location)
+	}
+	if u.EmploymentType == nil || *u.EmploymentType != usermodels.EmploymentIntern {
+		t.Fatalf("employment_type = %v", u.EmploymentType)
+	}
+	if u.HireDate == nil || u.HireDate.Format(hireDateLayout) != "2024-06-01" {
+		t.Fatalf("hire_date = %v", u.HireDate)
+	}
+	// แทนทั้งชุด, key ตัวเล็ก, value ว่างถูกทิ้ง
+	if len(u.Attributes) != 1 || u.Attributes["shift"] != "night" {
+		t.Fatalf("attributes = %v", u.Attributes)
+	}
+
+	// เหลือแต่ค่าว่าง = ล้าง attributes ทั้งหมด
+	if err := applyProfile(u, user.ProfileInput{Attributes: map[string]string{"shift": ""}}); err != nil {
+		t.Fatal(err)
+	}
+	if u.Attributes != nil {
+		t.Fatalf("attributes = %v, want nil", u.Attributes)
+	}
+	// field ที่ไม่ได้ส่ง (nil) ไม่ถูกแตะ
+	if u.JobTitle == nil || *u.JobTitle != "Supervisor" {
+		t.Fatalf("job_title changed: %v", u.JobTitle)
+	}
+}
@@ -3,60 +3,170 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Marugo/birdlax/internal/modules/user"
 	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
 	"github.com/Marugo/birdlax/internal/shared/password"
+	"github.com/Marugo/birdlax/internal/shared/security"
 	"gorm.io/gorm"
 )
 
 var (
 	ErrEmailAlreadyExists       = errors.New("email already exists")
 	ErrEmployeeCodeAlreadyExist = errors.New("employee_code already exists")
+
+	ErrManagerNotFound          = errors.New("manager user not found")
+	ErrManagerCycle             = errors.New("manager would create a reporting cycle")
+	ErrParentDepartmentNotFound = errors.New("parent department not found")
+	ErrDepartmentCycle          = user.ErrDepartmentCycle
+	ErrDepartmentTooDeep        = errors.New("department tree is too deep")
+	ErrDepartmentHasChildren    = errors.New("department still has sub-departments")
+	ErrInvalidListFilter        = errors.New("invalid user filter")
+
+	ErrEffectiveDateInFuture    = errors.New("effective date cannot be in the future")
+	ErrEffectiveDateBeforeStart = errors.New("effective date is before the membership started")
+	ErrNotDepartmentMember      = errors.New("user is not currently a member of the source department")
+	ErrSameDepartment           = errors.New("source and target department are the same")
+	ErrMembershipOverlap        = errors.New("membership overlaps an existing period in this department")
 )
 
-type svc struct{ repo user.Repository }
+type svc struct {
+	repo   user.Repository
+	policy password.Policy
+}
 
-func NewService(r user.Repository) user.Service { return &svc{repo: r} }
+func NewService(r user.Repository) user.Service {
+	return &svc{repo: r, policy: password.PolicyFromEnv()}
+}
 
-func (s *svc) List(ctx context.Context, page, perPage int, q string) ([]usermodels.User, int64, error) {
+func (s *svc) List(ctx context.Context, f user.ListFilter, page, perPage int) ([]user.UserWithDepartments, int64, error) {
 	if perPage <= 0 || perPage > 200 {
 		perPage = 20
 	}
 	if page <= 0 {
 		page = 1
 	}
+	if err := checkListFilter(&f); err != nil {
+		return nil, 0, err
+	}
 	offset := (page - 1) * perPage
-	return s.repo.FindAll(ctx, perPage, offset, q)
+	rows, total, err := s.repo.FindAll(ctx, f, perPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// แผนกของทั้งหน้าดึงใน query เดียว
+	ids := make([]string, 0, len(rows))
+	for _, u := range rows {
+		ids = append(ids, u.ID)
+	}
+	rels, err := s.repo.ListDepartmentRolesForUsers(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+	byUser := map[string][]usermodels.UserDepartmentRole{}
+	for _, r := range rels {
+		byUser[r.UserID] = append(byUser[r.UserID], r)
+	}
+	out := make([]user.UserWithDepartments, 0, len(rows))
+	for _, u := range rows {
+		deps := byUser[u.ID]
+		if deps == nil {
+			deps = []usermodels.UserDepartmentRole{}
+		}
+		out = append(out, user.UserWithDepartments{User: u, Departments: deps})
+	}
+	return out, total, nil
+}
+
+func checkListFilter(f *user.ListFilter) error {
+	f.Q = strings.TrimSpace(f.Q)
+	switch f.Sort {
+	case "", user.SortCreatedAt, user.SortName, user.SortEmployeeCode, user.SortLastLogin:
+	default:
+		return fmt.Errorf("%w: unknown sort %q", ErrInvalidListFilter, f.Sort)
+	}
+	if f.Role != "" {
+		r, ok := usermodels.ParseRole(f.Role)
+		if !ok {
+			return fmt.Errorf("%w: invalid role", ErrInvalidListFilter)
+		}
+		f.Role = string(r)
+	}
+	if f.DepartmentRole != "" {
+		r, ok := usermodels.ParseDepartmentRole(f.DepartmentRole)
+		if !ok {
+			return fmt.Errorf("%w: invalid department_role", ErrInvalidListFilter)
+		}
+		f.DepartmentRole = string(r)
+	}
+	if f.EmploymentType != "" {
+		et, ok := usermodels.ParseEmploymentType(f.EmploymentType)
+		if !ok {
+			return fmt.Errorf("%w: invalid employment_type", ErrInvalidListFilter)
+		}
+		f.EmploymentType = string(et)
+	}
+	if len(f.Attributes) > 0 {
+		attrs := make(map[string]string, len(f.Attributes))
+		for k, v := range f.Attributes {
+			key, err := attributeKeyOf(k)
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidListFilter, err)
+			}
+			attrs[key] = v
+		}
+		f.Attributes = attrs
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		return fmt.Errorf("%w: created_from must be before created_to", ErrInvalidListFilter)
+	}
+	return nil
 }
 
 func (s *svc) Get(ctx context.Context, id string) (*usermodels.User, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *svc) Create(ctx context.Context,
-	employeeCode, email, firstName, lastName, role string, phone *string, passwordPlain string) (*usermodels.User, error) {
-	employeeCode = strings.TrimSpace(employeeCode)
-	email = strings.TrimSpace(strings.ToLower(email))
-	firstName = strings.TrimSpace(firstName)
-	lastName = strings.TrimSpace(lastName)
+func (s *svc) Create(ctx context.Context, in user.CreateInput) (*usermodels.User, error) {
+	employeeCode := strings.TrimSpace(in.EmployeeCode)
+	email := strings.TrimSpace(strings.ToLower(in.Email))
+	firstName := strings.TrimSpace(in.FirstName)
+	lastName := strings.TrimSpace(in.LastName)
+	passwordPlain := in.Password
 
 	if employeeCode == "" || email == "" || firstName == "" || lastName == "" {
 		return nil, errors.New("employee_code, email, first_name, last_name are required")
 	}
 
-	r, ok := usermodels.ParseRole(role)
+	r, ok := usermodels.ParseRole(in.Role)
 	if !ok {
 		return nil, errors.New("invalid role")
 	}
 	if strings.TrimSpace(passwordPlain) == "" {
 		return nil, errors.New("password is required")
 	}
+	var manager *string
+	if in.ManagerUserID != nil && strings.TrimSpace(*in.ManagerUserID) != "" {
+		mid := strings.TrimSpace(*in.ManagerUserID)
+		if err := s.checkManager(ctx, "", mid); err != nil {
+			return nil, err
+		}
+		manager = &mid
+	}
+	if err := s.policy.Check(ctx, s.repo, password.Subject{
+		Personal: []string{employeeCode, email, firstName, lastName},
+	}, passwordPlain); err != nil {
+		return nil, err
+	}
 	hash, err := password.Hash(passwordPlain)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
 
 	u := &usermodels.User{
 		EmployeeCode: employeeCode,
@@ -64,9 +174,15 @@ func (s *svc) Create(ctx context.Context,
 		FirstName:    firstName,
 		LastName:     lastName,
 		Role:         r,
-		Phone:        phone,
+		Phone:        in.Phone,
 		IsActive:     true,
 		PasswordHash: hash, // <-- เก็บ hash
+
+		PasswordChangedAt: &now,
+		ManagerUserID:     manager,
+	}
+	if err := applyProfile(u, in.Profile); err != nil {
+		return nil, err
 	}
 	if err := s.repo.Create(ctx, u); err != nil {
 		// GORM จะ map duplicate เป็น ErrDuplicatedKey
@@ -81,75 +197,108 @@ func (s *svc) Create(ctx context.Context,
 		}
 		return nil, err
 	}
+	if err := s.recordPassword(ctx, u.ID, hash); err != nil {
+		return nil, err
+	}
 	return u, nil
 }
 
-func (s *svc) Update(ctx context.Context,
-	id string,
-	employeeCode, email, firstName, lastName, role *string, phone *string, isActive *bool,
-	// เพิ่มรับรหัสผ่านใหม่ (optional)
-	passwordPlain *string,
-) (*usermodels.User, error) {
+func (s *svc) Update(ctx context.Context, id string, in user.UpdateInput) (*usermodels.User, error) {
 	u, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	prevRole, prevActive, prevHash := u.Role, u.IsActive, u.PasswordHash
+	var cols []string
 
-	if employeeCode != nil {
-		ec := strings.TrimSpace(*employeeCode)
+	if in.EmployeeCode != nil {
+		ec := strings.TrimSpace(*in.EmployeeCode)
 		if ec == "" {
 			return nil, errors.New("employee_code cannot be empty")
 		}
 		u.EmployeeCode = ec
+		cols = append(cols, "employee_code")
 	}
-	if email != nil {
-		e := strings.TrimSpace(strings.ToLower(*email))
+	if in.Email != nil {
+		e := strings.TrimSpace(strings.ToLower(*in.Email))
 		if e == "" {
 			return nil, errors.New("email cannot be empty")
 		}
 		u.Email = e
+		cols = append(cols, "email")
 	}
-	if firstName != nil {
-		fn := strings.TrimSpace(*firstName)
+	if in.FirstName != nil {
+		fn := strings.TrimSpace(*in.FirstName)
 		if fn == "" {
 			return nil, errors.New("first_name cannot be empty")
 		}
 		u.FirstName = fn
+		cols = append(cols, "first_name")
 	}
-	if lastName != nil {
-		ln := strings.TrimSpace(*lastName)
+	if in.LastName != nil {
+		ln := strings.TrimSpace(*in.LastName)
 		if ln == "" {
 			return nil, errors.New("last_name cannot be empty")
 		}
 		u.LastName = ln
+		cols = append(cols, "last_name")
 	}
-	if role != nil {
-		if r, ok := usermodels.ParseRole(*role); ok {
+	if in.Role != nil {
+		if r, ok := usermodels.ParseRole(*in.Role); ok {
 			u.Role = r
+			cols = append(cols, "role")
 		} else {
 			return nil, errors.New("invalid role")
 		}
 	}
 
-	if passwordPlain != nil {
-		p := strings.TrimSpace(*passwordPlain)
-		if p == "" {
+	if in.Password != nil {
+		if strings.TrimSpace(*in.Password) == "" {
 			return nil, errors.New("password cannot be empty")
 		}
-		hash, err := password.Hash(p)
+		if err := s.policy.Check(ctx, s.repo, password.Subject{
+			UserID:      u.ID,
+			CurrentHash: u.PasswordHash,
+			Personal:    []string{u.EmployeeCode, u.Email, u.FirstName, u.LastName},
+		}, *in.Password); err != nil {
+			return nil, err
+		}
+		hash, err := password.Hash(*in.Password)
 		if err != nil {
 			return nil, err
 		}
+		now := time.Now()
 		u.PasswordHash = hash // <-- อัปเดต hash
+		u.PasswordChangedAt = &now
+		u.MustChangePassword = false
+		cols = append(cols, "password_hash", "password_changed_at", "must_change_password")
+	}
+	if in.Phone != nil {
+		u.Phone = in.Phone
+		cols = append(cols, "phone")
+	}
+	if in.ManagerUserID != nil {
+		mid := strings.TrimSpace(*in.ManagerUserID)
+		if mid == "" {
+			u.ManagerUserID = nil
+		} else {
+			if err := s.checkManager(ctx, u.ID, mid); err != nil {
+				return nil, err
+			}
+			u.ManagerUserID = &mid
+		}
+		cols = append(cols, "manager_user_id")
 	}
-	if phone != nil {
-		u.Phone = phone
+	if in.IsActive != nil {
+		u.IsActive = *in.IsActive
+		cols = append(cols, "is_active")
 	}
-	if isActive != nil {
-		u.IsActive = *isActive
+	if err := applyProfile(u, in.Profile); err != nil {
+		return nil, err
 	}
+	cols = append(cols, profileColumns(in.Profile)...)
 
-	if err := s.repo.Update(ctx, u); err != nil {
+	if err := s.repo.Update(ctx, u, cols...); err != nil {
 		if errors.Is(err, gorm.ErrDuplicatedKey) {
 			msg := strings.ToLower(err.Error())
 			if strings.Contains(msg, "employee_code") {
@@ -159,10 +308,58 @@ func (s *svc) Update(ctx context.Context,
 		}
 		return nil, err
 	}
+	if u.PasswordHash != prevHash {
+		if err := s.recordPassword(ctx, u.ID, u.PasswordHash); err != nil {
+			return nil, err
+		}
+	}
+	// เปลี่ยน role / ปิด account / เปลี่ยนรหัส => access token เดิมต้องใช้ไม่ได้ทันที
+	if u.Role != prevRole || u.IsActive != prevActive || u.PasswordHash != prevHash {
+		if err := s.revokeTokens(ctx, u.ID); err != nil {
+			return nil, err
+		}
+	}
 	return u, nil
 }
 
-func (s *svc) Delete(ctx context.Context, id string) error { return s.repo.Delete(ctx, id) }
+func (s *svc) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	security.InvalidateTokenState(id)
+	return nil
+}
+
+// recordPassword เก็บ hash ลง history (ใช้ตรวจ "ห้ามใช้รหัสเก่า")
+func (s *svc) recordPassword(ctx context.Context, id, hash string) error {
+	if s.policy.History <= 0 {
+		return nil
+	}
+	return s.repo.AddPasswordHistory(ctx, id, hash, s.policy.History)
+}
+
+func (s *svc) revokeTokens(ctx context.Context, id string) error {
+	if err := s.repo.BumpTokenVersion(ctx, id); err != nil {
+		return err
+	}
+	security.InvalidateTokenState(id)
+	return nil
+}
+
+// Unlock ล้างตัวนับ login ผิดและปลดล็อก account (ใช้โดย admin/hr)
+func (s *svc) Unlock(ctx context.Context, id string) (*usermodels.User, error) {
+	u, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.UpdateLoginState(ctx, id, 0, nil, nil); err != nil {
+		return nil, err
+	}
+	u.FailedLoginCount = 0
+	u.LastFailedLoginAt = nil
+	u.LockedUntil = nil
+	return u, nil
+}
 
 func (s *svc) ListDepartments(ctx context.Context, q string) ([]usermodels.Department, error) {
 	return s.repo.ListDepartments(ctx, q)
@@ -172,7 +369,7 @@ func (s *svc) GetDepartment(ctx context.Context, id string) (*usermodels.Departm
 	return s.repo.GetDepartmentByID(ctx, id)
 }
 
-func (s *svc) CreateDepartment(ctx context.Context, code, name string, isActive *bool) (*usermodels.Department, error) {
+func (s *svc) CreateDepartment(ctx context.Context, code, name string, parentID *string, isActive *bool) (*usermodels.Department, error) {
 	code = strings.TrimSpace(code)
 	name = strings.TrimSpace(name)
 	if code == "" || name == "" {
@@ -186,17 +383,34 @@ func (s *svc) CreateDepartment(ctx context.Context, code, name string, isActive
 	if isActive != nil {
 		d.IsActive = *isActive
 	}
+	if parentID != nil && strings.TrimSpace(*parentID) != "" {
+		p, err := s.parentDepartment(ctx, strings.TrimSpace(*parentID))
+		if err != nil {
+			return nil, err
+		}
+		if len(p.Path)+departmentPathSegment > maxDepartmentPath {
+			return nil, ErrDepartmentTooDeep
+		}
+		d.ParentID = &p.ID
+	}
 	if err := s.repo.CreateDepartment(ctx, d); err != nil {
 		return nil, err
 	}
 	return d, nil
 }
 
-func (s *svc) UpdateDepartment(ctx context.Context, id string, code, name *string, isActive *bool) (*usermodels.Department, error) {
+func (s *svc) UpdateDepartment(ctx context.Context, id string, code, name, parentID *string, isActive *bool) (*usermodels.Department, error) {
 	d, err := s.repo.GetDepartmentByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	// ตรวจการย้ายก่อนเขียนอะไรลง DB
+	var move *departmentMove
+	if parentID != nil {
+		if move, err = s.planMove(ctx, d, strings.TrimSpace(*parentID)); err != nil {
+			return nil, err
+		}
+	}
 	if code != nil {
 		c := strings.TrimSpace(*code)
 		if c == "" {
@@ -217,10 +431,23 @@ func (s *svc) UpdateDepartment(ctx context.Context, id string, code, name *strin
 	if err := s.repo.UpdateDepartment(ctx, d); err != nil {
 		return nil, err
 	}
+	if move != nil {
+		if err := s.repo.MoveDepartment(ctx, d.ID, move.parentID); err != nil {
+			return nil, err
+		}
+		return s.repo.GetDepartmentByID(ctx, d.ID)
+	}
 	return d, nil
 }
 
 func (s *svc) DeleteDepartment(ctx context.Context, id string) error {
+	sub, err := s.repo.ListDepartmentSubtree(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(sub) > 1 {
+		return ErrDepartmentHasChildren
+	}
 	return s.repo.DeleteDepartment(ctx, id)
 }
 
@@ -234,7 +461,7 @@ func (s *svc) ListUserDepartmentRoles(ctx context.Context, userID string) ([]use
 	return s.repo.ListUserDepartmentRoles(ctx, userID)
 }
 
-func (s *svc) AddUserDepartmentRole(ctx context.Context, userID, departmentID, role string) (*usermodels.UserDepartmentRole, error) {
+func (s *svc) AddUserDepartmentRole(ctx context.Context, userID, departmentID, role string, effectiveFrom *time.Time) (*usermodels.UserDepartmentRole, error) {
 	// validate user / dept
 	if _, err := s.repo.FindByID(ctx, userID); err != nil {
 		return nil, err
@@ -246,10 +473,18 @@ func (s *svc) AddUserDepartmentRole(ctx context.Context, userID, departmentID, r
 	if !ok {
 		return nil, errors.New("invalid department role")
 	}
+	from, err := effectiveAt(effectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkMembershipOverlap(ctx, userID, departmentID, from, ""); err != nil {
+		return nil, err
+	}
 	rel := &usermodels.UserDepartmentRole{
-		UserID:       userID,
-		DepartmentID: departmentID,
-		Role:         r,
+		UserID:        userID,
+		DepartmentID:  departmentID,
+		Role:          r,
+		EffectiveFrom: from,
 	}
 	if err := s.repo.AddUserDepartmentRole(ctx, rel); err != nil {
 		return nil, err
@@ -257,8 +492,153 @@ func (s *svc) AddUserDepartmentRole(ctx context.Context, userID, departmentID, r
 	return rel, nil
 }
 
-func (s *svc) DeleteUserDepartmentRole(ctx context.Context, relID string) error {
-	return s.repo.DeleteUserDepartmentRole(ctx, relID)
+// checkMembershipOverlap สังกัดใหม่ [from, ไม่มีกำหนด) ต้องไม่ทับช่วงเดิมในแผนกเดียวกัน
+// ยังสังกัดอยู่ = ต้องเปลี่ยน role ผ่าน ChangeDepartmentRole; ย้อนหลังก็ต้องเริ่มหลังแถวเก่าจบแล้ว
+// endingRelID แถวที่กำลังจะจบ ณ from ในการเปลี่ยนเดียวกัน (ไม่นับว่าทับ)
+func (s *svc) checkMembershipOverlap(ctx context.Context, userID, departmentID string, from time.Time, endingRelID string) error {
+	hist, err := s.repo.ListUserDepartmentHistory(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, h := range hist {
+		if h.DepartmentID != departmentID || h.ID == endingRelID {
+			continue
+		}
+		if h.EffectiveTo == nil || h.EffectiveTo.After(from) {
+			return ErrMembershipOverlap
+		}
+	}
+	return nil
+}
+
+func (s *svc) EndUserDepartmentRole(ctx context.Context, userID, relID string, at *time.Time) error {
+	rel, err := s.userDepartmentRole(ctx, userID, relID)
+	if err != nil {
+		return err
+	}
+	end, err := effectiveAt(at)
+	if err != nil {
+		return err
+	}
+	if end.Before(rel.EffectiveFrom) {
+		return ErrEffectiveDateBeforeStart
+	}
+	return s.repo.EndUserDepartmentRole(ctx, relID, end)
+}
+
+func (s *svc) PurgeUserDepartmentRole(ctx context.Context, userID, relID string) error {
+	if _, err := s.userDepartmentRole(ctx, userID, relID); err != nil {
+		return err
+	}
+	return s.repo.PurgeUserDepartmentRole(ctx, relID)
+}
+
+func (s *svc) TransferUser(ctx context.Context, userID, fromDepartmentID, toDepartmentID string, role *string, at *time.Time) (*usermodels.UserDepartmentRole, error) {
+	if fromDepartmentID == toDepartmentID {
+		return nil, ErrSameDepartment
+	}
+	if _, err := s.repo.GetDepartmentByID(ctx, toDepartmentID); err != nil {
+		return nil, err
+	}
+	return s.replaceDepartmentRole(ctx, userID, fromDepartmentID, toDepartmentID, role, at)
+}
+
+func (s *svc) ChangeDepartmentRole(ctx context.Context, userID, relID, role string, at *time.Time) (*usermodels.UserDepartmentRole, error) {
+	rel, err := s.userDepartmentRole(ctx, userID, relID)
+	if err != nil {
+		return nil, err
+	}
+	return s.replaceDepartmentRole(ctx, userID, rel.DepartmentID, rel.DepartmentID, &role, at)
+}
+
+// replaceDepartmentRole จบสังกัดใน fromDepartmentID แล้วเริ่มสังกัดใหม่ ณ at (role nil = role เดิม)
+// แผนกเดิมและ role เดิม = ไม่มีอะไรเปลี่ยน ไม่เขียนประวัติซ้ำ
+func (s *svc) replaceDepartmentRole(ctx context.Context, userID, fromDepartmentID, toDepartmentID string, role *string, at *time.Time) (*usermodels.UserDepartmentRole, error) {
+	rels, err := s.ListUserDepartmentRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var from *usermodels.UserDepartmentRole
+	for i := range rels {
+		if rels[i].DepartmentID == fromDepartmentID {
+			from = &rels[i]
+			break
+		}
+	}
+	if from == nil {
+		return nil, ErrNotDepartmentMember
+	}
+
+	r := from.Role
+	if role != nil {
+		var ok bool
+		if r, ok = usermodels.ParseDepartmentRole(*role); !ok {
+			return nil, errors.New("invalid department role")
+		}
+	}
+	if toDepartmentID == from.DepartmentID && r == from.Role {
+		return from, nil
+	}
+	when, err := effectiveAt(at)
+	if err != nil {
+		return nil, err
+	}
+	if when.Before(from.EffectiveFrom) {
+		return nil, ErrEffectiveDateBeforeStart
+	}
+	// ย้ายเข้าแผนกที่ยังสังกัดอยู่ หรือย้อนหลังไปทับช่วงเก่า = นับซ้ำในรายชื่อสมาชิก ณ วันนั้น
+	if err := s.checkMembershipOverlap(ctx, userID, toDepartmentID, when, from.ID); err != nil {
+		return nil, err
+	}
+
+	to := &usermodels.UserDepartmentRole{
+		UserID:        userID,
+		DepartmentID:  toDepartmentID,
+		Role:          r,
+		EffectiveFrom: when,
+	}
+	if err := s.repo.TransferUserDepartment(ctx, from.ID, to); err != nil {
+		return nil, err
+	}
+	return to, nil
+}
+
+func (s *svc) ListUserDepartmentHistory(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error) {
+	if _, err := s.repo.FindByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListUserDepartmentHistory(ctx, userID)
+}
+
+func (s *svc) ListDepartmentMembersAt(ctx context.Context, departmentID string, at time.Time) ([]usermodels.UserDepartmentRole, error) {
+	if _, err := s.repo.GetDepartmentByID(ctx, departmentID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDepartmentMembersAt(ctx, departmentID, at)
+}
+
+// userDepartmentRole สังกัดปัจจุบันที่เป็นของ userID จริง (relID ของคนอื่น = ไม่พบ)
+func (s *svc) userDepartmentRole(ctx context.Context, userID, relID string) (*usermodels.UserDepartmentRole, error) {
+	rel, err := s.repo.GetUserDepartmentRole(ctx, relID)
+	if err != nil {
+		return nil, err
+	}
+	if rel.UserID != userID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return rel, nil
+}
+
+// effectiveAt วันที่มีผลของการเปลี่ยนสังกัด: nil = ตอนนี้, ล่วงหน้าไม่ได้ (ยังไม่มีอะไรมาเปิดใช้ตอนถึงวัน)
+func effectiveAt(at *time.Time) (time.Time, error) {
+	now := time.Now()
+	if at == nil {
+		return now, nil
+	}
+	if at.After(now) {
+		return time.Time{}, ErrEffectiveDateInFuture
+	}
+	return *at, nil
 }
 
 func (s *svc) ListDepartmentManagers(ctx context.Context, departmentID string) ([]usermodels.UserDepartmentRole, error) {
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	usermodels "github.com/Marugo/birdlax/internal/modules/user/models"
+)
+
+func (f *fakeMemberships) FindByID(_ context.Context, id string) (*usermodels.User, error) {
+	return &usermodels.User{ID: id}, nil
+}
+
+func (f *fakeMemberships) GetDepartmentByID(_ context.Context, id string) (*usermodels.Department, error) {
+	return &usermodels.Department{ID: id}, nil
+}
+
+func (f *fakeMemberships) ListUserDepartmentHistory(ctx context.Context, userID string) ([]usermodels.UserDepartmentRole, error) {
+	return f.ListDepartmentRolesForUsers(ctx, []string{userID})
+}
+
+func (f *fakeMemberships) AddUserDepartmentRole(_ context.Context, r *usermodels.UserDepartmentRole) error {
+	f.rels = append(f.rels, *r)
+	return nil
+}
+
+// ListUserDepartmentRoles เฉพาะสังกัดปัจจุบัน (History คืนทุกแถว)
+func (f *fakeMemberships) ListUserDepartmentRoles(_ context.Context, userID string) ([]usermodels.UserDepartmentRole, error) {
+	var out []usermodels.UserDepartmentRole
+	for _, r := range f.rels {
+		if r.UserID == userID && r.EffectiveTo == nil {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeMemberships) GetUserDepartmentRole(_ context.Context, relID string) (*usermodels.UserDepartmentRole, error) {
+	for _, r := range f.rels {
+		if r.ID == relID && r.EffectiveTo == nil {
+			return &r, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (f *fakeMemberships) TransferUserDepartment(_ context.Context, fromRelID string, to *usermodels.UserDepartmentRole) error {
+	for i := range f.rels {
+		if f.rels[i].ID == fromRelID {
+			end := to.EffectiveFrom
+			f.rels[i].EffectiveTo = &end
+			f.rels = append(f.rels, *to)
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+func TestAddUserDepartmentRoleRejectsOverlap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	day := func(n int) time.Time { return now.AddDate(0, 0, n) }
+	ended := day(-10)
+	repo := &fakeMemberships{rels: []usermodels.UserDepartmentRole{
+		{ID: "r1", UserID: "u1", DepartmentID: "d1", Role: usermodels.DeptRoleMember, EffectiveFrom: day(-30), EffectiveTo: &ended},
+		{ID: "r2", UserID: "u1", DepartmentID: "d2", Role: usermodels.DeptRoleMember, EffectiveFrom: day(-5)},
+	}}
+	s := &svc{repo: repo}
+
+	for _, tc := range []struct {
+		name string
+		dept string
+		from time.Time
+	}{
+		{"still a member", "d2", now},
+		{"backdated into an ended period", "d1", day(-20)},
+		{"backdated before an ended period", "d1", day(-40)},
+	} {
+		from := tc.from
+		if _, err := s.AddUserDepartmentRole(ctx, "u1", tc.dept, "leader", &from); !errors.Is(err, ErrMembershipOverlap) {
+			t.Fatalf("%s: err = %v", tc.name, err)
+		}
+	}
+	if len(repo.rels) != 2 {
+		t.Fatalf("rels = %+v", repo.rels)
+	}
+
+	// กลับเข้าแผนกเดิมหลังแถวเก่าจบแล้ว / แผนกใหม่ = ได้
+	for _, tc := range []struct {
+		dept string
+		from time.Time
+	}{{"d1", ended}, {"d3", day(-100)}} {
+		from := tc.from
+		if _, err := s.AddUserDepartmentRole(ctx, "u1", tc.dept, "member", &from); err != nil {
+			t.Fatalf("%s: %v", tc.dept, err)
+		}
+	}
+}
+
+func TestTransferUserRejectsOverlap(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	day := func(n int) time.Time { return now.AddDate(0, 0, n) }
+	ended := day(-10)
+	newRepo := func() *fakeMemberships {
+		return &fakeMemberships{rels: []usermodels.UserDepartmentRole{
+			{ID: "r1", UserID: "u1", DepartmentID: "d1", Role: usermodels.DeptRoleMember, EffectiveFrom: day(-30), EffectiveTo: &ended},
+			{ID: "r2", UserID: "u1", DepartmentID: "d2", Role: usermodels.DeptRoleMember, EffectiveFrom: day(-60)},
+			{ID: "r3", UserID: "u1", DepartmentID: "d3", Role: usermodels.DeptRoleMember, EffectiveFrom: day(-60)},
+		}}
+	}
+
+	for _, tc := range []struct {
+		name string
+		to   string
+		at   time.Time
+	}{
+		{"into a department still a member of", "d3", now},
+		{"backdated into an ended period", "d1", day(-20)},
+	} {
+		repo := newRepo()
+		s := &svc{repo: repo}
+		at := tc.at
+		if _, err := s.TransferUser(ctx, "u1", "d2", tc.to, nil, &at); !errors.Is(err, ErrMembershipOverlap) {
+			t.Fatalf("%s: err = %v", tc.name, err)
+		}
+		if len(repo.rels) != 3 || repo.rels[1].EffectiveTo != nil {
+			t.Fatalf("%s: rels = %+v", tc.name, repo.rels)
+		}
+	}
+
+	// หลังช่วงเก่าจบ = ย้ายได้; เปลี่ยน role ในแผนกเดิมไม่นับแถวที่กำลังจบเป็นการทับ
+	repo := newRepo()
+	s := &svc{repo: repo}
+	at := day(-5)
+	if _, err := s.TransferUser(ctx, "u1", "d2", "d1", nil, &at); err != nil {
+		t.Fatalf("transfer after old period: %v", err)
+	}
+	if _, err := s.ChangeDepartmentRole(ctx, "u1", "r3", "leader", nil); err != nil {
+		t.Fatalf("change role: %v", err)
+	}
+	if len(repo.rels) != 5 {
+		t.Fatalf("rels = %+v", repo.rels)
+	}
+}
+
+func TestChangeDepartmentRoleByRelation(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeMemberships{rels: []usermodels.UserDepartmentRole{
+		{ID: "r1", UserID: "u1", DepartmentID: "d1", Role: usermodels.DeptRoleMember, EffectiveFrom: time.Now().AddDate(0, -1, 0)},
+		{ID: "r2", UserID: "u2", DepartmentID: "d1", Role: usermodels.DeptRoleMember, EffectiveFrom: time.Now().AddDate(0, -1, 0)},
+	}}
+	s := &svc{repo: repo}
+
+	// relID ของคนอื่น = ไม่พบ
+	if _, err := s.ChangeDepartmentRole(ctx, "u1", "r2", "manager", nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("other user's relation: err = %v", err)
+	}
+	rel, err := s.ChangeDepartmentRole(ctx, "u1", "r1", "manager", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.DepartmentID != "d1" || rel.Role != usermodels.DeptRoleManager {
+		t.Fatalf("new relation = %+v", rel)
+	}
+	if repo.rels[0].EffectiveTo == nil || len(repo.rels) != 3 {
+		t.Fatalf("rels = %+v", repo.rels)
+	}
+	// แถวที่จบแล้วเปลี่ยนต่อไม่ได้
+	if _, err := s.ChangeDepartmentRole(ctx, "u1", "r1", "leader", nil); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("ended relation: err = %v", err)
+	}
+}
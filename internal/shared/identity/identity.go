@@ -0,0 +1,31 @@
+// Package identity helper ที่ใช้ร่วมกันตอนสร้าง/sync บัญชี (import, SCIM, service account)
+package identity
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/mail"
+	"strings"
+)
+
+// SplitName แยกชื่อเต็มเป็นชื่อ (คำแรก) กับนามสกุล (ที่เหลือ; "" ถ้ามีคำเดียว)
+func SplitName(name string) (first, last string) {
+	parts := strings.Fields(name)
+	if len(parts) == 0 {
+		return "", ""
+	}
+	return parts[0], strings.Join(parts[1:], " ")
+}
+
+// ValidEmail เป็น address ล้วน ๆ (ไม่มีชื่อหรือ <...> ครอบ)
+func ValidEmail(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s
+}
+
+// RandomSecret สุ่ม 256 bit (base64url) ใช้เป็น secret หรือรหัสผ่านที่ไม่มีใครรู้
+func RandomSecret() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
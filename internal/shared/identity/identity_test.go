@@ -0,0 +1,37 @@
+package identity
+
+import "testing"
+
+func TestSplitName(t *testing.T) {
+	cases := []struct{ in, first, last string }{
+		{"", "", ""},
+		{"  Somchai  ", "Somchai", ""},
+		{"Somchai Jaidee", "Somchai", "Jaidee"},
+		{" Anna  de la Cruz ", "Anna", "de la Cruz"},
+	}
+	for _, tc := range cases {
+		if f, l := SplitName(tc.in); f != tc.first || l != tc.last {
+			t.Errorf("SplitName(%q) = %q, %q", tc.in, f, l)
+		}
+	}
+}
+
+func TestValidEmail(t *testing.T) {
+	for _, s := range []string{"a@example.com", "first.last@sub.example.co.th"} {
+		if !ValidEmail(s) {
+			t.Errorf("%q should be valid", s)
+		}
+	}
+	for _, s := range []string{"", "nope", "A <a@example.com>", "a@example.com "} {
+		if ValidEmail(s) {
+			t.Errorf("%q should be invalid", s)
+		}
+	}
+}
+
+func TestRandomSecret(t *testing.T) {
+	a, b := RandomSecret(), RandomSecret()
+	if len(a) != 43 || a == b {
+		t.Fatalf("secrets %q %q", a, b)
+	}
+}
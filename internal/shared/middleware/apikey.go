@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyPrefix key ทุกตัวขึ้นต้นด้วยค่านี้ ใช้แยกจาก JWT ใน Authorization: Bearer
+const APIKeyPrefix = "bk_"
+
+// APIKeyPrincipal service account ที่ยืนยันตัวตนด้วย API key แล้ว
+type APIKeyPrincipal struct {
+	ServiceAccountID string
+	KeyID            string
+	Role             string
+	Scopes           []string
+}
+
+// APIKeyAuthenticator ตรวจ API key (implement โดย apikey service)
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key, ip string) (*APIKeyPrincipal, error)
+}
+
+// AuthRequiredOrAPIKey รับได้ทั้ง JWT ของคน (AuthRequired เดิม) และ API key ของ service account
+// API key ส่งมาทาง X-API-Key หรือ Authorization: Bearer bk_...
+func AuthRequiredOrAPIKey(keys APIKeyAuthenticator) fiber.Handler {
+	jwtAuth := AuthRequired()
+	return func(c *fiber.Ctx) error {
+		key := c.Get("X-API-Key")
+		if key == "" {
+			if bearer := strings.TrimPrefix(c.Get("Authorization"), "Bearer "); strings.HasPrefix(bearer, APIKeyPrefix) {
+				key = bearer
+			}
+		}
+		if key == "" {
+			return jwtAuth(c)
+		}
+
+		p, err := keys.Authenticate(c.Context(), key, c.IP())
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or revoked api key"})
+		}
+		if !scopeAllows(p.Scopes, requiredScope(c)) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"code": "INSUFFICIENT_SCOPE", "message": "api key scope does not allow " + requiredScope(c)})
+		}
+		c.Locals("user_id", p.ServiceAccountID)
+		c.Locals("role", p.Role)
+		c.Locals("principal", "service_account")
+		c.Locals("scopes", p.Scopes)
+		return c.Next()
+	}
+}
+
+// RequireUser ปิด route ที่ต้องเป็นคนเท่านั้น (เช่นจัดการ API key เอง)
+func RequireUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if p, _ := c.Locals("principal").(string); p == "service_account" {
+			return fiber.NewError(fiber.StatusForbidden, "not available for service accounts")
+		}
+		return c.Next()
+	}
+}
+
+// requiredScope = <resource>.read สำหรับ GET/HEAD, <resource>.write สำหรับอย่างอื่น
+// resource คือ path segment แรกหลัง /api/v1 เช่น /api/v1/users/123 -> users
+func requiredScope(c *fiber.Ctx) string {
+	path := strings.TrimPrefix(c.Path(), "/api/v1")
+	resource, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	action := "write"
+	if c.Method() == fiber.MethodGet || c.Method() == fiber.MethodHead {
+		action = "read"
+	}
+	return resource + "." + action
+}
+
+// scopeAllows รองรับ "*" (ทุกอย่าง), "users.*" และ write ครอบ read ของ resource เดียวกัน
+func scopeAllows(granted []string, need string) bool {
+	resource, action, _ := strings.Cut(need, ".")
+	for _, g := range granted {
+		switch g {
+		case "*", need, resource + ".*":
+			return true
+		case resource + ".write":
+			if action == "read" {
+				return true
+			}
+		}
+	}
+	return false
+}
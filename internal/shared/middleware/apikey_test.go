@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestScopeAllows(t *testing.T) {
+	cases := []struct {
+		granted []string
+		need    string
+		want    bool
+	}{
+		{[]string{"*"}, "users.write", true},
+		{[]string{"users.*"}, "users.write", true},
+		{[]string{"users.*"}, "courses.read", false},
+		{[]string{"users.write"}, "users.read", true}, // write ครอบ read
+		{[]string{"users.read"}, "users.write", false},
+		{[]string{"users.write"}, "courses.read", false},
+		{[]string{"courses.read", "users.read"}, "users.read", true},
+		{nil, "users.read", false},
+	}
+	for _, tc := range cases {
+		if got := scopeAllows(tc.granted, tc.need); got != tc.want {
+			t.Errorf("scopeAllows(%v, %s) = %v, want %v", tc.granted, tc.need, got, tc.want)
+		}
+	}
+}
+
+func TestRequiredScope(t *testing.T) {
+	var got string
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		got = requiredScope(c)
+		return c.SendStatus(fiber.StatusOK)
+	})
+	cases := []struct{ method, path, want string }{
+		{fiber.MethodGet, "/api/v1/users/123", "users.read"},
+		{fiber.MethodHead, "/api/v1/courses", "courses.read"},
+		{fiber.MethodPost, "/api/v1/users/import", "users.write"},
+		{fiber.MethodDelete, "/api/v1/departments/9", "departments.write"},
+	}
+	for _, tc := range cases {
+		if _, err := app.Test(httptest.NewRequest(tc.method, tc.path, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("%s %s: scope = %q, want %q", tc.method, tc.path, got, tc.want)
+		}
+	}
+}
+
+type fakeKeys map[string]*APIKeyPrincipal
+
+func (f fakeKeys) Authenticate(_ context.Context, key, _ string) (*APIKeyPrincipal, error) {
+	if p, ok := f[key]; ok {
+		return p, nil
+	}
+	return nil, errors.New("invalid api key") // key ผิด/ถูก revoke/หมดอายุ
+}
+
+func TestAuthRequiredOrAPIKey(t *testing.T) {
+	keys := fakeKeys{
+		"bk_bi.secret":  {ServiceAccountID: "sa-bi", Role: "employee", Scopes: []string{"users.read", "courses.write"}},
+		"bk_all.secret": {ServiceAccountID: "sa-all", Role: "admin", Scopes: []string{"*"}},
+	}
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app := fiber.New()
+	api := app.Group("/api/v1", AuthRequiredOrAPIKey(keys))
+	api.Get("/users", ok)
+	api.Post("/users", ok)
+	api.Get("/courses/:id", ok)
+	api.Post("/courses/:id/enroll", RequireUser(), ok) // route ของผู้เรียน
+
+	cases := []struct {
+		method, path, key string
+		want              int
+	}{
+		{fiber.MethodGet, "/api/v1/users", "bk_bi.secret", fiber.StatusOK},
+		{fiber.MethodPost, "/api/v1/users", "bk_bi.secret", fiber.StatusForbidden},
+		{fiber.MethodGet, "/api/v1/courses/1", "bk_bi.secret", fiber.StatusOK}, // courses.write ครอบ read
+		{fiber.MethodPost, "/api/v1/users", "bk_all.secret", fiber.StatusOK},
+		{fiber.MethodGet, "/api/v1/users", "bk_revoked.secret", fiber.StatusUnauthorized},
+		{fiber.MethodPost, "/api/v1/courses/1/enroll", "bk_all.secret", fiber.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.key)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != tc.want {
+			t.Errorf("%s %s with %s: status = %d, want %d", tc.method, tc.path, tc.key, res.StatusCode, tc.want)
+		}
+	}
+}
@@ -19,10 +19,33 @@ func AuthRequired() fiber.Handler {
 		if err != nil || claims.ExpiresAt.Time.Before(time.Now()) {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "invalid or expired token"})
 		}
+		// ปิด account / เปลี่ยน role / log out everywhere => version ใน DB เปลี่ยน token เก่าใช้ไม่ได้ทันที
+		if st, ok, err := security.CurrentTokenState(c.Context(), claims.UserID); ok {
+			if err != nil || !st.Active || st.Version != claims.Ver {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"code": "TOKEN_REVOKED", "message": "token has been revoked"})
+			}
+		}
+		// รหัสหมดอายุ: ทำได้แค่ดูตัวเอง เปลี่ยนรหัส หรือ logout
+		if claims.PwdChange && !passwordChangeAllowed(c.Path()) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"code": "PASSWORD_CHANGE_REQUIRED", "message": "password must be changed before continuing"})
+		}
 		// inject context
 		c.Locals("user_id", claims.UserID)
 		c.Locals("role", claims.Role)
 		c.Locals("emp_code", claims.Emp)
+		if claims.Act != nil {
+			return impersonate(c, claims)
+		}
 		return c.Next()
 	}
 }
+
+func passwordChangeAllowed(path string) bool {
+	path = strings.TrimSuffix(path, "/")
+	for _, p := range []string{"/auth/me", "/auth/me/password", "/auth/logout"} {
+		if strings.HasSuffix(path, p) {
+			return true
+		}
+	}
+	return false
+}
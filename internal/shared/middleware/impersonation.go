@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/Marugo/birdlax/internal/shared/security"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+)
+
+// ImpersonatedRequest หนึ่ง request ที่ทำด้วย impersonation token
+type ImpersonatedRequest struct {
+	SessionID string
+	ActorID   string
+	SubjectID string
+	Method    string
+	Path      string
+	Status    int
+	IP        string
+	UserAgent string
+}
+
+// ImpersonationAuditor บันทึก request ภายใต้ impersonation (implement โดย audit service)
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(ctx context.Context, r ImpersonatedRequest) error
+}
+
+// ImpersonationRevocations บอกว่า session ถูกจบก่อนหมดอายุหรือยัง (implement โดย auth repo)
+type ImpersonationRevocations interface {
+	ImpersonationRevoked(ctx context.Context, sessionID string) (bool, error)
+}
+
+var impersonationAudit struct {
+	sync.RWMutex
+	auditor     ImpersonationAuditor
+	revocations ImpersonationRevocations
+}
+
+// SetImpersonationAuditor ตั้งตอน DI; ถ้าไม่ได้ตั้ง impersonation token จะถูกปฏิเสธทั้งหมด
+func SetImpersonationAuditor(a ImpersonationAuditor) {
+	impersonationAudit.Lock()
+	defer impersonationAudit.Unlock()
+	impersonationAudit.auditor = a
+}
+
+// SetImpersonationRevocations ตั้งตอน DI ให้ DELETE /auth/impersonation มีผลทันที
+func SetImpersonationRevocations(r ImpersonationRevocations) {
+	impersonationAudit.Lock()
+	defer impersonationAudit.Unlock()
+	impersonationAudit.revocations = r
+}
+
+// DenyImpersonation ปิด route ที่ห้ามทำแทนคนอื่น (รหัสผ่าน, session, MFA, งาน admin)
+func DenyImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if actor, _ := c.Locals("actor_id").(string); actor != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"code": "IMPERSONATION_FORBIDDEN", "message": "not available while impersonating"})
+		}
+		return c.Next()
+	}
+}
+
+// impersonate ใช้แทน c.Next() ใน AuthRequired เมื่อ token มี act claim
+// ทุก request ถูกบันทึก รวมถึงที่ถูกปฏิเสธ (เช่นพยายามเขียนตอน read-only)
+func impersonate(c *fiber.Ctx, claims *security.AccessClaims) error {
+	impersonationAudit.RLock()
+	auditor, revocations := impersonationAudit.auditor, impersonationAudit.revocations
+	impersonationAudit.RUnlock()
+	if auditor == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"code": "UNAUTHORIZED", "message": "impersonation is not enabled"})
+	}
+
+	// คนที่สวมรอยถูกปิด account / เปลี่ยน role => token ใช้ไม่ได้ทันทีเหมือนกัน
+	if st, ok, err := security.CurrentTokenState(c.Context(), claims.Act.Sub); ok {
+		if err != nil || !st.Active || st.Version != claims.Act.Ver {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"code": "TOKEN_REVOKED", "message": "token has been revoked"})
+		}
+	}
+	if revocations != nil {
+		revoked, err := revocations.ImpersonationRevoked(c.Context(), claims.ID)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "cannot check impersonation session")
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"code": "TOKEN_REVOKED", "message": "impersonation session has ended"})
+		}
+	}
+
+	// fiber reuse buffer หลังจบ request จึงต้อง copy ค่าที่จะบันทึก
+	entry := ImpersonatedRequest{
+		SessionID: claims.ID,
+		ActorID:   claims.Act.Sub,
+		SubjectID: claims.UserID,
+		Method:    utils.CopyString(c.Method()),
+		Path:      utils.CopyString(c.OriginalURL()),
+		IP:        utils.CopyString(c.IP()),
+		UserAgent: utils.CopyString(c.Get(fiber.HeaderUserAgent)),
+	}
+
+	var err error
+	if claims.ReadOnly && !isReadMethod(c.Method()) && !endsImpersonation(c) {
+		err = c.Status(fiber.StatusForbidden).JSON(fiber.Map{"code": "IMPERSONATION_READ_ONLY", "message": "impersonation session is read-only"})
+	} else {
+		c.Locals("actor_id", claims.Act.Sub)
+		c.Locals("impersonation_id", claims.ID)
+		c.Locals("impersonation_read_only", claims.ReadOnly)
+		err = c.Next()
+	}
+
+	entry.Status = c.Response().StatusCode()
+	if fe, ok := err.(*fiber.Error); ok {
+		entry.Status = fe.Code
+	} else if err != nil {
+		entry.Status = fiber.StatusInternalServerError
+	}
+	if aerr := auditor.RecordImpersonatedRequest(c.Context(), entry); aerr != nil {
+		log.Printf("impersonation audit: session %s: %v", claims.ID, aerr)
+	}
+	return err
+}
+
+// endsImpersonation DELETE /auth/impersonation: session read-only ก็ต้องจบตัวเองได้
+func endsImpersonation(c *fiber.Ctx) bool {
+	return c.Method() == fiber.MethodDelete && strings.HasSuffix(strings.TrimSuffix(c.Path(), "/"), "/auth/impersonation")
+}
+
+func isReadMethod(m string) bool {
+	return m == fiber.MethodGet || m == fiber.MethodHead || m == fiber.MethodOptions
+}
@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Marugo/birdlax/internal/shared/security"
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMain(m *testing.M) {
+	os.Setenv("JWT_ACCESS_SECRET", "test-access-secret")
+	os.Exit(m.Run())
+}
+
+type fakeImpersonationStore struct {
+	mu       sync.Mutex
+	revoked  map[string]bool
+	requests []ImpersonatedRequest
+}
+
+func (f *fakeImpersonationStore) RecordImpersonatedRequest(_ context.Context, r ImpersonatedRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+	return nil
+}
+
+func (f *fakeImpersonationStore) ImpersonationRevoked(_ context.Context, id string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.revoked[id], nil
+}
+
+func TestEndedImpersonationIsRejected(t *testing.T) {
+	store := &fakeImpersonationStore{revoked: map[string]bool{}}
+	SetImpersonationAuditor(store)
+	SetImpersonationRevocations(store)
+	t.Cleanup(func() {
+		SetImpersonationAuditor(nil)
+		SetImpersonationRevocations(nil)
+	})
+
+	claims := security.AccessClaims{UserID: "subject", Role: "employee", Act: &security.Actor{Sub: "admin"}, ReadOnly: true}
+	claims.ID = "sess-1"
+	token, err := security.SignAccessClaims(claims, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/api/v1/courses", AuthRequired(), ok)
+	app.Post("/api/v1/courses", AuthRequired(), ok)
+	app.Delete("/api/v1/auth/impersonation", AuthRequired(), func(c *fiber.Ctx) error {
+		store.revoked[c.Locals("impersonation_id").(string)] = true
+		return c.SendStatus(fiber.StatusOK)
+	})
+	call := func(method, path string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode
+	}
+
+	if got := call(fiber.MethodGet, "/api/v1/courses"); got != fiber.StatusOK {
+		t.Fatalf("read: %d", got)
+	}
+	if got := call(fiber.MethodPost, "/api/v1/courses"); got != fiber.StatusForbidden {
+		t.Fatalf("write while read-only: %d", got)
+	}
+	// read-only session จบตัวเองได้
+	if got := call(fiber.MethodDelete, "/api/v1/auth/impersonation"); got != fiber.StatusOK {
+		t.Fatalf("end: %d", got)
+	}
+	if got := call(fiber.MethodGet, "/api/v1/courses"); got != fiber.StatusUnauthorized {
+		t.Fatalf("after end: %d", got)
+	}
+	if len(store.requests) != 3 {
+		t.Fatalf("audited %d requests, want 3", len(store.requests))
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// PermissionResolver คืน permission ทั้งหมดของ role (ตั้งตอน DI เพื่อไม่ให้ shared ต้อง import module)
+type PermissionResolver func(ctx context.Context, role string) ([]string, error)
+
+var permissionResolver struct {
+	sync.RWMutex
+	resolve   PermissionResolver
+	humanOnly map[string]struct{}
+}
+
+func SetPermissionResolver(f PermissionResolver) {
+	permissionResolver.Lock()
+	defer permissionResolver.Unlock()
+	permissionResolver.resolve = f
+}
+
+// SetHumanOnlyPermissions permission ที่ตัดทิ้งเสมอเมื่อผู้เรียกเป็น service account
+func SetHumanOnlyPermissions(perms ...string) {
+	m := make(map[string]struct{}, len(perms))
+	for _, p := range perms {
+		m[p] = struct{}{}
+	}
+	permissionResolver.Lock()
+	defer permissionResolver.Unlock()
+	permissionResolver.humanOnly = m
+}
+
+// RequirePermission ต้องวางหลัง AuthRequired / AuthRequiredOrAPIKey
+func RequirePermission(perm string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		perms, err := Permissions(c)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "cannot resolve permissions")
+		}
+		if _, ok := perms[perm]; !ok {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"code": "FORBIDDEN", "message": "missing permission " + perm})
+		}
+		return c.Next()
+	}
+}
+
+// HasPermission สำหรับเช็คใน handler (เช่นฟิลด์ที่ต้องใช้สิทธิ์เพิ่ม)
+func HasPermission(c *fiber.Ctx, perm string) bool {
+	perms, err := Permissions(c)
+	if err != nil {
+		return false
+	}
+	_, ok := perms[perm]
+	return ok
+}
+
+// Permissions ของผู้เรียก; resolve ครั้งเดียวต่อ request แล้วเก็บใน Locals
+func Permissions(c *fiber.Ctx) (map[string]struct{}, error) {
+	if perms, ok := c.Locals("permissions").(map[string]struct{}); ok {
+		return perms, nil
+	}
+	permissionResolver.RLock()
+	resolve, humanOnly := permissionResolver.resolve, permissionResolver.humanOnly
+	permissionResolver.RUnlock()
+
+	perms := map[string]struct{}{}
+	role, _ := c.Locals("role").(string)
+	principal, _ := c.Locals("principal").(string)
+	if resolve != nil && role != "" {
+		list, err := resolve(c.Context(), role)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			if _, denied := humanOnly[p]; denied && principal == "service_account" {
+				continue
+			}
+			perms[p] = struct{}{}
+		}
+	}
+	c.Locals("permissions", perms)
+	return perms, nil
+}
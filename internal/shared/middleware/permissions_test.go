@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestServiceAccountLosesHumanOnlyPermissions(t *testing.T) {
+	SetPermissionResolver(func(context.Context, string) ([]string, error) {
+		return []string{"user.read", "user.security"}, nil
+	})
+	SetHumanOnlyPermissions("user.security")
+	t.Cleanup(func() {
+		SetPermissionResolver(nil)
+		SetHumanOnlyPermissions()
+	})
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "admin")
+		if c.Get("X-Principal") != "" {
+			c.Locals("principal", c.Get("X-Principal"))
+		}
+		return c.Next()
+	})
+	app.Get("/read", RequirePermission("user.read"), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+	app.Get("/security", RequirePermission("user.security"), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	cases := []struct {
+		path, principal string
+		want            int
+	}{
+		{"/read", "", fiber.StatusOK},
+		{"/security", "", fiber.StatusOK},
+		{"/read", "service_account", fiber.StatusOK},
+		{"/security", "service_account", fiber.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(fiber.MethodGet, tc.path, nil)
+		req.Header.Set("X-Principal", tc.principal)
+		res, err := app.Test(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != tc.want {
+			t.Fatalf("%s as %q: status = %d, want %d", tc.path, tc.principal, res.StatusCode, tc.want)
+		}
+	}
+}
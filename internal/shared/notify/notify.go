@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"os"
+	"time"
+)
+
+// Message ข้อความที่จะส่งถึงผู้ใช้ (ตอนนี้มีแค่ email แบบ plain text)
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Notifier interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// FromEnv เลือก notifier ตาม NOTIFIER_DRIVER: "smtp" หรือ "outbox" (default, สำหรับ dev)
+func FromEnv() Notifier {
+	from := getEnv("NOTIFY_FROM", "no-reply@localhost")
+	switch os.Getenv("NOTIFIER_DRIVER") {
+	case "smtp":
+		return &SMTP{
+			Host:     getEnv("SMTP_HOST", "localhost"),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USER"),
+			Password: os.Getenv("SMTP_PASS"),
+			From:     from,
+		}
+	default:
+		return &Outbox{Dir: getEnv("NOTIFY_OUTBOX_DIR", "./var/outbox"), From: from}
+	}
+}
+
+// render ประกอบ message เป็น RFC 5322 (ใช้ร่วมกันทั้ง SMTP และ outbox)
+func (m Message) render(from string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(m.Body)
+	return b.Bytes()
+}
+
+func getEnv(k, def string) string {
+	if v := os.Getenv(k); v != "" {
+		return v
+	}
+	return def
+}
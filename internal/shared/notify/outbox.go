@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox เขียน email เป็นไฟล์ .eml ลง Dir แทนการส่งจริง (ใช้ตอน dev)
+type Outbox struct {
+	Dir  string
+	From string
+}
+
+func (o *Outbox) Send(_ context.Context, m Message) error {
+	if err := os.MkdirAll(o.Dir, 0755); err != nil {
+		return err
+	}
+	fn := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405"), uuid.NewString())
+	path := filepath.Join(o.Dir, fn)
+	if err := os.WriteFile(path, m.render(o.From), 0600); err != nil {
+		return err
+	}
+	log.Printf("notify: wrote %q for %s to %s", m.Subject, m.To, path)
+	return nil
+}
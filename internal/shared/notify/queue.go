@@ -0,0 +1,44 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+var ErrQueueFull = errors.New("notify: queue is full")
+
+// Queue ส่งข้อความเบื้องหลังด้วย worker ตัวเดียว: Send คืนทันทีไม่รอ SMTP
+// (เวลาตอบของ endpoint จะได้ไม่ต่างกันระหว่างมี/ไม่มีผู้รับ) error ตอนส่งจริงแค่ log ไว้
+type Queue struct {
+	next    Notifier
+	ch      chan Message
+	timeout time.Duration
+}
+
+func NewQueue(next Notifier, size int) *Queue {
+	q := &Queue{next: next, ch: make(chan Message, size), timeout: 30 * time.Second}
+	go q.run()
+	return q
+}
+
+// Send ใส่คิว; คิวเต็ม = ErrQueueFull (ไม่ block ผู้เรียก)
+func (q *Queue) Send(_ context.Context, m Message) error {
+	select {
+	case q.ch <- m:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (q *Queue) run() {
+	for m := range q.ch {
+		ctx, cancel := context.WithTimeout(context.Background(), q.timeout)
+		if err := q.next.Send(ctx, m); err != nil {
+			log.Printf("notify: send %q: %v", m.Subject, err)
+		}
+		cancel()
+	}
+}
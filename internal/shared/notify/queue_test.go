@@ -0,0 +1,57 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type blockingNotifier struct {
+	release chan struct{}
+	sent    chan Message
+}
+
+func (b *blockingNotifier) Send(_ context.Context, m Message) error {
+	<-b.release
+	b.sent <- m
+	return nil
+}
+
+func TestQueueSendDoesNotWait(t *testing.T) {
+	b := &blockingNotifier{release: make(chan struct{}), sent: make(chan Message, 4)}
+	q := NewQueue(b, 1)
+
+	start := time.Now()
+	if err := q.Send(context.Background(), Message{To: "a@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Fatal("Send blocked on the underlying notifier")
+	}
+	close(b.release)
+	select {
+	case m := <-b.sent:
+		if m.To != "a@example.com" {
+			t.Fatalf("got %+v", m)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was never delivered")
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	b := &blockingNotifier{release: make(chan struct{}), sent: make(chan Message, 4)}
+	q := NewQueue(b, 1)
+	defer close(b.release)
+
+	// worker หยิบตัวแรกไปค้างไว้, ตัวที่สองอยู่ในคิว, ตัวถัดไปต้องเต็ม
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		err = q.Send(context.Background(), Message{})
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("want ErrQueueFull, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package notify
+
+import (
+	"context"
+	"net"
+	"net/smtp"
+)
+
+type SMTP struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (s *SMTP) Send(_ context.Context, m Message) error {
+	var a smtp.Auth
+	if s.Username != "" {
+		a = smtp.PlainAuth("", s.Username, s.Password, s.Host)
+	}
+	// smtp.SendMail จะ STARTTLS ให้เองถ้า server รองรับ
+	return smtp.SendMail(net.JoinHostPort(s.Host, s.Port), a, s.From, []string{m.To}, m.render(s.From))
+}
@@ -0,0 +1,43 @@
+package password
+
+import (
+	_ "embed"
+	"strings"
+)
+
+//go:embed common_passwords.txt
+var commonPasswords string
+
+func defaultBlocklist() map[string]struct{} {
+	m := map[string]struct{}{}
+	addWords(m, commonPasswords)
+	return m
+}
+
+// addWords หนึ่งบรรทัดหนึ่งคำ (# = comment)
+func addWords(m map[string]struct{}, s string) {
+	for _, line := range strings.Split(s, "\n") {
+		w := strings.ToLower(strings.TrimSpace(line))
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		m[w] = struct{}{}
+	}
+}
+
+// blocked เทียบแบบไม่สนตัวพิมพ์ และตัดเลข/สัญลักษณ์ท้ายออกด้วย (Password123! = password)
+func (p Policy) blocked(pw string) bool {
+	if len(p.blocklist) == 0 {
+		return false
+	}
+	w := strings.ToLower(strings.TrimSpace(pw))
+	if _, ok := p.blocklist[w]; ok {
+		return true
+	}
+	base := strings.TrimRight(w, "0123456789!@#$%^&*()-_=+.?~")
+	if base == "" || base == w {
+		return false
+	}
+	_, ok := p.blocklist[base]
+	return ok
+}
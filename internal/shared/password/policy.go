@@ -0,0 +1,183 @@
+package password
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// bcrypt ใช้แค่ 72 byte แรก รหัสที่ยาวกว่านี้ส่วนเกินไม่มีผล
+const maxBytes = 72
+
+// Violation กฎหนึ่งข้อที่รหัสผ่านไม่ผ่าน (Rule ใช้เป็น key ให้ frontend แปลข้อความเอง)
+type Violation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// PolicyError รวมทุกข้อที่ไม่ผ่านในครั้งเดียว
+type PolicyError struct {
+	Violations []Violation
+}
+
+func (e *PolicyError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.Message)
+	}
+	return "password policy: " + strings.Join(msgs, "; ")
+}
+
+// Policy กฎรหัสผ่าน (โหลดจาก env ด้วย PolicyFromEnv)
+type Policy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	History       int           // ห้ามซ้ำกับรหัสเก่า N ตัวล่าสุด (0 = เช็คแค่รหัสปัจจุบัน)
+	MaxAge        time.Duration // 0 = ไม่หมดอายุ
+
+	blocklist map[string]struct{}
+}
+
+// HistoryStore ที่เก็บ hash ของรหัสเก่า (implement โดย user repo)
+type HistoryStore interface {
+	RecentPasswordHashes(ctx context.Context, userID string, n int) ([]string, error)
+}
+
+// Subject เจ้าของรหัสผ่านที่กำลังตั้ง
+type Subject struct {
+	UserID      string   // ว่าง = user ใหม่ (ไม่มีประวัติ)
+	CurrentHash string   // รหัสปัจจุบัน (ห้ามตั้งซ้ำ)
+	Personal    []string // employee code, email, ชื่อ — ห้ามอยู่ในรหัส
+}
+
+func PolicyFromEnv() Policy {
+	p := Policy{
+		MinLength:     envInt("PASSWORD_MIN_LENGTH", 8),
+		RequireUpper:  envBool("PASSWORD_REQUIRE_UPPER"),
+		RequireLower:  envBool("PASSWORD_REQUIRE_LOWER"),
+		RequireDigit:  envBool("PASSWORD_REQUIRE_DIGIT"),
+		RequireSymbol: envBool("PASSWORD_REQUIRE_SYMBOL"),
+		History:       envInt("PASSWORD_HISTORY", 5),
+		blocklist:     defaultBlocklist(),
+	}
+	if d, err := time.ParseDuration(os.Getenv("PASSWORD_MAX_AGE")); err == nil && d > 0 {
+		p.MaxAge = d
+	}
+	if f := os.Getenv("PASSWORD_BLOCKLIST_FILE"); f != "" {
+		if b, err := os.ReadFile(f); err == nil {
+			addWords(p.blocklist, string(b))
+		}
+	}
+	return p
+}
+
+// Validate ตรวจกฎที่ไม่ต้องใช้ฐานข้อมูล (ความยาว, ชนิดตัวอักษร, blocklist, ข้อมูลส่วนตัว)
+func (p Policy) Validate(pw string, personal ...string) []Violation {
+	var out []Violation
+	add := func(rule, msg string) { out = append(out, Violation{Rule: rule, Message: msg}) }
+
+	if n := len([]rune(pw)); n < p.MinLength {
+		add("min_length", fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(pw) > maxBytes {
+		add("max_length", fmt.Sprintf("must be at most %d bytes", maxBytes))
+	}
+
+	var upper, lower, digit, symbol bool
+	for _, r := range pw {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r) && !unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+	if p.RequireUpper && !upper {
+		add("upper", "must contain an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		add("lower", "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		add("digit", "must contain a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		add("symbol", "must contain a symbol")
+	}
+
+	if p.blocked(pw) {
+		add("common", "is too common")
+	}
+	lpw := strings.ToLower(pw)
+	for _, s := range personal {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if at := strings.IndexByte(s, '@'); at >= 0 {
+			s = s[:at] // เอาแค่ส่วนหน้าของ email
+		}
+		if len([]rune(s)) >= 3 && strings.Contains(lpw, s) {
+			add("personal_info", "must not contain your name, email or employee code")
+			break
+		}
+	}
+	return out
+}
+
+// Check ตรวจทุกกฎรวมถึงห้ามใช้รหัสเดิม/รหัสเก่า; ไม่ผ่านคืน *PolicyError
+func (p Policy) Check(ctx context.Context, store HistoryStore, sub Subject, pw string) error {
+	violations := p.Validate(pw, sub.Personal...)
+
+	hashes := []string{}
+	if sub.CurrentHash != "" {
+		hashes = append(hashes, sub.CurrentHash)
+	}
+	if sub.UserID != "" && p.History > 0 && store != nil {
+		old, err := store.RecentPasswordHashes(ctx, sub.UserID, p.History)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, old...)
+	}
+	for _, h := range hashes {
+		if Verify(h, pw) {
+			violations = append(violations, Violation{
+				Rule:    "reused",
+				Message: "must not match your current or a recently used password",
+			})
+			break
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// Expired รหัสที่ตั้งเมื่อ changedAt เกิน MaxAge แล้วหรือยัง
+func (p Policy) Expired(changedAt, now time.Time) bool {
+	return p.MaxAge > 0 && !changedAt.IsZero() && now.Sub(changedAt) > p.MaxAge
+}
+
+func envInt(key string, def int) int {
+	n, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+func envBool(key string) bool {
+	b, _ := strconv.ParseBool(os.Getenv(key))
+	return b
+}
@@ -30,3 +30,8 @@ func OK(ctx *fiber.Ctx, data interface{}) error {
 func Err(ctx *fiber.Ctx, status int, code, msg string) error {
 	return JSON(ctx, status, code, msg, nil)
 }
+
+// Invalid ตอบ 422 พร้อมรายละเอียดรายฟิลด์ (field -> ปัญหาของฟิลด์นั้น)
+func Invalid(ctx *fiber.Ctx, code, msg string, fields map[string]interface{}) error {
+	return JSON(ctx, fiber.StatusUnprocessableEntity, code, msg, fiber.Map{"errors": fields})
+}
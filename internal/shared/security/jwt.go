@@ -1,41 +1,66 @@
 package security
 
 import (
-	"os"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// typ header ของ access token (RFC 9068) กันเอา token ชนิดอื่นมาใช้แทน
+const accessTokenType = "at+jwt"
+
 type AccessClaims struct {
 	UserID string `json:"uid"`
 	Role   string `json:"role"`
 	Emp    string `json:"emp_code"`
+	Ver    int    `json:"ver"` // token version ของ user ตอนออก token
+
+	// PwdChange รหัสหมดอายุ/ถูกบังคับเปลี่ยน: ใช้ได้แค่ route เปลี่ยนรหัส
+	PwdChange bool `json:"pwd_chg,omitempty"`
+
+	// impersonation: uid = user ที่ถูกสวมรอย, Act = คนที่ใช้ token จริง (RFC 8693)
+	Act      *Actor `json:"act,omitempty"`
+	ReadOnly bool   `json:"ro,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// Actor คนที่ใช้ token แทน user อื่น
+type Actor struct {
+	Sub string `json:"sub"`
+	Emp string `json:"emp_code"`
+	Ver int    `json:"ver"` // token version ของ actor (ปิด account/ลด role แล้วใช้ต่อไม่ได้)
+}
+
 type RefreshClaims struct {
 	UserID string `json:"uid"`
 	JTI    string `json:"jti"`
 	jwt.RegisteredClaims
 }
 
-func SignAccess(uid, role, emp string, ttl time.Duration) (string, error) {
-	secret := []byte(os.Getenv("JWT_ACCESS_SECRET"))
+// SignAccess เซ็นด้วย active key (RS256/EdDSA) ถ้ามี JWT_KEYS_DIR ไม่งั้นใช้ HS256 + JWT_ACCESS_SECRET
+func SignAccess(uid, role, emp string, ver int, ttl time.Duration) (string, error) {
+	return SignAccessClaims(AccessClaims{UserID: uid, Role: role, Emp: emp, Ver: ver}, ttl)
+}
+
+// SignAccessClaims เหมือน SignAccess แต่ให้ผู้เรียกกำหนด claim เพิ่มเติมเอง (iat/exp ตั้งให้)
+func SignAccessClaims(claims AccessClaims, ttl time.Duration) (string, error) {
 	now := time.Now()
-	claims := AccessClaims{
-		UserID: uid, Role: role, Emp: emp,
-		RegisteredClaims: jwt.RegisteredClaims{
-			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
-		},
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	kr := currentRing()
+	if kr.active == nil {
+		t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		return t.SignedString(kr.accessSecret)
 	}
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString(secret)
+	t := jwt.NewWithClaims(kr.active.method, claims)
+	t.Header["kid"] = kr.active.kid
+	t.Header["typ"] = accessTokenType
+	return t.SignedString(kr.active.priv)
 }
 
+// refresh token ใช้ภายใน service นี้เท่านั้น จึงยังเป็น HS256
 func SignRefresh(uid, jti string, ttl time.Duration) (string, error) {
-	secret := []byte(os.Getenv("JWT_REFRESH_SECRET"))
 	now := time.Now()
 	claims := RefreshClaims{
 		UserID: uid, JTI: jti,
@@ -45,14 +70,31 @@ func SignRefresh(uid, jti string, ttl time.Duration) (string, error) {
 		},
 	}
 	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return t.SignedString(secret)
+	return t.SignedString(currentRing().refreshSecret)
 }
 
 func ParseAccess(tokenStr string) (*AccessClaims, error) {
-	secret := []byte(os.Getenv("JWT_ACCESS_SECRET"))
+	kr := currentRing()
 	tok, err := jwt.ParseWithClaims(tokenStr, &AccessClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return secret, nil
-	})
+		if kid, _ := t.Header["kid"].(string); kid != "" {
+			k, ok := kr.keys[kid]
+			if !ok {
+				return nil, errors.New("unknown kid")
+			}
+			if t.Method.Alg() != k.method.Alg() {
+				return nil, errors.New("unexpected signing method")
+			}
+			if typ, _ := t.Header["typ"].(string); typ != accessTokenType {
+				return nil, errors.New("unexpected token type")
+			}
+			return k.pub, nil
+		}
+		// ไม่มี kid = token HS256: ใช้ได้เมื่อยังไม่มี key directory หรืออยู่ในช่วง JWT_LEGACY_HS256_UNTIL
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok || !kr.acceptsLegacyHS256(time.Now()) {
+			return nil, errors.New("unexpected signing method")
+		}
+		return kr.accessSecret, nil
+	}, jwt.WithValidMethods([]string{"RS256", "EdDSA", "HS256"}))
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +105,13 @@ func ParseAccess(tokenStr string) (*AccessClaims, error) {
 }
 
 func ParseRefresh(tokenStr string) (*RefreshClaims, error) {
-	secret := []byte(os.Getenv("JWT_REFRESH_SECRET"))
+	secret := currentRing().refreshSecret
 	tok, err := jwt.ParseWithClaims(tokenStr, &RefreshClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if typ, _ := t.Header["typ"].(string); typ == mfaTokenType { // เซ็นด้วย secret เดียวกัน
+			return nil, errors.New("unexpected token type")
+		}
 		return secret, nil
-	})
+	}, jwt.WithValidMethods([]string{"HS256"}))
 	if err != nil {
 		return nil, err
 	}
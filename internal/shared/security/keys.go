@@ -0,0 +1,269 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// signingKey หนึ่งคู่ใน key directory; kid = ชื่อไฟล์ (ไม่รวม .pem)
+type signingKey struct {
+	kid    string
+	method jwt.SigningMethod
+	priv   crypto.PrivateKey // nil = ใช้ verify อย่างเดียว (key ที่ปลดระวางแล้ว)
+	pub    crypto.PublicKey
+}
+
+// keyRing ชุด key ที่ใช้อยู่ตอนนี้; ถ้าไม่มี key directory จะใช้ HS256 + secret เดิม
+type keyRing struct {
+	active        *signingKey
+	keys          map[string]*signingKey
+	accessSecret  []byte
+	refreshSecret []byte
+	// legacyUntil มี key directory แล้ว ยังรับ access token HS256 (ไม่มี kid) ได้ถึงเวลานี้เท่านั้น
+	// zero = ไม่รับเลย (secret เดิมจะได้ไม่เป็นช่องปลอม token ไปตลอด)
+	legacyUntil time.Time
+}
+
+// acceptsLegacyHS256 ใช้ HS256 อยู่ (ไม่มี key directory) หรืออยู่ในช่วงเปลี่ยนผ่านที่ตั้งไว้
+func (kr *keyRing) acceptsLegacyHS256(now time.Time) bool {
+	if len(kr.accessSecret) == 0 {
+		return false
+	}
+	return kr.active == nil || now.Before(kr.legacyUntil)
+}
+
+var (
+	ring     atomic.Pointer[keyRing]
+	ringOnce sync.Once
+)
+
+// InitKeys โหลด key จาก env และ (ถ้ามี JWT_KEYS_DIR) reload เป็นระยะเพื่อ rotate โดยไม่ต้อง restart
+//
+//	JWT_KEYS_DIR        โฟลเดอร์ *.pem (private = ใช้เซ็นได้, *.pub.pem = verify อย่างเดียว)
+//	JWT_ACTIVE_KID      kid ที่ใช้เซ็น (default: private key ที่ชื่อมากสุดตามลำดับตัวอักษร)
+//	JWT_KEYS_RELOAD     ระยะ reload (default 1m, 0 = ไม่ reload)
+//	JWT_LEGACY_HS256_UNTIL  (RFC3339) ยังรับ access token HS256 เดิมถึงเวลานี้ ตั้งเป็นเวลาที่เปลี่ยน + อายุ access token
+func InitKeys() error {
+	kr, err := loadRing()
+	if err != nil {
+		return err
+	}
+	ring.Store(kr)
+	ringOnce.Do(func() {})
+
+	dir := os.Getenv("JWT_KEYS_DIR")
+	every, err := time.ParseDuration(os.Getenv("JWT_KEYS_RELOAD"))
+	if err != nil {
+		every = time.Minute
+	}
+	if dir != "" && every > 0 {
+		go func() {
+			for range time.Tick(every) {
+				kr, err := loadRing()
+				if err != nil {
+					log.Printf("jwt keys: reload %s: %v (keeping previous keys)", dir, err)
+					continue
+				}
+				ring.Store(kr)
+			}
+		}()
+	}
+	return nil
+}
+
+func currentRing() *keyRing {
+	ringOnce.Do(func() {
+		kr, err := loadRing()
+		if err != nil {
+			log.Printf("jwt keys: %v (falling back to HS256)", err)
+			kr = &keyRing{
+				keys:          map[string]*signingKey{},
+				accessSecret:  []byte(os.Getenv("JWT_ACCESS_SECRET")),
+				refreshSecret: []byte(os.Getenv("JWT_REFRESH_SECRET")),
+			}
+		}
+		ring.Store(kr)
+	})
+	return ring.Load()
+}
+
+func loadRing() (*keyRing, error) {
+	kr := &keyRing{
+		keys:          map[string]*signingKey{},
+		accessSecret:  []byte(os.Getenv("JWT_ACCESS_SECRET")),
+		refreshSecret: []byte(os.Getenv("JWT_REFRESH_SECRET")),
+	}
+	dir := os.Getenv("JWT_KEYS_DIR")
+	if dir == "" {
+		return kr, nil
+	}
+
+	if v := os.Getenv("JWT_LEGACY_HS256_UNTIL"); v != "" {
+		until, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("JWT_LEGACY_HS256_UNTIL: %w", err)
+		}
+		kr.legacyUntil = until
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	for _, f := range files {
+		k, err := loadKeyFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Base(f), err)
+		}
+		// <kid>.pem กับ <kid>.pub.pem อยู่ด้วยกันได้ แต่ต้องเป็นคู่เดียวกัน และ private key ต้องไม่ถูกทับ
+		if prev, ok := kr.keys[k.kid]; ok {
+			if !samePublicKey(prev.pub, k.pub) {
+				return nil, fmt.Errorf("kid %q: %s does not match the other key file", k.kid, filepath.Base(f))
+			}
+			if prev.priv != nil && k.priv != nil {
+				return nil, fmt.Errorf("kid %q: more than one private key", k.kid)
+			}
+			if k.priv == nil {
+				continue
+			}
+		}
+		kr.keys[k.kid] = k
+		if k.priv != nil && (kr.active == nil || k.kid > kr.active.kid) {
+			kr.active = k // ชื่อมากสุดชนะ ถ้าไม่ได้ระบุ JWT_ACTIVE_KID
+		}
+	}
+	if kid := os.Getenv("JWT_ACTIVE_KID"); kid != "" {
+		k, ok := kr.keys[kid]
+		if !ok || k.priv == nil {
+			return nil, fmt.Errorf("JWT_ACTIVE_KID %q: no private key in %s", kid, dir)
+		}
+		kr.active = k
+	}
+	if kr.active == nil {
+		return nil, fmt.Errorf("no private key in %s", dir)
+	}
+	return kr, nil
+}
+
+func samePublicKey(a, b crypto.PublicKey) bool {
+	eq, ok := a.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && eq.Equal(b)
+}
+
+func loadKeyFile(path string) (*signingKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("not a PEM file")
+	}
+	name := filepath.Base(path)
+	k := &signingKey{kid: strings.TrimSuffix(strings.TrimSuffix(name, ".pem"), ".pub")}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		priv, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.priv, k.pub = priv, &priv.PublicKey
+	case "PRIVATE KEY":
+		priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		switch p := priv.(type) {
+		case *rsa.PrivateKey:
+			k.priv, k.pub = p, &p.PublicKey
+		case ed25519.PrivateKey:
+			k.priv, k.pub = p, p.Public()
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T", priv)
+		}
+	case "PUBLIC KEY":
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		k.pub = pub
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+
+	switch p := k.pub.(type) {
+	case *rsa.PublicKey:
+		if p.N.BitLen() < 2048 {
+			return nil, errors.New("RSA key must be at least 2048 bits")
+		}
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", k.pub)
+	}
+	return k, nil
+}
+
+// JWK / JWKS ตาม RFC 7517 (เฉพาะ public key)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS คืน public key ทั้งหมด (รวมที่ปลดระวางแล้วแต่ยังต้อง verify) ให้ service อื่นใช้ตรวจ access token
+func PublicJWKS() JWKS {
+	kr := currentRing()
+	out := JWKS{Keys: []JWK{}}
+	kids := make([]string, 0, len(kr.keys))
+	for kid := range kr.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+	b64 := base64.RawURLEncoding
+	for _, kid := range kids {
+		k := kr.keys[kid]
+		j := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+		switch p := k.pub.(type) {
+		case *rsa.PublicKey:
+			j.Kty = "RSA"
+			j.N = b64.EncodeToString(p.N.Bytes())
+			j.E = b64.EncodeToString(big.NewInt(int64(p.E)).Bytes())
+		case ed25519.PublicKey:
+			j.Kty = "OKP"
+			j.Crv = "Ed25519"
+			j.X = b64.EncodeToString(p)
+		}
+		out.Keys = append(out.Keys, j)
+	}
+	return out
+}
@@ -0,0 +1,114 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func writeKeyPair(t *testing.T, dir, kid string, withPub bool) ed25519.PublicKey {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, _ := x509.MarshalPKCS8PrivateKey(priv)
+	writePEM(t, filepath.Join(dir, kid+".pem"), "PRIVATE KEY", der)
+	if withPub {
+		der, _ := x509.MarshalPKIXPublicKey(pub)
+		writePEM(t, filepath.Join(dir, kid+".pub.pem"), "PUBLIC KEY", der)
+	}
+	return pub
+}
+
+func writePEM(t *testing.T, path, typ string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadRingKeepsPrivateKeyWhenPubFileExists(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, "2026-01", true) // 2026-01.pub.pem เรียงหลัง 2026-01.pem
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACTIVE_KID", "")
+
+	kr, err := loadRing()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kr.active == nil || kr.active.kid != "2026-01" || kr.keys["2026-01"].priv == nil {
+		t.Fatalf("private key was lost: active=%+v", kr.active)
+	}
+}
+
+func TestLoadRingRejectsMismatchedKid(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, "k1", false)
+	other, _, _ := ed25519.GenerateKey(rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(other)
+	writePEM(t, filepath.Join(dir, "k1.pub.pem"), "PUBLIC KEY", der)
+	t.Setenv("JWT_KEYS_DIR", dir)
+
+	if _, err := loadRing(); err == nil {
+		t.Fatal("want error for k1.pub.pem that does not match k1.pem")
+	}
+}
+
+func TestParseAccessLegacyHS256(t *testing.T) {
+	dir := t.TempDir()
+	writeKeyPair(t, dir, "k1", false)
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_ACCESS_SECRET", "old-shared-secret")
+
+	hs := func() string {
+		tok := jwt.NewWithClaims(jwt.SigningMethodHS256, AccessClaims{UserID: "u1",
+			RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}})
+		s, err := tok.SignedString([]byte("old-shared-secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	use := func(until string) {
+		t.Helper()
+		t.Setenv("JWT_LEGACY_HS256_UNTIL", until)
+		kr, err := loadRing()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ringOnce.Do(func() {})
+		ring.Store(kr)
+	}
+	defer ring.Store(&keyRing{keys: map[string]*signingKey{}})
+
+	use("")
+	if _, err := ParseAccess(hs()); err == nil {
+		t.Fatal("HS256 token accepted with a key ring and no legacy window")
+	}
+	use(time.Now().Add(-time.Minute).Format(time.RFC3339))
+	if _, err := ParseAccess(hs()); err == nil {
+		t.Fatal("HS256 token accepted after JWT_LEGACY_HS256_UNTIL")
+	}
+	use(time.Now().Add(time.Hour).Format(time.RFC3339))
+	if _, err := ParseAccess(hs()); err != nil {
+		t.Fatalf("HS256 token rejected inside legacy window: %v", err)
+	}
+
+	// token ที่เซ็นด้วย key ring ยังใช้ได้ตามปกติ
+	signed, err := SignAccess("u1", "admin", "E1", 0, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := ParseAccess(signed); err != nil || c.UserID != "u1" {
+		t.Fatalf("ParseAccess(signed) = %v, %v", c, err)
+	}
+}
@@ -0,0 +1,50 @@
+package security
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// typ ของ MFA challenge token — ใช้แทน access/refresh ไม่ได้
+const mfaTokenType = "mfa+jwt"
+
+// MFAClaims token อายุสั้นที่ได้หลังรหัสผ่านถูก ใช้ยืนยันขั้นที่สองเท่านั้น
+type MFAClaims struct {
+	UserID string `json:"uid"`
+	Ver    int    `json:"ver"`
+	Enroll bool   `json:"enroll,omitempty"` // true = ต้องลงทะเบียน MFA ก่อน (policy บังคับ)
+	jwt.RegisteredClaims
+}
+
+func SignMFAChallenge(uid string, ver int, enroll bool, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := MFAClaims{
+		UserID: uid, Ver: ver, Enroll: enroll,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	t.Header["typ"] = mfaTokenType
+	return t.SignedString(currentRing().refreshSecret)
+}
+
+func ParseMFAChallenge(tokenStr string) (*MFAClaims, error) {
+	secret := currentRing().refreshSecret
+	tok, err := jwt.ParseWithClaims(tokenStr, &MFAClaims{}, func(t *jwt.Token) (interface{}, error) {
+		if typ, _ := t.Header["typ"].(string); typ != mfaTokenType {
+			return nil, errors.New("unexpected token type")
+		}
+		return secret, nil
+	}, jwt.WithValidMethods([]string{"HS256"}))
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := tok.Claims.(*MFAClaims); ok && tok.Valid {
+		return c, nil
+	}
+	return nil, jwt.ErrTokenInvalidClaims
+}
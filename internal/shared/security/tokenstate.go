@@ -0,0 +1,98 @@
+package security
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenState สถานะปัจจุบันของ user ที่ใช้ตัดสินว่า access token ที่ออกไปแล้วยังใช้ได้ไหม
+type TokenState struct {
+	Version int
+	Active  bool
+}
+
+// TokenStateLookup อ่านสถานะจาก DB (ตั้งตอน DI เพื่อไม่ให้ shared ต้อง import module)
+type TokenStateLookup func(ctx context.Context, userID string) (TokenState, error)
+
+type cachedState struct {
+	state   TokenState
+	expires time.Time
+}
+
+// maxTokenStates ขนาดสูงสุดของ cache (กันโตไม่หยุดเมื่อมี user login มากๆ)
+const maxTokenStates = 50000
+
+var tokenStates = struct {
+	sync.Mutex
+	lookup    TokenStateLookup
+	ttl       time.Duration
+	items     map[string]cachedState
+	lastSweep time.Time
+}{items: map[string]cachedState{}}
+
+// SetTokenStateLookup เปิดการเช็ค token version; ttl คืออายุ cache ต่อ user
+// (instance อื่นจะเห็นการเปลี่ยนแปลงช้าสุดไม่เกิน ttl)
+func SetTokenStateLookup(f TokenStateLookup, ttl time.Duration) {
+	tokenStates.Lock()
+	defer tokenStates.Unlock()
+	tokenStates.lookup = f
+	tokenStates.ttl = ttl
+	tokenStates.items = map[string]cachedState{}
+}
+
+// CurrentTokenState คืน ok=false ถ้ายังไม่ได้ตั้ง lookup (ไม่เช็ค)
+func CurrentTokenState(ctx context.Context, userID string) (st TokenState, ok bool, err error) {
+	tokenStates.Lock()
+	f, ttl := tokenStates.lookup, tokenStates.ttl
+	if f == nil {
+		tokenStates.Unlock()
+		return TokenState{}, false, nil
+	}
+	if c, hit := tokenStates.items[userID]; hit && time.Now().Before(c.expires) {
+		tokenStates.Unlock()
+		return c.state, true, nil
+	}
+	tokenStates.Unlock()
+
+	st, err = f(ctx, userID)
+	if err != nil {
+		return TokenState{}, true, err
+	}
+	if ttl > 0 {
+		now := time.Now()
+		tokenStates.Lock()
+		pruneTokenStatesLocked(now)
+		tokenStates.items[userID] = cachedState{state: st, expires: now.Add(ttl)}
+		tokenStates.Unlock()
+	}
+	return st, true, nil
+}
+
+// InvalidateTokenState ล้าง cache ของ user (เรียกหลัง bump version / ปิด account)
+func InvalidateTokenState(userID string) {
+	tokenStates.Lock()
+	delete(tokenStates.items, userID)
+	tokenStates.Unlock()
+}
+
+// pruneTokenStatesLocked ลบตัวที่หมดอายุทุกๆ ttl (หรือเมื่อเต็ม); ถ้ายังเต็มอยู่ทิ้งตัวไหนก็ได้
+// (cache หายแค่ทำให้ต้องอ่าน DB ใหม่ ไม่กระทบความถูกต้อง) ต้องถือ lock อยู่
+func pruneTokenStatesLocked(now time.Time) {
+	full := len(tokenStates.items) >= maxTokenStates
+	if !full && now.Sub(tokenStates.lastSweep) < tokenStates.ttl {
+		return
+	}
+	for id, c := range tokenStates.items {
+		if !now.Before(c.expires) {
+			delete(tokenStates.items, id)
+		}
+	}
+	tokenStates.lastSweep = now
+	for id := range tokenStates.items {
+		if len(tokenStates.items) < maxTokenStates {
+			break
+		}
+		delete(tokenStates.items, id)
+	}
+}
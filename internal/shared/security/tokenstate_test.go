@@ -0,0 +1,57 @@
+package security
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTokenStateCacheIsPruned(t *testing.T) {
+	calls := 0
+	SetTokenStateLookup(func(context.Context, string) (TokenState, error) {
+		calls++
+		return TokenState{Version: 1, Active: true}, nil
+	}, 20*time.Millisecond)
+	defer SetTokenStateLookup(nil, 0)
+
+	ctx := context.Background()
+	for i := 0; i < 100; i++ {
+		if _, _, err := CurrentTokenState(ctx, "u"+strconv.Itoa(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, _ = CurrentTokenState(ctx, "u0"); calls != 100 {
+		t.Fatalf("cache miss within ttl: %d lookups", calls)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	// insert หลังครบ ttl กวาดตัวที่หมดอายุทิ้ง
+	if _, _, err := CurrentTokenState(ctx, "fresh"); err != nil {
+		t.Fatal(err)
+	}
+	tokenStates.Lock()
+	n := len(tokenStates.items)
+	tokenStates.Unlock()
+	if n != 1 {
+		t.Fatalf("cache size after sweep = %d, want 1", n)
+	}
+}
+
+func TestTokenStateCacheIsBounded(t *testing.T) {
+	SetTokenStateLookup(func(context.Context, string) (TokenState, error) {
+		return TokenState{Active: true}, nil
+	}, time.Hour)
+	defer SetTokenStateLookup(nil, 0)
+
+	ctx := context.Background()
+	for i := 0; i < maxTokenStates+10; i++ {
+		_, _, _ = CurrentTokenState(ctx, strconv.Itoa(i))
+	}
+	tokenStates.Lock()
+	n := len(tokenStates.items)
+	tokenStates.Unlock()
+	if n > maxTokenStates {
+		t.Fatalf("cache size = %d, want <= %d", n, maxTokenStates)
+	}
+}